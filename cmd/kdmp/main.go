@@ -5,11 +5,16 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/portworx/kdmp/pkg/apis"
+	"github.com/portworx/kdmp/pkg/controllers"
 	"github.com/portworx/kdmp/pkg/controllers/dataexport"
+	"github.com/portworx/kdmp/pkg/controllers/resourceexport"
+	"github.com/portworx/kdmp/pkg/controllers/retention"
+	"github.com/portworx/kdmp/pkg/metrics"
 	"github.com/portworx/kdmp/pkg/version"
 	log "github.com/sirupsen/logrus"
 	"github.com/urfave/cli"
@@ -23,8 +28,49 @@ const (
 	defaultLockLease           = 15 * time.Second
 	defaultLockRenew           = 10 * time.Second
 	defaultLockRetry           = 2 * time.Second
+	defaultMetricsBindAddress  = ":8080"
+	defaultHealthProbeAddress  = ":8081"
 )
 
+// kdmpController is a controller run by the operator.
+type kdmpController interface {
+	Init(mgr manager.Manager) error
+	Probe() *controllers.Probe
+}
+
+// controllerEntry is a controller which can be enabled with --enable-controllers.
+type controllerEntry struct {
+	name string
+	// enabled tells if the controller is started when --enable-controllers
+	// is not set.
+	enabled bool
+	new     func(mgr manager.Manager, opts ...controllers.Option) (kdmpController, error)
+}
+
+var kdmpControllers = []controllerEntry{
+	{
+		name:    "dataexport",
+		enabled: true,
+		new: func(mgr manager.Manager, opts ...controllers.Option) (kdmpController, error) {
+			return dataexport.NewController(mgr, opts...)
+		},
+	},
+	{
+		// disabled by default as stork runs its own copy of the controller
+		name: "resourceexport",
+		new: func(mgr manager.Manager, opts ...controllers.Option) (kdmpController, error) {
+			return resourceexport.NewController(mgr, opts...)
+		},
+	},
+	{
+		name:    "volumebackupretention",
+		enabled: true,
+		new: func(mgr manager.Manager, opts ...controllers.Option) (kdmpController, error) {
+			return retention.NewController(mgr, opts...)
+		},
+	},
+}
+
 func main() {
 	// TODO: review klog config
 
@@ -53,11 +99,33 @@ func main() {
 			Usage: "Namespace for the lock object",
 			Value: defaultLockObjectNamespace,
 		},
+		cli.StringFlag{
+			Name:  "metrics-bind-address",
+			Usage: "Address the metrics endpoint binds to, \"0\" disables it",
+			Value: defaultMetricsBindAddress,
+		},
+		cli.StringFlag{
+			Name:  "health-probe-bind-address",
+			Usage: "Address the health and readiness probes bind to, \"0\" disables them",
+			Value: defaultHealthProbeAddress,
+		},
 		cli.StringSliceFlag{
 			Name:  "enable-controllers",
-			Usage: "Enable provided custom controllers",
+			Usage: fmt.Sprintf("Enable provided custom controllers: %s (default: %s)", strings.Join(controllerNames(false), ", "), strings.Join(controllerNames(true), ", ")),
 		},
 	}
+	for _, entry := range kdmpControllers {
+		app.Flags = append(app.Flags,
+			cli.IntFlag{
+				Name:  entry.name + "-workers",
+				Usage: fmt.Sprintf("Number of resources reconciled concurrently by the %s controller", entry.name),
+			},
+			cli.DurationFlag{
+				Name:  entry.name + "-resync-period",
+				Usage: fmt.Sprintf("Period at which the %s controller reconciles a resource again", entry.name),
+			},
+		)
+	}
 
 	if err := app.Run(os.Args); err != nil {
 		log.Fatalf("Error starting kdmp: %v", err)
@@ -70,7 +138,10 @@ func run(c *cli.Context) {
 	v := version.Get()
 	log.Infof("Starting kdmp: %s, build date %s", v.String(), v.BuildDate)
 
-	mgrOpts := manager.Options{}
+	mgrOpts := manager.Options{
+		MetricsBindAddress:     c.String("metrics-bind-address"),
+		HealthProbeBindAddress: c.String("health-probe-bind-address"),
+	}
 	if c.BoolT("leader-elect") {
 		mgrOpts.LeaderElection = true
 		mgrOpts.LeaderElectionID = c.String("lock-object-name")
@@ -96,13 +167,13 @@ func run(c *cli.Context) {
 		log.Fatalf("Setup scheme for kdmp resources: %v", err)
 	}
 
-	if err := runApp(mgr); err != nil {
+	if err := runApp(c, mgr); err != nil {
 		log.Fatalf("Controller manager: %v", err)
 	}
 	os.Exit(0)
 }
 
-func runApp(mgr manager.Manager) error {
+func runApp(c *cli.Context, mgr manager.Manager) error {
 	signalChan := make(chan os.Signal, 1)
 	signal.Notify(signalChan, syscall.SIGINT, syscall.SIGTERM)
 
@@ -114,18 +185,81 @@ func runApp(mgr manager.Manager) error {
 		}
 	}()
 
-	de, err := dataexport.NewController(mgr)
+	entries, err := enabledControllers(c.StringSlice("enable-controllers"))
 	if err != nil {
-		return fmt.Errorf("build DataExport controller: %s", err)
+		return err
 	}
-	if err = de.Init(mgr); err != nil {
-		return fmt.Errorf("init DataExport controller: %s", err)
+	for _, entry := range entries {
+		var opts []controllers.Option
+		if c.IsSet(entry.name + "-workers") {
+			opts = append(opts, controllers.WithWorkers(c.Int(entry.name+"-workers")))
+		}
+		if c.IsSet(entry.name + "-resync-period") {
+			opts = append(opts, controllers.WithResyncPeriod(c.Duration(entry.name+"-resync-period")))
+		}
+		ctrl, err := entry.new(mgr, opts...)
+		if err != nil {
+			return fmt.Errorf("build %s controller: %s", entry.name, err)
+		}
+		if err = ctrl.Init(mgr); err != nil {
+			return fmt.Errorf("init %s controller: %s", entry.name, err)
+		}
+		if err = mgr.AddHealthzCheck(entry.name, ctrl.Probe().Healthz); err != nil {
+			return fmt.Errorf("add %s controller health probe: %s", entry.name, err)
+		}
+		if err = mgr.AddReadyzCheck(entry.name, ctrl.Probe().Readyz); err != nil {
+			return fmt.Errorf("add %s controller readiness probe: %s", entry.name, err)
+		}
+		log.Infof("Enabled %s controller", entry.name)
+	}
+
+	if err = mgr.Add(metrics.NewRefresher(mgr.GetCache())); err != nil {
+		return fmt.Errorf("add metrics refresher: %s", err)
 	}
 
 	log.Info("Starting controller manager")
 	return mgr.Start(context.Background())
 }
 
+// enabledControllers returns the controllers named by --enable-controllers,
+// the default ones if names is empty.
+func enabledControllers(names []string) ([]controllerEntry, error) {
+	if len(names) == 0 {
+		names = controllerNames(true)
+	}
+	enabled := make(map[string]bool)
+	for _, name := range names {
+		for _, n := range strings.Split(name, ",") {
+			if n = strings.TrimSpace(n); n != "" {
+				enabled[n] = true
+			}
+		}
+	}
+	var entries []controllerEntry
+	for _, entry := range kdmpControllers {
+		if enabled[entry.name] {
+			entries = append(entries, entry)
+			delete(enabled, entry.name)
+		}
+	}
+	for name := range enabled {
+		return nil, fmt.Errorf("unknown controller %q, supported controllers: %s", name, strings.Join(controllerNames(false), ", "))
+	}
+	return entries, nil
+}
+
+// controllerNames returns the names of the controllers, only the default
+// ones if enabledOnly is set.
+func controllerNames(enabledOnly bool) []string {
+	var names []string
+	for _, entry := range kdmpControllers {
+		if !enabledOnly || entry.enabled {
+			names = append(names, entry.name)
+		}
+	}
+	return names
+}
+
 func durationPtr(in time.Duration) *time.Duration {
 	return &in
 }
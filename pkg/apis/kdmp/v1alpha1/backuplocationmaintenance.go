@@ -45,6 +45,55 @@ type BackupLocationMaintenanceSpec struct {
 type BackupLocationMaintenanceStatus struct {
 	FullMaintenanceRepoStatus  map[string]RepoMaintenanceStatus
 	QuickMaintenanceRepoStatus map[string]RepoMaintenanceStatus
+	// KeyRotationRepoStatus is the status of the data key rotation of the
+	// repositories, for the BackupLocations with a KMS.
+	KeyRotationRepoStatus map[string]RepoMaintenanceStatus
+	// OrphanGCStatus is the status of the last garbage collection of the
+	// orphaned repositories and snapshots.
+	OrphanGCStatus *OrphanGCStatus
+}
+
+// OrphanGCStatus reports the repositories and snapshots of a backuplocation
+// which are not referenced by any VolumeBackup or ApplicationBackup.
+type OrphanGCStatus struct {
+	// LastRunTimestamp - last garbage collection run timestamp
+	LastRunTimestamp metav1.Time `json:"lastRunTimestamp"`
+	// Status - last garbage collection run status
+	Status RepoMaintenanceStatusType `json:"status"`
+	// Reason - Failure reason. In case of success it will be empty.
+	Reason string `json:"reason"`
+	// DeleteEnabled is whether the orphans older than the grace period are
+	// deleted, they are only reported otherwise.
+	DeleteEnabled bool `json:"deleteEnabled"`
+	// GracePeriod is the age under which an orphan isn't deleted.
+	GracePeriod metav1.Duration `json:"gracePeriod,omitempty"`
+	// Repositories are the repositories with orphaned snapshots.
+	Repositories []OrphanedRepository `json:"repositories,omitempty"`
+}
+
+// OrphanedRepository is a repository with orphaned snapshots.
+type OrphanedRepository struct {
+	// Repository is the path of the repository in the backuplocation.
+	Repository string `json:"repository"`
+	// Orphaned is true when the repository itself isn't referenced, it is
+	// deleted with its last snapshot.
+	Orphaned bool `json:"orphaned"`
+	// Deleted is true when the repository was deleted.
+	Deleted bool `json:"deleted"`
+	// Snapshots are the orphaned snapshots of the repository.
+	Snapshots []OrphanedSnapshot `json:"snapshots,omitempty"`
+	// Reason is the failure reason of the deletion of the orphans.
+	Reason string `json:"reason,omitempty"`
+}
+
+// OrphanedSnapshot is a snapshot not referenced by any backup.
+type OrphanedSnapshot struct {
+	// ID is the kopia snapshot ID.
+	ID string `json:"id"`
+	// StartTime is the start time of the snapshot.
+	StartTime metav1.Time `json:"startTime"`
+	// Deleted is true when the snapshot was deleted.
+	Deleted bool `json:"deleted"`
 }
 
 // RepoMaintenanceStatusType is the status of the repository maintenance run.
@@ -42,6 +42,66 @@ const (
 	DataExportStatusFailed DataExportStatus = "Failed"
 	// DataExportStatusSuccessful when data has been transferred.
 	DataExportStatusSuccessful DataExportStatus = "Successful"
+	// DataExportStatusPaused when data export is paused by the Pause action.
+	DataExportStatusPaused DataExportStatus = "Paused"
+	// DataExportStatusCancelled when data export is cancelled by the Cancel action.
+	DataExportStatusCancelled DataExportStatus = "Cancelled"
+)
+
+// DataExportFailureClass classifies the failures of the data transfer jobs.
+type DataExportFailureClass string
+
+const (
+	// DataExportFailureTransient is a failure which is likely to go away, like a
+	// network error or an object store throttling or 5xx error. The transfer
+	// job is retried.
+	DataExportFailureTransient DataExportFailureClass = "Transient"
+	// DataExportFailureNode is a failure of the node of the transfer job or of
+	// its volume mounts.
+	DataExportFailureNode DataExportFailureClass = "Node"
+	// DataExportFailurePermanent is a failure which needs to be fixed, like
+	// invalid credentials or a corrupted repository.
+	DataExportFailurePermanent DataExportFailureClass = "Permanent"
+	// DataExportFailureUnknown is a failure which could not be classified.
+	DataExportFailureUnknown DataExportFailureClass = "Unknown"
+)
+
+// DataExportHookPhase defines when a hook is run.
+type DataExportHookPhase string
+
+const (
+	// DataExportHookPhasePre hooks are run before the snapshot of the volume
+	// is taken, or before the data transfer if there is no snapshot.
+	DataExportHookPhasePre DataExportHookPhase = "Pre"
+	// DataExportHookPhasePost hooks are run once the snapshot of the volume is
+	// taken, or once the data transfer is over if there is no snapshot.
+	DataExportHookPhasePost DataExportHookPhase = "Post"
+)
+
+// DataExportHookFailurePolicy defines what is done when a hook fails.
+type DataExportHookFailurePolicy string
+
+const (
+	// DataExportHookFailurePolicyFail fails the DataExport.
+	DataExportHookFailurePolicyFail DataExportHookFailurePolicy = "Fail"
+	// DataExportHookFailurePolicyContinue records the failure and goes on
+	// with the DataExport.
+	DataExportHookFailurePolicyContinue DataExportHookFailurePolicy = "Continue"
+)
+
+// DataExportActionType defines an action requested on an in-flight DataExport.
+type DataExportActionType string
+
+const (
+	// DataExportActionPause stops the data transfer job. The stages which are
+	// already started run up to the beginning of the next stage.
+	DataExportActionPause DataExportActionType = "Pause"
+	// DataExportActionResume restarts a paused DataExport from the stage it was
+	// paused in.
+	DataExportActionResume DataExportActionType = "Resume"
+	// DataExportActionCancel stops the data transfer and removes the resources
+	// created for it.
+	DataExportActionCancel DataExportActionType = "Cancel"
 )
 
 // DataExportStage defines different stages for DataExport when its Status changes
@@ -104,6 +164,58 @@ type DataExportSpec struct {
 	TriggeredFromNs string                    `json:"triggerFromNs,omitempty"`
 	Source          DataExportObjectReference `json:"source,omitempty"`
 	Destination     DataExportObjectReference `json:"destination,omitempty"`
+	// Action pauses, resumes or cancels an in-flight DataExport.
+	Action DataExportActionType `json:"action,omitempty"`
+	// FileRestore restores only the selected entries of the snapshot instead
+	// of the whole volume. Supported by the kopia restore only.
+	FileRestore *DataExportFileRestore `json:"fileRestore,omitempty"`
+	// Hooks are commands run in the application pods to make the backup
+	// application-consistent, like flushing and freezing a database.
+	Hooks *DataExportHooks `json:"hooks,omitempty"`
+}
+
+// DataExportHooks are the hooks run before and after the snapshot or the
+// data transfer.
+type DataExportHooks struct {
+	// Pre hooks are run in order, the post hooks are run even if one of them
+	// fails.
+	Pre []DataExportHook `json:"pre,omitempty"`
+	// Post hooks are run in order.
+	Post []DataExportHook `json:"post,omitempty"`
+}
+
+// DataExportHook is a command run in each of the running pods selected by
+// its pod selector.
+type DataExportHook struct {
+	// Name of the hook in the hook statuses.
+	Name string `json:"name"`
+	// Namespace of the pods, the namespace of the PVC if not set. Only the
+	// namespaces of the DataExport and of the PVC are allowed.
+	Namespace string `json:"namespace,omitempty"`
+	// PodSelector selects the pods of the hook.
+	PodSelector metav1.LabelSelector `json:"podSelector"`
+	// Container the command is run in, required if the pods have more than
+	// one container.
+	Container string `json:"container,omitempty"`
+	// Command is run without a shell.
+	Command []string `json:"command"`
+	// Timeout of the command in each pod, 30s if not set.
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+	// OnFailure is Fail if not set. A selector matching no running pod is a
+	// failure.
+	OnFailure DataExportHookFailurePolicy `json:"onFailure,omitempty"`
+}
+
+// DataExportFileRestore selects the entries of a snapshot to be restored.
+type DataExportFileRestore struct {
+	// IncludePaths are the paths or glob patterns, relative to the volume root,
+	// of the files and directories to be restored. A "*" matches within a
+	// path element, a "**" element matches any number of path elements and a
+	// pattern without "/" matches the entries of that name in any directory.
+	IncludePaths []string `json:"includePaths,omitempty"`
+	// TargetSubPath is the directory in the destination PVC the entries are
+	// restored into. The entries are restored in the PVC root if not set.
+	TargetSubPath string `json:"targetSubPath,omitempty"`
 }
 
 // DataExportObjectReference contains enough information to let you inspect the referred object.
@@ -136,6 +248,35 @@ type ExportStatus struct {
 	VolumeSnapshot       string                    `json:"volumeSnapshot,omitempty"`
 	RestorePVC           *v1.PersistentVolumeClaim `json:"restorePVC,omitempty"`
 	LocalSnapshotRestore bool                      `json:"localSnapshotRestore,omitempty"`
+	// QueuePosition is the position of the data transfer job in the job queue
+	// while it waits for a free job slot, starting at 1.
+	QueuePosition int `json:"queuePosition,omitempty"`
+	// FailureClass is the class of the last failure of the transfer job. It
+	// is cleared with RetryCount once the transfer job succeeds.
+	FailureClass DataExportFailureClass `json:"failureClass,omitempty"`
+	// RetryCount is the number of times the transfer job was retried after a
+	// transient failure.
+	RetryCount int `json:"retryCount,omitempty"`
+	// NextRetryTime is the time the transfer job is retried at, while it waits
+	// for its backoff.
+	NextRetryTime *metav1.Time `json:"nextRetryTime,omitempty"`
+	// Hooks are the outcomes of the hooks run so far.
+	Hooks []DataExportHookStatus `json:"hooks,omitempty"`
+	// Stats are the statistics of the backup, copied from its VolumeBackup.
+	Stats *TransferStats `json:"stats,omitempty"`
+}
+
+// DataExportHookStatus is the outcome of a hook in a pod.
+type DataExportHookStatus struct {
+	Name  string              `json:"name"`
+	Phase DataExportHookPhase `json:"phase"`
+	// Pod the command was run in, empty if no pod was selected.
+	Pod string `json:"pod,omitempty"`
+	// Status is Successful or Failed.
+	Status DataExportStatus `json:"status"`
+	// Reason is the error of a failed hook.
+	Reason         string      `json:"reason,omitempty"`
+	CompletionTime metav1.Time `json:"completionTime,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
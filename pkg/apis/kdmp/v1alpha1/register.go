@@ -36,6 +36,12 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 		&VolumeBackupList{},
 		&VolumeBackupDelete{},
 		&VolumeBackupDeleteList{},
+		&VolumeBackupRetention{},
+		&VolumeBackupRetentionList{},
+		&RestoreTransformation{},
+		&RestoreTransformationList{},
+		&RepositoryStatus{},
+		&RepositoryStatusList{},
 		&BackupLocationMaintenance{},
 		&BackupLocationMaintenanceList{},
 		&ResourceExport{},
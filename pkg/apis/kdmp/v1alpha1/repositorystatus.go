@@ -0,0 +1,84 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// RepositoryStatusResourceName is name for the RepositoryStatus resource.
+	RepositoryStatusResourceName = "repositorystatus"
+	// RepositoryStatusResourcePlural is the name for list of RepositoryStatus resources.
+	RepositoryStatusResourcePlural = "repositorystatuses"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// RepositoryStatus reports the health and the usage of a kopia repository of
+// a backuplocation. It is updated by the repository-status maintenance job of
+// the backuplocation, in the namespace of its BackupLocationMaintenance.
+type RepositoryStatus struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              RepositoryStatusSpec   `json:"spec"`
+	Status            RepositoryStatusStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// RepositoryStatusList is a list of RepositoryStatus resources.
+type RepositoryStatusList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []RepositoryStatus `json:"items"`
+}
+
+// RepositoryStatusSpec identifies the repository.
+type RepositoryStatusSpec struct {
+	// BackupLocationName is the name of the backuplocation of the repository.
+	BackupLocationName string `json:"backupLocationName,omitempty"`
+	// Repository is the path of the repository in the backuplocation.
+	Repository string `json:"repository"`
+}
+
+// RepositoryStatusStatus is the last known state of the repository.
+type RepositoryStatusStatus struct {
+	// Status is the status of the last update, the usage is the one of the
+	// last successful update.
+	Status RepoMaintenanceStatusType `json:"status,omitempty"`
+	// Reason is the failure reason of the last update.
+	Reason string `json:"reason,omitempty"`
+	// LastUpdateTimestamp is the time of the last update.
+	LastUpdateTimestamp metav1.Time `json:"lastUpdateTimestamp,omitempty"`
+	// Namespaces are the namespaces of the VolumeBackups of the repository.
+	Namespaces []string `json:"namespaces,omitempty"`
+	// Usage is the content and storage usage of the repository.
+	Usage *RepositoryUsage `json:"usage,omitempty"`
+}
+
+// RepositoryUsage is the content and storage usage of a repository.
+type RepositoryUsage struct {
+	// FormatVersion is the version of the repository format.
+	FormatVersion int `json:"formatVersion,omitempty"`
+	// SnapshotCount is the number of snapshots.
+	SnapshotCount int64 `json:"snapshotCount"`
+	// LogicalBytes is the total size of the snapshots.
+	LogicalBytes uint64 `json:"logicalBytes"`
+	// PhysicalBytes is the total size of the blobs in the backuplocation.
+	PhysicalBytes uint64 `json:"physicalBytes"`
+	// ContentCount is the number of contents, including the deleted ones not
+	// removed by the maintenance yet.
+	ContentCount int64 `json:"contentCount"`
+	// BlobCount is the number of blobs in the backuplocation.
+	BlobCount int64 `json:"blobCount"`
+	// OrphanedBlobCount is the number of pack blobs with no content, which are
+	// removed by the full maintenance.
+	OrphanedBlobCount int64 `json:"orphanedBlobCount"`
+	// OrphanedBlobBytes is the total size of the orphaned blobs.
+	OrphanedBlobBytes uint64 `json:"orphanedBlobBytes"`
+	// OldestSnapshotTime and NewestSnapshotTime are the start times of the
+	// oldest and newest snapshots.
+	OldestSnapshotTime *metav1.Time `json:"oldestSnapshotTime,omitempty"`
+	NewestSnapshotTime *metav1.Time `json:"newestSnapshotTime,omitempty"`
+}
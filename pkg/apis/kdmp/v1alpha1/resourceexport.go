@@ -12,6 +12,9 @@ const (
 	ResourceExportResourcePlural = "resourceexports"
 	// ResourceExportNFS resource export provided by nfs path
 	ResourceExportNFS ResourceExportType = "nfs"
+	// ResourceExportObjectStore resource export provided by the bucket of an
+	// object store backuplocation
+	ResourceExportObjectStore ResourceExportType = "objectstore"
 )
 
 // ResourceExportType defines a method of achieving Resource transfer.
@@ -33,11 +36,16 @@ type ObjectInfo struct {
 	metav1.GroupVersionKind `json:",inline"`
 }
 
+// ResourceRestorePlan is what a restore would do with a resource.
+type ResourceRestorePlan string
+
 // ResourceRestoreResourceInfo is the info for the restore of a resource
 type ResourceRestoreResourceInfo struct {
 	ObjectInfo `json:",inline"`
 	Status     ResourceRestoreStatus `json:"status"`
 	Reason     string                `json:"reason"`
+	// Plan is set instead of the status by dry-run restores
+	Plan ResourceRestorePlan `json:"plan,omitempty"`
 }
 
 const (
@@ -51,6 +59,18 @@ const (
 	ResourceRestoreStatusInProgress ResourceRestoreStatus = "InProgress"
 )
 
+const (
+	// ResourceRestorePlanCreate the resource would be created
+	ResourceRestorePlanCreate ResourceRestorePlan = "Create"
+	// ResourceRestorePlanReplace the existing resource would be deleted and
+	// created again
+	ResourceRestorePlanReplace ResourceRestorePlan = "Replace"
+	// ResourceRestorePlanSkipExisting the existing resource would be retained
+	ResourceRestorePlanSkipExisting ResourceRestorePlan = "SkipExisting"
+	// ResourceRestorePlanWouldFail the restore of the resource would fail
+	ResourceRestorePlanWouldFail ResourceRestorePlan = "WouldFail"
+)
+
 const (
 	// ResourceExportStatusInitial is the initial status of ResourceExport. It indicates
 	// that a volume export request has been received.
@@ -123,6 +143,9 @@ type ResourceExportSpec struct {
 	Source ResourceExportObjectReference `json:"source,omitempty"`
 	// Destination is the ref to BL CR
 	Destination ResourceExportObjectReference `json:"destination,omitempty"`
+	// DryRun restores only report in the status what they would do with each
+	// resource, without changing the cluster
+	DryRun bool `json:"dryRun,omitempty"`
 }
 
 // ResourceStatus overall resource backup/restore progress
@@ -0,0 +1,94 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// RestoreTransformationResourceName is name for the RestoreTransformation resource.
+	RestoreTransformationResourceName = "restoretransformation"
+	// RestoreTransformationResourcePlural is the name for list of RestoreTransformation resources.
+	RestoreTransformationResourcePlural = "restoretransformations"
+	// RestoreTransformationAnnotation is the annotation of the ApplicationRestores
+	// with the name of the RestoreTransformation, in the namespace of the
+	// restore, applied to the restored resources.
+	RestoreTransformationAnnotation = "kdmp.portworx.com/restore-transformation"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// RestoreTransformation is a set of rules rewriting the resources of a
+// backup before they are restored.
+type RestoreTransformation struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              RestoreTransformationSpec `json:"spec"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// RestoreTransformationList is a list of RestoreTransformation resources.
+type RestoreTransformationList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []RestoreTransformation `json:"items"`
+}
+
+// RestoreTransformationSpec defines the rules of a RestoreTransformation.
+type RestoreTransformationSpec struct {
+	// Rules are applied in order to the resources they select.
+	Rules []RestoreTransformationRule `json:"rules"`
+}
+
+// RestoreTransformationRule rewrites the resources matching its selector.
+type RestoreTransformationRule struct {
+	// Selector selects the resources of the rule, all of them if empty.
+	Selector RestoreTransformationSelector `json:"selector,omitempty"`
+	// StorageClassMapping maps the storage classes of the PVCs, PVs and
+	// StatefulSet volume claim templates.
+	StorageClassMapping map[string]string `json:"storageClassMapping,omitempty"`
+	// ImageRegistryMapping maps the image prefixes, usually registries, of
+	// the containers of the pod templates.
+	ImageRegistryMapping map[string]string `json:"imageRegistryMapping,omitempty"`
+	// IngressHostMapping maps the hosts of the rules and TLS of the Ingresses.
+	IngressHostMapping map[string]string `json:"ingressHostMapping,omitempty"`
+	// Replicas sets spec.replicas of the resources having it.
+	Replicas *int64 `json:"replicas,omitempty"`
+	// Labels are added to the resources, overriding existing values.
+	Labels map[string]string `json:"labels,omitempty"`
+	// RemoveLabels are removed from the resources.
+	RemoveLabels []string `json:"removeLabels,omitempty"`
+	// Annotations are added to the resources, overriding existing values.
+	Annotations map[string]string `json:"annotations,omitempty"`
+	// RemoveAnnotations are removed from the resources.
+	RemoveAnnotations []string `json:"removeAnnotations,omitempty"`
+	// Fields set or remove arbitrary fields.
+	Fields []FieldTransformation `json:"fields,omitempty"`
+}
+
+// RestoreTransformationSelector selects resources by type, namespace and
+// name. The empty fields match any resource.
+type RestoreTransformationSelector struct {
+	// Group, Version and Kind of the resources, "core" or empty for the core group.
+	metav1.GroupVersionKind `json:",inline"`
+	// Namespaces of the resources in the backup, before the namespace mapping.
+	Namespaces []string `json:"namespaces,omitempty"`
+	// Names are shell patterns matching the resource names.
+	Names []string `json:"names,omitempty"`
+	// LabelSelector selects the resources by labels.
+	LabelSelector *metav1.LabelSelector `json:"labelSelector,omitempty"`
+}
+
+// FieldTransformation sets or removes a field of a resource.
+type FieldTransformation struct {
+	// Path is a JSONPath to the field, like .spec.template.spec.containers[*].image.
+	// Only the child, index and wildcard operators are supported.
+	Path string `json:"path"`
+	// Value is the JSON encoded value of the field, taken as a string if it
+	// isn't valid JSON.
+	Value string `json:"value,omitempty"`
+	// Remove removes the field instead of setting it.
+	Remove bool `json:"remove,omitempty"`
+}
@@ -39,6 +39,8 @@ type VolumeBackupSpec struct {
 	Repository string `json:"repository,omitempty"`
 	// BackupLocation contains a reference (name and namespace) to the object.
 	BackupLocation DataExportObjectReference `json:"backupLocation,omitempty"`
+	// ReplicaOf is the VolumeBackup this backup was replicated from.
+	ReplicaOf *DataExportObjectReference `json:"replicaOf,omitempty"`
 }
 
 // VolumeBackupStatus defines status for VolumeBackup.
@@ -53,4 +55,80 @@ type VolumeBackupStatus struct {
 	SnapshotID string `json:"snapshotID,omitempty"`
 	// LastKnownError contains an error in case of failure.
 	LastKnownError string `json:"lastKnownError,omitempty"`
+	// Verification is the result of the last integrity check of the snapshot.
+	Verification *VolumeBackupVerification `json:"verification,omitempty"`
+	// Replicas are the VolumeBackups the snapshot was replicated to.
+	Replicas []DataExportObjectReference `json:"replicas,omitempty"`
+	// Stats are the statistics of the backup, set once it is done.
+	Stats *TransferStats `json:"stats,omitempty"`
+	// ObjectLockExpiry is the time the object-lock retention of the snapshot
+	// expires at, set at backup time if the repository is object-locked. The
+	// snapshot can't be deleted before.
+	ObjectLockExpiry *metav1.Time `json:"objectLockExpiry,omitempty"`
+}
+
+// TransferStats are the statistics of a backup reported by kopia or restic.
+// The fields not reported by the tool are left empty.
+type TransferStats struct {
+	// FileCount is the number of files of the snapshot.
+	FileCount int64 `json:"fileCount,omitempty"`
+	// DirCount is the number of directories of the snapshot.
+	DirCount int64 `json:"dirCount,omitempty"`
+	// CachedFiles is the number of files unchanged since the previous
+	// snapshot, which were not read again.
+	CachedFiles int64 `json:"cachedFiles,omitempty"`
+	// HashedFiles is the number of files read and hashed.
+	HashedFiles int64 `json:"hashedFiles,omitempty"`
+	// CachedBytes is the size of the cached files.
+	CachedBytes uint64 `json:"cachedBytes,omitempty"`
+	// HashedBytes is the size of the hashed files.
+	HashedBytes uint64 `json:"hashedBytes,omitempty"`
+	// UploadedBytes is the size of the new data written to the repository,
+	// after deduplication and compression.
+	UploadedBytes uint64 `json:"uploadedBytes,omitempty"`
+	// DeduplicationRatio is the size of the snapshot over the uploaded bytes.
+	DeduplicationRatio float64 `json:"deduplicationRatio,omitempty"`
+	// ErrorCount is the number of entries which could not be backed up.
+	ErrorCount int64 `json:"errorCount,omitempty"`
+	// IgnoredErrorCount is the number of errors ignored by the policy.
+	IgnoredErrorCount int64 `json:"ignoredErrorCount,omitempty"`
+	// Errors are the first errors of the entries.
+	Errors []TransferError `json:"errors,omitempty"`
+	// StartTime and EndTime are the times the backup started and ended at.
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+	EndTime   *metav1.Time `json:"endTime,omitempty"`
+	// Throughput is the size of the snapshot over the backup duration, in
+	// bytes per second.
+	Throughput uint64 `json:"throughput,omitempty"`
+}
+
+// TransferError is the error of an entry of a backup.
+type TransferError struct {
+	Path  string `json:"path,omitempty"`
+	Error string `json:"error"`
+}
+
+// VolumeBackupVerificationStatusType is the status of a snapshot verification.
+type VolumeBackupVerificationStatusType string
+
+const (
+	// VolumeBackupVerificationStatusInProgress - verification in progress status
+	VolumeBackupVerificationStatusInProgress VolumeBackupVerificationStatusType = "InProgress"
+	// VolumeBackupVerificationStatusSuccess - verification success status
+	VolumeBackupVerificationStatusSuccess VolumeBackupVerificationStatusType = "Successful"
+	// VolumeBackupVerificationStatusFailed - verification fail status
+	VolumeBackupVerificationStatusFailed VolumeBackupVerificationStatusType = "Failed"
+)
+
+// VolumeBackupVerification defines the outcome of a snapshot verification.
+type VolumeBackupVerification struct {
+	Status VolumeBackupVerificationStatusType `json:"status,omitempty"`
+	Reason string                             `json:"reason,omitempty"`
+	// SnapshotID is the verified snapshot id.
+	SnapshotID string `json:"snapshotID,omitempty"`
+	// VerifyFilesPercent is the percentage of files whose content was read
+	// back from the repository. Only the metadata is checked if it is zero.
+	VerifyFilesPercent int `json:"verifyFilesPercent,omitempty"`
+	// LastVerifiedTimestamp is the time when the verification finished.
+	LastVerifiedTimestamp metav1.Time `json:"lastVerifiedTimestamp,omitempty"`
 }
@@ -36,6 +36,9 @@ type VolumeBackupDeleteList struct {
 type VolumeBackupDeleteSpec struct {
 	PvcName    string `json:"pvcName,omitempty"`
 	SnapshotID string `json:"snapshotID,omitempty"`
+	// ObjectLockExpiry is the object-lock expiry recorded on the VolumeBackup
+	// of the snapshot, if any.
+	ObjectLockExpiry *metav1.Time `json:"objectLockExpiry,omitempty"`
 }
 
 // VolumeBackupDeleteStatusType is the status of the volume delete job
@@ -50,6 +53,9 @@ const (
 	VolumeBackupDeleteStatusSuccess VolumeBackupDeleteStatusType = "Successful"
 	// VolumeBackupDeleteStatusFailed - Fail status
 	VolumeBackupDeleteStatusFailed VolumeBackupDeleteStatusType = "Failed"
+	// VolumeBackupDeleteStatusLocked - the snapshot is still under object-lock
+	// retention, the delete has to be retried after it expires
+	VolumeBackupDeleteStatusLocked VolumeBackupDeleteStatusType = "Locked"
 )
 
 // VolumeBackupDeleteStatus defines status for VolumeBackupDelete.
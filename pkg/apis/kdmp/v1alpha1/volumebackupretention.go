@@ -0,0 +1,92 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// VolumeBackupRetentionResourceName is name for the VolumeBackupRetention resource.
+	VolumeBackupRetentionResourceName = "volumebackupretention"
+	// VolumeBackupRetentionResourcePlural is the name for list of VolumeBackupRetention resources.
+	VolumeBackupRetentionResourcePlural = "volumebackupretentions"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// VolumeBackupRetention is a retention policy of the VolumeBackups. The
+// snapshots it expires are deleted with VolumeBackupDelete jobs.
+type VolumeBackupRetention struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              VolumeBackupRetentionSpec   `json:"spec"`
+	Status            VolumeBackupRetentionStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// VolumeBackupRetentionList is a list of VolumeBackupRetention resources.
+type VolumeBackupRetentionList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []VolumeBackupRetention `json:"items"`
+}
+
+// VolumeBackupRetentionSpec defines configuration parameters for VolumeBackupRetention.
+// The keep counts are applied to the snapshots of each PVC in each
+// BackupLocation, a snapshot is kept if any of them retains it.
+type VolumeBackupRetentionSpec struct {
+	// BackupLocation restricts the policy to the VolumeBackups of a BackupLocation.
+	BackupLocation *DataExportObjectReference `json:"backupLocation,omitempty"`
+	// Namespaces restricts the policy to the VolumeBackups of these
+	// namespaces, the namespace of the policy if empty. Only the policies of
+	// the kube-system namespace can set other namespaces than their own.
+	Namespaces []string `json:"namespaces,omitempty"`
+	// PVCSelector restricts the policy to the VolumeBackups of the PVCs
+	// matching the selector.
+	PVCSelector *metav1.LabelSelector `json:"pvcSelector,omitempty"`
+	// KeepLast is the number of latest snapshots kept.
+	KeepLast int `json:"keepLast,omitempty"`
+	// KeepHourly is the number of hours for which the latest snapshot is kept.
+	KeepHourly int `json:"keepHourly,omitempty"`
+	// KeepDaily is the number of days for which the latest snapshot is kept.
+	KeepDaily int `json:"keepDaily,omitempty"`
+	// KeepWeekly is the number of weeks for which the latest snapshot is kept.
+	KeepWeekly int `json:"keepWeekly,omitempty"`
+	// KeepMonthly is the number of months for which the latest snapshot is kept.
+	KeepMonthly int `json:"keepMonthly,omitempty"`
+	// MinAge is the age under which the snapshots are never expired.
+	MinAge metav1.Duration `json:"minAge,omitempty"`
+	// DryRun only lists the expired snapshots in the status.
+	DryRun bool `json:"dryRun,omitempty"`
+	// TriggeredFrom is the deployment the kopia executor image of the delete
+	// jobs is taken from.
+	TriggeredFrom   string `json:"triggerFrom,omitempty"`
+	TriggeredFromNs string `json:"triggerFromNs,omitempty"`
+}
+
+// VolumeBackupRetentionStatus defines status for VolumeBackupRetention.
+type VolumeBackupRetentionStatus struct {
+	// LastEvaluationTimestamp is the time of the last policy evaluation.
+	LastEvaluationTimestamp metav1.Time `json:"lastEvaluationTimestamp,omitempty"`
+	// Reason is the error of the last evaluation, if any.
+	Reason string `json:"reason,omitempty"`
+	// ExpiredSnapshots are the snapshots expired by the last evaluation, the
+	// ones which would be removed in dry-run.
+	ExpiredSnapshots []ExpiredSnapshot `json:"expiredSnapshots,omitempty"`
+}
+
+// ExpiredSnapshot is a snapshot expired by a VolumeBackupRetention.
+type ExpiredSnapshot struct {
+	// VolumeBackup of the snapshot.
+	VolumeBackup DataExportObjectReference `json:"volumeBackup"`
+	SnapshotID   string                    `json:"snapshotID,omitempty"`
+	// CreationTimestamp of the VolumeBackup.
+	CreationTimestamp metav1.Time `json:"creationTimestamp,omitempty"`
+	// DeleteJob is the id of the snapshot delete job.
+	DeleteJob string `json:"deleteJob,omitempty"`
+	// Status of the VolumeBackupDelete, empty until the delete job is started.
+	Status VolumeBackupDeleteStatusType `json:"status,omitempty"`
+	Reason string                       `json:"reason,omitempty"`
+}
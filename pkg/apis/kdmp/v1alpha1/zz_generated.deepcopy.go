@@ -13,7 +13,8 @@ package v1alpha1
 
 import (
 	storkv1alpha1 "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
-	v1 "k8s.io/api/core/v1"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -111,6 +112,18 @@ func (in *BackupLocationMaintenanceStatus) DeepCopyInto(out *BackupLocationMaint
 			(*out)[key] = *val.DeepCopy()
 		}
 	}
+	if in.KeyRotationRepoStatus != nil {
+		in, out := &in.KeyRotationRepoStatus, &out.KeyRotationRepoStatus
+		*out = make(map[string]RepoMaintenanceStatus, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	if in.OrphanGCStatus != nil {
+		in, out := &in.OrphanGCStatus, &out.OrphanGCStatus
+		*out = new(OrphanGCStatus)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -129,7 +142,7 @@ func (in *DataExport) DeepCopyInto(out *DataExport) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
+	in.Spec.DeepCopyInto(&out.Spec)
 	in.Status.DeepCopyInto(&out.Status)
 	return
 }
@@ -152,6 +165,101 @@ func (in *DataExport) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DataExportFileRestore) DeepCopyInto(out *DataExportFileRestore) {
+	*out = *in
+	if in.IncludePaths != nil {
+		in, out := &in.IncludePaths, &out.IncludePaths
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataExportFileRestore.
+func (in *DataExportFileRestore) DeepCopy() *DataExportFileRestore {
+	if in == nil {
+		return nil
+	}
+	out := new(DataExportFileRestore)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DataExportHook) DeepCopyInto(out *DataExportHook) {
+	*out = *in
+	in.PodSelector.DeepCopyInto(&out.PodSelector)
+	if in.Command != nil {
+		in, out := &in.Command, &out.Command
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataExportHook.
+func (in *DataExportHook) DeepCopy() *DataExportHook {
+	if in == nil {
+		return nil
+	}
+	out := new(DataExportHook)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DataExportHookStatus) DeepCopyInto(out *DataExportHookStatus) {
+	*out = *in
+	in.CompletionTime.DeepCopyInto(&out.CompletionTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataExportHookStatus.
+func (in *DataExportHookStatus) DeepCopy() *DataExportHookStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DataExportHookStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DataExportHooks) DeepCopyInto(out *DataExportHooks) {
+	*out = *in
+	if in.Pre != nil {
+		in, out := &in.Pre, &out.Pre
+		*out = make([]DataExportHook, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Post != nil {
+		in, out := &in.Post, &out.Post
+		*out = make([]DataExportHook, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataExportHooks.
+func (in *DataExportHooks) DeepCopy() *DataExportHooks {
+	if in == nil {
+		return nil
+	}
+	out := new(DataExportHooks)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DataExportList) DeepCopyInto(out *DataExportList) {
 	*out = *in
@@ -206,6 +314,16 @@ func (in *DataExportSpec) DeepCopyInto(out *DataExportSpec) {
 	*out = *in
 	out.Source = in.Source
 	out.Destination = in.Destination
+	if in.FileRestore != nil {
+		in, out := &in.FileRestore, &out.FileRestore
+		*out = new(DataExportFileRestore)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Hooks != nil {
+		in, out := &in.Hooks, &out.Hooks
+		*out = new(DataExportHooks)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -219,12 +337,46 @@ func (in *DataExportSpec) DeepCopy() *DataExportSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExpiredSnapshot) DeepCopyInto(out *ExpiredSnapshot) {
+	*out = *in
+	out.VolumeBackup = in.VolumeBackup
+	in.CreationTimestamp.DeepCopyInto(&out.CreationTimestamp)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExpiredSnapshot.
+func (in *ExpiredSnapshot) DeepCopy() *ExpiredSnapshot {
+	if in == nil {
+		return nil
+	}
+	out := new(ExpiredSnapshot)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ExportStatus) DeepCopyInto(out *ExportStatus) {
 	*out = *in
 	if in.RestorePVC != nil {
 		in, out := &in.RestorePVC, &out.RestorePVC
-		*out = new(v1.PersistentVolumeClaim)
+		*out = new(corev1.PersistentVolumeClaim)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NextRetryTime != nil {
+		in, out := &in.NextRetryTime, &out.NextRetryTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Hooks != nil {
+		in, out := &in.Hooks, &out.Hooks
+		*out = make([]DataExportHookStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Stats != nil {
+		in, out := &in.Stats, &out.Stats
+		*out = new(TransferStats)
 		(*in).DeepCopyInto(*out)
 	}
 	return
@@ -240,6 +392,22 @@ func (in *ExportStatus) DeepCopy() *ExportStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FieldTransformation) DeepCopyInto(out *FieldTransformation) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FieldTransformation.
+func (in *FieldTransformation) DeepCopy() *FieldTransformation {
+	if in == nil {
+		return nil
+	}
+	out := new(FieldTransformation)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ObjectInfo) DeepCopyInto(out *ObjectInfo) {
 	*out = *in
@@ -257,6 +425,71 @@ func (in *ObjectInfo) DeepCopy() *ObjectInfo {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OrphanGCStatus) DeepCopyInto(out *OrphanGCStatus) {
+	*out = *in
+	in.LastRunTimestamp.DeepCopyInto(&out.LastRunTimestamp)
+	out.GracePeriod = in.GracePeriod
+	if in.Repositories != nil {
+		in, out := &in.Repositories, &out.Repositories
+		*out = make([]OrphanedRepository, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OrphanGCStatus.
+func (in *OrphanGCStatus) DeepCopy() *OrphanGCStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(OrphanGCStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OrphanedRepository) DeepCopyInto(out *OrphanedRepository) {
+	*out = *in
+	if in.Snapshots != nil {
+		in, out := &in.Snapshots, &out.Snapshots
+		*out = make([]OrphanedSnapshot, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OrphanedRepository.
+func (in *OrphanedRepository) DeepCopy() *OrphanedRepository {
+	if in == nil {
+		return nil
+	}
+	out := new(OrphanedRepository)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OrphanedSnapshot) DeepCopyInto(out *OrphanedSnapshot) {
+	*out = *in
+	in.StartTime.DeepCopyInto(&out.StartTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OrphanedSnapshot.
+func (in *OrphanedSnapshot) DeepCopy() *OrphanedSnapshot {
+	if in == nil {
+		return nil
+	}
+	out := new(OrphanedSnapshot)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RepoMaintenanceStatus) DeepCopyInto(out *RepoMaintenanceStatus) {
 	*out = *in
@@ -274,6 +507,134 @@ func (in *RepoMaintenanceStatus) DeepCopy() *RepoMaintenanceStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RepositoryStatus) DeepCopyInto(out *RepositoryStatus) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RepositoryStatus.
+func (in *RepositoryStatus) DeepCopy() *RepositoryStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RepositoryStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RepositoryStatus) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RepositoryStatusList) DeepCopyInto(out *RepositoryStatusList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]RepositoryStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RepositoryStatusList.
+func (in *RepositoryStatusList) DeepCopy() *RepositoryStatusList {
+	if in == nil {
+		return nil
+	}
+	out := new(RepositoryStatusList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RepositoryStatusList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RepositoryStatusSpec) DeepCopyInto(out *RepositoryStatusSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RepositoryStatusSpec.
+func (in *RepositoryStatusSpec) DeepCopy() *RepositoryStatusSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RepositoryStatusSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RepositoryStatusStatus) DeepCopyInto(out *RepositoryStatusStatus) {
+	*out = *in
+	in.LastUpdateTimestamp.DeepCopyInto(&out.LastUpdateTimestamp)
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Usage != nil {
+		in, out := &in.Usage, &out.Usage
+		*out = new(RepositoryUsage)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RepositoryStatusStatus.
+func (in *RepositoryStatusStatus) DeepCopy() *RepositoryStatusStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RepositoryStatusStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RepositoryUsage) DeepCopyInto(out *RepositoryUsage) {
+	*out = *in
+	if in.OldestSnapshotTime != nil {
+		in, out := &in.OldestSnapshotTime, &out.OldestSnapshotTime
+		*out = (*in).DeepCopy()
+	}
+	if in.NewestSnapshotTime != nil {
+		in, out := &in.NewestSnapshotTime, &out.NewestSnapshotTime
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RepositoryUsage.
+func (in *RepositoryUsage) DeepCopy() *RepositoryUsage {
+	if in == nil {
+		return nil
+	}
+	out := new(RepositoryUsage)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ResourceBackup) DeepCopyInto(out *ResourceBackup) {
 	*out = *in
@@ -613,13 +974,245 @@ func (in *ResourceStatus) DeepCopy() *ResourceStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RestoreTransformation) DeepCopyInto(out *RestoreTransformation) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RestoreTransformation.
+func (in *RestoreTransformation) DeepCopy() *RestoreTransformation {
+	if in == nil {
+		return nil
+	}
+	out := new(RestoreTransformation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RestoreTransformation) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RestoreTransformationList) DeepCopyInto(out *RestoreTransformationList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]RestoreTransformation, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RestoreTransformationList.
+func (in *RestoreTransformationList) DeepCopy() *RestoreTransformationList {
+	if in == nil {
+		return nil
+	}
+	out := new(RestoreTransformationList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RestoreTransformationList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RestoreTransformationRule) DeepCopyInto(out *RestoreTransformationRule) {
+	*out = *in
+	in.Selector.DeepCopyInto(&out.Selector)
+	if in.StorageClassMapping != nil {
+		in, out := &in.StorageClassMapping, &out.StorageClassMapping
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ImageRegistryMapping != nil {
+		in, out := &in.ImageRegistryMapping, &out.ImageRegistryMapping
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.IngressHostMapping != nil {
+		in, out := &in.IngressHostMapping, &out.IngressHostMapping
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = new(int64)
+		**out = **in
+	}
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.RemoveLabels != nil {
+		in, out := &in.RemoveLabels, &out.RemoveLabels
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.RemoveAnnotations != nil {
+		in, out := &in.RemoveAnnotations, &out.RemoveAnnotations
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Fields != nil {
+		in, out := &in.Fields, &out.Fields
+		*out = make([]FieldTransformation, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RestoreTransformationRule.
+func (in *RestoreTransformationRule) DeepCopy() *RestoreTransformationRule {
+	if in == nil {
+		return nil
+	}
+	out := new(RestoreTransformationRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RestoreTransformationSelector) DeepCopyInto(out *RestoreTransformationSelector) {
+	*out = *in
+	out.GroupVersionKind = in.GroupVersionKind
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Names != nil {
+		in, out := &in.Names, &out.Names
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.LabelSelector != nil {
+		in, out := &in.LabelSelector, &out.LabelSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RestoreTransformationSelector.
+func (in *RestoreTransformationSelector) DeepCopy() *RestoreTransformationSelector {
+	if in == nil {
+		return nil
+	}
+	out := new(RestoreTransformationSelector)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RestoreTransformationSpec) DeepCopyInto(out *RestoreTransformationSpec) {
+	*out = *in
+	if in.Rules != nil {
+		in, out := &in.Rules, &out.Rules
+		*out = make([]RestoreTransformationRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RestoreTransformationSpec.
+func (in *RestoreTransformationSpec) DeepCopy() *RestoreTransformationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RestoreTransformationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TransferError) DeepCopyInto(out *TransferError) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TransferError.
+func (in *TransferError) DeepCopy() *TransferError {
+	if in == nil {
+		return nil
+	}
+	out := new(TransferError)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TransferStats) DeepCopyInto(out *TransferStats) {
+	*out = *in
+	if in.Errors != nil {
+		in, out := &in.Errors, &out.Errors
+		*out = make([]TransferError, len(*in))
+		copy(*out, *in)
+	}
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.EndTime != nil {
+		in, out := &in.EndTime, &out.EndTime
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TransferStats.
+func (in *TransferStats) DeepCopy() *TransferStats {
+	if in == nil {
+		return nil
+	}
+	out := new(TransferStats)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VolumeBackup) DeepCopyInto(out *VolumeBackup) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
-	out.Status = in.Status
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 	return
 }
 
@@ -646,7 +1239,7 @@ func (in *VolumeBackupDelete) DeepCopyInto(out *VolumeBackupDelete) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
+	in.Spec.DeepCopyInto(&out.Spec)
 	out.Status = in.Status
 	return
 }
@@ -705,6 +1298,10 @@ func (in *VolumeBackupDeleteList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VolumeBackupDeleteSpec) DeepCopyInto(out *VolumeBackupDeleteSpec) {
 	*out = *in
+	if in.ObjectLockExpiry != nil {
+		in, out := &in.ObjectLockExpiry, &out.ObjectLockExpiry
+		*out = (*in).DeepCopy()
+	}
 	return
 }
 
@@ -767,10 +1364,132 @@ func (in *VolumeBackupList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VolumeBackupRetention) DeepCopyInto(out *VolumeBackupRetention) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VolumeBackupRetention.
+func (in *VolumeBackupRetention) DeepCopy() *VolumeBackupRetention {
+	if in == nil {
+		return nil
+	}
+	out := new(VolumeBackupRetention)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *VolumeBackupRetention) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VolumeBackupRetentionList) DeepCopyInto(out *VolumeBackupRetentionList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]VolumeBackupRetention, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VolumeBackupRetentionList.
+func (in *VolumeBackupRetentionList) DeepCopy() *VolumeBackupRetentionList {
+	if in == nil {
+		return nil
+	}
+	out := new(VolumeBackupRetentionList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *VolumeBackupRetentionList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VolumeBackupRetentionSpec) DeepCopyInto(out *VolumeBackupRetentionSpec) {
+	*out = *in
+	if in.BackupLocation != nil {
+		in, out := &in.BackupLocation, &out.BackupLocation
+		*out = new(DataExportObjectReference)
+		**out = **in
+	}
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.PVCSelector != nil {
+		in, out := &in.PVCSelector, &out.PVCSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	out.MinAge = in.MinAge
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VolumeBackupRetentionSpec.
+func (in *VolumeBackupRetentionSpec) DeepCopy() *VolumeBackupRetentionSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(VolumeBackupRetentionSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VolumeBackupRetentionStatus) DeepCopyInto(out *VolumeBackupRetentionStatus) {
+	*out = *in
+	in.LastEvaluationTimestamp.DeepCopyInto(&out.LastEvaluationTimestamp)
+	if in.ExpiredSnapshots != nil {
+		in, out := &in.ExpiredSnapshots, &out.ExpiredSnapshots
+		*out = make([]ExpiredSnapshot, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VolumeBackupRetentionStatus.
+func (in *VolumeBackupRetentionStatus) DeepCopy() *VolumeBackupRetentionStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(VolumeBackupRetentionStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VolumeBackupSpec) DeepCopyInto(out *VolumeBackupSpec) {
 	*out = *in
 	out.BackupLocation = in.BackupLocation
+	if in.ReplicaOf != nil {
+		in, out := &in.ReplicaOf, &out.ReplicaOf
+		*out = new(DataExportObjectReference)
+		**out = **in
+	}
 	return
 }
 
@@ -787,6 +1506,25 @@ func (in *VolumeBackupSpec) DeepCopy() *VolumeBackupSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VolumeBackupStatus) DeepCopyInto(out *VolumeBackupStatus) {
 	*out = *in
+	if in.Verification != nil {
+		in, out := &in.Verification, &out.Verification
+		*out = new(VolumeBackupVerification)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = make([]DataExportObjectReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.Stats != nil {
+		in, out := &in.Stats, &out.Stats
+		*out = new(TransferStats)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ObjectLockExpiry != nil {
+		in, out := &in.ObjectLockExpiry, &out.ObjectLockExpiry
+		*out = (*in).DeepCopy()
+	}
 	return
 }
 
@@ -799,3 +1537,20 @@ func (in *VolumeBackupStatus) DeepCopy() *VolumeBackupStatus {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VolumeBackupVerification) DeepCopyInto(out *VolumeBackupVerification) {
+	*out = *in
+	in.LastVerifiedTimestamp.DeepCopyInto(&out.LastVerifiedTimestamp)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VolumeBackupVerification.
+func (in *VolumeBackupVerification) DeepCopy() *VolumeBackupVerification {
+	if in == nil {
+		return nil
+	}
+	out := new(VolumeBackupVerification)
+	in.DeepCopyInto(out)
+	return out
+}
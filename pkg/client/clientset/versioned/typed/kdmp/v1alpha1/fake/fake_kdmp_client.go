@@ -26,6 +26,10 @@ func (c *FakeKdmpV1alpha1) DataExports(namespace string) v1alpha1.DataExportInte
 	return &FakeDataExports{c, namespace}
 }
 
+func (c *FakeKdmpV1alpha1) RepositoryStatuses(namespace string) v1alpha1.RepositoryStatusInterface {
+	return &FakeRepositoryStatuses{c, namespace}
+}
+
 func (c *FakeKdmpV1alpha1) ResourceBackups(namespace string) v1alpha1.ResourceBackupInterface {
 	return &FakeResourceBackups{c, namespace}
 }
@@ -34,6 +38,10 @@ func (c *FakeKdmpV1alpha1) ResourceExports(namespace string) v1alpha1.ResourceEx
 	return &FakeResourceExports{c, namespace}
 }
 
+func (c *FakeKdmpV1alpha1) RestoreTransformations(namespace string) v1alpha1.RestoreTransformationInterface {
+	return &FakeRestoreTransformations{c, namespace}
+}
+
 func (c *FakeKdmpV1alpha1) VolumeBackups(namespace string) v1alpha1.VolumeBackupInterface {
 	return &FakeVolumeBackups{c, namespace}
 }
@@ -42,6 +50,10 @@ func (c *FakeKdmpV1alpha1) VolumeBackupDeletes(namespace string) v1alpha1.Volume
 	return &FakeVolumeBackupDeletes{c, namespace}
 }
 
+func (c *FakeKdmpV1alpha1) VolumeBackupRetentions(namespace string) v1alpha1.VolumeBackupRetentionInterface {
+	return &FakeVolumeBackupRetentions{c, namespace}
+}
+
 // RESTClient returns a RESTClient that is used to communicate
 // with API server by this client implementation.
 func (c *FakeKdmpV1alpha1) RESTClient() rest.Interface {
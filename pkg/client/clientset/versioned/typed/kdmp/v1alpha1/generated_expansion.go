@@ -12,10 +12,16 @@ type BackupLocationMaintenanceExpansion interface{}
 
 type DataExportExpansion interface{}
 
+type RepositoryStatusExpansion interface{}
+
 type ResourceBackupExpansion interface{}
 
 type ResourceExportExpansion interface{}
 
+type RestoreTransformationExpansion interface{}
+
 type VolumeBackupExpansion interface{}
 
 type VolumeBackupDeleteExpansion interface{}
+
+type VolumeBackupRetentionExpansion interface{}
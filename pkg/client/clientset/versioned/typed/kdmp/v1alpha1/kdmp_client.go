@@ -20,10 +20,13 @@ type KdmpV1alpha1Interface interface {
 	RESTClient() rest.Interface
 	BackupLocationMaintenancesGetter
 	DataExportsGetter
+	RepositoryStatusesGetter
 	ResourceBackupsGetter
 	ResourceExportsGetter
+	RestoreTransformationsGetter
 	VolumeBackupsGetter
 	VolumeBackupDeletesGetter
+	VolumeBackupRetentionsGetter
 }
 
 // KdmpV1alpha1Client is used to interact with features provided by the kdmp.portworx.com group.
@@ -39,6 +42,10 @@ func (c *KdmpV1alpha1Client) DataExports(namespace string) DataExportInterface {
 	return newDataExports(c, namespace)
 }
 
+func (c *KdmpV1alpha1Client) RepositoryStatuses(namespace string) RepositoryStatusInterface {
+	return newRepositoryStatuses(c, namespace)
+}
+
 func (c *KdmpV1alpha1Client) ResourceBackups(namespace string) ResourceBackupInterface {
 	return newResourceBackups(c, namespace)
 }
@@ -47,6 +54,10 @@ func (c *KdmpV1alpha1Client) ResourceExports(namespace string) ResourceExportInt
 	return newResourceExports(c, namespace)
 }
 
+func (c *KdmpV1alpha1Client) RestoreTransformations(namespace string) RestoreTransformationInterface {
+	return newRestoreTransformations(c, namespace)
+}
+
 func (c *KdmpV1alpha1Client) VolumeBackups(namespace string) VolumeBackupInterface {
 	return newVolumeBackups(c, namespace)
 }
@@ -55,6 +66,10 @@ func (c *KdmpV1alpha1Client) VolumeBackupDeletes(namespace string) VolumeBackupD
 	return newVolumeBackupDeletes(c, namespace)
 }
 
+func (c *KdmpV1alpha1Client) VolumeBackupRetentions(namespace string) VolumeBackupRetentionInterface {
+	return newVolumeBackupRetentions(c, namespace)
+}
+
 // NewForConfig creates a new KdmpV1alpha1Client for the given config.
 // NewForConfig is equivalent to NewForConfigAndClient(c, httpClient),
 // where httpClient was generated with rest.HTTPClientFor(c).
@@ -47,14 +47,20 @@ func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Kdmp().V1alpha1().BackupLocationMaintenances().Informer()}, nil
 	case v1alpha1.SchemeGroupVersion.WithResource("dataexports"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Kdmp().V1alpha1().DataExports().Informer()}, nil
+	case v1alpha1.SchemeGroupVersion.WithResource("repositorystatuses"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Kdmp().V1alpha1().RepositoryStatuses().Informer()}, nil
 	case v1alpha1.SchemeGroupVersion.WithResource("resourcebackups"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Kdmp().V1alpha1().ResourceBackups().Informer()}, nil
 	case v1alpha1.SchemeGroupVersion.WithResource("resourceexports"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Kdmp().V1alpha1().ResourceExports().Informer()}, nil
+	case v1alpha1.SchemeGroupVersion.WithResource("restoretransformations"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Kdmp().V1alpha1().RestoreTransformations().Informer()}, nil
 	case v1alpha1.SchemeGroupVersion.WithResource("volumebackups"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Kdmp().V1alpha1().VolumeBackups().Informer()}, nil
 	case v1alpha1.SchemeGroupVersion.WithResource("volumebackupdeletes"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Kdmp().V1alpha1().VolumeBackupDeletes().Informer()}, nil
+	case v1alpha1.SchemeGroupVersion.WithResource("volumebackupretentions"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Kdmp().V1alpha1().VolumeBackupRetentions().Informer()}, nil
 
 	}
 
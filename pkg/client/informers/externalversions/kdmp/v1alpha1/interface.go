@@ -18,14 +18,20 @@ type Interface interface {
 	BackupLocationMaintenances() BackupLocationMaintenanceInformer
 	// DataExports returns a DataExportInformer.
 	DataExports() DataExportInformer
+	// RepositoryStatuses returns a RepositoryStatusInformer.
+	RepositoryStatuses() RepositoryStatusInformer
 	// ResourceBackups returns a ResourceBackupInformer.
 	ResourceBackups() ResourceBackupInformer
 	// ResourceExports returns a ResourceExportInformer.
 	ResourceExports() ResourceExportInformer
+	// RestoreTransformations returns a RestoreTransformationInformer.
+	RestoreTransformations() RestoreTransformationInformer
 	// VolumeBackups returns a VolumeBackupInformer.
 	VolumeBackups() VolumeBackupInformer
 	// VolumeBackupDeletes returns a VolumeBackupDeleteInformer.
 	VolumeBackupDeletes() VolumeBackupDeleteInformer
+	// VolumeBackupRetentions returns a VolumeBackupRetentionInformer.
+	VolumeBackupRetentions() VolumeBackupRetentionInformer
 }
 
 type version struct {
@@ -49,6 +55,11 @@ func (v *version) DataExports() DataExportInformer {
 	return &dataExportInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
 
+// RepositoryStatuses returns a RepositoryStatusInformer.
+func (v *version) RepositoryStatuses() RepositoryStatusInformer {
+	return &repositoryStatusInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
 // ResourceBackups returns a ResourceBackupInformer.
 func (v *version) ResourceBackups() ResourceBackupInformer {
 	return &resourceBackupInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
@@ -59,6 +70,11 @@ func (v *version) ResourceExports() ResourceExportInformer {
 	return &resourceExportInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
 
+// RestoreTransformations returns a RestoreTransformationInformer.
+func (v *version) RestoreTransformations() RestoreTransformationInformer {
+	return &restoreTransformationInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
 // VolumeBackups returns a VolumeBackupInformer.
 func (v *version) VolumeBackups() VolumeBackupInformer {
 	return &volumeBackupInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
@@ -68,3 +84,8 @@ func (v *version) VolumeBackups() VolumeBackupInformer {
 func (v *version) VolumeBackupDeletes() VolumeBackupDeleteInformer {
 	return &volumeBackupDeleteInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
+
+// VolumeBackupRetentions returns a VolumeBackupRetentionInformer.
+func (v *version) VolumeBackupRetentions() VolumeBackupRetentionInformer {
+	return &volumeBackupRetentionInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
@@ -24,6 +24,14 @@ type DataExportListerExpansion interface{}
 // DataExportNamespaceLister.
 type DataExportNamespaceListerExpansion interface{}
 
+// RepositoryStatusListerExpansion allows custom methods to be added to
+// RepositoryStatusLister.
+type RepositoryStatusListerExpansion interface{}
+
+// RepositoryStatusNamespaceListerExpansion allows custom methods to be added to
+// RepositoryStatusNamespaceLister.
+type RepositoryStatusNamespaceListerExpansion interface{}
+
 // ResourceBackupListerExpansion allows custom methods to be added to
 // ResourceBackupLister.
 type ResourceBackupListerExpansion interface{}
@@ -40,6 +48,14 @@ type ResourceExportListerExpansion interface{}
 // ResourceExportNamespaceLister.
 type ResourceExportNamespaceListerExpansion interface{}
 
+// RestoreTransformationListerExpansion allows custom methods to be added to
+// RestoreTransformationLister.
+type RestoreTransformationListerExpansion interface{}
+
+// RestoreTransformationNamespaceListerExpansion allows custom methods to be added to
+// RestoreTransformationNamespaceLister.
+type RestoreTransformationNamespaceListerExpansion interface{}
+
 // VolumeBackupListerExpansion allows custom methods to be added to
 // VolumeBackupLister.
 type VolumeBackupListerExpansion interface{}
@@ -55,3 +71,11 @@ type VolumeBackupDeleteListerExpansion interface{}
 // VolumeBackupDeleteNamespaceListerExpansion allows custom methods to be added to
 // VolumeBackupDeleteNamespaceLister.
 type VolumeBackupDeleteNamespaceListerExpansion interface{}
+
+// VolumeBackupRetentionListerExpansion allows custom methods to be added to
+// VolumeBackupRetentionLister.
+type VolumeBackupRetentionListerExpansion interface{}
+
+// VolumeBackupRetentionNamespaceListerExpansion allows custom methods to be added to
+// VolumeBackupRetentionNamespaceLister.
+type VolumeBackupRetentionNamespaceListerExpansion interface{}
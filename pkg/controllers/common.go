@@ -1,12 +1,16 @@
 package controllers
 
 import (
+	"fmt"
+	"net/http"
 	"os"
+	"sync"
 	"time"
 
 	storkapi "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
 	"github.com/portworx/sched-ops/k8s/stork"
 	"k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 )
 
 var (
@@ -48,3 +52,90 @@ func ReadBackupLocation(name, namespace, filePath string) (*storkapi.BackupLocat
 
 	return out, nil
 }
+
+// Options are the settings of a controller.
+type Options struct {
+	// Workers is the number of resources reconciled concurrently.
+	Workers int
+	// ResyncPeriod is the period at which a resource is reconciled again.
+	ResyncPeriod time.Duration
+}
+
+// Option overrides a default setting of a controller.
+type Option func(*Options)
+
+// WithWorkers sets the number of resources reconciled concurrently.
+func WithWorkers(workers int) Option {
+	return func(o *Options) {
+		o.Workers = workers
+	}
+}
+
+// WithResyncPeriod sets the period at which a resource is reconciled again.
+func WithResyncPeriod(period time.Duration) Option {
+	return func(o *Options) {
+		o.ResyncPeriod = period
+	}
+}
+
+// NewOptions returns the settings of a controller, its defaults overridden by
+// opts.
+func NewOptions(workers int, resyncPeriod time.Duration, opts ...Option) (Options, error) {
+	o := Options{
+		Workers:      workers,
+		ResyncPeriod: resyncPeriod,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.Workers < 1 {
+		return o, fmt.Errorf("workers should be at least 1, got %d", o.Workers)
+	}
+	if o.ResyncPeriod <= 0 {
+		return o, fmt.Errorf("resync period should be positive, got %v", o.ResyncPeriod)
+	}
+	return o, nil
+}
+
+// Probe is the health and readiness probe of a controller.
+type Probe struct {
+	name     string
+	mu       sync.RWMutex
+	informer cache.Informer
+}
+
+// NewProbe returns the probe of the controller name.
+func NewProbe(name string) *Probe {
+	return &Probe{name: name}
+}
+
+// Started records the controller is initialized, informer is the informer of
+// the resources it reconciles.
+func (p *Probe) Started(informer cache.Informer) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.informer = informer
+}
+
+// Healthz fails until the controller is initialized.
+func (p *Probe) Healthz(_ *http.Request) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.informer == nil {
+		return fmt.Errorf("%s controller is not initialized", p.name)
+	}
+	return nil
+}
+
+// Readyz fails until the resources reconciled by the controller are synced.
+func (p *Probe) Readyz(req *http.Request) error {
+	if err := p.Healthz(req); err != nil {
+		return err
+	}
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if !p.informer.HasSynced() {
+		return fmt.Errorf("%s controller cache is not synced", p.name)
+	}
+	return nil
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/libopenstorage/stork/pkg/snapshotter"
 	kdmpapi "github.com/portworx/kdmp/pkg/apis/kdmp/v1alpha1"
 	kdmpcontroller "github.com/portworx/kdmp/pkg/controllers"
+	"github.com/portworx/kdmp/pkg/jobratelimit"
 	"github.com/portworx/kdmp/pkg/utils"
 	"github.com/portworx/kdmp/pkg/version"
 	"github.com/portworx/sched-ops/k8s/apiextensions"
@@ -27,16 +28,31 @@ import (
 type Controller struct {
 	client      runtimeclient.Client
 	snapshotter snapshotter.Snapshotter
+	jobQueue    *jobratelimit.Queue
+	opts        kdmpcontroller.Options
+	probe       *kdmpcontroller.Probe
 }
 
 // NewController returns a new instance of the controller.
-func NewController(mgr manager.Manager) (*Controller, error) {
+func NewController(mgr manager.Manager, opts ...kdmpcontroller.Option) (*Controller, error) {
+	o, err := kdmpcontroller.NewOptions(10, kdmpcontroller.ResyncPeriod, opts...)
+	if err != nil {
+		return nil, err
+	}
 	return &Controller{
 		client:      mgr.GetClient(),
 		snapshotter: snapshotter.NewDefaultSnapshotter(),
+		jobQueue:    jobratelimit.DefaultQueue(),
+		opts:        o,
+		probe:       kdmpcontroller.NewProbe("dataexport"),
 	}, nil
 }
 
+// Probe returns the health and readiness probe of the controller.
+func (c *Controller) Probe() *kdmpcontroller.Probe {
+	return c.probe
+}
+
 // Init Initialize the application backup controller
 func (c *Controller) Init(mgr manager.Manager) error {
 	err := c.createCRD()
@@ -47,14 +63,23 @@ func (c *Controller) Init(mgr manager.Manager) error {
 	// Create a new controller
 	ctrl, err := controller.New("data-export-controller", mgr, controller.Options{
 		Reconciler:              c,
-		MaxConcurrentReconciles: 10,
+		MaxConcurrentReconciles: c.opts.Workers,
 	})
 	if err != nil {
 		return err
 	}
 
 	// Watch for changes to primary resource
-	return ctrl.Watch(&source.Kind{Type: &kdmpapi.DataExport{}}, &handler.EnqueueRequestForObject{})
+	if err := ctrl.Watch(&source.Kind{Type: &kdmpapi.DataExport{}}, &handler.EnqueueRequestForObject{}); err != nil {
+		return err
+	}
+
+	informer, err := mgr.GetCache().GetInformer(context.TODO(), &kdmpapi.DataExport{})
+	if err != nil {
+		return err
+	}
+	c.probe.Started(informer)
+	return nil
 }
 
 // Reconcile reads that state of the cluster for an object and makes changes based on the state read
@@ -91,7 +116,7 @@ func (c *Controller) Reconcile(ctx context.Context, request reconcile.Request) (
 		return reconcile.Result{RequeueAfter: kdmpcontroller.RequeuePeriod}, nil
 	}
 
-	return reconcile.Result{RequeueAfter: kdmpcontroller.ResyncPeriod}, nil
+	return reconcile.Result{RequeueAfter: c.opts.ResyncPeriod}, nil
 }
 
 func (c *Controller) createCRD() error {
@@ -153,5 +178,62 @@ func (c *Controller) createCRD() error {
 			return err
 		}
 	}
+
+	// repositorystatuses are updated by the kopia repository-status maintenance jobs
+	repositoryStatus := apiextensions.CustomResource{
+		Name:    kdmpapi.RepositoryStatusResourceName,
+		Plural:  kdmpapi.RepositoryStatusResourcePlural,
+		Group:   kdmpapi.SchemeGroupVersion.Group,
+		Version: kdmpapi.SchemeGroupVersion.Version,
+		Scope:   apiextensionsv1beta1.NamespaceScoped,
+		Kind:    reflect.TypeOf(kdmpapi.RepositoryStatus{}).Name(),
+	}
+
+	if requiresV1 {
+		err := utils.CreateCRD(repositoryStatus)
+		if err != nil && !errors.IsAlreadyExists(err) {
+			return err
+		}
+		if err := apiextensions.Instance().ValidateCRD(repositoryStatus.Plural+"."+repositoryStatus.Group, kdmpcontroller.ValidateCRDTimeout, kdmpcontroller.ValidateCRDInterval); err != nil {
+			return err
+		}
+	} else {
+		err = apiextensions.Instance().CreateCRDV1beta1(repositoryStatus)
+		if err != nil && !errors.IsAlreadyExists(err) {
+			return err
+		}
+		if err := apiextensions.Instance().ValidateCRDV1beta1(repositoryStatus, kdmpcontroller.ValidateCRDTimeout, kdmpcontroller.ValidateCRDInterval); err != nil {
+			return err
+		}
+	}
+
+	// restoretransformations are read by the nfs resource restore jobs, they
+	// are registered here as the resourceexport controller may be disabled
+	restoreTransformation := apiextensions.CustomResource{
+		Name:    kdmpapi.RestoreTransformationResourceName,
+		Plural:  kdmpapi.RestoreTransformationResourcePlural,
+		Group:   kdmpapi.SchemeGroupVersion.Group,
+		Version: kdmpapi.SchemeGroupVersion.Version,
+		Scope:   apiextensionsv1beta1.NamespaceScoped,
+		Kind:    reflect.TypeOf(kdmpapi.RestoreTransformation{}).Name(),
+	}
+
+	if requiresV1 {
+		err := utils.CreateCRD(restoreTransformation)
+		if err != nil && !errors.IsAlreadyExists(err) {
+			return err
+		}
+		if err := apiextensions.Instance().ValidateCRD(restoreTransformation.Plural+"."+restoreTransformation.Group, kdmpcontroller.ValidateCRDTimeout, kdmpcontroller.ValidateCRDInterval); err != nil {
+			return err
+		}
+	} else {
+		err = apiextensions.Instance().CreateCRDV1beta1(restoreTransformation)
+		if err != nil && !errors.IsAlreadyExists(err) {
+			return err
+		}
+		if err := apiextensions.Instance().ValidateCRDV1beta1(restoreTransformation, kdmpcontroller.ValidateCRDTimeout, kdmpcontroller.ValidateCRDInterval); err != nil {
+			return err
+		}
+	}
 	return nil
 }
@@ -0,0 +1,208 @@
+package dataexport
+
+import (
+	"fmt"
+	"time"
+
+	kdmpapi "github.com/portworx/kdmp/pkg/apis/kdmp/v1alpha1"
+	"github.com/portworx/sched-ops/k8s/core"
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+const defaultHookTimeout = 30 * time.Second
+
+// listHookPods and execHookCommand are replaced in the tests.
+var (
+	listHookPods = func(namespace string) ([]corev1.Pod, error) {
+		pods, err := core.Instance().GetPods(namespace, nil)
+		if err != nil {
+			return nil, err
+		}
+		return pods.Items, nil
+	}
+	execHookCommand = func(command []string, pod, container, namespace string) (string, error) {
+		return core.Instance().RunCommandInPod(command, pod, container, namespace)
+	}
+)
+
+// runHooks runs the hooks of a phase, unless they were already run, and
+// returns their statuses. The error is the first failure of a hook with the
+// Fail policy. The pre hooks stop at this failure, while all the post hooks
+// are run so that none of the pods is left quiesced.
+func runHooks(de *kdmpapi.DataExport, phase kdmpapi.DataExportHookPhase) ([]kdmpapi.DataExportHookStatus, error) {
+	if hasHookStatus(de, phase) {
+		return nil, nil
+	}
+	var (
+		statuses []kdmpapi.DataExportHookStatus
+		hookErr  error
+	)
+	for _, hook := range getHooks(de, phase) {
+		hookStatuses := runHook(de, phase, hook)
+		statuses = append(statuses, hookStatuses...)
+		if hookErr != nil || hook.OnFailure == kdmpapi.DataExportHookFailurePolicyContinue {
+			continue
+		}
+		for _, status := range hookStatuses {
+			if status.Status == kdmpapi.DataExportStatusFailed {
+				hookErr = fmt.Errorf("%v hook %v failed: %v", phase, hook.Name, status.Reason)
+				break
+			}
+		}
+		if hookErr != nil && phase == kdmpapi.DataExportHookPhasePre {
+			break
+		}
+	}
+	return statuses, hookErr
+}
+
+// runPendingPostHooks runs the post hooks of a DataExport whose pre hooks were
+// run, if they were not run yet, so that the pods are not left quiesced after
+// a failure or a cancel.
+func runPendingPostHooks(de *kdmpapi.DataExport) ([]kdmpapi.DataExportHookStatus, error) {
+	if !hasHookStatus(de, kdmpapi.DataExportHookPhasePre) {
+		return nil, nil
+	}
+	return runHooks(de, kdmpapi.DataExportHookPhasePost)
+}
+
+// releaseHooks runs the pending post hooks of a paused DataExport without a
+// snapshot stage, as its application is quiesced during the whole transfer.
+// It returns true if the hook statuses have to be cleared, so that the pre
+// hooks are run again once the DataExport is resumed.
+func releaseHooks(de *kdmpapi.DataExport) bool {
+	if hasSnapshotStage(de) || !hasHookStatus(de, kdmpapi.DataExportHookPhasePre) {
+		return false
+	}
+	if _, err := runPendingPostHooks(de); err != nil {
+		logrus.Errorf("dataexport %v/%v: %v", de.Namespace, de.Name, err)
+	}
+	return true
+}
+
+// runHook runs a hook in each of its running pods.
+func runHook(de *kdmpapi.DataExport, phase kdmpapi.DataExportHookPhase, hook kdmpapi.DataExportHook) []kdmpapi.DataExportHookStatus {
+	namespace := hook.Namespace
+	if namespace == "" {
+		namespace = getHookNamespace(de)
+	}
+	newStatus := func(pod string, err error) kdmpapi.DataExportHookStatus {
+		status := kdmpapi.DataExportHookStatus{
+			Name:           hook.Name,
+			Phase:          phase,
+			Pod:            pod,
+			Status:         kdmpapi.DataExportStatusSuccessful,
+			CompletionTime: metav1.Now(),
+		}
+		if err != nil {
+			status.Status = kdmpapi.DataExportStatusFailed
+			status.Reason = err.Error()
+		}
+		return status
+	}
+
+	// The hooks can't reach the pods of the namespaces the DataExport is not
+	// allowed to back up.
+	if namespace != de.Namespace && namespace != getHookNamespace(de) {
+		err := fmt.Errorf("namespace %v is neither the namespace of the dataexport nor the one of its pvc", namespace)
+		logrus.Errorf("%v hook %v of dataexport %v/%v: %v", phase, hook.Name, de.Namespace, de.Name, err)
+		return []kdmpapi.DataExportHookStatus{newStatus("", err)}
+	}
+	pods, err := getHookPods(hook, namespace)
+	if err != nil {
+		logrus.Errorf("%v hook %v of dataexport %v/%v: %v", phase, hook.Name, de.Namespace, de.Name, err)
+		return []kdmpapi.DataExportHookStatus{newStatus("", err)}
+	}
+	timeout := defaultHookTimeout
+	if hook.Timeout != nil && hook.Timeout.Duration > 0 {
+		timeout = hook.Timeout.Duration
+	}
+	statuses := make([]kdmpapi.DataExportHookStatus, 0, len(pods))
+	for _, pod := range pods {
+		output, err := execHookWithTimeout(hook, pod.Name, namespace, timeout)
+		if err != nil {
+			logrus.Errorf("%v hook %v of dataexport %v/%v failed in pod %v/%v: %v",
+				phase, hook.Name, de.Namespace, de.Name, namespace, pod.Name, err)
+		} else {
+			logrus.Infof("%v hook %v of dataexport %v/%v run in pod %v/%v: %v",
+				phase, hook.Name, de.Namespace, de.Name, namespace, pod.Name, output)
+		}
+		statuses = append(statuses, newStatus(pod.Name, err))
+	}
+	return statuses
+}
+
+// getHookPods returns the running pods selected by a hook.
+func getHookPods(hook kdmpapi.DataExportHook, namespace string) ([]corev1.Pod, error) {
+	selector, err := metav1.LabelSelectorAsSelector(&hook.PodSelector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pod selector: %v", err)
+	}
+	pods, err := listHookPods(namespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the pods of namespace %v: %v", namespace, err)
+	}
+	selected := make([]corev1.Pod, 0)
+	for _, pod := range pods {
+		if pod.Status.Phase == corev1.PodRunning && pod.DeletionTimestamp == nil &&
+			selector.Matches(labels.Set(pod.Labels)) {
+			selected = append(selected, pod)
+		}
+	}
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("no running pod in namespace %v matches the pod selector", namespace)
+	}
+	return selected, nil
+}
+
+// execHookWithTimeout runs the command of a hook in a pod. The exec stream of
+// the vendored client-go has no context, so it can't be cancelled: after a
+// timeout the command and its goroutine are left running until the command
+// exits, the result is then dropped in the buffered channel.
+func execHookWithTimeout(hook kdmpapi.DataExportHook, pod, namespace string, timeout time.Duration) (string, error) {
+	type result struct {
+		output string
+		err    error
+	}
+	done := make(chan result, 1)
+	go func() {
+		output, err := execHookCommand(hook.Command, pod, hook.Container, namespace)
+		done <- result{output, err}
+	}()
+	select {
+	case r := <-done:
+		return r.output, r.err
+	case <-time.After(timeout):
+		return "", fmt.Errorf("command timed out after %v", timeout)
+	}
+}
+
+func getHooks(de *kdmpapi.DataExport, phase kdmpapi.DataExportHookPhase) []kdmpapi.DataExportHook {
+	if de.Spec.Hooks == nil {
+		return nil
+	}
+	if phase == kdmpapi.DataExportHookPhasePre {
+		return de.Spec.Hooks.Pre
+	}
+	return de.Spec.Hooks.Post
+}
+
+func hasHookStatus(de *kdmpapi.DataExport, phase kdmpapi.DataExportHookPhase) bool {
+	for _, status := range de.Status.Hooks {
+		if status.Phase == phase {
+			return true
+		}
+	}
+	return false
+}
+
+// getHookNamespace returns the namespace of the PVC of the DataExport.
+func getHookNamespace(de *kdmpapi.DataExport) string {
+	if isPVCRef(de.Spec.Source) {
+		return de.Spec.Source.Namespace
+	}
+	return de.Spec.Destination.Namespace
+}
@@ -0,0 +1,164 @@
+package dataexport
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	kdmpapi "github.com/portworx/kdmp/pkg/apis/kdmp/v1alpha1"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newHookPod(name string, phase corev1.PodPhase, labels map[string]string) corev1.Pod {
+	return corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "app", Labels: labels},
+		Status:     corev1.PodStatus{Phase: phase},
+	}
+}
+
+func TestRunHooks(t *testing.T) {
+	pods := []corev1.Pod{
+		newHookPod("db-0", corev1.PodRunning, map[string]string{"app": "db"}),
+		newHookPod("db-1", corev1.PodRunning, map[string]string{"app": "db"}),
+		newHookPod("db-2", corev1.PodPending, map[string]string{"app": "db"}),
+		newHookPod("web-0", corev1.PodRunning, map[string]string{"app": "web"}),
+	}
+	var (
+		mu       sync.Mutex
+		executed []string
+	)
+	list, exec := listHookPods, execHookCommand
+	defer func() { listHookPods, execHookCommand = list, exec }()
+	listHookPods = func(namespace string) ([]corev1.Pod, error) {
+		require.Equal(t, "app", namespace)
+		return pods, nil
+	}
+	execHookCommand = func(command []string, pod, container, namespace string) (string, error) {
+		mu.Lock()
+		executed = append(executed, pod+":"+strings.Join(command, " "))
+		mu.Unlock()
+		switch command[0] {
+		case "fail":
+			return "", fmt.Errorf("exit code 1")
+		case "sleep":
+			time.Sleep(time.Second)
+		}
+		return "", nil
+	}
+
+	db := metav1.LabelSelector{MatchLabels: map[string]string{"app": "db"}}
+	de := &kdmpapi.DataExport{
+		Spec: kdmpapi.DataExportSpec{
+			Source: kdmpapi.DataExportObjectReference{APIVersion: "v1", Kind: "PersistentVolumeClaim", Namespace: "app", Name: "data"},
+			Hooks: &kdmpapi.DataExportHooks{
+				Pre: []kdmpapi.DataExportHook{
+					{Name: "flush", PodSelector: db, Command: []string{"flush"}},
+					{Name: "check", PodSelector: db, Command: []string{"fail"}, OnFailure: kdmpapi.DataExportHookFailurePolicyContinue},
+					{Name: "freeze", PodSelector: metav1.LabelSelector{MatchLabels: map[string]string{"app": "none"}}, Command: []string{"freeze"}},
+					{Name: "never", PodSelector: db, Command: []string{"never"}},
+				},
+				Post: []kdmpapi.DataExportHook{
+					{Name: "slow", PodSelector: db, Command: []string{"sleep"}, Timeout: &metav1.Duration{Duration: 10 * time.Millisecond}},
+					{Name: "unfreeze", PodSelector: db, Command: []string{"unfreeze"}},
+				},
+			},
+		},
+	}
+
+	// The pre hooks stop at the failure of the hook matching no pod
+	statuses, err := runHooks(de, kdmpapi.DataExportHookPhasePre)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "Pre hook freeze failed")
+	require.Equal(t, []string{"db-0:flush", "db-1:flush", "db-0:fail", "db-1:fail"}, executed)
+	require.Len(t, statuses, 5)
+	require.Equal(t, kdmpapi.DataExportStatusSuccessful, statuses[0].Status)
+	require.Equal(t, kdmpapi.DataExportStatusFailed, statuses[2].Status)
+	require.Equal(t, "exit code 1", statuses[2].Reason)
+	require.Equal(t, "", statuses[4].Pod)
+	de.Status.Hooks = statuses
+
+	// The hooks of a phase are run once
+	statuses, err = runHooks(de, kdmpapi.DataExportHookPhasePre)
+	require.NoError(t, err)
+	require.Empty(t, statuses)
+
+	// All the post hooks are run despite the timeout
+	executed = nil
+	statuses, err = runPendingPostHooks(de)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "timed out")
+	require.Len(t, statuses, 4)
+	require.Equal(t, kdmpapi.DataExportHookPhasePost, statuses[0].Phase)
+	require.Equal(t, kdmpapi.DataExportStatusFailed, statuses[0].Status)
+	require.Equal(t, kdmpapi.DataExportStatusSuccessful, statuses[3].Status)
+	require.Contains(t, executed, "db-1:unfreeze")
+
+	// No post hooks without pre hooks
+	de.Status.Hooks = nil
+	statuses, err = runPendingPostHooks(de)
+	require.NoError(t, err)
+	require.Empty(t, statuses)
+}
+
+func TestRunHookNamespace(t *testing.T) {
+	list, exec := listHookPods, execHookCommand
+	defer func() { listHookPods, execHookCommand = list, exec }()
+	listHookPods = func(namespace string) ([]corev1.Pod, error) {
+		return []corev1.Pod{newHookPod("db-0", corev1.PodRunning, nil)}, nil
+	}
+	execHookCommand = func(command []string, pod, container, namespace string) (string, error) {
+		return "", nil
+	}
+	de := &kdmpapi.DataExport{
+		ObjectMeta: metav1.ObjectMeta{Name: "de", Namespace: "admin"},
+		Spec: kdmpapi.DataExportSpec{
+			Source: kdmpapi.DataExportObjectReference{APIVersion: "v1", Kind: "PersistentVolumeClaim", Namespace: "app", Name: "data"},
+		},
+	}
+
+	// The hooks run in the namespaces of the DataExport and of the PVC
+	for _, namespace := range []string{"", "app", "admin"} {
+		statuses := runHook(de, kdmpapi.DataExportHookPhasePre, kdmpapi.DataExportHook{Name: "flush", Namespace: namespace, Command: []string{"flush"}})
+		require.Len(t, statuses, 1)
+		require.Equal(t, kdmpapi.DataExportStatusSuccessful, statuses[0].Status, namespace)
+	}
+
+	// Any other namespace is rejected
+	statuses := runHook(de, kdmpapi.DataExportHookPhasePre, kdmpapi.DataExportHook{Name: "flush", Namespace: "kube-system", Command: []string{"flush"}})
+	require.Len(t, statuses, 1)
+	require.Equal(t, kdmpapi.DataExportStatusFailed, statuses[0].Status)
+	require.Contains(t, statuses[0].Reason, "namespace kube-system")
+}
+
+func TestExecHookWithTimeout(t *testing.T) {
+	exec := execHookCommand
+	defer func() { execHookCommand = exec }()
+	release, returned := make(chan struct{}), make(chan struct{})
+	execHookCommand = func(command []string, pod, container, namespace string) (string, error) {
+		defer close(returned)
+		<-release
+		return "done", nil
+	}
+
+	// The timeout doesn't wait for the command, which is left running
+	_, err := execHookWithTimeout(kdmpapi.DataExportHook{Command: []string{"sleep"}}, "db-0", "app", 10*time.Millisecond)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "timed out")
+	select {
+	case <-returned:
+		t.Fatal("command returned before its release")
+	default:
+	}
+
+	// The command returns once released, its result is dropped
+	close(release)
+	select {
+	case <-returned:
+	case <-time.After(time.Second):
+		t.Fatal("command didn't return")
+	}
+}
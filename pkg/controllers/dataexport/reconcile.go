@@ -21,6 +21,9 @@ import (
 	"github.com/portworx/kdmp/pkg/drivers"
 	"github.com/portworx/kdmp/pkg/drivers/driversinstance"
 	"github.com/portworx/kdmp/pkg/drivers/utils"
+	"github.com/portworx/kdmp/pkg/jobratelimit"
+	"github.com/portworx/kdmp/pkg/kms"
+	"github.com/portworx/kdmp/pkg/metrics"
 	kdmpopts "github.com/portworx/kdmp/pkg/util/ops"
 
 	"github.com/portworx/kdmp/pkg/version"
@@ -67,6 +70,23 @@ const (
 	snapDeleteAnnotation            = "snapshotScheduledForDeletion"
 	snapRestoreAnnotation           = "snapshotScheduledForRestore"
 
+	// backupModeKey set to "block" backs up the volume as a raw block device,
+	// uploading only the blocks changed since the snapshot of the last
+	// successful VolumeBackup of the repository. Set to
+	// "live", the volume is backed up without a snapshot, from the node of the
+	// running pod using it.
+	backupModeKey   = kdmpAnnotationPrefix + "backup-mode"
+	backupModeBlock = "block"
+	backupModeLive  = "live"
+	// jobPriorityKey orders the data transfer jobs waiting in the job queue,
+	// the higher first.
+	jobPriorityKey = kdmpAnnotationPrefix + "priority"
+	// maxUploadSpeedKey and maxDownloadSpeedKey override the speed limits of
+	// the kdmp config for a DataExport. The format is the one of the config,
+	// and like the config they only apply when the transfer job is started.
+	maxUploadSpeedKey   = kdmpAnnotationPrefix + "max-upload-speed"
+	maxDownloadSpeedKey = kdmpAnnotationPrefix + "max-download-speed"
+
 	// pvcNameLenLimit is the max length of PVC name that DataExport related CRs
 	// will incorporate in their names
 	pvcNameLenLimit = 247
@@ -98,6 +118,23 @@ type updateDataExportDetail struct {
 	removeFinalizer           bool
 	volumeSnapshot            string
 	resetLocalSnapshotRestore bool
+	queuePosition             int
+	// failureReason is the reason label of the failure metric, if the status
+	// is set to failed. Defaults to metrics.FailureReasonOther.
+	failureReason string
+	failureClass  kdmpapi.DataExportFailureClass
+	// retryCount and nextRetryTime are set when the transfer job is retried.
+	// resetRetries clears them with the failure class once the transfer job
+	// succeeds.
+	retryCount         int
+	nextRetryTime      *metav1.Time
+	resetNextRetryTime bool
+	resetRetries       bool
+	// hooks are appended to the hook statuses, after clearing them if
+	// resetHooks is set.
+	hooks      []kdmpapi.DataExportHookStatus
+	resetHooks bool
+	stats      *kdmpapi.TransferStats
 }
 
 func (c *Controller) sync(ctx context.Context, in *kdmpapi.DataExport) (bool, error) {
@@ -162,12 +199,22 @@ func (c *Controller) sync(ctx context.Context, in *kdmpapi.DataExport) (bool, er
 		if err = c.cleanUp(driver, dataExport); err != nil {
 			return true, fmt.Errorf("%s: cleanup: %s", reflect.TypeOf(dataExport), err)
 		}
+		hookStatuses, err := runPendingPostHooks(dataExport)
+		if err != nil {
+			logrus.Errorf("dataexport %v/%v: %v", dataExport.Namespace, dataExport.Name, err)
+		}
 
 		data := updateDataExportDetail{
 			removeFinalizer: true,
+			hooks:           hookStatuses,
 		}
 		return true, c.updateStatus(dataExport, data)
 	}
+
+	if handled, requeue, err := c.handleAction(driver, dataExport); handled {
+		return requeue, err
+	}
+
 	switch dataExport.Status.Stage {
 	case kdmpapi.DataExportStageInitial:
 		return c.stageInitial(ctx, dataExport)
@@ -205,6 +252,54 @@ func (c *Controller) sync(ctx context.Context, in *kdmpapi.DataExport) (bool, er
 			return false, c.updateStatus(dataExport, data)
 		}
 
+		if dataExport.Status.NextRetryTime != nil && !isRetryDue(dataExport) {
+			return true, nil
+		}
+
+		if jobratelimit.IsRateLimited(driverName) {
+			admitted, position, err := c.jobQueue.Admit(jobratelimit.QueueRequest{
+				ID:        utils.NamespacedName(dataExport.Namespace, dataExport.Name),
+				Driver:    driverName,
+				Namespace: dataExport.Namespace,
+				Priority:  func() int32 { return getJobPriority(dataExport) },
+			})
+			if err != nil {
+				logrus.Warnf("failed to queue the data transfer job of dataexport [%v/%v]: %v", dataExport.Namespace, dataExport.Name, err)
+				return true, nil
+			}
+			if !admitted {
+				if dataExport.Status.Status == kdmpapi.DataExportStatusPending && dataExport.Status.QueuePosition == position {
+					return true, nil
+				}
+				data := updateDataExportDetail{
+					status:        kdmpapi.DataExportStatusPending,
+					reason:        fmt.Sprintf("waiting for a free %v job slot", driverName),
+					queuePosition: position,
+				}
+				return true, c.updateStatus(dataExport, data)
+			}
+		}
+
+		// Without a snapshot the application is quiesced during the whole
+		// transfer, the post hooks are run in the cleanup stage.
+		if !hasSnapshotStage(dataExport) {
+			hookStatuses, err := runHooks(dataExport, kdmpapi.DataExportHookPhasePre)
+			if err != nil {
+				data := updateDataExportDetail{
+					status: kdmpapi.DataExportStatusFailed,
+					reason: err.Error(),
+					hooks:  hookStatuses,
+				}
+				return false, c.updateStatus(dataExport, data)
+			}
+			if len(hookStatuses) != 0 {
+				data := updateDataExportDetail{
+					hooks: hookStatuses,
+				}
+				return true, c.updateStatus(dataExport, data)
+			}
+		}
+
 		// use snapshot pvc in the dst namespace if it's available
 		srcPVCName := dataExport.Spec.Source.Name
 		if dataExport.Status.SnapshotPVCName != "" {
@@ -213,7 +308,7 @@ func (c *Controller) sync(ctx context.Context, in *kdmpapi.DataExport) (bool, er
 
 		logrus.Debugf("drivername: %v", driverName)
 		var vb *kdmpapi.VolumeBackup
-		if driverName == drivers.KopiaRestore {
+		if driverName == drivers.KopiaRestore || driverName == drivers.KopiaReplicate {
 			// Get the volumebackup
 			vb, err = kdmpopts.Instance().GetVolumeBackup(context.Background(),
 				dataExport.Spec.Source.Name, dataExport.Spec.Source.Namespace)
@@ -227,7 +322,8 @@ func (c *Controller) sync(ctx context.Context, in *kdmpapi.DataExport) (bool, er
 				}
 				return false, c.updateStatus(dataExport, data)
 			}
-
+		}
+		if driverName == drivers.KopiaRestore {
 			// Create the pvc from the spec provided in the dataexport CR
 			pvcSpec := dataExport.Status.RestorePVC
 			// For NFS PVC creation happens upfront and createPVC() fails internally during vol restore
@@ -267,6 +363,7 @@ func (c *Controller) sync(ctx context.Context, in *kdmpapi.DataExport) (bool, er
 		var compressionType string
 		var podDataPath string
 		var excludeFileList string
+		var maxUploadSpeed, maxDownloadSpeed int64
 		pvcStorageClass := dataExport.Annotations[kdmpStorageClassKey]
 		var backupLocation *storkapi.BackupLocation
 		var data updateDataExportDetail
@@ -289,6 +386,16 @@ func (c *Controller) sync(ctx context.Context, in *kdmpapi.DataExport) (bool, er
 			}
 			compressionType = kdmpData.Data[compressionKey]
 			podDataPath = kdmpData.Data[backupPath]
+			maxUploadSpeed, maxDownloadSpeed, err = getSpeedLimits(dataExport, vb, driverName, kdmpData.Data)
+			if err != nil {
+				msg := fmt.Sprintf("Failed in getting the speed limits of dataexport [%v]: %v", dataExport.Name, err)
+				logrus.Errorf(msg)
+				data := updateDataExportDetail{
+					status: kdmpapi.DataExportStatusFailed,
+					reason: msg,
+				}
+				return false, c.updateStatus(dataExport, data)
+			}
 			if driverName == drivers.KopiaBackup {
 				if len(kdmpData.Data[excludeFileListKey]) != 0 {
 					excludeFileList, err = parseExcludeFileListKey(pvcStorageClass, kdmpData.Data[excludeFileListKey])
@@ -324,7 +431,9 @@ func (c *Controller) sync(ctx context.Context, in *kdmpapi.DataExport) (bool, er
 			blName := dataExport.Spec.Destination.Name
 			blNamespace := dataExport.Spec.Destination.Namespace
 
-			if driverName == drivers.KopiaRestore {
+			// The job mounts the NFS share of the backuplocation of the
+			// VolumeBackup to restore or replicate.
+			if driverName == drivers.KopiaRestore || driverName == drivers.KopiaReplicate {
 				blName = vb.Spec.BackupLocation.Name
 				blNamespace = vb.Spec.BackupLocation.Namespace
 			}
@@ -356,13 +465,16 @@ func (c *Controller) sync(ctx context.Context, in *kdmpapi.DataExport) (bool, er
 			utils.KdmpConfigmapName,
 			utils.KdmpConfigmapNamespace,
 			backupLocation,
+			maxUploadSpeed,
+			maxDownloadSpeed,
 		)
-		if err != nil && err != utils.ErrJobAlreadyRunning && err != utils.ErrOutOfJobResources {
+		if err != nil && err != utils.ErrJobAlreadyRunning && err != utils.ErrOutOfJobResources && err != utils.ErrJobNotReady {
 			msg := fmt.Sprintf("failed to start a data transfer job, dataexport [%v]: %v", dataExport.Name, err)
 			logrus.Warnf(msg)
 			data := updateDataExportDetail{
-				status: kdmpapi.DataExportStatusFailed,
-				reason: msg,
+				status:        kdmpapi.DataExportStatusFailed,
+				reason:        msg,
+				failureReason: metrics.FailureReasonJobStartFailed,
 			}
 			return false, c.updateStatus(dataExport, data)
 		} else if err != nil {
@@ -371,8 +483,9 @@ func (c *Controller) sync(ctx context.Context, in *kdmpapi.DataExport) (bool, er
 
 		dataExport.Status.TransferID = id
 		data = updateDataExportDetail{
-			status:     kdmpapi.DataExportStatusSuccessful,
-			transferID: id,
+			status:             kdmpapi.DataExportStatusSuccessful,
+			transferID:         id,
+			resetNextRetryTime: true,
 		}
 		return true, c.updateStatus(dataExport, data)
 	case kdmpapi.DataExportStageTransferInProgress:
@@ -408,20 +521,17 @@ func (c *Controller) sync(ctx context.Context, in *kdmpapi.DataExport) (bool, er
 		// fail the backup.
 		logrus.Infof("DE CR name: %v/%v job status: %v job error: %v", dataExport.Namespace, dataExport.Name, progress.Status, err)
 		if progress.Status == batchv1.JobFailed {
-			data := updateDataExportDetail{
-				status: kdmpapi.DataExportStatusFailed,
-				reason: progress.Reason,
-			}
-			if len(progress.Reason) == 0 {
+			reason := progress.Reason
+			if len(reason) == 0 {
 				// As we couldn't get actual reason from kopia executor
 				// marking it as internal error
-				data.reason = "internal error from executor"
-				return true, c.updateStatus(dataExport, data)
+				reason = "internal error from executor"
 			}
-			return true, c.updateStatus(dataExport, data)
+			return c.transferJobFailed(driver, dataExport, reason, progress.NodeFailed)
 		} else if progress.Status == batchv1.JobConditionType("") {
 			data := updateDataExportDetail{
-				status: kdmpapi.DataExportStatusInProgress,
+				status:             kdmpapi.DataExportStatusInProgress,
+				progressPercentage: int(progress.ProgressPercents),
 			}
 			return true, c.updateStatus(dataExport, data)
 		}
@@ -430,12 +540,9 @@ func (c *Controller) sync(ctx context.Context, in *kdmpapi.DataExport) (bool, er
 		case drivers.JobStateFailed:
 			errMsg := fmt.Sprintf("%s transfer job failed: %s", dataExport.Status.TransferID, progress.Reason)
 			// If a job has failed it means it has tried all possible retires and given up.
-			// In such a scenario we need to fail DE CR and move to clean up stage
-			data := updateDataExportDetail{
-				status: kdmpapi.DataExportStatusFailed,
-				reason: errMsg,
-			}
-			return true, c.updateStatus(dataExport, data)
+			// In such a scenario we need to fail DE CR and move to clean up stage,
+			// unless the failure is transient and the job can be started again.
+			return c.transferJobFailed(driver, dataExport, errMsg, progress.NodeFailed)
 		case drivers.JobStateCompleted:
 			var (
 				vbName, vbNamespace string
@@ -444,7 +551,8 @@ func (c *Controller) sync(ctx context.Context, in *kdmpapi.DataExport) (bool, er
 				data                updateDataExportDetail
 			)
 			if driverName != drivers.Rsync {
-				if driverName == drivers.KopiaBackup {
+				// The VolumeBackup of the backup or replica is named after the job
+				if driverName == drivers.KopiaBackup || driverName == drivers.ResticBackup || driverName == drivers.KopiaReplicate {
 					vbNamespace, vbName, err = utils.ParseJobID(dataExport.Status.TransferID)
 					if err != nil {
 						errMsg := fmt.Sprintf("failed to parse job ID %v from DataExport CR: %v: %v",
@@ -494,21 +602,30 @@ func (c *Controller) sync(ctx context.Context, in *kdmpapi.DataExport) (bool, er
 					return false, c.updateStatus(dataExport, data)
 				}
 				data = updateDataExportDetail{
-					status:     kdmpapi.DataExportStatusSuccessful,
-					snapshotID: volumeBackupCR.Status.SnapshotID,
-					size:       volumeBackupCR.Status.TotalBytes,
+					status:       kdmpapi.DataExportStatusSuccessful,
+					snapshotID:   volumeBackupCR.Status.SnapshotID,
+					size:         volumeBackupCR.Status.TotalBytes,
+					resetRetries: true,
+				}
+				// Only the backups record their stats, a restore reads the
+				// VolumeBackup of the backup it restores from
+				if driverName == drivers.KopiaBackup || driverName == drivers.ResticBackup {
+					data.stats = volumeBackupCR.Status.Stats
 				}
 			} else {
 				data = updateDataExportDetail{
 					status:             kdmpapi.DataExportStatusSuccessful,
 					progressPercentage: int(progress.ProgressPercents),
+					resetRetries:       true,
 				}
 			}
+			observeTransfer(driverName, dataExport.Status.TransferID, data.size)
 
 			return false, c.updateStatus(dataExport, data)
 		}
 		data := updateDataExportDetail{
-			status: kdmpapi.DataExportStatusInProgress,
+			status:             kdmpapi.DataExportStatusInProgress,
+			progressPercentage: int(progress.ProgressPercents),
 		}
 		return false, c.updateStatus(dataExport, data)
 	case kdmpapi.DataExportStageCleanup:
@@ -518,6 +635,15 @@ func (c *Controller) sync(ctx context.Context, in *kdmpapi.DataExport) (bool, er
 			stage:  kdmpapi.DataExportStageFinal,
 			reason: dataExport.Status.Reason,
 		}
+		hookStatuses, err := runPendingPostHooks(dataExport)
+		data.hooks = hookStatuses
+		if err != nil {
+			logrus.Errorf("dataexport %v/%v: %v", dataExport.Namespace, dataExport.Name, err)
+			if dataExport.Status.Status == kdmpapi.DataExportStatusSuccessful {
+				data.status = kdmpapi.DataExportStatusFailed
+				data.reason = err.Error()
+			}
+		}
 		// Append the job-pod log to stork's pod log in case of failure
 		// it is best effort approach, hence errors are ignored.
 		if dataExport.Status.Status == kdmpapi.DataExportStatusFailed && dataExport.Status.TransferID != "" {
@@ -543,6 +669,7 @@ func (c *Controller) sync(ctx context.Context, in *kdmpapi.DataExport) (bool, er
 			logrus.Errorf("%v", errMsg)
 			// Exhausted all retries, fail the CR
 			data.status = kdmpapi.DataExportStatusFailed
+			data.failureReason = metrics.FailureReasonCleanupFailed
 		}
 		return true, c.updateStatus(dataExport, data)
 	case kdmpapi.DataExportStageFinal:
@@ -614,14 +741,20 @@ func (c *Controller) createJobCredCertSecrets(
 ) (updateDataExportDetail, error) {
 	namespace := dataExport.Namespace
 	// Below are default values for kopiaRestore. There values will be updated with different, if the driveName is kopiaBackup.
-	var blName, blNamespace string
-	if driverName == drivers.KopiaRestore {
+	var blName, blNamespace, repository string
+	if driverName == drivers.KopiaRestore || driverName == drivers.KopiaReplicate {
 		blName = vb.Spec.BackupLocation.Name
 		blNamespace = vb.Spec.BackupLocation.Namespace
+		repository = vb.Spec.Repository
 	}
 	if driverName == drivers.KopiaBackup {
 		blName = dataExport.Spec.Destination.Name
 		blNamespace = dataExport.Spec.Destination.Namespace
+		repository = utils.GetKopiaRepositoryPath(getRepoPVCName(dataExport, srcPVCName), dataExport.Spec.Source.Namespace)
+	}
+	var repositories []string
+	if repository != "" {
+		repositories = append(repositories, repository)
 	}
 	// Creating a secret for ssl enabled objectstore with custom certificates
 	// User creates a secret in kube-system ns, mounts it to stork and recommendation is
@@ -633,7 +766,7 @@ func (c *Controller) createJobCredCertSecrets(
 
 	// Check if the above env is present and read the certs file contents and
 	// secret for the job pod for kopia to access the same
-	err := createCertificateSecret(utils.GetCertSecretName(dataExport.Name), namespace, blName, blNamespace, dataExport.Labels)
+	err := CreateCertificateSecret(utils.GetCertSecretName(dataExport.Name), namespace, blName, blNamespace, dataExport.Labels)
 	if err != nil {
 		msg := fmt.Sprintf("error in creating certificate secret[%v/%v]: %v", namespace, dataExport.Name, err)
 		logrus.Errorf(msg)
@@ -646,11 +779,12 @@ func (c *Controller) createJobCredCertSecrets(
 	// This will create a unique secret per PVC being backedup / restore
 	// Create secret in source ns because in case of multi ns backup
 	// BL CR is created in kube-system ns
-	err = CreateCredentialsSecret(
+	err = CreateRepositoryCredentialsSecret(
 		utils.GetCredSecretName(dataExport.Name),
 		blName,
 		blNamespace,
 		namespace,
+		repositories,
 		dataExport.Labels,
 	)
 	if err != nil {
@@ -662,6 +796,28 @@ func (c *Controller) createJobCredCertSecrets(
 		}
 		return data, err
 	}
+	if driverName == drivers.KopiaReplicate {
+		// The credentials of the backuplocation the snapshot is replicated to
+		err = createReplicaCredentialsSecret(
+			utils.GetReplicaCredSecretName(dataExport.Name),
+			blName,
+			blNamespace,
+			dataExport.Spec.Destination.Name,
+			dataExport.Spec.Destination.Namespace,
+			namespace,
+			repository,
+			dataExport.Labels,
+		)
+		if err != nil {
+			msg := fmt.Sprintf("failed to create replica cloud credential secret during %v : %v", driverName, err)
+			logrus.Errorf(msg)
+			data := updateDataExportDetail{
+				status: kdmpapi.DataExportStatusFailed,
+				reason: msg,
+			}
+			return data, err
+		}
+	}
 	return updateDataExportDetail{}, nil
 }
 
@@ -702,6 +858,8 @@ func (c *Controller) stageInitial(ctx context.Context, dataExport *kdmpapi.DataE
 		err = c.checkKopiaBackup(dataExport)
 	case drivers.KopiaRestore:
 		err = c.checkKopiaRestore(dataExport)
+	case drivers.KopiaReplicate:
+		err = c.checkKopiaReplicate(dataExport)
 	}
 	if err != nil {
 		msg := fmt.Sprintf("check failed: %s", err)
@@ -792,6 +950,15 @@ func (c *Controller) stageSnapshotScheduled(ctx context.Context, dataExport *kdm
 	annotations[pvcUIDKey] = pvcUID
 	labels := make(map[string]string)
 	labels[pvcNameKey] = utils.GetValidLabel(dataExport.Spec.Source.Name)
+	hookStatuses, err := runHooks(dataExport, kdmpapi.DataExportHookPhasePre)
+	if err != nil {
+		data := updateDataExportDetail{
+			status: kdmpapi.DataExportStatusFailed,
+			reason: err.Error(),
+			hooks:  hookStatuses,
+		}
+		return false, c.updateStatus(dataExport, data)
+	}
 	name, namespace, _, err := snapshotDriver.CreateSnapshot(
 		snapshotter.Name(snapName),
 		snapshotter.PVCName(dataExport.Spec.Source.Name),
@@ -802,11 +969,26 @@ func (c *Controller) stageSnapshotScheduled(ctx context.Context, dataExport *kdm
 		snapshotter.Annotations(annotations),
 		snapshotter.Labels(labels),
 	)
+	// The post hooks are run whether the snapshot is taken or not
+	postHookStatuses, hookErr := runHooks(dataExport, kdmpapi.DataExportHookPhasePost)
+	hookStatuses = append(hookStatuses, postHookStatuses...)
 	if err != nil {
 		msg := fmt.Sprintf("failed to create a snapshot: %s", err)
 		data := updateDataExportDetail{
 			status: kdmpapi.DataExportStatusFailed,
 			reason: msg,
+			hooks:  hookStatuses,
+		}
+		return false, c.updateStatus(dataExport, data)
+	}
+	if hookErr != nil {
+		data := updateDataExportDetail{
+			snapshotID:        name,
+			snapshotNamespace: namespace,
+			status:            kdmpapi.DataExportStatusFailed,
+			volumeSnapshot:    snapName,
+			reason:            hookErr.Error(),
+			hooks:             hookStatuses,
 		}
 		return false, c.updateStatus(dataExport, data)
 	}
@@ -817,6 +999,7 @@ func (c *Controller) stageSnapshotScheduled(ctx context.Context, dataExport *kdm
 		status:            kdmpapi.DataExportStatusSuccessful,
 		volumeSnapshot:    snapName,
 		reason:            "",
+		hooks:             hookStatuses,
 	}
 	return true, c.updateStatus(dataExport, data)
 }
@@ -1358,8 +1541,9 @@ func (c *Controller) stageLocalSnapshotRestoreInProgress(ctx context.Context, da
 		logrus.Infof("DE CR name: %v/%v job status: %v", dataExport.Namespace, dataExport.Name, progress.Status)
 		if progress.Status == batchv1.JobFailed {
 			data := updateDataExportDetail{
-				status: kdmpapi.DataExportStatusFailed,
-				reason: progress.Reason,
+				status:        kdmpapi.DataExportStatusFailed,
+				reason:        progress.Reason,
+				failureReason: metrics.FailureReasonJobFailed,
 			}
 			if len(progress.Reason) == 0 {
 				// As we couldn't get actual reason from kopia executor
@@ -1381,8 +1565,9 @@ func (c *Controller) stageLocalSnapshotRestoreInProgress(ctx context.Context, da
 			// If a job has failed it means it has tried all possible retires and given up.
 			// In such a scenario we need to fail DE CR and move to clean up stage
 			data := updateDataExportDetail{
-				status: kdmpapi.DataExportStatusFailed,
-				reason: errMsg,
+				status:        kdmpapi.DataExportStatusFailed,
+				reason:        errMsg,
+				failureReason: metrics.FailureReasonJobFailed,
 			}
 			return true, c.updateStatus(dataExport, data)
 		case drivers.JobStateCompleted:
@@ -1452,7 +1637,151 @@ func (c *Controller) stageLocalSnapshotRestoreInProgress(ctx context.Context, da
 
 }
 
+// handleAction applies the spec.action of an in-flight DataExport. It returns
+// true if the reconcile of the current stage must be skipped.
+func (c *Controller) handleAction(driver drivers.Interface, de *kdmpapi.DataExport) (bool, bool, error) {
+	stage := de.Status.Stage
+	if stage == kdmpapi.DataExportStageCleanup || stage == kdmpapi.DataExportStageFinal {
+		return false, false, nil
+	}
+
+	switch de.Spec.Action {
+	case kdmpapi.DataExportActionCancel:
+		// Stop the transfer right away, the cleanup stage removes the rest of
+		// the resources. The rsync jobs are not removed by the cleanup.
+		logrus.Infof("cancelling dataexport %v/%v in stage %v", de.Namespace, de.Name, stage)
+		if de.Status.TransferID != "" {
+			if err := driver.DeleteJob(de.Status.TransferID); err != nil && !k8sErrors.IsNotFound(err) {
+				return true, true, fmt.Errorf("delete %s job: %s", de.Status.TransferID, err)
+			}
+		}
+		data := updateDataExportDetail{
+			stage:  kdmpapi.DataExportStageCleanup,
+			status: kdmpapi.DataExportStatusCancelled,
+			reason: fmt.Sprintf("cancelled in stage %v", stage),
+		}
+		return true, true, c.updateStatus(de, data)
+	case kdmpapi.DataExportActionPause:
+		if de.Status.Status == kdmpapi.DataExportStatusPaused {
+			return true, false, nil
+		}
+		if isTransferJobRunning(de) {
+			if err := driver.DeleteJob(de.Status.TransferID); err != nil && !k8sErrors.IsNotFound(err) {
+				return true, true, fmt.Errorf("delete %s job: %s", de.Status.TransferID, err)
+			}
+			// The job is created again once the dataexport is resumed.
+			logrus.Infof("paused dataexport %v/%v, deleted transfer job %v", de.Namespace, de.Name, de.Status.TransferID)
+			data := updateDataExportDetail{
+				stage:      kdmpapi.DataExportStageTransferScheduled,
+				status:     kdmpapi.DataExportStatusPaused,
+				reason:     fmt.Sprintf("paused in stage %v", stage),
+				resetHooks: releaseHooks(de),
+			}
+			return true, false, c.updateStatus(de, data)
+		}
+		// A stage is paused only before its work is started. A started stage
+		// goes on until the next one, so that resume does not repeat its work.
+		// A job waiting in the job queue gives up its place.
+		if de.Status.Status == kdmpapi.DataExportStatusInitial || de.Status.Status == kdmpapi.DataExportStatusPending {
+			c.jobQueue.Remove(utils.NamespacedName(de.Namespace, de.Name))
+			logrus.Infof("paused dataexport %v/%v in stage %v", de.Namespace, de.Name, stage)
+			data := updateDataExportDetail{
+				status:     kdmpapi.DataExportStatusPaused,
+				reason:     fmt.Sprintf("paused in stage %v", stage),
+				resetHooks: releaseHooks(de),
+			}
+			return true, false, c.updateStatus(de, data)
+		}
+		return false, false, nil
+	}
+
+	// Resume action or the pause action removed.
+	if de.Status.Status == kdmpapi.DataExportStatusPaused {
+		// The job deleted by the pause is created again under the same name,
+		// once it is gone.
+		if !isTransferJobDeleted(de) {
+			logrus.Infof("dataexport %v/%v waits for the deletion of transfer job %v to resume", de.Namespace, de.Name, de.Status.TransferID)
+			return true, true, nil
+		}
+		logrus.Infof("resuming dataexport %v/%v from stage %v", de.Namespace, de.Name, stage)
+		data := updateDataExportDetail{
+			status: kdmpapi.DataExportStatusInitial,
+		}
+		return true, true, c.updateStatus(de, data)
+	}
+	return false, false, nil
+}
+
+// transferJobFailed fails the DataExport after a transfer job failure, or
+// deletes the job and schedules it again after a backoff if the failure is
+// transient and retries are left. nodeFailed is set by the driver if the job
+// failed to mount its volumes or its node is not ready.
+func (c *Controller) transferJobFailed(driver drivers.Interface, de *kdmpapi.DataExport, reason string, nodeFailed bool) (bool, error) {
+	class := classifyFailure(reason, nodeFailed)
+	policy := getRetryPolicy()
+	if !policy.shouldRetry(class, de.Status.RetryCount) {
+		data := updateDataExportDetail{
+			status:        kdmpapi.DataExportStatusFailed,
+			reason:        reason,
+			failureReason: metrics.FailureReasonJobFailed,
+			failureClass:  class,
+		}
+		return true, c.updateStatus(de, data)
+	}
+
+	if err := driver.DeleteJob(de.Status.TransferID); err != nil && !k8sErrors.IsNotFound(err) {
+		return true, fmt.Errorf("delete %s job: %s", de.Status.TransferID, err)
+	}
+	retryCount := de.Status.RetryCount + 1
+	nextRetryTime := metav1.NewTime(time.Now().Add(policy.backoffFor(de.Status.RetryCount)))
+	logrus.Infof("retrying transfer job %v of dataexport %v/%v at %v (%d/%d) after a transient failure: %v",
+		de.Status.TransferID, de.Namespace, de.Name, nextRetryTime, retryCount, policy.limit, reason)
+	data := updateDataExportDetail{
+		stage:         kdmpapi.DataExportStageTransferScheduled,
+		status:        kdmpapi.DataExportStatusInitial,
+		reason:        fmt.Sprintf("retry %d/%d after a transient failure: %s", retryCount, policy.limit, reason),
+		failureClass:  class,
+		retryCount:    retryCount,
+		nextRetryTime: &nextRetryTime,
+	}
+	return true, c.updateStatus(de, data)
+}
+
+// isRetryDue returns whether a transfer job waiting for its retry backoff can
+// be started again. The failed job has to be deleted first.
+func isRetryDue(de *kdmpapi.DataExport) bool {
+	if time.Now().Before(de.Status.NextRetryTime.Time) {
+		return false
+	}
+	return isTransferJobDeleted(de)
+}
+
+// isTransferJobDeleted returns whether the transfer job of a DataExport is
+// gone, so that it can be created again.
+func isTransferJobDeleted(de *kdmpapi.DataExport) bool {
+	if de.Status.TransferID == "" {
+		return true
+	}
+	namespace, name, err := utils.ParseJobID(de.Status.TransferID)
+	if err != nil {
+		return true
+	}
+	return !jobratelimit.IsJobAlreadyPresent(name, namespace)
+}
+
+func isTransferJobRunning(de *kdmpapi.DataExport) bool {
+	switch de.Status.Stage {
+	case kdmpapi.DataExportStageTransferScheduled:
+		return de.Status.Status == kdmpapi.DataExportStatusSuccessful
+	case kdmpapi.DataExportStageTransferInProgress:
+		return de.Status.Status != kdmpapi.DataExportStatusSuccessful &&
+			de.Status.Status != kdmpapi.DataExportStatusFailed
+	}
+	return false
+}
+
 func (c *Controller) cleanUp(driver drivers.Interface, de *kdmpapi.DataExport) error {
+	c.jobQueue.Remove(utils.NamespacedName(de.Namespace, de.Name))
 	doCleanup, err := utils.DoCleanupResource()
 	if err != nil {
 		return err
@@ -1464,6 +1793,10 @@ func (c *Controller) cleanUp(driver drivers.Interface, de *kdmpapi.DataExport) e
 		return fmt.Errorf("driver is nil")
 	}
 	if driver.Name() == string(kdmpapi.DataExportRsync) {
+		// The rsync daemon of a cross-cluster migration holds the destination pvc
+		if de.Spec.ClusterPair != "" && de.Status.TransferID != "" {
+			return driver.DeleteJob(de.Status.TransferID)
+		}
 		// No cleanup needed for rsync
 		return nil
 	}
@@ -1583,6 +1916,13 @@ func (c *Controller) cleanUp(driver drivers.Interface, de *kdmpapi.DataExport) e
 		logrus.Errorf(errMsg)
 		return fmt.Errorf(errMsg)
 	}
+	if driver.Name() == drivers.KopiaReplicate {
+		if err := core.Instance().DeleteSecret(utils.GetReplicaCredSecretName(de.Name), namespace); err != nil && !k8sErrors.IsNotFound(err) {
+			errMsg := fmt.Sprintf("deletion of replica credential secret %s failed: %v", de.Name, err)
+			logrus.Errorf(errMsg)
+			return fmt.Errorf(errMsg)
+		}
+	}
 	// Deleting image secret, if present
 	// Not checking for presence of the secret, instead try delete and ignore if the error is NotFound
 	if err := core.Instance().DeleteSecret(utils.GetImageSecretName(de.Name), namespace); err != nil && !k8sErrors.IsNotFound(err) {
@@ -1658,7 +1998,11 @@ func (c *Controller) cleanupLocalRestoredSnapshotResources(de *kdmpapi.DataExpor
 }
 
 func (c *Controller) updateStatus(de *kdmpapi.DataExport, data updateDataExportDetail) error {
-	var actualErr error
+	var (
+		actualErr          error
+		prevStage, stage   kdmpapi.DataExportStage
+		prevStatus, status kdmpapi.DataExportStatus
+	)
 	t := func() (interface{}, bool, error) {
 		namespacedName := types.NamespacedName{}
 		namespacedName.Name = de.Name
@@ -1669,8 +2013,11 @@ func (c *Controller) updateStatus(de *kdmpapi.DataExport, data updateDataExportD
 			logrus.Infof("%v", errMsg)
 			return "", true, fmt.Errorf("%v", errMsg)
 		}
+		prevStage, prevStatus = de.Status.Stage, de.Status.Status
 		// Need to set the reason with out any check as in the success case, we need to set the reason to empty.
 		de.Status.Reason = data.reason
+		// The position is only kept while the job waits in the queue.
+		de.Status.QueuePosition = data.queuePosition
 		if data.stage != "" {
 			de.Status.Stage = data.stage
 		}
@@ -1707,11 +2054,34 @@ func (c *Controller) updateStatus(de *kdmpapi.DataExport, data updateDataExportD
 		if data.volumeSnapshot != "" {
 			de.Status.VolumeSnapshot = data.volumeSnapshot
 		}
+		if data.failureClass != "" {
+			de.Status.FailureClass = data.failureClass
+		}
+		if data.retryCount != 0 {
+			de.Status.RetryCount = data.retryCount
+		}
+		if data.nextRetryTime != nil {
+			de.Status.NextRetryTime = data.nextRetryTime
+		}
+		if data.resetNextRetryTime {
+			de.Status.NextRetryTime = nil
+		}
+		if data.resetRetries {
+			resetRetries(&de.Status)
+		}
+		if data.resetHooks {
+			de.Status.Hooks = nil
+		}
+		de.Status.Hooks = append(de.Status.Hooks, data.hooks...)
+		if data.stats != nil {
+			de.Status.Stats = data.stats
+		}
 		if data.resetLocalSnapshotRestore {
 			// Resetting the SnapshotStorageClass to empty, when ever we try kopia restore, if localsnapshot restore fail.
 			de.Spec.SnapshotStorageClass = ""
 			de.Status.LocalSnapshotRestore = false
 		}
+		stage, status = de.Status.Stage, de.Status.Status
 
 		actualErr = c.client.Update(context.TODO(), de)
 		if actualErr != nil {
@@ -1728,9 +2098,54 @@ func (c *Controller) updateStatus(de *kdmpapi.DataExport, data updateDataExportD
 		// Exhausted all retries, fail the CR
 		return fmt.Errorf("%v", errMsg)
 	}
+	observeStatusChange(de, prevStage, prevStatus, stage, status, data.failureReason)
 	return nil
 }
 
+func observeStatusChange(
+	de *kdmpapi.DataExport,
+	prevStage kdmpapi.DataExportStage,
+	prevStatus kdmpapi.DataExportStatus,
+	stage kdmpapi.DataExportStage,
+	status kdmpapi.DataExportStatus,
+	failureReason string,
+) {
+	if stage == prevStage && status == prevStatus {
+		return
+	}
+	driverName, err := getDriverType(de)
+	if err != nil {
+		driverName = metrics.UnknownDriver
+	}
+	metrics.ObserveStageTransition(driverName, stage, status)
+	// The failed status is carried over to the cleanup and final stages.
+	if status == kdmpapi.DataExportStatusFailed && prevStatus != kdmpapi.DataExportStatusFailed {
+		if failureReason == "" {
+			failureReason = metrics.FailureReasonOther
+		}
+		metrics.ObserveFailure(driverName, stage, failureReason)
+	}
+}
+
+// observeTransfer records the duration and the size of a completed transfer
+// job. It is best effort, the errors are only logged.
+func observeTransfer(driverName, transferID string, bytes uint64) {
+	namespace, name, err := utils.ParseJobID(transferID)
+	if err != nil {
+		logrus.Warnf("failed to parse job ID %v for metrics: %v", transferID, err)
+		return
+	}
+	job, err := batch.Instance().GetJob(name, namespace)
+	if err != nil {
+		logrus.Warnf("failed to get job %v for metrics: %v", transferID, err)
+		return
+	}
+	if job.Status.StartTime == nil || job.Status.CompletionTime == nil {
+		return
+	}
+	metrics.ObserveTransfer(driverName, job.Status.CompletionTime.Sub(job.Status.StartTime.Time), bytes)
+}
+
 func (c *Controller) restoreSnapshot(ctx context.Context, snapshotDriver snapshotter.Driver, de *kdmpapi.DataExport) (*corev1.PersistentVolumeClaim, error) {
 	if snapshotDriver == nil {
 		return nil, fmt.Errorf("snapshot driver is nil")
@@ -1753,6 +2168,12 @@ func (c *Controller) restoreSnapshot(ctx context.Context, snapshotDriver snapsho
 		},
 	}
 
+	// A block backup attaches the snapshot to the backup job as a raw block
+	// device, the source PVC is checked to be in block volume mode too.
+	if isBlockBackup(de) {
+		pvc.Spec.VolumeMode = srcPvc.Spec.VolumeMode
+	}
+
 	// We don't want other reconcilors (if any) to backup this temporary PVC
 	pvc.Annotations = make(map[string]string)
 	pvc.Annotations[skipResourceAnnotation] = "true"
@@ -1798,6 +2219,9 @@ func (c *Controller) createPVC(dataExport *kdmpapi.DataExport) (*corev1.Persiste
 }
 
 func (c *Controller) checkClaims(de *kdmpapi.DataExport) error {
+	if de.Spec.ClusterPair != "" {
+		return c.checkRemoteClaims(de)
+	}
 	if !hasSnapshotStage(de) && de.Spec.Source.Namespace != de.Spec.Destination.Namespace {
 		return fmt.Errorf("source and destination volume claims should be in the same namespace if no snapshot class is provided")
 	}
@@ -1823,22 +2247,117 @@ func (c *Controller) checkClaims(de *kdmpapi.DataExport) error {
 	return nil
 }
 
+// checkRemoteClaims checks the claims of a cross-cluster migration, the
+// destination pvc is on the remote cluster of the ClusterPair.
+func (c *Controller) checkRemoteClaims(de *kdmpapi.DataExport) error {
+	if hasSnapshotStage(de) {
+		return fmt.Errorf("snapshots are not supported with a cluster pair")
+	}
+
+	// the sync can be repeated while the application is running, the job is
+	// scheduled on its node
+	srcPVC, err := checkPVC(de.Spec.Source, false)
+	if err != nil {
+		return fmt.Errorf("source pvc: %v", err)
+	}
+
+	if err := checkNameNamespace(de.Spec.Destination); err != nil {
+		return fmt.Errorf("destination pvc: %v", err)
+	}
+	remote, err := utils.GetClusterPairClient(de.Spec.ClusterPair, de.Namespace)
+	if err != nil {
+		return err
+	}
+	dstPVC, err := remote.GetPersistentVolumeClaim(de.Spec.Destination.Name, de.Spec.Destination.Namespace)
+	if err != nil {
+		return fmt.Errorf("destination pvc: %v", err)
+	}
+	pods, err := remote.GetPodsUsingPVC(dstPVC.Name, dstPVC.Namespace)
+	if err != nil {
+		return fmt.Errorf("destination pvc: get mounted pods: %v", err)
+	}
+	for _, pod := range pods {
+		// ignore the rsync daemon of a previous sync
+		if _, ok := pod.Labels[drivers.DriverNameLabel]; !ok {
+			return fmt.Errorf("destination pvc: mounted to %v pods", toPodNames(pods))
+		}
+	}
+
+	srcReq := srcPVC.Spec.Resources.Requests[corev1.ResourceStorage]
+	dstReq := dstPVC.Spec.Resources.Requests[corev1.ResourceStorage]
+	// dstReq < srcReq
+	if dstReq.Cmp(srcReq) == -1 {
+		return fmt.Errorf("size of the destination pvc (%s) is less than of the source one (%s)", dstReq.String(), srcReq.String())
+	}
+
+	return nil
+}
+
 func (c *Controller) checkResticBackup(de *kdmpapi.DataExport) error {
 	return c.checkGenericBackup(de)
 }
 
 func (c *Controller) checkResticRestore(de *kdmpapi.DataExport) error {
+	if de.Spec.FileRestore != nil {
+		return fmt.Errorf("file restore is not supported for restic")
+	}
 	return c.checkGenericRestore(de)
 }
 
 func (c *Controller) checkKopiaBackup(de *kdmpapi.DataExport) error {
-	return c.checkGenericBackup(de)
+	if err := c.checkGenericBackup(de); err != nil {
+		return err
+	}
+	// The block backups are restored by writing them onto a PVC in block
+	// volume mode, the backed up PVC has to be in block volume mode too.
+	if isBlockBackup(de) {
+		pvc, err := checkPVC(de.Spec.Source, false)
+		if err != nil {
+			return fmt.Errorf("source: %s", err)
+		}
+		if pvc.Spec.VolumeMode == nil || *pvc.Spec.VolumeMode != corev1.PersistentVolumeBlock {
+			return fmt.Errorf("source: block backup mode needs a pvc in %s volume mode", corev1.PersistentVolumeBlock)
+		}
+	}
+	return nil
 }
 
 func (c *Controller) checkKopiaRestore(de *kdmpapi.DataExport) error {
+	if de.Spec.FileRestore != nil && len(de.Spec.FileRestore.IncludePaths) == 0 {
+		return fmt.Errorf("include paths should be set for file restore")
+	}
 	return c.checkGenericRestore(de)
 }
 
+// checkKopiaReplicate checks the VolumeBackup to replicate and the
+// backuplocation it is replicated to. The job accessing the VolumeBackup runs
+// in the namespace of the DataExport.
+func (c *Controller) checkKopiaReplicate(de *kdmpapi.DataExport) error {
+	if de.Spec.Source.Namespace != de.Namespace {
+		return fmt.Errorf("source is expected to be in the dataexport namespace %s", de.Namespace)
+	}
+	vb, err := checkVolumeBackup(de.Spec.Source)
+	if err != nil {
+		return fmt.Errorf("source: %s", err)
+	}
+	if vb.Status.SnapshotID == "" {
+		return fmt.Errorf("source: volumebackup %s/%s has no snapshot", vb.Namespace, vb.Name)
+	}
+
+	bl, err := checkBackupLocation(de.Spec.Destination)
+	if err != nil {
+		return fmt.Errorf("destination: %s", err)
+	}
+	if bl.Name == vb.Spec.BackupLocation.Name && bl.Namespace == vb.Spec.BackupLocation.Namespace {
+		return fmt.Errorf("destination: volumebackup %s/%s is already in backuplocation %s/%s", vb.Namespace, vb.Name, bl.Namespace, bl.Name)
+	}
+	// The job mounts a single NFS share, the one of the source backuplocation.
+	if bl.Location.Type == storkapi.BackupLocationNFS {
+		return fmt.Errorf("destination: replication to an NFS backuplocation is not supported")
+	}
+	return nil
+}
+
 func (c *Controller) checkGenericBackup(de *kdmpapi.DataExport) error {
 	if !isPVCRef(de.Spec.Source) && !isAPIVersionKindNotSetRef(de.Spec.Source) {
 		return fmt.Errorf("source is expected to be PersistentVolumeClaim")
@@ -1882,6 +2401,8 @@ func startTransferJob(
 	jobConfigMap string,
 	jobConfigMapNs string,
 	backupLocation *storkapi.BackupLocation,
+	maxUploadSpeed int64,
+	maxDownloadSpeed int64,
 ) (string, error) {
 	if drv == nil {
 		return "", fmt.Errorf("data transfer driver is not set")
@@ -1910,11 +2431,19 @@ func startTransferJob(
 
 	switch drv.Name() {
 	case drivers.Rsync:
+		namespace := dataExport.Spec.Destination.Namespace
+		if dataExport.Spec.ClusterPair != "" {
+			// the destination namespace is on the remote cluster
+			namespace = dataExport.Spec.Source.Namespace
+		}
 		return drv.StartJob(
 			drivers.WithSourcePVC(srcPVCName),
-			drivers.WithNamespace(dataExport.Spec.Destination.Namespace),
+			drivers.WithNamespace(namespace),
 			drivers.WithDataExportUID(string(dataExport.UID)),
 			drivers.WithDestinationPVC(dataExport.Spec.Destination.Name),
+			drivers.WithDestinationPVCNamespace(dataExport.Spec.Destination.Namespace),
+			drivers.WithClusterPair(dataExport.Spec.ClusterPair),
+			drivers.WithClusterPairNamespace(dataExport.Namespace),
 			drivers.WithLabels(dataExport.Labels),
 		)
 	case drivers.ResticBackup:
@@ -1924,6 +2453,7 @@ func startTransferJob(
 			drivers.WithBackupLocationName(dataExport.Spec.Destination.Name),
 			drivers.WithBackupLocationNamespace(dataExport.Spec.Destination.Namespace),
 			drivers.WithLabels(dataExport.Labels),
+			drivers.WithMaxUploadSpeed(maxUploadSpeed),
 		)
 	case drivers.ResticRestore:
 		return drv.StartJob(
@@ -1933,6 +2463,7 @@ func startTransferJob(
 			drivers.WithVolumeBackupName(dataExport.Spec.Source.Name),
 			drivers.WithVolumeBackupNamespace(dataExport.Spec.Source.Namespace),
 			drivers.WithLabels(dataExport.Labels),
+			drivers.WithMaxDownloadSpeed(maxDownloadSpeed),
 		)
 	case drivers.KopiaBackup:
 		return drv.StartJob(
@@ -1959,6 +2490,10 @@ func startTransferJob(
 			drivers.WithNfsMountOption(nfsMountOption),
 			drivers.WithPodUserId(psaJobUid),
 			drivers.WithPodGroupId(psaJobGid),
+			drivers.WithBlockMode(isBlockBackup(dataExport)),
+			drivers.WithLiveBackup(isLiveBackup(dataExport)),
+			drivers.WithMaxUploadSpeed(maxUploadSpeed),
+			drivers.WithJobQueueAdmitted(true),
 		)
 	case drivers.KopiaRestore:
 		return drv.StartJob(
@@ -1969,6 +2504,8 @@ func startTransferJob(
 			drivers.WithVolumeBackupName(dataExport.Spec.Source.Name),
 			drivers.WithVolumeBackupNamespace(dataExport.Spec.Source.Namespace),
 			drivers.WithBackupLocationNamespace(dataExport.Spec.Source.Namespace),
+			drivers.WithIncludePaths(getIncludePaths(dataExport)),
+			drivers.WithRestoreSubPath(getRestoreSubPath(dataExport)),
 			drivers.WithLabels(dataExport.Labels),
 			drivers.WithDataExportName(dataExport.GetName()),
 			drivers.WithCertSecretName(utils.GetCertSecretName(dataExport.GetName())),
@@ -1981,6 +2518,31 @@ func startTransferJob(
 			drivers.WithPodUserId(psaJobUid),
 			drivers.WithPodGroupId(psaJobGid),
 			drivers.WithNfsMountOption(nfsMountOption),
+			drivers.WithMaxDownloadSpeed(maxDownloadSpeed),
+			drivers.WithJobQueueAdmitted(true),
+		)
+	case drivers.KopiaReplicate:
+		return drv.StartJob(
+			drivers.WithKopiaImageExecutorSource(dataExport.Spec.TriggeredFrom),
+			drivers.WithKopiaImageExecutorSourceNs(dataExport.Spec.TriggeredFromNs),
+			drivers.WithNamespace(dataExport.Namespace),
+			drivers.WithVolumeBackupName(dataExport.Spec.Source.Name),
+			drivers.WithVolumeBackupNamespace(dataExport.Spec.Source.Namespace),
+			drivers.WithBackupLocationName(dataExport.Spec.Destination.Name),
+			drivers.WithBackupLocationNamespace(dataExport.Spec.Destination.Namespace),
+			drivers.WithLabels(dataExport.Labels),
+			drivers.WithDataExportName(dataExport.GetName()),
+			drivers.WithCertSecretName(utils.GetCertSecretName(dataExport.GetName())),
+			drivers.WithCertSecretNamespace(dataExport.Namespace),
+			drivers.WithJobConfigMap(jobConfigMap),
+			drivers.WithJobConfigMapNs(jobConfigMapNs),
+			drivers.WithNodeAffinity(nodeLabel),
+			drivers.WithNfsServer(nfsServerAddr),
+			drivers.WithNfsExportDir(nfsExportPath),
+			drivers.WithPodUserId(psaJobUid),
+			drivers.WithPodGroupId(psaJobGid),
+			drivers.WithNfsMountOption(nfsMountOption),
+			drivers.WithJobQueueAdmitted(true),
 		)
 	}
 
@@ -2134,7 +2696,21 @@ func toPodNames(objs []corev1.Pod) []string {
 }
 
 func hasSnapshotStage(de *kdmpapi.DataExport) bool {
-	return de.Spec.SnapshotStorageClass != ""
+	return de.Spec.SnapshotStorageClass != "" && !isLiveBackup(de)
+}
+
+func getIncludePaths(de *kdmpapi.DataExport) []string {
+	if de.Spec.FileRestore == nil {
+		return nil
+	}
+	return de.Spec.FileRestore.IncludePaths
+}
+
+func getRestoreSubPath(de *kdmpapi.DataExport) string {
+	if de.Spec.FileRestore == nil {
+		return ""
+	}
+	return de.Spec.FileRestore.TargetSubPath
 }
 
 func hasLocalRestoreStage(de *kdmpapi.DataExport) bool {
@@ -2146,6 +2722,7 @@ func getDriverType(de *kdmpapi.DataExport) (string, error) {
 	dst := de.Spec.Destination
 	doBackup := false
 	doRestore := false
+	doReplicate := false
 
 	if de.Spec.Type == kdmpapi.DataExportRsync {
 		return string(de.Spec.Type), nil
@@ -2161,6 +2738,8 @@ func getDriverType(de *kdmpapi.DataExport) (string, error) {
 	case isVolumeBackupRef(src):
 		if isPVCRef(dst) || (isAPIVersionKindNotSetRef(dst)) {
 			doRestore = true
+		} else if isBackupLocationRef(dst) {
+			doReplicate = true
 		} else {
 			return "", fmt.Errorf("invalid kind for generic restore destination: expected PersistentVolumeClaim")
 		}
@@ -2184,6 +2763,10 @@ func getDriverType(de *kdmpapi.DataExport) (string, error) {
 		if doRestore {
 			return drivers.KopiaRestore, nil
 		}
+
+		if doReplicate {
+			return drivers.KopiaReplicate, nil
+		}
 		return "", fmt.Errorf("invalid kind for generic source: expected PersistentVolumeClaim or VolumeBackup")
 	}
 
@@ -2222,16 +2805,63 @@ func CreateCredentialsSecret(secretName, blName, blNamespace, namespace string,
 	if err != nil {
 		return err
 	}
+	return createCredentialsSecret(secretName, backupLocation, namespace, labels, nil)
+}
+
+// CreateRepositoryCredentialsSecret is CreateCredentialsSecret for the jobs
+// using kopia repositories. The data keys of the repositories are added if the
+// backup location has a KMS.
+func CreateRepositoryCredentialsSecret(secretName, blName, blNamespace, namespace string, repositories []string, labels map[string]string) error {
+	backupLocation, err := readBackupLocation(blName, blNamespace, "")
+	if err != nil {
+		return err
+	}
+	return createCredentialsSecret(secretName, backupLocation, namespace, labels, func(credentialData map[string][]byte) error {
+		return kms.AddDataKeyCredentials(credentialData, backupLocation, repositories...)
+	})
+}
+
+// createReplicaCredentialsSecret creates the credential secret of the backup
+// location a repository is replicated to, with the data key of the repository
+// wrapped with its KMS.
+func createReplicaCredentialsSecret(secretName, srcBLName, srcBLNamespace, blName, blNamespace, namespace, repository string, labels map[string]string) error {
+	srcBackupLocation, err := readBackupLocation(srcBLName, srcBLNamespace, "")
+	if err != nil {
+		return err
+	}
+	backupLocation, err := readBackupLocation(blName, blNamespace, "")
+	if err != nil {
+		return err
+	}
+	return createCredentialsSecret(secretName, backupLocation, namespace, labels, func(credentialData map[string][]byte) error {
+		return kms.AddReplicaDataKeyCredentials(credentialData, srcBackupLocation, backupLocation, repository)
+	})
+}
+
+func createCredentialsSecret(
+	secretName string,
+	backupLocation *storkapi.BackupLocation,
+	namespace string,
+	labels map[string]string,
+	addDataKeys func(map[string][]byte) error,
+) error {
+	// fail early on an invalid or unsupported kms config
+	if _, err := kms.ConfigFromBackupLocation(backupLocation); err != nil {
+		return err
+	}
+	if addDataKeys == nil {
+		addDataKeys = func(map[string][]byte) error { return nil }
+	}
 
 	// TODO: Add for other cloud providers
 	// Creating cloud cred secret
 	switch backupLocation.Location.Type {
 	case storkapi.BackupLocationS3:
-		return createS3Secret(secretName, backupLocation, namespace, labels)
+		return createS3Secret(secretName, backupLocation, namespace, labels, addDataKeys)
 	case storkapi.BackupLocationGoogle:
-		return createGoogleSecret(secretName, backupLocation, namespace, labels)
+		return createGoogleSecret(secretName, backupLocation, namespace, labels, addDataKeys)
 	case storkapi.BackupLocationAzure:
-		return createAzureSecret(secretName, backupLocation, namespace, labels)
+		return createAzureSecret(secretName, backupLocation, namespace, labels, addDataKeys)
 	case storkapi.BackupLocationNFS:
 		return utils.CreateNfsSecret(secretName, backupLocation, namespace, labels)
 	}
@@ -2261,7 +2891,7 @@ func readBackupLocation(name, namespace, filePath string) (*storkapi.BackupLocat
 	return out, nil
 }
 
-func createS3Secret(secretName string, backupLocation *storkapi.BackupLocation, namespace string, labels map[string]string) error {
+func createS3Secret(secretName string, backupLocation *storkapi.BackupLocation, namespace string, labels map[string]string, addDataKeys func(map[string][]byte) error) error {
 	credentialData := make(map[string][]byte)
 	credentialData["endpoint"] = []byte(backupLocation.Location.S3Config.Endpoint)
 	credentialData["accessKey"] = []byte(backupLocation.Location.S3Config.AccessKeyID)
@@ -2272,24 +2902,33 @@ func createS3Secret(secretName string, backupLocation *storkapi.BackupLocation,
 	credentialData["password"] = []byte(backupLocation.Location.RepositoryPassword)
 	credentialData["disablessl"] = []byte(strconv.FormatBool(backupLocation.Location.S3Config.DisableSSL))
 	credentialData["sse"] = []byte(backupLocation.Location.S3Config.SSE)
+	if err := addDataKeys(credentialData); err != nil {
+		return err
+	}
+	if err := utils.AddObjectLockCredentials(credentialData, backupLocation); err != nil {
+		return err
+	}
 	err := utils.CreateJobSecret(secretName, namespace, credentialData, labels)
 
 	return err
 }
 
-func createGoogleSecret(secretName string, backupLocation *storkapi.BackupLocation, namespace string, labels map[string]string) error {
+func createGoogleSecret(secretName string, backupLocation *storkapi.BackupLocation, namespace string, labels map[string]string, addDataKeys func(map[string][]byte) error) error {
 	credentialData := make(map[string][]byte)
 	credentialData["type"] = []byte(backupLocation.Location.Type)
 	credentialData["password"] = []byte(backupLocation.Location.RepositoryPassword)
 	credentialData["accountkey"] = []byte(backupLocation.Location.GoogleConfig.AccountKey)
 	credentialData["projectid"] = []byte(backupLocation.Location.GoogleConfig.ProjectID)
 	credentialData["path"] = []byte(backupLocation.Location.Path)
+	if err := addDataKeys(credentialData); err != nil {
+		return err
+	}
 	err := utils.CreateJobSecret(secretName, namespace, credentialData, labels)
 
 	return err
 }
 
-func createAzureSecret(secretName string, backupLocation *storkapi.BackupLocation, namespace string, labels map[string]string) error {
+func createAzureSecret(secretName string, backupLocation *storkapi.BackupLocation, namespace string, labels map[string]string, addDataKeys func(map[string][]byte) error) error {
 	credentialData := make(map[string][]byte)
 	credentialData["type"] = []byte(backupLocation.Location.Type)
 	credentialData["password"] = []byte(backupLocation.Location.RepositoryPassword)
@@ -2297,12 +2936,17 @@ func createAzureSecret(secretName string, backupLocation *storkapi.BackupLocatio
 	credentialData["storageaccountname"] = []byte(backupLocation.Location.AzureConfig.StorageAccountName)
 	credentialData["storageaccountkey"] = []byte(backupLocation.Location.AzureConfig.StorageAccountKey)
 	credentialData["environment"] = []byte(backupLocation.Location.AzureConfig.Environment)
+	if err := addDataKeys(credentialData); err != nil {
+		return err
+	}
 	err := utils.CreateJobSecret(secretName, namespace, credentialData, labels)
 
 	return err
 }
 
-func createCertificateSecret(secretName, namespace, blName, blNamespace string, labels map[string]string) error {
+// CreateCertificateSecret creates the secret holding the custom certificate
+// of the objectstore of a BackupLocation, if one is configured.
+func CreateCertificateSecret(secretName, namespace, blName, blNamespace string, labels map[string]string) error {
 	backupLocation, err := readBackupLocation(blName, blNamespace, "")
 	if err != nil {
 		return err
@@ -2365,6 +3009,86 @@ func getRepoPVCName(de *kdmpapi.DataExport, pvcName string) string {
 	return pvcName
 }
 
+func isBlockBackup(de *kdmpapi.DataExport) bool {
+	driverName, err := getDriverType(de)
+	if err != nil {
+		return false
+	}
+	return driverName == drivers.KopiaBackup && getAnnotationValue(de, backupModeKey) == backupModeBlock
+}
+
+func isLiveBackup(de *kdmpapi.DataExport) bool {
+	driverName, err := getDriverType(de)
+	if err != nil {
+		return false
+	}
+	return driverName == drivers.KopiaBackup && getAnnotationValue(de, backupModeKey) == backupModeLive
+}
+
+// getSpeedLimits returns the upload and download speed limits of the data
+// transfer job of a DataExport, evaluated at the job start time.
+func getSpeedLimits(
+	de *kdmpapi.DataExport,
+	vb *kdmpapi.VolumeBackup,
+	driverName string,
+	config map[string]string,
+) (int64, int64, error) {
+	blName := de.Spec.Destination.Name
+	blNamespace := de.Spec.Destination.Namespace
+	if driverName == drivers.KopiaRestore || driverName == drivers.ResticRestore {
+		if vb == nil {
+			var err error
+			vb, err = kdmpopts.Instance().GetVolumeBackup(context.Background(), de.Spec.Source.Name, de.Spec.Source.Namespace)
+			if err != nil {
+				return 0, 0, err
+			}
+		}
+		blName = vb.Spec.BackupLocation.Name
+		blNamespace = vb.Spec.BackupLocation.Namespace
+	}
+
+	now := time.Now()
+	uploadSpeed, err := utils.GetSpeedLimit(now, blNamespace, blName,
+		getAnnotationValue(de, maxUploadSpeedKey), config[utils.MaxUploadSpeedKey])
+	if err != nil {
+		return 0, 0, err
+	}
+	downloadSpeed, err := utils.GetSpeedLimit(now, blNamespace, blName,
+		getAnnotationValue(de, maxDownloadSpeedKey), config[utils.MaxDownloadSpeedKey])
+	if err != nil {
+		return 0, 0, err
+	}
+	return uploadSpeed, downloadSpeed, nil
+}
+
+// getJobPriority returns the priority of the data transfer job in the job
+// queue. It is taken from the priority annotation or else from the priority of
+// the application pods using the source PVC.
+func getJobPriority(de *kdmpapi.DataExport) int32 {
+	if val := getAnnotationValue(de, jobPriorityKey); val != "" {
+		priority, err := strconv.ParseInt(val, 10, 32)
+		if err == nil {
+			return int32(priority)
+		}
+		logrus.Warnf("invalid %v annotation %q of dataexport [%v/%v]: %v", jobPriorityKey, val, de.Namespace, de.Name, err)
+	}
+	if !isPVCRef(de.Spec.Source) && !isAPIVersionKindNotSetRef(de.Spec.Source) {
+		return 0
+	}
+	pods, err := core.Instance().GetPodsUsingPVC(de.Spec.Source.Name, de.Spec.Source.Namespace)
+	if err != nil {
+		logrus.Warnf("failed to get the pods using pvc [%v/%v]: %v", de.Spec.Source.Namespace, de.Spec.Source.Name, err)
+		return 0
+	}
+	var priority int32
+	for _, pod := range pods {
+		if pod.Spec.Priority != nil && *pod.Spec.Priority > priority {
+			priority = *pod.Spec.Priority
+		}
+	}
+	return priority
+}
+
 func getAnnotationValue(de *kdmpapi.DataExport, key string) string {
 	var val string
 	if _, ok := de.Annotations[key]; ok {
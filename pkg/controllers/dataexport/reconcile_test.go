@@ -0,0 +1,169 @@
+package dataexport
+
+import (
+	"context"
+	"testing"
+
+	kdmpapi "github.com/portworx/kdmp/pkg/apis/kdmp/v1alpha1"
+	"github.com/portworx/kdmp/pkg/drivers"
+	"github.com/portworx/kdmp/pkg/jobratelimit"
+	"github.com/portworx/sched-ops/k8s/batch"
+	"github.com/stretchr/testify/require"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// fakeClient keeps a single DataExport, the other methods are not used.
+type fakeClient struct {
+	runtimeclient.Client
+	de *kdmpapi.DataExport
+}
+
+func (c *fakeClient) Get(ctx context.Context, key runtimeclient.ObjectKey, obj runtimeclient.Object, opts ...runtimeclient.GetOption) error {
+	c.de.DeepCopyInto(obj.(*kdmpapi.DataExport))
+	return nil
+}
+
+func (c *fakeClient) Update(ctx context.Context, obj runtimeclient.Object, opts ...runtimeclient.UpdateOption) error {
+	obj.(*kdmpapi.DataExport).DeepCopyInto(c.de)
+	return nil
+}
+
+// fakeDriver records the deleted jobs.
+type fakeDriver struct {
+	drivers.Interface
+	deleted []string
+}
+
+func (d *fakeDriver) DeleteJob(id string) error {
+	d.deleted = append(d.deleted, id)
+	return nil
+}
+
+// fakeBatch returns the jobs of a set.
+type fakeBatch struct {
+	batch.Ops
+	jobs map[string]bool
+}
+
+func (b *fakeBatch) GetJob(name, namespace string) (*batchv1.Job, error) {
+	if !b.jobs[namespace+"/"+name] {
+		return nil, k8sErrors.NewNotFound(schema.GroupResource{Group: "batch", Resource: "jobs"}, name)
+	}
+	return &batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}}, nil
+}
+
+func newActionDataExport(action kdmpapi.DataExportActionType, stage kdmpapi.DataExportStage, status kdmpapi.DataExportStatus) *kdmpapi.DataExport {
+	return &kdmpapi.DataExport{
+		ObjectMeta: metav1.ObjectMeta{Name: "backup", Namespace: "app"},
+		Spec: kdmpapi.DataExportSpec{
+			Type:        kdmpapi.DataExportKopia,
+			Action:      action,
+			Source:      kdmpapi.DataExportObjectReference{APIVersion: "v1", Kind: "PersistentVolumeClaim", Namespace: "app", Name: "data"},
+			Destination: kdmpapi.DataExportObjectReference{APIVersion: "stork.libopenstorage.org/v1alpha1", Kind: "BackupLocation", Namespace: "app", Name: "bl"},
+		},
+		Status: kdmpapi.ExportStatus{
+			Stage:      stage,
+			Status:     status,
+			TransferID: "app/backup",
+		},
+	}
+}
+
+func TestHandleAction(t *testing.T) {
+	jobs := &fakeBatch{jobs: map[string]bool{}}
+	batch.SetInstance(jobs)
+	newController := func(de *kdmpapi.DataExport) (*Controller, *fakeClient) {
+		client := &fakeClient{de: de.DeepCopy()}
+		return &Controller{client: client, jobQueue: jobratelimit.NewQueue()}, client
+	}
+
+	// Cancel deletes the job and moves to the cleanup stage
+	de := newActionDataExport(kdmpapi.DataExportActionCancel, kdmpapi.DataExportStageTransferInProgress, kdmpapi.DataExportStatusInProgress)
+	c, client := newController(de)
+	driver := &fakeDriver{}
+	handled, requeue, err := c.handleAction(driver, de)
+	require.NoError(t, err)
+	require.True(t, handled)
+	require.True(t, requeue)
+	require.Equal(t, []string{"app/backup"}, driver.deleted)
+	require.Equal(t, kdmpapi.DataExportStageCleanup, client.de.Status.Stage)
+	require.Equal(t, kdmpapi.DataExportStatusCancelled, client.de.Status.Status)
+
+	// No action once in the cleanup stage
+	handled, _, err = c.handleAction(driver, client.de)
+	require.NoError(t, err)
+	require.False(t, handled)
+
+	// Pause deletes the running job and goes back to the transfer scheduled stage
+	de = newActionDataExport(kdmpapi.DataExportActionPause, kdmpapi.DataExportStageTransferInProgress, kdmpapi.DataExportStatusInProgress)
+	c, client = newController(de)
+	driver = &fakeDriver{}
+	handled, requeue, err = c.handleAction(driver, de)
+	require.NoError(t, err)
+	require.True(t, handled)
+	require.False(t, requeue)
+	require.Equal(t, []string{"app/backup"}, driver.deleted)
+	require.Equal(t, kdmpapi.DataExportStageTransferScheduled, client.de.Status.Stage)
+	require.Equal(t, kdmpapi.DataExportStatusPaused, client.de.Status.Status)
+
+	// Pausing a transfer without a snapshot runs the post hooks and clears
+	// the hook statuses, so that the pre hooks are run again on resume
+	var executed []string
+	list, exec := listHookPods, execHookCommand
+	defer func() { listHookPods, execHookCommand = list, exec }()
+	listHookPods = func(namespace string) ([]corev1.Pod, error) {
+		return []corev1.Pod{newHookPod("db-0", corev1.PodRunning, map[string]string{"app": "db"})}, nil
+	}
+	execHookCommand = func(command []string, pod, container, namespace string) (string, error) {
+		executed = append(executed, pod+":"+command[0])
+		return "", nil
+	}
+	db := metav1.LabelSelector{MatchLabels: map[string]string{"app": "db"}}
+	de = newActionDataExport(kdmpapi.DataExportActionPause, kdmpapi.DataExportStageTransferInProgress, kdmpapi.DataExportStatusInProgress)
+	de.Spec.Hooks = &kdmpapi.DataExportHooks{
+		Pre:  []kdmpapi.DataExportHook{{Name: "freeze", PodSelector: db, Command: []string{"freeze"}}},
+		Post: []kdmpapi.DataExportHook{{Name: "unfreeze", PodSelector: db, Command: []string{"unfreeze"}}},
+	}
+	de.Status.Hooks = []kdmpapi.DataExportHookStatus{
+		{Name: "freeze", Phase: kdmpapi.DataExportHookPhasePre, Pod: "db-0", Status: kdmpapi.DataExportStatusSuccessful},
+	}
+	c, client = newController(de)
+	handled, _, err = c.handleAction(&fakeDriver{}, de)
+	require.NoError(t, err)
+	require.True(t, handled)
+	require.Equal(t, []string{"db-0:unfreeze"}, executed)
+	require.Equal(t, kdmpapi.DataExportStatusPaused, client.de.Status.Status)
+	require.Empty(t, client.de.Status.Hooks)
+
+	// A started stage which isn't a transfer goes on
+	de = newActionDataExport(kdmpapi.DataExportActionPause, kdmpapi.DataExportStageSnapshotInProgress, kdmpapi.DataExportStatusInProgress)
+	c, _ = newController(de)
+	handled, _, err = c.handleAction(&fakeDriver{}, de)
+	require.NoError(t, err)
+	require.False(t, handled)
+
+	// Resume waits for the deletion of the paused job
+	jobs.jobs["app/backup"] = true
+	de = client.de.DeepCopy()
+	de.Spec.Action = kdmpapi.DataExportActionResume
+	c, client = newController(de)
+	handled, requeue, err = c.handleAction(&fakeDriver{}, de)
+	require.NoError(t, err)
+	require.True(t, handled)
+	require.True(t, requeue)
+	require.Equal(t, kdmpapi.DataExportStatusPaused, client.de.Status.Status)
+
+	// and then restarts the stage
+	delete(jobs.jobs, "app/backup")
+	handled, requeue, err = c.handleAction(&fakeDriver{}, de)
+	require.NoError(t, err)
+	require.True(t, handled)
+	require.True(t, requeue)
+	require.Equal(t, kdmpapi.DataExportStageTransferScheduled, client.de.Status.Stage)
+	require.Equal(t, kdmpapi.DataExportStatusInitial, client.de.Status.Status)
+}
@@ -0,0 +1,141 @@
+package dataexport
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	kdmpapi "github.com/portworx/kdmp/pkg/apis/kdmp/v1alpha1"
+	"github.com/portworx/kdmp/pkg/drivers/utils"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// retryLimitKey is the number of retries of a transfer job after
+	// transient failures, 0 disables them.
+	retryLimitKey = "KDMP_JOB_RETRY_LIMIT"
+	// retryBackoffKey is the wait before the first retry, doubled for each
+	// next one.
+	retryBackoffKey = "KDMP_JOB_RETRY_BACKOFF"
+	// retryMaxBackoffKey is the longest wait before a retry.
+	retryMaxBackoffKey = "KDMP_JOB_RETRY_MAX_BACKOFF"
+
+	defaultRetryLimit      = 3
+	defaultRetryBackoff    = 30 * time.Second
+	defaultRetryMaxBackoff = 10 * time.Minute
+)
+
+// failurePatterns are the messages of the failures of each class, matched
+// case-insensitively in order: an authentication error over a 5xx status is
+// permanent.
+var failurePatterns = []struct {
+	class    kdmpapi.DataExportFailureClass
+	patterns []string
+}{
+	{
+		class: kdmpapi.DataExportFailurePermanent,
+		patterns: []string{
+			"accessdenied", "access denied", "invalidaccesskeyid", "signaturedoesnotmatch",
+			"expiredtoken", "authorizationfailure", "authenticationfailed", "authentication failed",
+			"unauthorized", "forbidden", "invalid_grant", "nosuchbucket", "invalid repository password",
+			"repository not initialized", "corrupt", "checksum mismatch", "hmac mismatch",
+			"decryption failed", "pod security standard", "no such host",
+		},
+	},
+	{
+		class: kdmpapi.DataExportFailureTransient,
+		patterns: []string{
+			"slowdown", "throttl", "toomanyrequests", "too many requests", "requestlimitexceeded",
+			"serverbusy", "requesttimeout", "request timeout", "serviceunavailable", "service unavailable",
+			"internalerror", "internal server error", "bad gateway", "gateway timeout",
+			"status code: 500", "status code: 502", "status code: 503", "status code: 504",
+			"connection reset", "connection refused", "broken pipe", "i/o timeout",
+			"tls handshake", "unexpected eof", "temporarily unavailable",
+		},
+	},
+}
+
+// classifyFailure returns the class of a transfer job failure from its reason.
+// The node failures are the ones the driver reports as such.
+func classifyFailure(reason string, nodeFailed bool) kdmpapi.DataExportFailureClass {
+	if nodeFailed {
+		return kdmpapi.DataExportFailureNode
+	}
+	reason = strings.ToLower(reason)
+	for _, f := range failurePatterns {
+		for _, pattern := range f.patterns {
+			if strings.Contains(reason, pattern) {
+				return f.class
+			}
+		}
+	}
+	return kdmpapi.DataExportFailureUnknown
+}
+
+// retryPolicy is the retry policy of the transfer jobs after transient
+// failures.
+type retryPolicy struct {
+	limit      int
+	backoff    time.Duration
+	maxBackoff time.Duration
+}
+
+// getRetryPolicy reads the retry policy from the kdmp config map.
+func getRetryPolicy() retryPolicy {
+	policy := retryPolicy{
+		limit:      defaultRetryLimit,
+		backoff:    defaultRetryBackoff,
+		maxBackoff: defaultRetryMaxBackoff,
+	}
+	if value := utils.GetConfigValue(utils.KdmpConfigmapName, utils.KdmpConfigmapNamespace, retryLimitKey); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 0 {
+			logrus.Warnf("invalid %v value %q, using %v", retryLimitKey, value, policy.limit)
+		} else {
+			policy.limit = limit
+		}
+	}
+	policy.backoff = getConfigDuration(retryBackoffKey, policy.backoff)
+	policy.maxBackoff = getConfigDuration(retryMaxBackoffKey, policy.maxBackoff)
+	return policy
+}
+
+func getConfigDuration(key string, defaultValue time.Duration) time.Duration {
+	value := utils.GetConfigValue(utils.KdmpConfigmapName, utils.KdmpConfigmapNamespace, key)
+	if value == "" {
+		return defaultValue
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		logrus.Warnf("invalid %v value %q, using %v", key, value, defaultValue)
+		return defaultValue
+	}
+	return d
+}
+
+// shouldRetry returns whether a failure of the given class is retried after
+// retryCount retries.
+func (p retryPolicy) shouldRetry(class kdmpapi.DataExportFailureClass, retryCount int) bool {
+	return class == kdmpapi.DataExportFailureTransient && retryCount < p.limit
+}
+
+// backoffFor returns the wait before the retry following retryCount retries.
+func (p retryPolicy) backoffFor(retryCount int) time.Duration {
+	backoff := p.backoff
+	for i := 0; i < retryCount && backoff < p.maxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > p.maxBackoff {
+		return p.maxBackoff
+	}
+	return backoff
+}
+
+// resetRetries clears the failure class and the retries of a transfer job
+// which succeeded, so that they only describe the failures of the current
+// transfer.
+func resetRetries(status *kdmpapi.ExportStatus) {
+	status.FailureClass = ""
+	status.RetryCount = 0
+	status.NextRetryTime = nil
+}
@@ -0,0 +1,59 @@
+package dataexport
+
+import (
+	"testing"
+	"time"
+
+	kdmpapi "github.com/portworx/kdmp/pkg/apis/kdmp/v1alpha1"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestClassifyFailure(t *testing.T) {
+	for reason, class := range map[string]kdmpapi.DataExportFailureClass{
+		"upload failed: SlowDown: Please reduce your request rate":                             kdmpapi.DataExportFailureTransient,
+		"error writing blob: status code: 503, request id: 1234":                               kdmpapi.DataExportFailureTransient,
+		"dial tcp 10.0.0.1:443: i/o timeout":                                                   kdmpapi.DataExportFailureTransient,
+		"AccessDenied: Access Denied status code: 503":                                         kdmpapi.DataExportFailurePermanent,
+		"failed to open repository: invalid repository password":                               kdmpapi.DataExportFailurePermanent,
+		"dial tcp: lookup bucket.example.com: no such host":                                    kdmpapi.DataExportFailurePermanent,
+		"timed out waiting for the snapshot":                                                   kdmpapi.DataExportFailureUnknown,
+		"job [ns/job] failed to mount pvc, please check job pod's description for more detail": kdmpapi.DataExportFailureUnknown,
+		"internal error from executor":                                                         kdmpapi.DataExportFailureUnknown,
+	} {
+		require.Equal(t, class, classifyFailure(reason, false), reason)
+	}
+	// The node failures are reported by the drivers
+	require.Equal(t, kdmpapi.DataExportFailureNode, classifyFailure("Node [node1] on which job [ns/job] schedules is NotReady", true))
+	require.Equal(t, kdmpapi.DataExportFailureNode, classifyFailure("status code: 503", true))
+}
+
+func TestRetryPolicy(t *testing.T) {
+	policy := retryPolicy{limit: 2, backoff: 30 * time.Second, maxBackoff: 90 * time.Second}
+	require.True(t, policy.shouldRetry(kdmpapi.DataExportFailureTransient, 0))
+	require.True(t, policy.shouldRetry(kdmpapi.DataExportFailureTransient, 1))
+	require.False(t, policy.shouldRetry(kdmpapi.DataExportFailureTransient, 2))
+	require.False(t, policy.shouldRetry(kdmpapi.DataExportFailurePermanent, 0))
+	require.False(t, policy.shouldRetry(kdmpapi.DataExportFailureNode, 0))
+
+	require.Equal(t, 30*time.Second, policy.backoffFor(0))
+	require.Equal(t, 60*time.Second, policy.backoffFor(1))
+	require.Equal(t, 90*time.Second, policy.backoffFor(2))
+	require.Equal(t, 90*time.Second, policy.backoffFor(10))
+}
+
+func TestResetRetries(t *testing.T) {
+	nextRetryTime := metav1.Now()
+	status := kdmpapi.ExportStatus{
+		Status:        kdmpapi.DataExportStatusSuccessful,
+		TransferID:    "ns/job",
+		FailureClass:  kdmpapi.DataExportFailureTransient,
+		RetryCount:    2,
+		NextRetryTime: &nextRetryTime,
+	}
+	resetRetries(&status)
+	require.Equal(t, kdmpapi.ExportStatus{
+		Status:     kdmpapi.DataExportStatusSuccessful,
+		TransferID: "ns/job",
+	}, status)
+}
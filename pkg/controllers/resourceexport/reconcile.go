@@ -10,6 +10,7 @@ import (
 	"github.com/libopenstorage/stork/pkg/controllers"
 	kdmpapi "github.com/portworx/kdmp/pkg/apis/kdmp/v1alpha1"
 	kdmpcontroller "github.com/portworx/kdmp/pkg/controllers"
+	"github.com/portworx/kdmp/pkg/controllers/dataexport"
 	"github.com/portworx/kdmp/pkg/drivers"
 	"github.com/portworx/kdmp/pkg/drivers/driversinstance"
 	"github.com/portworx/kdmp/pkg/drivers/utils"
@@ -121,20 +122,20 @@ func (c *Controller) process(ctx context.Context, in *kdmpapi.ResourceExport) (b
 			return false, c.updateStatus(resourceExport, updateData)
 		}
 		// start data transfer
-		id, serr := startNfsResourceJob(
+		id, serr := startResourceJob(
 			driver,
 			utils.KdmpConfigmapName,
 			utils.KdmpConfigmapNamespace,
 			resourceExport,
 			backupLocation,
 		)
-		logrus.Tracef("%s: startNfsResourceJob id: %v", funct, id)
+		logrus.Tracef("%s: startResourceJob id: %v", funct, id)
 		if serr != nil {
 			logrus.Errorf("%s: serr: %v", funct, serr)
 			updateData := updateResourceExportFields{
 				stage:  kdmpapi.ResourceExportStageFinal,
 				status: kdmpapi.ResourceExportStatusFailed,
-				reason: fmt.Sprintf("failed to create startNfsResourceJob job [%v/%v]", resourceExport.Namespace, resourceExport.Name),
+				reason: fmt.Sprintf("failed to create startResourceJob job [%v/%v]: %v", resourceExport.Namespace, resourceExport.Name, serr),
 			}
 			return false, c.updateStatus(resourceExport, updateData)
 		}
@@ -379,7 +380,9 @@ func getDriverType(re *kdmpapi.ResourceExport) (string, error) {
 	}
 
 	switch re.Spec.Type {
-	case kdmpapi.ResourceExportNFS:
+	case kdmpapi.ResourceExportNFS, kdmpapi.ResourceExportObjectStore:
+		// The resources are backed up and restored by the same jobs for
+		// all the backuplocations.
 		if doBackup {
 			return drivers.NFSBackup, nil
 		}
@@ -399,18 +402,29 @@ func isApplicationRestoreRef(ref kdmpapi.ResourceExportObjectReference) bool {
 	return ref.Kind == "ApplicationRestore" && ref.APIVersion == "stork.libopenstorage.org/v1alpha1"
 }
 
-func startNfsResourceJob(
+// startResourceJob starts the job backing up or restoring the resources to a
+// backuplocation, mounting the NFS share of NFS backuplocations.
+func startResourceJob(
 	drv drivers.Interface,
 	jobConfigMap string,
 	jobConfigMapNs string,
 	re *kdmpapi.ResourceExport,
 	bl *storkapi.BackupLocation,
 ) (string, error) {
+	isNFS := bl.Location.Type == storkapi.BackupLocationNFS
+	if isNFS != (re.Spec.Type == kdmpapi.ResourceExportNFS) {
+		return "", fmt.Errorf("resourceexport type %s does not support backuplocation type %s", re.Spec.Type, bl.Location.Type)
+	}
 
-	err := utils.CreateNfsSecret(utils.GetCredSecretName(re.Name), bl, re.Namespace, nil)
+	var err error
+	if isNFS {
+		err = utils.CreateNfsSecret(utils.GetCredSecretName(re.Name), bl, re.Namespace, nil)
+	} else {
+		err = dataexport.CreateCredentialsSecret(utils.GetCredSecretName(re.Name), bl.Name, bl.Namespace, re.Namespace, nil)
+	}
 	if err != nil {
-		logrus.Errorf("failed to create NFS cred secret: %v", err)
-		return "", fmt.Errorf("failed to create NFS cred secret: %v", err)
+		logrus.Errorf("failed to create cred secret: %v", err)
+		return "", fmt.Errorf("failed to create cred secret: %v", err)
 	}
 
 	nodeLabel, err := utils.GetNodeLabelFromDeployment(jobConfigMap, jobConfigMapNs, drivers.PxbJobNodeLabelKey)
@@ -418,48 +432,40 @@ func startNfsResourceJob(
 		return "", err
 	}
 
-	switch drv.Name() {
-	case drivers.NFSBackup:
-		return drv.StartJob(
-			// TODO: below two calls need to be generalized and changed in all the startJob Calls
-			// For NFS it need to be populated in ResourceExport CR and passed to Job via its reconciler.
-			drivers.WithNfsImageExecutorSource(re.Spec.TriggeredFrom),
-			drivers.WithNfsImageExecutorSourceNs(re.Spec.TriggeredFromNs),
-			drivers.WithRestoreExport(re.Name),
-			drivers.WithJobNamespace(re.Namespace),
-			drivers.WithNfsServer(bl.Location.NFSConfig.ServerAddr),
-			drivers.WithNfsExportDir(bl.Location.Path),
-			drivers.WithAppCRName(re.Spec.Source.Name),
-			drivers.WithAppCRNamespace(re.Spec.Source.Namespace),
-			drivers.WithNamespace(re.Namespace),
-			drivers.WithResoureBackupName(re.Name),
-			drivers.WithNodeAffinity(nodeLabel),
-			drivers.WithResoureBackupNamespace(re.Namespace),
-			drivers.WithNfsMountOption(bl.Location.NFSConfig.MountOptions),
-			drivers.WithNfsExportDir(bl.Location.NFSConfig.SubPath),
-			drivers.WithJobConfigMap(jobConfigMap),
-			drivers.WithJobConfigMapNs(jobConfigMapNs),
-		)
-	case drivers.NFSRestore:
-		return drv.StartJob(
-			drivers.WithNfsImageExecutorSource(re.Spec.TriggeredFrom),
-			drivers.WithNfsImageExecutorSourceNs(re.Spec.TriggeredFromNs),
-			drivers.WithRestoreExport(re.Name),
-			drivers.WithJobNamespace(re.Namespace),
+	opts := []drivers.JobOption{
+		// TODO: below two calls need to be generalized and changed in all the startJob Calls
+		// For NFS it need to be populated in ResourceExport CR and passed to Job via its reconciler.
+		drivers.WithNfsImageExecutorSource(re.Spec.TriggeredFrom),
+		drivers.WithNfsImageExecutorSourceNs(re.Spec.TriggeredFromNs),
+		drivers.WithRestoreExport(re.Name),
+		drivers.WithJobNamespace(re.Namespace),
+		drivers.WithAppCRName(re.Spec.Source.Name),
+		drivers.WithAppCRNamespace(re.Spec.Source.Namespace),
+		drivers.WithNamespace(re.Namespace),
+		drivers.WithResoureBackupName(re.Name),
+		drivers.WithNodeAffinity(nodeLabel),
+		drivers.WithResoureBackupNamespace(re.Namespace),
+		drivers.WithJobConfigMap(jobConfigMap),
+		drivers.WithJobConfigMapNs(jobConfigMapNs),
+	}
+	if re.Spec.DryRun {
+		if drv.Name() != drivers.NFSRestore {
+			return "", fmt.Errorf("dry-run is only supported for restores")
+		}
+		opts = append(opts, drivers.WithDryRun(true))
+	}
+	if isNFS {
+		opts = append(opts,
 			drivers.WithNfsServer(bl.Location.NFSConfig.ServerAddr),
-			drivers.WithNfsExportDir(bl.Location.Path),
-			drivers.WithAppCRName(re.Spec.Source.Name),
-			drivers.WithAppCRNamespace(re.Spec.Source.Namespace),
-			drivers.WithNamespace(re.Namespace),
-			drivers.WithResoureBackupName(re.Name),
-			drivers.WithNodeAffinity(nodeLabel),
-			drivers.WithResoureBackupNamespace(re.Namespace),
 			drivers.WithNfsMountOption(bl.Location.NFSConfig.MountOptions),
 			drivers.WithNfsExportDir(bl.Location.NFSConfig.SubPath),
-			drivers.WithJobConfigMap(jobConfigMap),
-			drivers.WithJobConfigMapNs(jobConfigMapNs),
 		)
 	}
+
+	switch drv.Name() {
+	case drivers.NFSBackup, drivers.NFSRestore:
+		return drv.StartJob(opts...)
+	}
 	return "", fmt.Errorf("unknown data transfer driver: %s", drv.Name())
 }
 
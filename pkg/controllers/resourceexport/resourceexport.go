@@ -26,15 +26,28 @@ import (
 // Controller is a k8s controller that handles ResourceExport resources.
 type Controller struct {
 	client runtimeclient.Client
+	opts   kdmpcontroller.Options
+	probe  *kdmpcontroller.Probe
 }
 
 // NewController returns a new instance of the controller.
-func NewController(mgr manager.Manager) (*Controller, error) {
+func NewController(mgr manager.Manager, opts ...kdmpcontroller.Option) (*Controller, error) {
+	o, err := kdmpcontroller.NewOptions(10, kdmpcontroller.ResyncPeriod, opts...)
+	if err != nil {
+		return nil, err
+	}
 	return &Controller{
 		client: mgr.GetClient(),
+		opts:   o,
+		probe:  kdmpcontroller.NewProbe("resourceexport"),
 	}, nil
 }
 
+// Probe returns the health and readiness probe of the controller.
+func (c *Controller) Probe() *kdmpcontroller.Probe {
+	return c.probe
+}
+
 // Init Initialize the application backup controller
 func (c *Controller) Init(mgr manager.Manager) error {
 	err := c.createCRD()
@@ -45,14 +58,23 @@ func (c *Controller) Init(mgr manager.Manager) error {
 	// Create a new controller
 	ctrl, err := controller.New("resource-export-controller", mgr, controller.Options{
 		Reconciler:              c,
-		MaxConcurrentReconciles: 10,
+		MaxConcurrentReconciles: c.opts.Workers,
 	})
 	if err != nil {
 		return err
 	}
 
 	// Watch for changes to primary resource
-	return ctrl.Watch(&source.Kind{Type: &kdmpapi.ResourceExport{}}, &handler.EnqueueRequestForObject{})
+	if err := ctrl.Watch(&source.Kind{Type: &kdmpapi.ResourceExport{}}, &handler.EnqueueRequestForObject{}); err != nil {
+		return err
+	}
+
+	informer, err := mgr.GetCache().GetInformer(context.TODO(), &kdmpapi.ResourceExport{})
+	if err != nil {
+		return err
+	}
+	c.probe.Started(informer)
+	return nil
 }
 
 // Reconcile reads that state of the cluster for an object and makes changes based on the state read
@@ -83,7 +105,7 @@ func (c *Controller) Reconcile(ctx context.Context, request reconcile.Request) (
 		return reconcile.Result{RequeueAfter: kdmpcontroller.RequeuePeriod}, nil
 	}
 
-	return reconcile.Result{RequeueAfter: kdmpcontroller.ResyncPeriod}, nil
+	return reconcile.Result{RequeueAfter: c.opts.ResyncPeriod}, nil
 }
 
 func (c *Controller) createCRD() error {
@@ -0,0 +1,116 @@
+package retention
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	kdmpapi "github.com/portworx/kdmp/pkg/apis/kdmp/v1alpha1"
+	"github.com/portworx/kdmp/pkg/drivers/utils"
+)
+
+// keepRule keeps the latest snapshot of count periods, the period of a
+// snapshot is given by its key.
+type keepRule struct {
+	count int
+	key   func(t time.Time) string
+}
+
+func keepRules(spec *kdmpapi.VolumeBackupRetentionSpec) []keepRule {
+	return []keepRule{
+		{spec.KeepHourly, func(t time.Time) string { return t.Format("2006-01-02 15") }},
+		{spec.KeepDaily, func(t time.Time) string { return t.Format("2006-01-02") }},
+		{spec.KeepWeekly, func(t time.Time) string {
+			year, week := t.ISOWeek()
+			return fmt.Sprintf("%d-%d", year, week)
+		}},
+		{spec.KeepMonthly, func(t time.Time) string { return t.Format("2006-01") }},
+	}
+}
+
+func validatePolicy(spec *kdmpapi.VolumeBackupRetentionSpec, namespace string) error {
+	// the policies of the users can only expire the backups of their namespace
+	for _, ns := range spec.Namespaces {
+		if ns != namespace && namespace != utils.AdminNamespace {
+			return fmt.Errorf("the namespaces of a policy can only differ from its own in the %s namespace", utils.AdminNamespace)
+		}
+	}
+	for _, count := range []int{spec.KeepLast, spec.KeepHourly, spec.KeepDaily, spec.KeepWeekly, spec.KeepMonthly} {
+		if count < 0 {
+			return fmt.Errorf("keep counts can not be negative")
+		}
+	}
+	if spec.MinAge.Duration < 0 {
+		return fmt.Errorf("minAge can not be negative")
+	}
+	// a policy without any of them would expire every snapshot
+	if spec.KeepLast+spec.KeepHourly+spec.KeepDaily+spec.KeepWeekly+spec.KeepMonthly == 0 && spec.MinAge.Duration == 0 {
+		return fmt.Errorf("at least one keep count or minAge has to be set")
+	}
+	return nil
+}
+
+// groupKey is the repository of a VolumeBackup, the snapshots of a PVC in a
+// BackupLocation.
+func groupKey(vb *kdmpapi.VolumeBackup) string {
+	return fmt.Sprintf("%s/%s/%s/%s", vb.Spec.BackupLocation.Namespace, vb.Spec.BackupLocation.Name, vb.Namespace, vb.Spec.Repository)
+}
+
+// expiredVolumeBackups returns the VolumeBackups whose snapshots are retained
+// by none of the keep rules of the policy and are older than its minimum age.
+// The latest successful backup of a PVC is always retained, so that a policy
+// with only a minimum age can't expire all the backups of a PVC.
+func expiredVolumeBackups(spec *kdmpapi.VolumeBackupRetentionSpec, vbs []kdmpapi.VolumeBackup, now time.Time) []kdmpapi.VolumeBackup {
+	groups := make(map[string][]kdmpapi.VolumeBackup)
+	var keys []string
+	for _, vb := range vbs {
+		key := groupKey(&vb)
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], vb)
+	}
+	sort.Strings(keys)
+
+	var expired []kdmpapi.VolumeBackup
+	for _, key := range keys {
+		group := groups[key]
+		// latest first
+		sort.SliceStable(group, func(i, j int) bool {
+			return group[i].CreationTimestamp.After(group[j].CreationTimestamp.Time)
+		})
+		keep := make([]bool, len(group))
+		for i := range group {
+			if group[i].Status.LastKnownError == "" {
+				keep[i] = true
+				break
+			}
+		}
+		for i := 0; i < spec.KeepLast && i < len(group); i++ {
+			keep[i] = true
+		}
+		for _, rule := range keepRules(spec) {
+			count := rule.count
+			var last string
+			for i := range group {
+				if count == 0 {
+					break
+				}
+				period := rule.key(group[i].CreationTimestamp.UTC())
+				if period == last {
+					continue
+				}
+				last = period
+				keep[i] = true
+				count--
+			}
+		}
+		for i := range group {
+			if keep[i] || now.Sub(group[i].CreationTimestamp.Time) < spec.MinAge.Duration {
+				continue
+			}
+			expired = append(expired, group[i])
+		}
+	}
+	return expired
+}
@@ -0,0 +1,97 @@
+package retention
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	kdmpapi "github.com/portworx/kdmp/pkg/apis/kdmp/v1alpha1"
+	"github.com/portworx/kdmp/pkg/drivers/utils"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func volumeBackup(pvc string, created time.Time) kdmpapi.VolumeBackup {
+	return kdmpapi.VolumeBackup{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              fmt.Sprintf("%s-%d", pvc, created.Unix()),
+			Namespace:         "ns",
+			CreationTimestamp: metav1.NewTime(created),
+		},
+		Spec: kdmpapi.VolumeBackupSpec{
+			Repository:     "generic-backup/ns-" + pvc + "/",
+			BackupLocation: kdmpapi.DataExportObjectReference{Name: "bl", Namespace: "ns"},
+		},
+		Status: kdmpapi.VolumeBackupStatus{SnapshotID: "snap"},
+	}
+}
+
+func names(vbs []kdmpapi.VolumeBackup) []string {
+	var out []string
+	for _, vb := range vbs {
+		out = append(out, vb.Name)
+	}
+	return out
+}
+
+func TestExpiredVolumeBackups(t *testing.T) {
+	now := time.Date(2021, 6, 15, 12, 0, 0, 0, time.UTC)
+	var vbs []kdmpapi.VolumeBackup
+	// two backups a day for ten days
+	for i := 0; i < 20; i++ {
+		vbs = append(vbs, volumeBackup("pvc", now.Add(-time.Duration(i)*12*time.Hour)))
+	}
+
+	spec := &kdmpapi.VolumeBackupRetentionSpec{KeepLast: 3}
+	require.Equal(t, names(vbs[3:]), names(expiredVolumeBackups(spec, vbs, now)))
+
+	// the latest of each of the last 5 days, noon is the latest of a day
+	spec = &kdmpapi.VolumeBackupRetentionSpec{KeepDaily: 5}
+	expired := expiredVolumeBackups(spec, vbs, now)
+	require.Len(t, expired, 15)
+	for _, i := range []int{0, 2, 4, 6, 8} {
+		require.NotContains(t, names(expired), vbs[i].Name)
+	}
+
+	// the rules are combined
+	spec = &kdmpapi.VolumeBackupRetentionSpec{KeepLast: 2, KeepDaily: 2}
+	require.Equal(t, names(vbs[3:]), names(expiredVolumeBackups(spec, vbs, now)))
+
+	// nothing younger than the minimum age is expired
+	spec = &kdmpapi.VolumeBackupRetentionSpec{KeepLast: 1, MinAge: metav1.Duration{Duration: 72 * time.Hour}}
+	require.Equal(t, names(vbs[6:]), names(expiredVolumeBackups(spec, vbs, now)))
+
+	// the latest successful backup is kept by a policy with only a minimum age
+	spec = &kdmpapi.VolumeBackupRetentionSpec{MinAge: metav1.Duration{Duration: time.Hour}}
+	require.Equal(t, names(vbs[1:]), names(expiredVolumeBackups(spec, vbs, now)))
+	failed := volumeBackup("pvc", now.Add(time.Hour))
+	failed.Status.LastKnownError = "upload failed"
+	expired = expiredVolumeBackups(spec, append([]kdmpapi.VolumeBackup{failed}, vbs...), now.Add(2*time.Hour))
+	require.Equal(t, append([]string{failed.Name}, names(vbs[1:])...), names(expired))
+
+	// the counts apply to the backups of each PVC
+	other := volumeBackup("other", now.Add(-240*time.Hour))
+	spec = &kdmpapi.VolumeBackupRetentionSpec{KeepLast: 1}
+	expired = expiredVolumeBackups(spec, append([]kdmpapi.VolumeBackup{other}, vbs...), now)
+	require.Equal(t, names(vbs[1:]), names(expired))
+}
+
+func TestValidatePolicy(t *testing.T) {
+	require.NoError(t, validatePolicy(&kdmpapi.VolumeBackupRetentionSpec{KeepWeekly: 4}, "app"))
+	require.NoError(t, validatePolicy(&kdmpapi.VolumeBackupRetentionSpec{MinAge: metav1.Duration{Duration: time.Hour}}, "app"))
+	require.Error(t, validatePolicy(&kdmpapi.VolumeBackupRetentionSpec{}, "app"))
+	require.Error(t, validatePolicy(&kdmpapi.VolumeBackupRetentionSpec{KeepLast: 1, KeepDaily: -1}, "app"))
+
+	// Only the policies of the admin namespace expire the backups of others
+	spec := &kdmpapi.VolumeBackupRetentionSpec{KeepLast: 1, Namespaces: []string{"app"}}
+	require.NoError(t, validatePolicy(spec, "app"))
+	require.NoError(t, validatePolicy(spec, utils.AdminNamespace))
+	spec.Namespaces = append(spec.Namespaces, "other")
+	require.Error(t, validatePolicy(spec, "app"))
+	require.NoError(t, validatePolicy(spec, utils.AdminNamespace))
+}
+
+func TestPVCName(t *testing.T) {
+	vb := volumeBackup("mysql-data", time.Now())
+	require.Equal(t, "mysql-data", pvcName(&vb))
+}
@@ -0,0 +1,435 @@
+package retention
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	storkapi "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"github.com/libopenstorage/stork/pkg/controllers"
+	kdmpapi "github.com/portworx/kdmp/pkg/apis/kdmp/v1alpha1"
+	kdmpcontroller "github.com/portworx/kdmp/pkg/controllers"
+	"github.com/portworx/kdmp/pkg/controllers/dataexport"
+	"github.com/portworx/kdmp/pkg/drivers"
+	"github.com/portworx/kdmp/pkg/drivers/driversinstance"
+	"github.com/portworx/kdmp/pkg/drivers/utils"
+	kdmpops "github.com/portworx/kdmp/pkg/util/ops"
+	"github.com/portworx/sched-ops/k8s/core"
+	kdmpschedops "github.com/portworx/sched-ops/k8s/kdmp"
+	"github.com/portworx/sched-ops/k8s/stork"
+	"github.com/sirupsen/logrus"
+	rbacv1 "k8s.io/api/rbac/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+const (
+	// defaultEvaluationInterval is the default interval at which the policies
+	// are evaluated.
+	defaultEvaluationInterval = 5 * time.Minute
+	deleteJobPrefix           = "retention"
+	// retentionNameLabel is the label of the resources of the delete jobs
+	// with the name of the VolumeBackupRetention.
+	retentionNameLabel = "kdmp.portworx.com/volumebackupretention"
+)
+
+func (c *Controller) sync(ctx context.Context, retention *kdmpapi.VolumeBackupRetention) (bool, error) {
+	driver, err := driversinstance.Get(drivers.KopiaDelete)
+	if err != nil {
+		return false, err
+	}
+
+	if retention.DeletionTimestamp != nil {
+		if !controllers.ContainsFinalizer(retention, kdmpcontroller.CleanupFinalizer) {
+			return false, nil
+		}
+		for _, snapshot := range retention.Status.ExpiredSnapshots {
+			if snapshot.DeleteJob == "" {
+				continue
+			}
+			if err := cleanUp(driver, snapshot.DeleteJob); err != nil {
+				return true, err
+			}
+		}
+		controllers.RemoveFinalizer(retention, kdmpcontroller.CleanupFinalizer)
+		return false, c.client.Update(ctx, retention)
+	}
+
+	status := retention.Status.DeepCopy()
+	requeue := false
+	for i := range status.ExpiredSnapshots {
+		if c.checkDelete(ctx, driver, &status.ExpiredSnapshots[i]) {
+			requeue = true
+		}
+	}
+
+	if time.Since(status.LastEvaluationTimestamp.Time) >= c.opts.ResyncPeriod {
+		status.LastEvaluationTimestamp = metav1.Now()
+		status.Reason = ""
+		if err := validatePolicy(&retention.Spec, retention.Namespace); err != nil {
+			status.Reason = fmt.Sprintf("invalid policy: %v", err)
+		} else if expired, err := c.evaluate(ctx, retention); err != nil {
+			status.Reason = err.Error()
+		} else {
+			status.ExpiredSnapshots = mergeExpiredSnapshots(status.ExpiredSnapshots, expired)
+		}
+	}
+
+	if !retention.Spec.DryRun {
+		for i := range status.ExpiredSnapshots {
+			snapshot := &status.ExpiredSnapshots[i]
+			if snapshot.Status != "" {
+				continue
+			}
+			if err := c.startDelete(ctx, driver, retention, snapshot); err != nil {
+				logrus.Errorf("failed to start the delete of snapshot [%v] of volumebackup %s/%s: %v",
+					snapshot.SnapshotID, snapshot.VolumeBackup.Namespace, snapshot.VolumeBackup.Name, err)
+				snapshot.Status = kdmpapi.VolumeBackupDeleteStatusFailed
+				snapshot.Reason = err.Error()
+				continue
+			}
+			// started or waiting for a job slot
+			requeue = true
+		}
+	}
+
+	if reflect.DeepEqual(status, &retention.Status) {
+		return requeue, nil
+	}
+	retention.Status = *status
+	return requeue, c.client.Update(ctx, retention)
+}
+
+// evaluate returns the snapshots of the VolumeBackups in the scope of the
+// policy which it expires.
+func (c *Controller) evaluate(ctx context.Context, retention *kdmpapi.VolumeBackupRetention) ([]kdmpapi.ExpiredSnapshot, error) {
+	namespaces := retention.Spec.Namespaces
+	if len(namespaces) == 0 {
+		namespaces = []string{retention.Namespace}
+	}
+	var selector labels.Selector
+	if retention.Spec.PVCSelector != nil {
+		var err error
+		selector, err = metav1.LabelSelectorAsSelector(retention.Spec.PVCSelector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pvc selector: %v", err)
+		}
+	}
+
+	var vbs []kdmpapi.VolumeBackup
+	for _, namespace := range namespaces {
+		list, err := kdmpops.Instance().ListVolumeBackups(ctx, namespace)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list the volumebackups of namespace %s: %v", namespace, err)
+		}
+		for _, vb := range list.Items {
+			inScope, err := isInScope(&retention.Spec, &vb, selector)
+			if err != nil {
+				return nil, err
+			}
+			if inScope {
+				vbs = append(vbs, vb)
+			}
+		}
+	}
+
+	now := time.Now()
+	expiredVBs := expiredVolumeBackups(&retention.Spec, vbs, now)
+	var referenced map[string]bool
+	if len(expiredVBs) > 0 {
+		var err error
+		if referenced, err = getApplicationBackupSnapshots(); err != nil {
+			return nil, err
+		}
+	}
+	var expired []kdmpapi.ExpiredSnapshot
+	for _, vb := range expiredVBs {
+		// The snapshots of the ApplicationBackups are deleted with them.
+		if referenced[vb.Status.SnapshotID] {
+			logrus.Debugf("snapshot [%v] of volumebackup %s/%s is referenced by an applicationbackup", vb.Status.SnapshotID, vb.Namespace, vb.Name)
+			continue
+		}
+		snapshot := kdmpapi.ExpiredSnapshot{
+			VolumeBackup: kdmpapi.DataExportObjectReference{
+				Name:      vb.Name,
+				Namespace: vb.Namespace,
+			},
+			SnapshotID:        vb.Status.SnapshotID,
+			CreationTimestamp: vb.CreationTimestamp,
+		}
+		// Don't start delete jobs which would be refused for the object-lock.
+		if expiry := vb.Status.ObjectLockExpiry; expiry != nil && now.Before(expiry.Time) {
+			snapshot.Status = kdmpapi.VolumeBackupDeleteStatusLocked
+			snapshot.Reason = fmt.Sprintf("snapshot is object-locked until %v", expiry.UTC().Format(time.RFC3339))
+		}
+		expired = append(expired, snapshot)
+	}
+	return expired, nil
+}
+
+// getApplicationBackupSnapshots returns the IDs of the snapshots referenced by
+// the ApplicationBackups, none if stork isn't installed.
+func getApplicationBackupSnapshots() (map[string]bool, error) {
+	backups, err := stork.Instance().ListApplicationBackups("", metav1.ListOptions{})
+	if k8sErrors.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to list the applicationbackups: %v", err)
+	}
+	return applicationBackupSnapshots(backups.Items), nil
+}
+
+func applicationBackupSnapshots(backups []storkapi.ApplicationBackup) map[string]bool {
+	snapshots := make(map[string]bool)
+	for _, backup := range backups {
+		for _, vol := range backup.Status.Volumes {
+			if vol != nil && vol.BackupID != "" {
+				snapshots[vol.BackupID] = true
+			}
+		}
+	}
+	return snapshots
+}
+
+func isInScope(spec *kdmpapi.VolumeBackupRetentionSpec, vb *kdmpapi.VolumeBackup, selector labels.Selector) (bool, error) {
+	// in progress and failed backups have no snapshot
+	if vb.Status.SnapshotID == "" || vb.DeletionTimestamp != nil {
+		return false, nil
+	}
+	if spec.BackupLocation != nil &&
+		(spec.BackupLocation.Name != vb.Spec.BackupLocation.Name || spec.BackupLocation.Namespace != vb.Spec.BackupLocation.Namespace) {
+		return false, nil
+	}
+	if selector == nil {
+		return true, nil
+	}
+	pvc, err := core.Instance().GetPersistentVolumeClaim(pvcName(vb), vb.Namespace)
+	if k8sErrors.IsNotFound(err) {
+		return false, nil
+	} else if err != nil {
+		return false, fmt.Errorf("failed to get the pvc of volumebackup %s/%s: %v", vb.Namespace, vb.Name, err)
+	}
+	return selector.Matches(labels.Set(pvc.Labels)), nil
+}
+
+// objectLockExpiry returns the object-lock expiry recorded on a VolumeBackup,
+// zero if it has none.
+func objectLockExpiry(vb *kdmpapi.VolumeBackup) time.Time {
+	if vb.Status.ObjectLockExpiry == nil {
+		return time.Time{}
+	}
+	return vb.Status.ObjectLockExpiry.Time
+}
+
+// pvcName returns the name of the PVC of a VolumeBackup from its repository,
+// generic-backup/<namespace>-<pvc name>/.
+func pvcName(vb *kdmpapi.VolumeBackup) string {
+	repo := strings.Trim(vb.Spec.Repository, "/")
+	repo = repo[strings.LastIndex(repo, "/")+1:]
+	return strings.TrimPrefix(repo, vb.Namespace+"-")
+}
+
+// mergeExpiredSnapshots returns the snapshots expired by an evaluation with
+// the state of their deletes. The failed deletes are retried and the ones in
+// progress are kept even if their snapshot is not expired anymore.
+func mergeExpiredSnapshots(previous, expired []kdmpapi.ExpiredSnapshot) []kdmpapi.ExpiredSnapshot {
+	var merged []kdmpapi.ExpiredSnapshot
+	inProgress := make(map[kdmpapi.DataExportObjectReference]bool)
+	for _, snapshot := range previous {
+		if snapshot.DeleteJob != "" {
+			inProgress[snapshot.VolumeBackup] = true
+			merged = append(merged, snapshot)
+		}
+	}
+	for _, snapshot := range expired {
+		if !inProgress[snapshot.VolumeBackup] {
+			merged = append(merged, snapshot)
+		}
+	}
+	return merged
+}
+
+func (c *Controller) startDelete(
+	ctx context.Context,
+	driver drivers.Interface,
+	retention *kdmpapi.VolumeBackupRetention,
+	snapshot *kdmpapi.ExpiredSnapshot,
+) error {
+	vb, err := kdmpops.Instance().GetVolumeBackup(ctx, snapshot.VolumeBackup.Name, snapshot.VolumeBackup.Namespace)
+	if k8sErrors.IsNotFound(err) {
+		snapshot.Status = kdmpapi.VolumeBackupDeleteStatusSuccess
+		return nil
+	} else if err != nil {
+		return err
+	}
+	bl, err := stork.Instance().GetBackupLocation(vb.Spec.BackupLocation.Name, vb.Spec.BackupLocation.Namespace)
+	if err != nil {
+		return err
+	}
+
+	namespace := vb.Namespace
+	jobName := toJobName(vb)
+	jobLabels := map[string]string{
+		retentionNameLabel: utils.GetValidLabel(retention.Name),
+	}
+	if err := dataexport.CreateCertificateSecret(utils.GetCertSecretName(jobName), namespace, bl.Name, bl.Namespace, jobLabels); err != nil {
+		return fmt.Errorf("failed to create the certificate secret: %v", err)
+	}
+	if err := dataexport.CreateRepositoryCredentialsSecret(utils.GetCredSecretName(jobName), bl.Name, bl.Namespace, namespace, []string{vb.Spec.Repository}, jobLabels); err != nil {
+		return fmt.Errorf("failed to create the credential secret: %v", err)
+	}
+	if err := utils.SetupServiceAccount(jobName, namespace, roleFor()); err != nil {
+		return fmt.Errorf("failed to setup the service account: %v", err)
+	}
+
+	var nfsServerAddr, nfsExportPath, nfsMountOption string
+	if bl.Location.NFSConfig != nil {
+		nfsServerAddr = bl.Location.NFSConfig.ServerAddr
+		nfsExportPath = bl.Location.NFSConfig.SubPath
+		nfsMountOption = bl.Location.NFSConfig.MountOptions
+	}
+	var s3DisableSSL bool
+	if bl.Location.S3Config != nil {
+		s3DisableSSL = bl.Location.S3Config.DisableSSL
+	}
+	id, err := driver.StartJob(
+		drivers.WithJobName(jobName),
+		drivers.WithJobNamespace(namespace),
+		drivers.WithNamespace(namespace),
+		drivers.WithServiceAccountName(jobName),
+		drivers.WithSnapshotID(vb.Status.SnapshotID),
+		drivers.WithSourcePVC(pvcName(vb)),
+		drivers.WithSourcePVCNamespace(namespace),
+		drivers.WithCredSecretName(utils.GetCredSecretName(jobName)),
+		drivers.WithCredSecretNamespace(namespace),
+		drivers.WithCertSecretName(utils.GetCertSecretName(jobName)),
+		drivers.WithCertSecretNamespace(namespace),
+		drivers.WithS3DisableSSL(s3DisableSSL),
+		drivers.WithVolumeBackupDeleteName(jobName),
+		drivers.WithVolumeBackupDeleteNamespace(namespace),
+		drivers.WithKopiaImageExecutorSource(retention.Spec.TriggeredFrom),
+		drivers.WithKopiaImageExecutorSourceNs(retention.Spec.TriggeredFromNs),
+		drivers.WithJobConfigMap(utils.KdmpConfigmapName),
+		drivers.WithJobConfigMapNs(utils.KdmpConfigmapNamespace),
+		drivers.WithBackupObjectName(retention.Name),
+		drivers.WithBackupObjectUID(string(retention.UID)),
+		drivers.WithLabels(jobLabels),
+		drivers.WithNfsServer(nfsServerAddr),
+		drivers.WithNfsExportDir(nfsExportPath),
+		drivers.WithNfsMountOption(nfsMountOption),
+		drivers.WithObjectLockExpiry(objectLockExpiry(vb)),
+	)
+	if err == utils.ErrOutOfJobResources {
+		logrus.Infof("delete of snapshot [%v] of volumebackup %s/%s waits for a job slot", vb.Status.SnapshotID, namespace, vb.Name)
+		return nil
+	} else if err != nil {
+		if cleanupErr := cleanUp(driver, utils.NamespacedName(namespace, jobName)); cleanupErr != nil {
+			logrus.Warnf("failed to clean up delete job %s/%s: %v", namespace, jobName, cleanupErr)
+		}
+		return err
+	}
+	logrus.Infof("started the delete of expired snapshot [%v] of volumebackup %s/%s", vb.Status.SnapshotID, namespace, vb.Name)
+	snapshot.DeleteJob = id
+	snapshot.Status = kdmpapi.VolumeBackupDeleteStatusProgress
+	snapshot.Reason = ""
+	return nil
+}
+
+// checkDelete updates the state of the delete of an expired snapshot, it
+// returns true while the delete job is running. The VolumeBackup is deleted
+// with its snapshot.
+func (c *Controller) checkDelete(ctx context.Context, driver drivers.Interface, snapshot *kdmpapi.ExpiredSnapshot) bool {
+	if snapshot.DeleteJob == "" {
+		return false
+	}
+	jobStatus, err := driver.JobStatus(snapshot.DeleteJob)
+	if err != nil {
+		logrus.Warnf("failed to get the status of delete job %s: %v", snapshot.DeleteJob, err)
+		return true
+	}
+
+	switch jobStatus.State {
+	case drivers.JobStateCompleted:
+		err := kdmpops.Instance().DeleteVolumeBackup(ctx, snapshot.VolumeBackup.Name, snapshot.VolumeBackup.Namespace)
+		if err != nil && !k8sErrors.IsNotFound(err) {
+			logrus.Warnf("failed to delete volumebackup %s/%s: %v", snapshot.VolumeBackup.Namespace, snapshot.VolumeBackup.Name, err)
+			return true
+		}
+		logrus.Infof("deleted expired snapshot [%v] of volumebackup %s/%s", snapshot.SnapshotID, snapshot.VolumeBackup.Namespace, snapshot.VolumeBackup.Name)
+		snapshot.Status = kdmpapi.VolumeBackupDeleteStatusSuccess
+		snapshot.Reason = ""
+	case drivers.JobStateFailed:
+		snapshot.Status = kdmpapi.VolumeBackupDeleteStatusFailed
+		snapshot.Reason = jobStatus.Reason
+		// the VolumeBackupDelete tells apart the snapshots still object-locked
+		namespace, name, err := utils.ParseJobID(snapshot.DeleteJob)
+		if err == nil {
+			vbd, err := kdmpschedops.Instance().GetVolumeBackupDelete(name, namespace)
+			if err == nil && vbd.Status.Status != "" {
+				snapshot.Status = vbd.Status.Status
+				snapshot.Reason = vbd.Status.Reason
+			}
+		}
+	default:
+		return true
+	}
+
+	if err := cleanUp(driver, snapshot.DeleteJob); err != nil {
+		logrus.Warnf("failed to clean up delete job %s: %v", snapshot.DeleteJob, err)
+		return true
+	}
+	snapshot.DeleteJob = ""
+	return false
+}
+
+// cleanUp deletes the delete job with its VolumeBackupDelete and the resources
+// created for it.
+func cleanUp(driver drivers.Interface, id string) error {
+	namespace, jobName, err := utils.ParseJobID(id)
+	if err != nil {
+		return err
+	}
+	if err := driver.DeleteJob(id); err != nil && !k8sErrors.IsNotFound(err) {
+		return fmt.Errorf("delete %s job: %s", id, err)
+	}
+	pvcName := utils.GetPvcNameForJob(jobName)
+	if err := core.Instance().DeletePersistentVolumeClaim(pvcName, namespace); err != nil && !k8sErrors.IsNotFound(err) {
+		return fmt.Errorf("delete %s/%s pvc: %s", namespace, pvcName, err)
+	}
+	pvName := utils.GetPvNameForJob(jobName)
+	if err := core.Instance().DeletePersistentVolume(pvName); err != nil && !k8sErrors.IsNotFound(err) {
+		return fmt.Errorf("delete %s pv: %s", pvName, err)
+	}
+	if err := utils.CleanServiceAccount(jobName, namespace); err != nil {
+		return fmt.Errorf("deletion of service account %s/%s failed: %v", namespace, jobName, err)
+	}
+	for _, secret := range []string{
+		utils.GetCredSecretName(jobName),
+		utils.GetCertSecretName(jobName),
+		utils.GetImageSecretName(jobName),
+	} {
+		if err := core.Instance().DeleteSecret(secret, namespace); err != nil && !k8sErrors.IsNotFound(err) {
+			return fmt.Errorf("deletion of secret %s/%s failed: %v", namespace, secret, err)
+		}
+	}
+	return nil
+}
+
+func toJobName(vb *kdmpapi.VolumeBackup) string {
+	return fmt.Sprintf("%s-%s", deleteJobPrefix, vb.UID)
+}
+
+func roleFor() *rbacv1.Role {
+	return &rbacv1.Role{
+		Rules: []rbacv1.PolicyRule{
+			{
+				APIGroups: []string{"kdmp.portworx.com"},
+				Resources: []string{"volumebackupdeletes"},
+				Verbs:     []string{rbacv1.VerbAll},
+			},
+		},
+	}
+}
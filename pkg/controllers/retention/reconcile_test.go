@@ -0,0 +1,22 @@
+package retention
+
+import (
+	"testing"
+
+	storkapi "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplicationBackupSnapshots(t *testing.T) {
+	backups := []storkapi.ApplicationBackup{
+		{Status: storkapi.ApplicationBackupStatus{Volumes: []*storkapi.ApplicationBackupVolumeInfo{
+			{Namespace: "ns", PersistentVolumeClaim: "data", BackupID: "k1"},
+			nil,
+			{Namespace: "ns", PersistentVolumeClaim: "logs"},
+		}}},
+		{Status: storkapi.ApplicationBackupStatus{Volumes: []*storkapi.ApplicationBackupVolumeInfo{
+			{Namespace: "ns", PersistentVolumeClaim: "data", BackupID: "k2"},
+		}}},
+	}
+	require.Equal(t, map[string]bool{"k1": true, "k2": true}, applicationBackupSnapshots(backups))
+}
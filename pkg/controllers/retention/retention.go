@@ -0,0 +1,137 @@
+package retention
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/libopenstorage/stork/pkg/controllers"
+	kdmpapi "github.com/portworx/kdmp/pkg/apis/kdmp/v1alpha1"
+	kdmpcontroller "github.com/portworx/kdmp/pkg/controllers"
+	"github.com/portworx/kdmp/pkg/utils"
+	"github.com/portworx/kdmp/pkg/version"
+	"github.com/portworx/sched-ops/k8s/apiextensions"
+	"github.com/sirupsen/logrus"
+	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+// Controller is a k8s controller that handles VolumeBackupRetention resources.
+type Controller struct {
+	client runtimeclient.Client
+	opts   kdmpcontroller.Options
+	probe  *kdmpcontroller.Probe
+}
+
+// NewController returns a new instance of the controller. The resync period
+// of the controller is the interval at which the policies are evaluated.
+func NewController(mgr manager.Manager, opts ...kdmpcontroller.Option) (*Controller, error) {
+	o, err := kdmpcontroller.NewOptions(1, defaultEvaluationInterval, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &Controller{
+		client: mgr.GetClient(),
+		opts:   o,
+		probe:  kdmpcontroller.NewProbe("volumebackupretention"),
+	}, nil
+}
+
+// Probe returns the health and readiness probe of the controller.
+func (c *Controller) Probe() *kdmpcontroller.Probe {
+	return c.probe
+}
+
+// Init Initialize the volume backup retention controller
+func (c *Controller) Init(mgr manager.Manager) error {
+	err := c.createCRD()
+	if err != nil {
+		return err
+	}
+
+	// Create a new controller
+	ctrl, err := controller.New("volume-backup-retention-controller", mgr, controller.Options{
+		Reconciler:              c,
+		MaxConcurrentReconciles: c.opts.Workers,
+	})
+	if err != nil {
+		return err
+	}
+
+	// Watch for changes to primary resource
+	if err := ctrl.Watch(&source.Kind{Type: &kdmpapi.VolumeBackupRetention{}}, &handler.EnqueueRequestForObject{}); err != nil {
+		return err
+	}
+
+	informer, err := mgr.GetCache().GetInformer(context.TODO(), &kdmpapi.VolumeBackupRetention{})
+	if err != nil {
+		return err
+	}
+	c.probe.Started(informer)
+	return nil
+}
+
+// Reconcile evaluates a VolumeBackupRetention policy and deletes the snapshots
+// it expires. The policy is evaluated again after the evaluation interval, or
+// sooner while snapshot deletes are in progress.
+func (c *Controller) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
+	logrus.Tracef("Reconciling VolumeBackupRetention %s/%s", request.Namespace, request.Name)
+
+	retention := &kdmpapi.VolumeBackupRetention{}
+	err := c.client.Get(ctx, request.NamespacedName, retention)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{RequeueAfter: kdmpcontroller.RequeuePeriod}, nil
+	}
+
+	if retention.DeletionTimestamp == nil && !controllers.ContainsFinalizer(retention, kdmpcontroller.CleanupFinalizer) {
+		controllers.SetFinalizer(retention, kdmpcontroller.CleanupFinalizer)
+		return reconcile.Result{Requeue: true}, c.client.Update(ctx, retention)
+	}
+
+	requeue, err := c.sync(ctx, retention)
+	if err != nil {
+		logrus.Errorf("kdmp retention controller: %s/%s: %s", request.Namespace, request.Name, err)
+		return reconcile.Result{RequeueAfter: kdmpcontroller.RequeuePeriod}, nil
+	}
+	if requeue {
+		return reconcile.Result{RequeueAfter: kdmpcontroller.RequeuePeriod}, nil
+	}
+
+	return reconcile.Result{RequeueAfter: c.opts.ResyncPeriod}, nil
+}
+
+func (c *Controller) createCRD() error {
+	resource := apiextensions.CustomResource{
+		Name:    kdmpapi.VolumeBackupRetentionResourceName,
+		Plural:  kdmpapi.VolumeBackupRetentionResourcePlural,
+		Group:   kdmpapi.SchemeGroupVersion.Group,
+		Version: kdmpapi.SchemeGroupVersion.Version,
+		Scope:   apiextensionsv1beta1.NamespaceScoped,
+		Kind:    reflect.TypeOf(kdmpapi.VolumeBackupRetention{}).Name(),
+	}
+
+	requiresV1, err := version.RequiresV1Registration()
+	if err != nil {
+		return err
+	}
+	if requiresV1 {
+		err := utils.CreateCRD(resource)
+		if err != nil && !errors.IsAlreadyExists(err) {
+			return err
+		}
+		return apiextensions.Instance().ValidateCRD(resource.Plural+"."+resource.Group, kdmpcontroller.ValidateCRDTimeout, kdmpcontroller.ValidateCRDInterval)
+	}
+	err = apiextensions.Instance().CreateCRDV1beta1(resource)
+	if err != nil && !errors.IsAlreadyExists(err) {
+		return err
+	}
+	return apiextensions.Instance().ValidateCRDV1beta1(resource, kdmpcontroller.ValidateCRDTimeout, kdmpcontroller.ValidateCRDInterval)
+}
@@ -8,17 +8,21 @@ import (
 
 // Known drivers.
 const (
-	Rsync            = "rsync"
-	ResticBackup     = "resticbackup"
-	ResticRestore    = "resticrestore"
-	KopiaBackup      = "kopiabackup"
-	KopiaRestore     = "kopiarestore"
-	KopiaDelete      = "kopiadelete"
-	KopiaMaintenance = "kopiamaintenance"
-	NFSBackup        = "nfsbackup"
-	NFSRestore       = "nfsrestore"
-	NFSCSIRestore    = "nfscsirestore"
-	NFSDelete        = "nfsdelete"
+	Rsync             = "rsync"
+	ResticBackup      = "resticbackup"
+	ResticRestore     = "resticrestore"
+	KopiaBackup       = "kopiabackup"
+	KopiaRestore      = "kopiarestore"
+	KopiaDelete       = "kopiadelete"
+	KopiaVerify       = "kopiaverify"
+	KopiaListFiles    = "kopialistfiles"
+	KopiaMaintenance  = "kopiamaintenance"
+	KopiaReplicate    = "kopiareplicate"
+	ResticMaintenance = "resticmaintenance"
+	NFSBackup         = "nfsbackup"
+	NFSRestore        = "nfsrestore"
+	NFSCSIRestore     = "nfscsirestore"
+	NFSDelete         = "nfsdelete"
 )
 
 // Docker images.
@@ -32,6 +36,9 @@ const (
 // Driver labels.
 const (
 	DriverNameLabel = "kdmp.portworx.com/driver-name"
+	// BatchVolumesLabel is the number of volumes of a batch job, which are
+	// counted against the job limit.
+	BatchVolumesLabel = "kdmp.portworx.com/batch-volumes"
 )
 
 const (
@@ -53,6 +60,11 @@ const (
 	CertSecretName       = "tls-s3-cert"
 	CertMount            = "/etc/tls-s3-cert"
 	NfsMount             = "/mnt/nfs-target/"
+	KopiaBlockDevicePath = "/dev/kdmp-block"
+
+	// KopiaReplicaCredSecretMount is where the credentials of the backuplocation
+	// the snapshots are replicated to are mounted.
+	KopiaReplicaCredSecretMount = "/etc/replica-cred-secret"
 )
 
 // Driver job options.
@@ -65,6 +77,7 @@ const (
 	RsyncRequestMemory           = "KDMP_RSYNC_REQUEST_MEMORY"
 	RsyncLimitCPU                = "KDMP_RSYNC_LIMIT_CPU"
 	RsyncLimitMemory             = "KDMP_RSYNC_LIMIT_MEMORY"
+	RsyncServiceTypeKey          = "KDMP_RSYNC_SERVICE_TYPE"
 	ResticExecutorImageKey       = "KDMP_RESTICEXECUTOR_IMAGE"
 	ResticExecutorImageSecretKey = "KDMP_RESTICEXECUTOR_IMAGE_SECRET"
 	ResticExecutorRequestCPU     = "KDMP_RESTICEXECUTOR_REQUEST_CPU"
@@ -82,6 +95,9 @@ const (
 	NFSExecutorLimitCPU          = "KDMP_NFSEXECUTOR_LIMIT_CPU"
 	NFSExecutorLimitMemory       = "KDMP_NFSEXECUTOR_LIMIT_MEMORNFS"
 	KdmpDisableIstioConfig       = "KDMP_DISABLE_ISTIO_CONFIG"
+	ResticForgetPolicy           = "KDMP_RESTIC_FORGET_POLICY"
+	KopiaOrphanGCDelete          = "KDMP_KOPIA_ORPHAN_GC_DELETE"
+	KopiaOrphanGCGracePeriod     = "KDMP_KOPIA_ORPHAN_GC_GRACE_PERIOD"
 )
 
 // Default parameters for job options.
@@ -145,6 +161,9 @@ type JobStatus struct {
 	State            JobState
 	Reason           string
 	Status           batchv1.JobConditionType
+	// NodeFailed is set if the job failed to mount its volumes or its node
+	// is not ready.
+	NodeFailed bool
 }
 
 // IsTransferCompleted allows to check transfer status.
@@ -7,13 +7,17 @@ import (
 	"github.com/portworx/kdmp/pkg/drivers"
 	"github.com/portworx/kdmp/pkg/drivers/kopiabackup"
 	"github.com/portworx/kdmp/pkg/drivers/kopiadelete"
+	"github.com/portworx/kdmp/pkg/drivers/kopialistfiles"
 	"github.com/portworx/kdmp/pkg/drivers/kopiamaintenance"
+	"github.com/portworx/kdmp/pkg/drivers/kopiareplicate"
 	"github.com/portworx/kdmp/pkg/drivers/kopiarestore"
+	"github.com/portworx/kdmp/pkg/drivers/kopiaverify"
 	"github.com/portworx/kdmp/pkg/drivers/nfsbackup"
 	"github.com/portworx/kdmp/pkg/drivers/nfscsirestore"
 	"github.com/portworx/kdmp/pkg/drivers/nfsdelete"
 	"github.com/portworx/kdmp/pkg/drivers/nfsrestore"
 	"github.com/portworx/kdmp/pkg/drivers/resticbackup"
+	"github.com/portworx/kdmp/pkg/drivers/resticmaintenance"
 	"github.com/portworx/kdmp/pkg/drivers/resticrestore"
 	"github.com/portworx/kdmp/pkg/drivers/rsync"
 )
@@ -21,17 +25,21 @@ import (
 var (
 	mu         sync.Mutex
 	driversMap = map[string]drivers.Interface{
-		drivers.Rsync:            rsync.Driver{},
-		drivers.ResticBackup:     resticbackup.Driver{},
-		drivers.ResticRestore:    resticrestore.Driver{},
-		drivers.KopiaBackup:      kopiabackup.Driver{},
-		drivers.KopiaRestore:     kopiarestore.Driver{},
-		drivers.KopiaDelete:      kopiadelete.Driver{},
-		drivers.KopiaMaintenance: kopiamaintenance.Driver{},
-		drivers.NFSBackup:        nfsbackup.Driver{},
-		drivers.NFSRestore:       nfsrestore.Driver{},
-		drivers.NFSDelete:        nfsdelete.Driver{},
-		drivers.NFSCSIRestore:    nfscsirestore.Driver{},
+		drivers.Rsync:             rsync.Driver{},
+		drivers.ResticBackup:      resticbackup.Driver{},
+		drivers.ResticRestore:     resticrestore.Driver{},
+		drivers.KopiaBackup:       kopiabackup.Driver{},
+		drivers.KopiaRestore:      kopiarestore.Driver{},
+		drivers.KopiaDelete:       kopiadelete.Driver{},
+		drivers.KopiaVerify:       kopiaverify.Driver{},
+		drivers.KopiaListFiles:    kopialistfiles.Driver{},
+		drivers.KopiaMaintenance:  kopiamaintenance.Driver{},
+		drivers.KopiaReplicate:    kopiareplicate.Driver{},
+		drivers.ResticMaintenance: resticmaintenance.Driver{},
+		drivers.NFSBackup:         nfsbackup.Driver{},
+		drivers.NFSRestore:        nfsrestore.Driver{},
+		drivers.NFSDelete:         nfsdelete.Driver{},
+		drivers.NFSCSIRestore:     nfscsirestore.Driver{},
 	}
 )
 
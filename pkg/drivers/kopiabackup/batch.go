@@ -0,0 +1,187 @@
+package kopiabackup
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/portworx/kdmp/pkg/drivers"
+	"github.com/portworx/kdmp/pkg/drivers/utils"
+	"github.com/portworx/kdmp/pkg/jobratelimit"
+	kdmpops "github.com/portworx/kdmp/pkg/util/ops"
+	"github.com/portworx/sched-ops/k8s/batch"
+	"github.com/sirupsen/logrus"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+const (
+	// batchVolumeBackupsAnnotation lists the VolumeBackups of a batch job.
+	batchVolumeBackupsAnnotation = "kdmp.portworx.com/batch-volume-backups"
+	batchVolumePrefix            = "vol-"
+)
+
+// startBatchJob creates a job backing up several volumes. The job name is the
+// DataExportName and the VolumeBackups are the ones of the volumes.
+func (d Driver) startBatchJob(o drivers.JobOpts) (string, error) {
+	fn := "startBatchJob"
+	if o.BlockMode {
+		return "", fmt.Errorf("block mode is not supported for batch backups")
+	}
+	if present := jobratelimit.IsJobAlreadyPresent(o.DataExportName, o.Namespace); present {
+		return utils.NamespacedName(o.Namespace, o.DataExportName), nil
+	}
+
+	// A batch job takes a slot by volume.
+	if !o.JobQueueAdmitted {
+		id := utils.NamespacedName(o.Namespace, o.DataExportName)
+		if err := jobratelimit.AdmitBatchJob(id, d.Name(), o.Namespace, len(o.BatchVolumes)); err != nil {
+			return "", err
+		}
+	}
+	if err := d.validate(o); err != nil {
+		logrus.Errorf("%s validate: err: %v", fn, err)
+		return "", err
+	}
+
+	jobName := o.DataExportName
+	logrus.Debugf("batch backup jobname: %s, volumes: %d", jobName, len(o.BatchVolumes))
+	resources, err := utils.KopiaResourceRequirements(o.JobConfigMap, o.JobConfigMapNs)
+	if err != nil {
+		return "", err
+	}
+	if err := utils.SetupServiceAccount(jobName, o.Namespace, roleFor()); err != nil {
+		errMsg := fmt.Sprintf("error creating service account %s/%s: %v", o.Namespace, jobName, err)
+		logrus.Errorf("%s: %v", fn, errMsg)
+		return "", fmt.Errorf(errMsg)
+	}
+	job, err := jobFor(o, jobName, resources, nil)
+	if err != nil {
+		errMsg := fmt.Sprintf("building batch backup job %s failed: %v", jobName, err)
+		logrus.Errorf("%s: %v", fn, errMsg)
+		return "", fmt.Errorf(errMsg)
+	}
+
+	// Create PV & PVC only in case of NFS.
+	if o.NfsServer != "" {
+		err := utils.CreateNFSPvPvcForJob(jobName, job.ObjectMeta.Namespace, o)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	if _, err = batch.Instance().CreateJob(job); err != nil && !apierrors.IsAlreadyExists(err) {
+		errMsg := fmt.Sprintf("creation of batch backup job %s failed: %v", jobName, err)
+		logrus.Errorf("%s: %v", fn, errMsg)
+		return "", fmt.Errorf(errMsg)
+	}
+
+	return utils.NamespacedName(job.Namespace, job.Name), nil
+}
+
+// BatchRepositories returns the repositories of the volumes of a batch, whose
+// data keys are needed in the credential secret of the job.
+func BatchRepositories(namespace string, volumes []drivers.BatchVolume) []string {
+	var repositories []string
+	for _, volume := range volumes {
+		repoPVCName := volume.RepoPVCName
+		if repoPVCName == "" {
+			repoPVCName = volume.PVCName
+		}
+		repositories = append(repositories, utils.GetKopiaRepositoryPath(repoPVCName, namespace))
+	}
+	return repositories
+}
+
+func batchVolumePath(i int) string {
+	return fmt.Sprintf("/data/%d", i)
+}
+
+// batchArgs returns the executor arguments of the volumes of a batch.
+func batchArgs(jobOption drivers.JobOpts) []string {
+	var args []string
+	for i, volume := range jobOption.BatchVolumes {
+		args = append(args, "--volume", fmt.Sprintf("%s:%s:%s",
+			volume.VolumeBackupName,
+			toRepoName(volume.RepoPVCName, jobOption.Namespace),
+			batchVolumePath(i),
+		))
+	}
+	if jobOption.BatchWorkers > 0 {
+		args = append(args, "--workers", strconv.Itoa(jobOption.BatchWorkers))
+	}
+	return args
+}
+
+// addBatchVolumes replaces the source PVC of the job with the PVCs of the batch.
+func addBatchVolumes(job *batchv1.Job, jobOption drivers.JobOpts) {
+	podSpec := &job.Spec.Template.Spec
+	var volumes []corev1.Volume
+	for _, volume := range podSpec.Volumes {
+		if volume.Name != "vol" {
+			volumes = append(volumes, volume)
+		}
+	}
+	container := &podSpec.Containers[0]
+	var volumeMounts []corev1.VolumeMount
+	for _, volumeMount := range container.VolumeMounts {
+		if volumeMount.Name != "vol" {
+			volumeMounts = append(volumeMounts, volumeMount)
+		}
+	}
+
+	var vbNames []string
+	for i, volume := range jobOption.BatchVolumes {
+		name := batchVolumePrefix + strconv.Itoa(i)
+		volumes = append(volumes, corev1.Volume{
+			Name: name,
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+					ClaimName: volume.PVCName,
+				},
+			},
+		})
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{
+			Name:      name,
+			MountPath: batchVolumePath(i),
+		})
+		vbNames = append(vbNames, volume.VolumeBackupName)
+	}
+	podSpec.Volumes = volumes
+	container.VolumeMounts = volumeMounts
+	job.Annotations[batchVolumeBackupsAnnotation] = strings.Join(vbNames, ",")
+}
+
+// batchVolumeBackups returns the VolumeBackups of a batch job, nil for the
+// other jobs.
+func batchVolumeBackups(job *batchv1.Job) []string {
+	if job == nil || job.Annotations[batchVolumeBackupsAnnotation] == "" {
+		return nil
+	}
+	return strings.Split(job.Annotations[batchVolumeBackupsAnnotation], ",")
+}
+
+// batchJobStatus returns the status of a batch job from the VolumeBackups of
+// its volumes, the progress is the average progress of the volumes.
+func batchJobStatus(job *batchv1.Job, vbNames []string, jobStatus batchv1.JobConditionType) (*drivers.JobStatus, error) {
+	var progress float64
+	var errs []string
+	for _, vbName := range vbNames {
+		vb, err := kdmpops.Instance().GetVolumeBackup(context.Background(), vbName, job.Namespace)
+		if apierrors.IsNotFound(err) {
+			// not started yet
+			continue
+		} else if err != nil {
+			errMsg := fmt.Sprintf("failed to fetch volumebackup %s/%s status: %v", job.Namespace, vbName, err)
+			logrus.Errorf("batchJobStatus: %v", errMsg)
+			return nil, fmt.Errorf(errMsg)
+		}
+		progress += vb.Status.ProgressPercentage
+		if vb.Status.LastKnownError != "" {
+			errs = append(errs, fmt.Sprintf("%s: %s", vbName, vb.Status.LastKnownError))
+		}
+	}
+	return utils.ToJobStatus(progress/float64(len(vbNames)), strings.Join(errs, "; "), jobStatus), nil
+}
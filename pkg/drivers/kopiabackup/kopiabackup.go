@@ -3,6 +3,7 @@ package kopiabackup
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"strings"
 	"sync"
 
@@ -43,6 +44,9 @@ func (d Driver) StartJob(opts ...drivers.JobOption) (id string, err error) {
 			}
 		}
 	}
+	if len(o.BatchVolumes) > 0 {
+		return d.startBatchJob(o)
+	}
 	// Sometimes the StartJob is getting called for the same dataexport CR,
 	// If the status update to the CR fails in the reconciler. In that case, if we
 	// the find job already created, we will exit from here with out doing anything.
@@ -71,13 +75,11 @@ func (d Driver) StartJob(opts ...drivers.JobOption) (id string, err error) {
 
 	// Check whether there is slot to schedule the job.
 	driverType := d.Name()
-	available, err := jobratelimit.CanJobBeScheduled(driverType)
-	if err != nil {
-		logrus.Errorf("%v", err)
-		return "", err
-	}
-	if !available {
-		return "", utils.ErrOutOfJobResources
+	if !o.JobQueueAdmitted {
+		id := utils.NamespacedName(o.Namespace, o.DataExportName)
+		if err := jobratelimit.AdmitJob(id, driverType, o.Namespace); err != nil {
+			return "", err
+		}
 	}
 	if err := d.validate(o); err != nil {
 		logrus.Errorf("%s validate: err: %v", fn, err)
@@ -138,6 +140,13 @@ func (d Driver) DeleteJob(id string) error {
 		logrus.Errorf("%s: %v", fn, errMsg)
 		return fmt.Errorf(errMsg)
 	}
+	for _, vbName := range batchVolumeBackups(job) {
+		err = kdmpops.Instance().DeleteVolumeBackup(context.Background(), vbName, namespace)
+		if err != nil && !apierrors.IsNotFound(err) {
+			errMsg := fmt.Sprintf("failed to delete VolumeBackup CR %v: %v", vbName, err)
+			return fmt.Errorf(errMsg)
+		}
+	}
 	nodeName := job.Spec.Template.Spec.NodeName
 	if nodeName != "" {
 		err := coreops.Instance().IsNodeReady(nodeName)
@@ -184,6 +193,7 @@ func (d Driver) DeleteJob(id string) error {
 			return fmt.Errorf(errMsg)
 		}
 	}
+	jobratelimit.ReleaseJob(id)
 
 	return nil
 }
@@ -221,7 +231,7 @@ func (d Driver) JobStatus(id string) (*drivers.JobStatus, error) {
 	if mountFailed {
 		utils.DisplayJobpodLogandEvents(job.Name, job.Namespace)
 		errMsg := fmt.Sprintf("job [%v/%v] failed to mount pvc, please check job pod's description for more detail", namespace, name)
-		return utils.ToJobStatus(0, errMsg, batchv1.JobFailed), nil
+		return utils.ToNodeFailedJobStatus(errMsg, batchv1.JobFailed), nil
 	}
 
 	err = utils.JobNodeExists(job)
@@ -239,7 +249,11 @@ func (d Driver) JobStatus(id string) (*drivers.JobStatus, error) {
 	}
 	if nodeErr {
 		errMsg = fmt.Sprintf("Node [%v] on which job [%v/%v] schedules is NotReady", job.Spec.Template.Spec.NodeName, namespace, name)
-		return utils.ToJobStatus(0, errMsg, jobStatus), nil
+		return utils.ToNodeFailedJobStatus(errMsg, jobStatus), nil
+	}
+
+	if vbNames := batchVolumeBackups(job); len(vbNames) > 0 {
+		return batchJobStatus(job, vbNames, jobStatus)
 	}
 
 	vb, err := kdmpops.Instance().GetVolumeBackup(context.Background(), name, namespace)
@@ -265,6 +279,9 @@ func (d Driver) validate(o drivers.JobOpts) error {
 	if o.BackupLocationNamespace == "" {
 		return fmt.Errorf("backuplocation namespace should be set")
 	}
+	if o.LiveBackup && (o.BlockMode || len(o.BatchVolumes) > 0) {
+		return fmt.Errorf("live backup can't be used with a block or a batch backup")
+	}
 	return nil
 }
 
@@ -272,20 +289,20 @@ func jobFor(
 	jobOption drivers.JobOpts,
 	jobName string,
 	resources corev1.ResourceRequirements,
-	nodeName string,
-	live bool,
+	mountPod *corev1.Pod,
 ) (*batchv1.Job, error) {
 	backupName := jobName
+	// live is set if the PVC is used by a running pod, the job is then run on
+	// its node. In live backup mode, the volume is read from the pod volumes
+	// directory of the node instead of being mounted again.
+	live := mountPod != nil
+	liveBackup := live && jobOption.LiveBackup
 
 	labels := addJobLabels(jobOption)
 
 	cmd := strings.Join([]string{
 		"/kopiaexecutor",
 		"backup",
-		"--volume-backup-name",
-		backupName,
-		"--repository",
-		toRepoName(jobOption.RepoPVCName, jobOption.Namespace),
 		"--credentials",
 		jobOption.DataExportName,
 		"--backup-location",
@@ -294,10 +311,41 @@ func jobFor(
 		jobOption.BackupLocationNamespace,
 		"--backup-namespace",
 		jobOption.Namespace,
-		"--source-path",
-		"/data",
 	}, " ")
 
+	if len(jobOption.BatchVolumes) == 0 {
+		splitCmd := strings.Split(cmd, " ")
+		splitCmd = append(splitCmd,
+			"--volume-backup-name",
+			backupName,
+			"--repository",
+			toRepoName(jobOption.RepoPVCName, jobOption.Namespace),
+		)
+		cmd = strings.Join(splitCmd, " ")
+	}
+
+	if len(jobOption.BatchVolumes) > 0 {
+		splitCmd := strings.Split(cmd, " ")
+		splitCmd = append(splitCmd, batchArgs(jobOption)...)
+		cmd = strings.Join(splitCmd, " ")
+	} else if jobOption.BlockMode {
+		splitCmd := strings.Split(cmd, " ")
+		splitCmd = append(splitCmd, "--block-device", drivers.KopiaBlockDevicePath)
+		cmd = strings.Join(splitCmd, " ")
+	} else if liveBackup {
+		volDir, err := utils.GetVolumeDirectory(jobOption.SourcePVCName, jobOption.SourcePVCNamespace)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get the volume directory of pvc [%s/%s]: %v", jobOption.SourcePVCNamespace, jobOption.SourcePVCName, err)
+		}
+		splitCmd := strings.Split(cmd, " ")
+		splitCmd = append(splitCmd, "--source-path-glob", utils.ShellQuote("/data/*/"+volDir), "--live")
+		cmd = strings.Join(splitCmd, " ")
+	} else {
+		splitCmd := strings.Split(cmd, " ")
+		splitCmd = append(splitCmd, "--source-path", "/data")
+		cmd = strings.Join(splitCmd, " ")
+	}
+
 	if jobOption.Compression != "" {
 		splitCmd := strings.Split(cmd, " ")
 		splitCmd = append(splitCmd, "--compression", jobOption.Compression)
@@ -310,6 +358,12 @@ func jobFor(
 		cmd = strings.Join(splitCmd, " ")
 	}
 
+	if speedLimitArgs := utils.SpeedLimitArgs(jobOption); len(speedLimitArgs) > 0 {
+		splitCmd := strings.Split(cmd, " ")
+		splitCmd = append(splitCmd, speedLimitArgs...)
+		cmd = strings.Join(splitCmd, " ")
+	}
+
 	kopiaExecutorImage, imageRegistrySecret, err := utils.GetExecutorImageAndSecret(drivers.KopiaExecutorImage,
 		jobOption.KopiaImageExecutorSource,
 		jobOption.KopiaImageExecutorSourceNs,
@@ -394,6 +448,20 @@ func jobFor(
 			},
 		},
 	}
+	// In block mode the PVC is attached to the job as a raw block device
+	// instead of being mounted.
+	if jobOption.BlockMode {
+		utils.SetBlockVolumeDevice(job, "vol")
+	}
+
+	if len(jobOption.BatchVolumes) > 0 {
+		addBatchVolumes(job, jobOption)
+	}
+
+	if liveBackup {
+		setLiveBackupVolume(job, *mountPod)
+	}
+
 	// Add security Context only if the PSA is enabled.
 	if jobOption.PodUserId != "" || jobOption.PodGroupId != "" {
 		job, err = utils.AddSecurityContextToJob(job, jobOption.PodUserId, jobOption.PodGroupId)
@@ -408,7 +476,10 @@ func jobFor(
 	}
 
 	// Add node affinity to the job spec
-	if !live {
+	if liveBackup {
+		// The pod volumes directory is only on the node of the pod.
+		job.Spec.Template.Spec.NodeName = mountPod.Spec.NodeName
+	} else if !live {
 		job, err = utils.AddNodeAffinityToJob(job, jobOption)
 		if err != nil {
 			return nil, err
@@ -427,8 +498,8 @@ func jobFor(
 			}
 		}
 
-		if singleNodeMount && len(nodeName) != 0 {
-			job.Spec.Template.Spec.NodeName = nodeName
+		if singleNodeMount && len(mountPod.Spec.NodeName) != 0 {
+			job.Spec.Template.Spec.NodeName = mountPod.Spec.NodeName
 		} else if !singleNodeMount {
 			job, err = utils.AddNodeAffinityToJob(job, jobOption)
 			if err != nil {
@@ -503,6 +574,9 @@ func addJobLabels(jobOpts drivers.JobOpts) map[string]string {
 	}
 
 	labels[drivers.DriverNameLabel] = drivers.KopiaBackup
+	if len(jobOpts.BatchVolumes) > 0 {
+		labels[drivers.BatchVolumesLabel] = strconv.Itoa(len(jobOpts.BatchVolumes))
+	}
 	labels = utils.SetDisableIstioLabel(labels, jobOpts)
 	return labels
 }
@@ -520,22 +594,23 @@ func buildJob(jobName string, jobOptions drivers.JobOpts) (*batchv1.Job, error)
 		return nil, fmt.Errorf(errMsg)
 	}
 	var resourceNamespace string
-	var nodeName string
-	var live bool
+	var mountPod *corev1.Pod
 	// filter out the pods that are create by us
-	for _, pod := range pods {
+	for i, pod := range pods {
 		labels := pod.ObjectMeta.Labels
 		if _, ok := labels[drivers.DriverNameLabel]; ok {
 			continue
 		}
 		if pod.Status.Phase == "Running" {
-			// get the nodeName, if the pods is in Running state, So that we can schedule
+			// get the pod, if it is in Running state, So that we can schedule
 			// kopia job on the same node.
-			nodeName = pod.Spec.NodeName
-			live = true
+			mountPod = &pods[i]
 			break
 		}
 	}
+	if jobOptions.LiveBackup && mountPod == nil {
+		logrus.Infof("%s: pvc %s/%s isn't used by a running pod, it is mounted in the backup job", fn, jobOptions.SourcePVCNamespace, jobOptions.SourcePVCName)
+	}
 	resourceNamespace = jobOptions.Namespace
 	if err := utils.SetupServiceAccount(jobName, resourceNamespace, roleFor()); err != nil {
 		errMsg := fmt.Sprintf("error creating service account %s/%s: %v", resourceNamespace, jobName, err)
@@ -546,8 +621,7 @@ func buildJob(jobName string, jobOptions drivers.JobOpts) (*batchv1.Job, error)
 		jobOptions,
 		jobName,
 		resources,
-		nodeName,
-		live,
+		mountPod,
 	)
 }
 
@@ -0,0 +1,221 @@
+package kopiabackup
+
+import (
+	"testing"
+
+	"github.com/portworx/kdmp/pkg/drivers"
+	"github.com/portworx/kdmp/pkg/drivers/utils"
+	"github.com/portworx/sched-ops/k8s/apps"
+	coreops "github.com/portworx/sched-ops/k8s/core"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// fakeCore serves a CSI PVC bound to its PV, and no config maps.
+type fakeCore struct {
+	coreops.Ops
+}
+
+func (c *fakeCore) GetPersistentVolumeClaim(pvcName string, namespace string) (*corev1.PersistentVolumeClaim, error) {
+	return &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: pvcName, Namespace: namespace},
+		Spec:       corev1.PersistentVolumeClaimSpec{VolumeName: "pvc-1234"},
+		Status:     corev1.PersistentVolumeClaimStatus{AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce}},
+	}, nil
+}
+
+func (c *fakeCore) GetPersistentVolume(pvName string) (*corev1.PersistentVolume, error) {
+	return &corev1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: pvName},
+		Spec: corev1.PersistentVolumeSpec{
+			PersistentVolumeSource: corev1.PersistentVolumeSource{CSI: &corev1.CSIPersistentVolumeSource{Driver: "csi.example.com"}},
+		},
+	}, nil
+}
+
+func (c *fakeCore) GetConfigMap(name, namespace string) (*corev1.ConfigMap, error) {
+	return nil, apierrors.NewNotFound(schema.GroupResource{Resource: "configmaps"}, name)
+}
+
+// fakeApps serves the executor source deployment.
+type fakeApps struct {
+	apps.Ops
+}
+
+func (a *fakeApps) GetDeployment(name, namespace string) (*appsv1.Deployment, error) {
+	return &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}}, nil
+}
+
+func findVolume(t *testing.T, podSpec corev1.PodSpec, name string) (corev1.Volume, corev1.VolumeMount) {
+	for _, volume := range podSpec.Volumes {
+		if volume.Name != name {
+			continue
+		}
+		for _, volumeMount := range podSpec.Containers[0].VolumeMounts {
+			if volumeMount.Name == name {
+				return volume, volumeMount
+			}
+		}
+	}
+	require.FailNow(t, "volume not found", name)
+	return corev1.Volume{}, corev1.VolumeMount{}
+}
+
+func TestJobForLiveBackup(t *testing.T) {
+	coreops.SetInstance(&fakeCore{})
+	apps.SetInstance(&fakeApps{})
+	t.Setenv("KOPIA-EXECUTOR-IMAGE-REGISTRY", "registry.example.com")
+
+	jobOption := drivers.JobOpts{
+		DataExportName:          "backup",
+		SourcePVCName:           "data",
+		SourcePVCNamespace:      "app",
+		RepoPVCName:             "data",
+		Namespace:               "app",
+		BackupLocationName:      "bl",
+		BackupLocationNamespace: "app",
+		LiveBackup:              true,
+		NodeAffinity:            map[string]string{"kubernetes.io/hostname": "node-2"},
+	}
+	mountPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "db-0", Namespace: "app", UID: "uid-1"},
+		Spec:       corev1.PodSpec{NodeName: "node-1"},
+	}
+
+	// The volumes directory of the running pod is read on its node
+	job, err := jobFor(jobOption, "backup-job", corev1.ResourceRequirements{}, mountPod)
+	require.NoError(t, err)
+	podSpec := job.Spec.Template.Spec
+	volume, volumeMount := findVolume(t, podSpec, "vol")
+	require.Nil(t, volume.PersistentVolumeClaim)
+	require.NotNil(t, volume.HostPath)
+	require.Equal(t, utils.GetPodVolumesPath(*mountPod), volume.HostPath.Path)
+	require.True(t, volumeMount.ReadOnly)
+	require.Equal(t, "node-1", podSpec.NodeName)
+	require.Nil(t, podSpec.Affinity)
+	cmd := podSpec.Containers[0].Command[3]
+	require.Contains(t, cmd, "--source-path-glob "+utils.ShellQuote("/data/*/pvc-1234/mount")+" --live")
+	require.NotContains(t, cmd, "--source-path /data")
+
+	// Without a running pod the PVC is mounted and the job follows the
+	// node affinity
+	job, err = jobFor(jobOption, "backup-job", corev1.ResourceRequirements{}, nil)
+	require.NoError(t, err)
+	podSpec = job.Spec.Template.Spec
+	volume, volumeMount = findVolume(t, podSpec, "vol")
+	require.Nil(t, volume.HostPath)
+	require.NotNil(t, volume.PersistentVolumeClaim)
+	require.Equal(t, "data", volume.PersistentVolumeClaim.ClaimName)
+	require.False(t, volumeMount.ReadOnly)
+	require.Empty(t, podSpec.NodeName)
+	require.NotNil(t, podSpec.Affinity)
+	terms := podSpec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+	require.Equal(t, []corev1.NodeSelectorRequirement{{
+		Key:      "kubernetes.io/hostname",
+		Operator: corev1.NodeSelectorOpIn,
+		Values:   []string{"node-2"},
+	}}, terms[0].MatchExpressions)
+	cmd = podSpec.Containers[0].Command[3]
+	require.Contains(t, cmd, "--source-path /data")
+	require.NotContains(t, cmd, "--live")
+}
+
+func TestJobForBlockBackup(t *testing.T) {
+	coreops.SetInstance(&fakeCore{})
+	apps.SetInstance(&fakeApps{})
+	t.Setenv("KOPIA-EXECUTOR-IMAGE-REGISTRY", "registry.example.com")
+
+	jobOption := drivers.JobOpts{
+		DataExportName:          "backup",
+		SourcePVCName:           "data",
+		SourcePVCNamespace:      "app",
+		RepoPVCName:             "data",
+		Namespace:               "app",
+		BackupLocationName:      "bl",
+		BackupLocationNamespace: "app",
+		BlockMode:               true,
+	}
+
+	// The PVC is attached as a raw block device and not mounted
+	job, err := jobFor(jobOption, "backup-job", corev1.ResourceRequirements{}, nil)
+	require.NoError(t, err)
+	container := job.Spec.Template.Spec.Containers[0]
+	require.Equal(t, []corev1.VolumeDevice{{Name: "vol", DevicePath: drivers.KopiaBlockDevicePath}}, container.VolumeDevices)
+	for _, volumeMount := range container.VolumeMounts {
+		require.NotEqual(t, "vol", volumeMount.Name)
+	}
+	cmd := container.Command[3]
+	require.Contains(t, cmd, "--block-device "+drivers.KopiaBlockDevicePath)
+	require.NotContains(t, cmd, "--source-path")
+}
+
+func TestValidate(t *testing.T) {
+	jobOption := drivers.JobOpts{
+		BackupLocationName:      "bl",
+		BackupLocationNamespace: "app",
+		LiveBackup:              true,
+	}
+	require.NoError(t, Driver{}.validate(jobOption))
+
+	jobOption.BlockMode = true
+	err := Driver{}.validate(jobOption)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "live backup can't be used with a block or a batch backup")
+
+	jobOption.BlockMode = false
+	jobOption.BatchVolumes = []drivers.BatchVolume{{VolumeBackupName: "vb-1", PVCName: "data-1"}}
+	require.Error(t, Driver{}.validate(jobOption))
+
+	jobOption.LiveBackup = false
+	require.NoError(t, Driver{}.validate(jobOption))
+}
+
+func TestJobForBatchBackup(t *testing.T) {
+	coreops.SetInstance(&fakeCore{})
+	apps.SetInstance(&fakeApps{})
+	t.Setenv("KOPIA-EXECUTOR-IMAGE-REGISTRY", "registry.example.com")
+
+	var jobOption drivers.JobOpts
+	for _, opt := range []drivers.JobOption{
+		drivers.WithDataExportName("batch"),
+		drivers.WithNamespace("app"),
+		drivers.WithBackupLocationName("bl"),
+		drivers.WithBackupLocationNamespace("app"),
+		drivers.WithBatchVolumes([]drivers.BatchVolume{
+			{VolumeBackupName: "vb-1", PVCName: "data-1"},
+			{VolumeBackupName: "vb-2", PVCName: "snap-2", RepoPVCName: "data-2"},
+		}),
+		drivers.WithBatchWorkers(2),
+	} {
+		require.NoError(t, opt(&jobOption))
+	}
+
+	// Each volume of the batch is mounted by the job in place of the source PVC
+	job, err := jobFor(jobOption, "batch", corev1.ResourceRequirements{}, nil)
+	require.NoError(t, err)
+	podSpec := job.Spec.Template.Spec
+	for _, volume := range podSpec.Volumes {
+		require.NotEqual(t, "vol", volume.Name)
+	}
+	volume, volumeMount := findVolume(t, podSpec, "vol-1")
+	require.Equal(t, "snap-2", volume.PersistentVolumeClaim.ClaimName)
+	require.Equal(t, "/data/1", volumeMount.MountPath)
+	cmd := podSpec.Containers[0].Command[3]
+	require.Contains(t, cmd, "--volume vb-1:app-data-1:/data/0 --volume vb-2:app-data-2:/data/1 --workers 2")
+	require.NotContains(t, cmd, "--volume-backup-name")
+	require.Equal(t, []string{"vb-1", "vb-2"}, batchVolumeBackups(job))
+	require.Equal(t, "2", job.Labels[drivers.BatchVolumesLabel])
+
+	// The credential secret has the data keys of all the repositories
+	require.Equal(t, []string{"generic-backup/app-data-1/", "generic-backup/app-data-2/"}, BatchRepositories("app", jobOption.BatchVolumes))
+
+	// The volumebackups of a batch are unique
+	require.Error(t, drivers.WithBatchVolumes([]drivers.BatchVolume{
+		{VolumeBackupName: "vb-1", PVCName: "data-1"},
+		{VolumeBackupName: "vb-1", PVCName: "data-2"},
+	})(&drivers.JobOpts{}))
+}
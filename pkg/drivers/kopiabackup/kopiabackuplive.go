@@ -0,0 +1,31 @@
+package kopiabackup
+
+import (
+	"github.com/portworx/kdmp/pkg/drivers/utils"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// setLiveBackupVolume replaces the PVC of a backup job by the volumes
+// directory of the pod using it, for the volumes which can't be mounted
+// twice. Pod volumes reside under the
+// /var/lib/kubelet/pods/<podUID>/volumes/<volumePlugin>/<volumeName>
+// directory, it is mounted as /data and the kopiaexecutor backs up
+// /data/*/<volumeName>.
+func setLiveBackupVolume(job *batchv1.Job, mountPod corev1.Pod) {
+	podSpec := &job.Spec.Template.Spec
+	for i := range podSpec.Volumes {
+		if podSpec.Volumes[i].Name == "vol" {
+			podSpec.Volumes[i].VolumeSource = corev1.VolumeSource{
+				HostPath: &corev1.HostPathVolumeSource{
+					Path: utils.GetPodVolumesPath(mountPod),
+				},
+			}
+		}
+	}
+	for i := range podSpec.Containers[0].VolumeMounts {
+		if podSpec.Containers[0].VolumeMounts[i].Name == "vol" {
+			podSpec.Containers[0].VolumeMounts[i].ReadOnly = true
+		}
+	}
+}
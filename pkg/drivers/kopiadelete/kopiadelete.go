@@ -45,21 +45,16 @@ func (d Driver) StartJob(opts ...drivers.JobOption) (id string, err error) {
 			}
 		}
 	}
-	// Check whether there is slot to schedule delete job.
-	driverType := d.Name()
-	available, err := jobratelimit.CanJobBeScheduled(driverType)
-	if err != nil {
-		logrus.Errorf("%v", err)
-		return "", err
-	}
-	if !available {
-		return "", utils.ErrOutOfJobResources
-	}
 	if err := d.validate(o); err != nil {
 		errMsg := fmt.Sprintf("validation failed for snapshot delete job for snapshotID [%v]: %v", o.SnapshotID, err)
 		logrus.Infof("%s %v", fn, errMsg)
 		return "", fmt.Errorf(errMsg)
 	}
+	// Check whether there is slot to schedule delete job.
+	jobName := toJobName(o.JobName, o.SnapshotID)
+	if err := jobratelimit.AdmitJob(utils.NamespacedName(o.JobNamespace, jobName), d.Name(), o.JobNamespace); err != nil {
+		return "", err
+	}
 	labels := addVolumeBackupDeleteLabels(o)
 	// Create the volumeBackupDelete CR to store the delete job status
 	vd := &kdmpapi.VolumeBackupDelete{}
@@ -71,13 +66,16 @@ func (d Driver) StartJob(opts ...drivers.JobOption) (id string, err error) {
 	vd.Namespace = o.VolumeBackupDeleteNamespace
 	vd.Spec.PvcName = o.SourcePVCName
 	vd.Spec.SnapshotID = o.SnapshotID
+	if !o.ObjectLockExpiry.IsZero() {
+		expiry := metav1.NewTime(o.ObjectLockExpiry)
+		vd.Spec.ObjectLockExpiry = &expiry
+	}
 	_, err = kdmpSchedOps.Instance().CreateVolumeBackupDelete(vd)
 	if err != nil && !apierrors.IsAlreadyExists(err) {
 		errMsg := fmt.Sprintf("failed in creating volumeBackupDelete  [%s/%s]: %v", o.VolumeBackupDeleteName, o.VolumeBackupDeleteNamespace, err)
 		logrus.Errorf("%s %v", fn, errMsg)
 		return "", fmt.Errorf("%v", errMsg)
 	}
-	jobName := toJobName(o.JobName, o.SnapshotID)
 	job, err := buildJob(jobName, o)
 	if err != nil {
 		errMsg := fmt.Sprintf("building backup snapshot delete job [%s] failed: %v", jobName, err)
@@ -122,6 +120,7 @@ func (d Driver) DeleteJob(id string) error {
 		return fmt.Errorf(errMsg)
 	}
 
+	jobratelimit.ReleaseJob(id)
 	return nil
 }
 
@@ -145,7 +144,7 @@ func (d Driver) JobStatus(id string) (*drivers.JobStatus, error) {
 	if mountFailed {
 		utils.DisplayJobpodLogandEvents(job.Name, job.Namespace)
 		errMsg := fmt.Sprintf("job [%v/%v] failed to mount pvc, please check job pod's description for more detail", namespace, name)
-		return utils.ToJobStatus(0, errMsg, batchv1.JobFailed), nil
+		return utils.ToNodeFailedJobStatus(errMsg, batchv1.JobFailed), nil
 	}
 
 	err = utils.JobNodeExists(job)
@@ -0,0 +1,410 @@
+package kopialistfiles
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/portworx/kdmp/pkg/drivers"
+	"github.com/portworx/kdmp/pkg/drivers/utils"
+	"github.com/portworx/kdmp/pkg/jobratelimit"
+	"github.com/portworx/sched-ops/k8s/batch"
+	"github.com/portworx/sched-ops/k8s/core"
+	"github.com/sirupsen/logrus"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	kopiaListFilesJobPrefix = "ls"
+)
+
+// Driver is a kopia snapshot file listing implementation. The entries of the
+// snapshot are written by the job to a ConfigMap of the job namespace.
+type Driver struct{}
+
+// Name returns a name of the driver.
+func (d Driver) Name() string {
+	return drivers.KopiaListFiles
+}
+
+var listFilesJobLock sync.Mutex
+
+// StartJob creates a job listing the files of a kopia snapshot
+func (d Driver) StartJob(opts ...drivers.JobOption) (id string, err error) {
+	fn := "StartJob:"
+	listFilesJobLock.Lock()
+	defer listFilesJobLock.Unlock()
+	o := drivers.JobOpts{}
+	for _, opt := range opts {
+		if opt != nil {
+			if err := opt(&o); err != nil {
+				return "", err
+			}
+		}
+	}
+	if err := d.validate(o); err != nil {
+		errMsg := fmt.Sprintf("validation failed for list files job for snapshotID [%v]: %v", o.SnapshotID, err)
+		logrus.Infof("%s %v", fn, errMsg)
+		return "", fmt.Errorf(errMsg)
+	}
+	// Check whether there is slot to schedule list files job.
+	jobName := toJobName(o.JobName, o.SnapshotID)
+	if err := jobratelimit.AdmitJob(utils.NamespacedName(o.JobNamespace, jobName), d.Name(), o.JobNamespace); err != nil {
+		return "", err
+	}
+	job, err := buildJob(jobName, o)
+	if err != nil {
+		errMsg := fmt.Sprintf("building list files job [%s] failed: %v", jobName, err)
+		logrus.Errorf("%s %v", fn, errMsg)
+		return "", fmt.Errorf(errMsg)
+	}
+
+	// Create PV & PVC only in case of NFS.
+	if o.NfsServer != "" {
+		err := utils.CreateNFSPvPvcForJob(jobName, job.ObjectMeta.Namespace, o)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	if _, err = batch.Instance().CreateJob(job); err != nil && !apierrors.IsAlreadyExists(err) {
+		errMsg := fmt.Sprintf("creation of list files job [%s] failed: %v", jobName, err)
+		logrus.Errorf("%s %v", fn, errMsg)
+		return "", fmt.Errorf(errMsg)
+	}
+	logrus.Infof("%s created list files job [%s] successfully", fn, job.Name)
+	return utils.NamespacedName(job.Namespace, job.Name), nil
+}
+
+// DeleteJob deletes the list files job.
+// The ConfigMap is retained as it holds the listing.
+func (d Driver) DeleteJob(id string) error {
+	fn := "DeleteJob:"
+	namespace, name, err := utils.ParseJobID(id)
+	if err != nil {
+		logrus.Errorf("%s %v", fn, err)
+		return err
+	}
+	if err = batch.Instance().DeleteJob(name, namespace); err != nil && !apierrors.IsNotFound(err) {
+		errMsg := fmt.Sprintf("deletion of list files job [%s/%s] failed: %v", namespace, name, err)
+		logrus.Errorf("%s: %v", fn, errMsg)
+		return fmt.Errorf(errMsg)
+	}
+	// The PV and PVC of a NFS backuplocation are created with the job.
+	pvcName := utils.GetPvcNameForJob(name)
+	if err := core.Instance().DeletePersistentVolumeClaim(pvcName, namespace); err != nil && !apierrors.IsNotFound(err) {
+		errMsg := fmt.Sprintf("deletion of pvc [%s/%s] of list files job failed: %v", namespace, pvcName, err)
+		logrus.Errorf("%s: %v", fn, errMsg)
+		return fmt.Errorf(errMsg)
+	}
+	pvName := utils.GetPvNameForJob(name)
+	if err := core.Instance().DeletePersistentVolume(pvName); err != nil && !apierrors.IsNotFound(err) {
+		errMsg := fmt.Sprintf("deletion of pv [%s] of list files job failed: %v", pvName, err)
+		logrus.Errorf("%s: %v", fn, errMsg)
+		return fmt.Errorf(errMsg)
+	}
+
+	jobratelimit.ReleaseJob(id)
+	return nil
+}
+
+// JobStatus returns a progress status for a data transfer.
+func (d Driver) JobStatus(id string) (*drivers.JobStatus, error) {
+	fn := "JobStatus"
+	namespace, name, err := utils.ParseJobID(id)
+	if err != nil {
+		return utils.ToJobStatus(0, err.Error(), batchv1.JobConditionType("")), nil
+	}
+
+	job, err := batch.Instance().GetJob(name, namespace)
+	if err != nil {
+		errMsg := fmt.Sprintf("failed to fetch list files %s/%s job: %v", namespace, name, err)
+		logrus.Errorf("%s: %v", fn, errMsg)
+		return nil, fmt.Errorf(errMsg)
+	}
+
+	// Check whether mount point failure
+	mountFailed := utils.IsJobPodMountFailed(job, namespace)
+	if mountFailed {
+		utils.DisplayJobpodLogandEvents(job.Name, job.Namespace)
+		errMsg := fmt.Sprintf("job [%v/%v] failed to mount pvc, please check job pod's description for more detail", namespace, name)
+		return utils.ToNodeFailedJobStatus(errMsg, batchv1.JobFailed), nil
+	}
+
+	err = utils.JobNodeExists(job)
+	if err != nil {
+		errMsg := fmt.Sprintf("failed to fetch the node info tied to the job %s/%s: %v", namespace, name, err)
+		logrus.Errorf("%s: %v", fn, errMsg)
+		return nil, fmt.Errorf(errMsg)
+	}
+	var jobStatus batchv1.JobConditionType
+	if len(job.Status.Conditions) != 0 {
+		jobStatus = job.Status.Conditions[0].Type
+
+	}
+
+	if utils.IsJobFailed(job) {
+		utils.DisplayJobpodLogandEvents(job.Name, job.Namespace)
+		errMsg := fmt.Sprintf("check %s/%s job for details: %s", namespace, name, drivers.ErrJobFailed)
+		return utils.ToJobStatus(0, errMsg, jobStatus), nil
+	}
+	if utils.IsJobCompleted(job) {
+		return utils.ToJobStatus(drivers.TransferProgressCompleted, "", jobStatus), nil
+	}
+	return utils.ToJobStatus(0, "", jobStatus), nil
+}
+
+func (d Driver) validate(o drivers.JobOpts) error {
+	if o.SnapshotID == "" {
+		return fmt.Errorf("snapshot id should be set")
+	}
+	if o.JobNamespace == "" {
+		return fmt.Errorf("job namespace should be set")
+	}
+	return nil
+}
+
+func jobFor(
+	jobOption drivers.JobOpts,
+	jobName string,
+	resources corev1.ResourceRequirements,
+	labels map[string]string,
+) (*batchv1.Job, error) {
+	cmd := strings.Join([]string{
+		"/kopiaexecutor",
+		"listfiles",
+		"--repository",
+		toRepoName(jobOption.SourcePVCName, jobOption.SourcePVCNamespace),
+		"--snapshot-id",
+		jobOption.SnapshotID,
+		"--configmap-name",
+		toConfigMapName(jobOption.ListFilesConfigMapName, jobName),
+		"--configmap-namespace",
+		jobOption.JobNamespace,
+	}, " ")
+	if jobOption.ListFilesPath != "" {
+		cmd = fmt.Sprintf("%s --path %s", cmd, utils.ShellQuote(jobOption.ListFilesPath))
+	}
+
+	kopiaExecutorImage, imageRegistrySecret, err := utils.GetExecutorImageAndSecret(drivers.KopiaExecutorImage,
+		jobOption.KopiaImageExecutorSource,
+		jobOption.KopiaImageExecutorSourceNs,
+		jobName,
+		jobOption)
+	if err != nil {
+		errMsg := fmt.Errorf("failed to get the executor image details for job %s", jobName)
+		logrus.Errorf("%v", errMsg)
+		return nil, errMsg
+	}
+	tolerations, err := utils.GetTolerationsFromDeployment(jobOption.KopiaImageExecutorSource,
+		jobOption.KopiaImageExecutorSourceNs)
+	if err != nil {
+		logrus.Errorf("failed to get the toleration details: %v", err)
+		return nil, fmt.Errorf("failed to get the toleration details for job [%s/%s]", jobOption.Namespace, jobName)
+	}
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      jobName,
+			Namespace: jobOption.JobNamespace,
+			Annotations: map[string]string{
+				utils.SkipResourceAnnotation: "true",
+			},
+			Labels: labels,
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: &utils.JobPodBackOffLimit,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: labels,
+				},
+				Spec: corev1.PodSpec{
+					RestartPolicy:      corev1.RestartPolicyOnFailure,
+					ServiceAccountName: jobOption.ServiceAccountName,
+					Containers: []corev1.Container{
+						{
+							Name:  "kopiaexecutor",
+							Image: kopiaExecutorImage,
+							// TODO: Need to revert it to NotPresent. For now keep it as PullAlways.
+							ImagePullPolicy: corev1.PullAlways,
+							Command: []string{
+								"/bin/sh",
+								"-x",
+								"-c",
+								cmd,
+							},
+							Resources: resources,
+							VolumeMounts: []corev1.VolumeMount{
+								{
+									Name:      "cred-secret",
+									MountPath: drivers.KopiaCredSecretMount,
+									ReadOnly:  true,
+								},
+							},
+						},
+					},
+					Tolerations: tolerations,
+					Volumes: []corev1.Volume{
+						{
+							Name: "cred-secret",
+							VolumeSource: corev1.VolumeSource{
+								Secret: &corev1.SecretVolumeSource{
+									SecretName: jobOption.CredSecretName,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	// Add the image secret in job spec only if it is present in the stork deployment.
+	if len(imageRegistrySecret) != 0 {
+		job.Spec.Template.Spec.ImagePullSecrets = utils.ToImagePullSecret(utils.GetImageSecretName(jobName))
+	}
+
+	if len(jobOption.NfsServer) != 0 {
+		volumeMount := corev1.VolumeMount{
+			Name:      utils.NfsVolumeName,
+			MountPath: drivers.NfsMount,
+		}
+		job.Spec.Template.Spec.Containers[0].VolumeMounts = append(
+			job.Spec.Template.Spec.Containers[0].VolumeMounts,
+			volumeMount,
+		)
+		volume := corev1.Volume{
+			Name: utils.NfsVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+					ClaimName: utils.GetPvcNameForJob(jobName),
+				},
+			},
+		}
+
+		job.Spec.Template.Spec.Volumes = append(job.Spec.Template.Spec.Volumes, volume)
+	}
+
+	if drivers.CertFilePath != "" {
+
+		if !jobOption.S3DisableSSL {
+
+			volumeMount := corev1.VolumeMount{
+				Name:      utils.TLSCertMountVol,
+				MountPath: drivers.CertMount,
+				ReadOnly:  true,
+			}
+
+			job.Spec.Template.Spec.Containers[0].VolumeMounts = append(
+				job.Spec.Template.Spec.Containers[0].VolumeMounts,
+				volumeMount,
+			)
+
+			volume := corev1.Volume{
+				Name: utils.TLSCertMountVol,
+				VolumeSource: corev1.VolumeSource{
+					Secret: &corev1.SecretVolumeSource{
+						SecretName: jobOption.CertSecretName,
+					},
+				},
+			}
+
+			job.Spec.Template.Spec.Volumes = append(job.Spec.Template.Spec.Volumes, volume)
+
+			env := []corev1.EnvVar{
+				{
+					Name:  drivers.CertDirPath,
+					Value: drivers.CertMount,
+				},
+			}
+			job.Spec.Template.Spec.Containers[0].Env = env
+		}
+
+		if len(jobOption.NodeAffinity) > 0 {
+			// List files jobs for baas paid customer will run on the dedicated nodes assigned to customer instance
+			// Nodes wil have the following label "tenant: <instance name>"
+			// Iterate over the map list having affinity rules
+			matchExpressions := []corev1.NodeSelectorRequirement{}
+			for key, val := range jobOption.NodeAffinity {
+				expression := corev1.NodeSelectorRequirement{
+					Key:      key,
+					Operator: corev1.NodeSelectorOpIn,
+					Values:   []string{val},
+				}
+				matchExpressions = append(matchExpressions, expression)
+			}
+
+			job.Spec.Template.Spec.Affinity = &corev1.Affinity{
+				NodeAffinity: &corev1.NodeAffinity{
+					RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+						NodeSelectorTerms: []corev1.NodeSelectorTerm{
+							{
+								MatchExpressions: matchExpressions,
+							},
+						},
+					},
+				},
+			}
+		} else {
+			nodeAffinity, err := utils.GetNodeAffinityFromDeployment(jobOption.KopiaImageExecutorSource,
+				jobOption.KopiaImageExecutorSourceNs)
+			if err != nil {
+				logrus.Errorf("failed to get the node affinity details: %v", err)
+				return nil, fmt.Errorf("failed to get the node affinity details for job [%s/%s]", jobOption.Namespace, jobName)
+			}
+			job.Spec.Template.Spec.Affinity = &corev1.Affinity{
+				NodeAffinity: nodeAffinity,
+			}
+		}
+	}
+	return job, nil
+}
+
+func toJobName(jobName, snapshotID string) string {
+	if jobName != "" {
+		return jobName
+	}
+	return fmt.Sprintf("%s-%s", kopiaListFilesJobPrefix, snapshotID)
+}
+
+// toConfigMapName returns the ConfigMap of the listing, named after the job
+// if not set.
+func toConfigMapName(configMapName, jobName string) string {
+	if configMapName != "" {
+		return configMapName
+	}
+	return jobName
+}
+
+func toRepoName(pvcName, pvcNamespace string) string {
+	return fmt.Sprintf("%s-%s", pvcNamespace, pvcName)
+}
+
+func addJobLabels(jobOpts drivers.JobOpts) map[string]string {
+	labels := jobOpts.Labels
+	if labels == nil {
+		labels = make(map[string]string)
+	}
+
+	labels[drivers.DriverNameLabel] = drivers.KopiaListFiles
+	labels[utils.BackupObjectNameKey] = utils.GetValidLabel(jobOpts.BackupObjectName)
+	labels[utils.BackupObjectUIDKey] = jobOpts.BackupObjectUID
+	labels = utils.SetDisableIstioLabel(labels, jobOpts)
+	return labels
+}
+
+func buildJob(jobName string, jobOpts drivers.JobOpts) (*batchv1.Job, error) {
+	resources, err := utils.KopiaResourceRequirements(jobOpts.JobConfigMap, jobOpts.JobConfigMapNs)
+	if err != nil {
+		return nil, err
+	}
+
+	labels := addJobLabels(jobOpts)
+	return jobFor(
+		jobOpts,
+		jobName,
+		resources,
+		labels,
+	)
+}
@@ -0,0 +1,69 @@
+package kopialistfiles
+
+import (
+	"testing"
+
+	"github.com/portworx/kdmp/pkg/drivers"
+	"github.com/portworx/kdmp/pkg/drivers/utils"
+	"github.com/portworx/sched-ops/k8s/apps"
+	coreops "github.com/portworx/sched-ops/k8s/core"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// fakeCore serves no config maps.
+type fakeCore struct {
+	coreops.Ops
+}
+
+func (c *fakeCore) GetConfigMap(name, namespace string) (*corev1.ConfigMap, error) {
+	return nil, apierrors.NewNotFound(schema.GroupResource{Resource: "configmaps"}, name)
+}
+
+// fakeApps serves the executor source deployment.
+type fakeApps struct {
+	apps.Ops
+}
+
+func (a *fakeApps) GetDeployment(name, namespace string) (*appsv1.Deployment, error) {
+	return &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}}, nil
+}
+
+func TestJobFor(t *testing.T) {
+	coreops.SetInstance(&fakeCore{})
+	apps.SetInstance(&fakeApps{})
+	t.Setenv("KOPIA-EXECUTOR-IMAGE-REGISTRY", "registry.example.com")
+
+	jobOption := drivers.JobOpts{
+		SnapshotID:         "k1",
+		SourcePVCName:      "data",
+		SourcePVCNamespace: "app",
+		JobNamespace:       "kube-system",
+		CredSecretName:     "cred",
+	}
+	require.NoError(t, Driver{}.validate(jobOption))
+
+	// The listing is written to a ConfigMap named after the job
+	job, err := jobFor(jobOption, toJobName("", "k1"), corev1.ResourceRequirements{}, nil)
+	require.NoError(t, err)
+	require.Equal(t, "ls-k1", job.Name)
+	require.Equal(t, "kube-system", job.Namespace)
+	cmd := job.Spec.Template.Spec.Containers[0].Command[3]
+	require.Equal(t, "/kopiaexecutor listfiles --repository app-data --snapshot-id k1"+
+		" --configmap-name ls-k1 --configmap-namespace kube-system", cmd)
+
+	jobOption.ListFilesPath = "etc/app config"
+	jobOption.ListFilesConfigMapName = "listing"
+	job, err = jobFor(jobOption, "ls-k1", corev1.ResourceRequirements{}, nil)
+	require.NoError(t, err)
+	cmd = job.Spec.Template.Spec.Containers[0].Command[3]
+	require.Contains(t, cmd, " --configmap-name listing ")
+	require.Contains(t, cmd, " --path "+utils.ShellQuote("etc/app config"))
+
+	jobOption.SnapshotID = ""
+	require.Error(t, Driver{}.validate(jobOption))
+}
@@ -1,14 +1,19 @@
 package kopiamaintenance
 
 import (
+	"bytes"
 	"fmt"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/portworx/kdmp/pkg/drivers"
 	"github.com/portworx/kdmp/pkg/drivers/utils"
+	"github.com/portworx/kdmp/pkg/kms"
 	"github.com/portworx/kdmp/pkg/version"
 	"github.com/portworx/sched-ops/k8s/batch"
+	"github.com/portworx/sched-ops/k8s/core"
+	"github.com/portworx/sched-ops/k8s/stork"
 	"github.com/sirupsen/logrus"
 	batchv1 "k8s.io/api/batch/v1"
 	batchv1beta1 "k8s.io/api/batch/v1beta1"
@@ -21,12 +26,29 @@ const (
 	kopiaMaintenanceJobPrefix         = "repo-maintenance"
 	defaultFullSchedule               = "1 */23 * * *"
 	defaultQuickSchedule              = "1 */3 * * *"
+	defaultKeyRotationSchedule        = "1 1 1 * *"
+	defaultRepositoryStatusSchedule   = "31 */12 * * *"
+	defaultOrphanGCSchedule           = "41 2 * * *"
 	fullMaintenanceType               = "full"
 	quickMaintenaceTye                = "quick"
+	keyRotationMaintenanceType        = "key-rotation"
+	repositoryStatusMaintenanceType   = "repository-status"
+	orphanGCMaintenanceType           = "orphan-gc"
 	defaultFailedJobsHistoryLimit     = 1
 	defaultSuccessfulJobsHistoryLimit = 1
+	// cronJobNameMaxLength is the longest cron job name, leaving room for the
+	// suffix of the names of its jobs.
+	cronJobNameMaxLength = 52
 )
 
+// extraMaintenanceTypes are scheduled along with the full maintenance, the
+// key rotation only for a BackupLocation with a KMS.
+var extraMaintenanceTypes = []string{
+	keyRotationMaintenanceType,
+	repositoryStatusMaintenanceType,
+	orphanGCMaintenanceType,
+}
+
 // Driver is a kopia maintenance snapshot implementation
 type Driver struct{}
 
@@ -63,18 +85,47 @@ func (d Driver) StartJob(opts ...drivers.JobOption) (id string, err error) {
 		return "", err
 	}
 
+	hasKMS, err := addMaintenanceCredentials(o)
+	if err != nil {
+		errMsg := fmt.Sprintf("adding the kms config of backuplocation [%v] to the maintenance credentials failed: %v", o.BackupLocationName, err)
+		logrus.Errorf("%s %v", fn, errMsg)
+		return "", fmt.Errorf(errMsg)
+	}
+
+	if err := createCronJob(jobName, o, requiresV1); err != nil {
+		return "", err
+	}
+	// The callers only start the full and the quick maintenance, the other
+	// maintenance types are scheduled along with the full one.
+	if o.MaintenanceType == fullMaintenanceType {
+		for _, maintenanceType := range extraMaintenanceTypes {
+			if maintenanceType == keyRotationMaintenanceType && !hasKMS {
+				continue
+			}
+			extraOpts := o
+			extraOpts.MaintenanceType = maintenanceType
+			if err := createCronJob(toExtraJobName(jobName, maintenanceType), extraOpts, requiresV1); err != nil {
+				return "", err
+			}
+		}
+	}
+	return utils.NamespacedName(o.JobNamespace, jobName), nil
+}
+
+func createCronJob(jobName string, o drivers.JobOpts, requiresV1 bool) error {
+	fn := "createCronJob:"
 	job, err := buildJob(jobName, o, requiresV1)
 	if err != nil {
 		errMsg := fmt.Sprintf("building maintenance job [%s] for backuplocation [%v] failed: %v", jobName, o.BackupLocationName, err)
 		logrus.Errorf("%s %v", fn, errMsg)
-		return "", fmt.Errorf(errMsg)
+		return fmt.Errorf(errMsg)
 	}
 
 	// Create PV & PVC only in case of NFS.
 	if o.NfsServer != "" {
 		err := utils.CreateNFSPvPvcForJob(jobName, o.JobNamespace, o)
 		if err != nil {
-			return "", err
+			return err
 		}
 	}
 
@@ -88,10 +139,48 @@ func (d Driver) StartJob(opts ...drivers.JobOption) (id string, err error) {
 	if err != nil && !apierrors.IsAlreadyExists(err) {
 		errMsg := fmt.Sprintf("creation of maintenance job [%s] for backuplocation [%v] failed: %v", jobName, o.BackupLocationName, err)
 		logrus.Errorf("%s %v", fn, errMsg)
-		return "", fmt.Errorf(errMsg)
+		return fmt.Errorf(errMsg)
 	}
-	logrus.Infof("%s created maintenance job [%s] for backuplocation [%v] successfully", fn, o.BackupLocationName, jobName)
-	return utils.NamespacedName(o.JobNamespace, jobName), nil
+	logrus.Infof("%s created maintenance job [%s] for backuplocation [%v] successfully", fn, jobName, o.BackupLocationName)
+	return nil
+}
+
+// addMaintenanceCredentials adds the KMS config of the BackupLocation to the
+// maintenance credential secret created by the caller, as the maintenance
+// jobs unwrap the data keys of the repositories themselves. It returns
+// whether the BackupLocation has a KMS.
+func addMaintenanceCredentials(o drivers.JobOpts) (bool, error) {
+	if o.BackupLocationName == "" {
+		return false, nil
+	}
+	bl, err := stork.Instance().GetBackupLocation(o.BackupLocationName, o.BackupLocationNamespace)
+	if err != nil {
+		return false, fmt.Errorf("failed to get backuplocation %s/%s: %v", o.BackupLocationNamespace, o.BackupLocationName, err)
+	}
+	credentialData := make(map[string][]byte)
+	if err := kms.AddMaintenanceCredentials(credentialData, bl); err != nil {
+		return false, err
+	}
+	kmsConfig, ok := credentialData[kms.CredentialsKey]
+	if !ok {
+		return false, nil
+	}
+
+	secret, err := core.Instance().GetSecret(o.CredSecretName, o.CredSecretNamespace)
+	if err != nil {
+		return false, fmt.Errorf("failed to get secret %s/%s: %v", o.CredSecretNamespace, o.CredSecretName, err)
+	}
+	if bytes.Equal(secret.Data[kms.CredentialsKey], kmsConfig) {
+		return true, nil
+	}
+	if secret.Data == nil {
+		secret.Data = make(map[string][]byte)
+	}
+	secret.Data[kms.CredentialsKey] = kmsConfig
+	if _, err := core.Instance().UpdateSecret(secret); err != nil {
+		return false, fmt.Errorf("failed to update secret %s/%s: %v", o.CredSecretNamespace, o.CredSecretName, err)
+	}
+	return true, nil
 }
 
 // DeleteJob deletes the maintenance job.
@@ -108,20 +197,36 @@ func (d Driver) DeleteJob(id string) error {
 		return err
 	}
 
-	if requiresV1 {
-		err = batch.Instance().DeleteCronJob(name, namespace)
-	} else {
-		err = batch.Instance().DeleteCronJobV1beta1(name, namespace)
-	}
-	if err != nil && !apierrors.IsNotFound(err) {
+	if err := deleteCronJob(name, namespace, requiresV1); err != nil && !apierrors.IsNotFound(err) {
 		errMsg := fmt.Sprintf("deletion of maintenance job [%s/%s] failed: %v", namespace, name, err)
 		logrus.Errorf("%s: %v", fn, errMsg)
 		return fmt.Errorf(errMsg)
 	}
 	logrus.Infof("deleted maintenance cron job [%s/%s] successfully", namespace, name)
+	// The cron jobs scheduled along with a full maintenance one, there are
+	// none for the other maintenance jobs.
+	for _, maintenanceType := range extraMaintenanceTypes {
+		extraName := toExtraJobName(name, maintenanceType)
+		err := deleteCronJob(extraName, namespace, requiresV1)
+		if apierrors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			errMsg := fmt.Sprintf("deletion of maintenance job [%s/%s] failed: %v", namespace, extraName, err)
+			logrus.Errorf("%s: %v", fn, errMsg)
+			return fmt.Errorf(errMsg)
+		}
+		logrus.Infof("deleted maintenance cron job [%s/%s] successfully", namespace, extraName)
+	}
 	return nil
 }
 
+func deleteCronJob(name, namespace string, requiresV1 bool) error {
+	if requiresV1 {
+		return batch.Instance().DeleteCronJob(name, namespace)
+	}
+	return batch.Instance().DeleteCronJobV1beta1(name, namespace)
+}
+
 // JobStatus returns a progress of the maintenance cron job.
 func (d Driver) JobStatus(id string) (*drivers.JobStatus, error) {
 	fn := "JobStatus"
@@ -183,6 +288,12 @@ func jobFor(
 	scheduleInterval := defaultQuickSchedule
 	if jobOption.MaintenanceType == fullMaintenanceType {
 		scheduleInterval = defaultFullSchedule
+	} else if jobOption.MaintenanceType == keyRotationMaintenanceType {
+		scheduleInterval = defaultKeyRotationSchedule
+	} else if jobOption.MaintenanceType == repositoryStatusMaintenanceType {
+		scheduleInterval = defaultRepositoryStatusSchedule
+	} else if jobOption.MaintenanceType == orphanGCMaintenanceType {
+		scheduleInterval = defaultOrphanGCSchedule
 	}
 
 	cmd := strings.Join([]string{
@@ -200,6 +311,20 @@ func jobFor(
 		jobOption.MaintenanceType,
 	}, " ")
 
+	// The orphans are only deleted if it is enabled with a grace period.
+	if jobOption.MaintenanceType == orphanGCMaintenanceType {
+		if strings.TrimSpace(utils.GetConfigValue(utils.KdmpConfigmapName, utils.KdmpConfigmapNamespace, drivers.KopiaOrphanGCDelete)) == "true" {
+			cmd = fmt.Sprintf("%s --orphan-gc-delete", cmd)
+		}
+		gracePeriod := strings.TrimSpace(utils.GetConfigValue(utils.KdmpConfigmapName, utils.KdmpConfigmapNamespace, drivers.KopiaOrphanGCGracePeriod))
+		if gracePeriod != "" {
+			if _, err := time.ParseDuration(gracePeriod); err != nil {
+				return nil, fmt.Errorf("invalid %v value %q: %v", drivers.KopiaOrphanGCGracePeriod, gracePeriod, err)
+			}
+			cmd = fmt.Sprintf("%s --orphan-gc-grace-period %s", cmd, gracePeriod)
+		}
+	}
+
 	kopiaExecutorImage, imageRegistrySecret, err := utils.GetExecutorImageAndSecret(drivers.KopiaExecutorImage,
 		jobOption.KopiaImageExecutorSource,
 		jobOption.KopiaImageExecutorSourceNs,
@@ -427,6 +552,15 @@ func toJobName(jobName, backupLocation string) string {
 	return fmt.Sprintf("%s-%s", kopiaMaintenanceJobPrefix, backupLocation)
 }
 
+// toExtraJobName returns the name of the cron job of a maintenance type
+// scheduled along with the full maintenance job.
+func toExtraJobName(jobName, maintenanceType string) string {
+	if maxLength := cronJobNameMaxLength - len(maintenanceType) - 1; len(jobName) > maxLength {
+		jobName = strings.TrimRight(jobName[:maxLength], "-")
+	}
+	return fmt.Sprintf("%s-%s", jobName, maintenanceType)
+}
+
 func addJobLabels(jobOpts drivers.JobOpts) map[string]string {
 	labels := jobOpts.Labels
 	if labels == nil {
@@ -0,0 +1,187 @@
+package kopiamaintenance
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	storkapi "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"github.com/portworx/kdmp/pkg/drivers"
+	"github.com/portworx/kdmp/pkg/kms"
+	"github.com/portworx/sched-ops/k8s/apps"
+	"github.com/portworx/sched-ops/k8s/batch"
+	coreops "github.com/portworx/sched-ops/k8s/core"
+	"github.com/portworx/sched-ops/k8s/stork"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/version"
+)
+
+// fakeCore serves the secrets and no config maps.
+type fakeCore struct {
+	coreops.Ops
+	secrets map[string]*corev1.Secret
+	updated []string
+}
+
+func (c *fakeCore) GetVersion() (*version.Info, error) {
+	return &version.Info{GitVersion: "v1.25.0"}, nil
+}
+
+func (c *fakeCore) GetConfigMap(name, namespace string) (*corev1.ConfigMap, error) {
+	return nil, apierrors.NewNotFound(schema.GroupResource{Resource: "configmaps"}, name)
+}
+
+func (c *fakeCore) GetSecret(name, namespace string) (*corev1.Secret, error) {
+	secret, ok := c.secrets[name]
+	if !ok {
+		return nil, apierrors.NewNotFound(schema.GroupResource{Resource: "secrets"}, name)
+	}
+	return secret.DeepCopy(), nil
+}
+
+func (c *fakeCore) UpdateSecret(secret *corev1.Secret) (*corev1.Secret, error) {
+	c.secrets[secret.Name] = secret
+	c.updated = append(c.updated, secret.Name)
+	return secret, nil
+}
+
+// fakeApps serves the executor source deployment.
+type fakeApps struct {
+	apps.Ops
+}
+
+func (a *fakeApps) GetDeployment(name, namespace string) (*appsv1.Deployment, error) {
+	deployment := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}}
+	deployment.Spec.Template.Spec.Affinity = &corev1.Affinity{}
+	return deployment, nil
+}
+
+// fakeBatch records the cron jobs, only the full maintenance one exists to be
+// deleted.
+type fakeBatch struct {
+	batch.Ops
+	cronJobs map[string]*batchv1.CronJob
+	deleted  []string
+}
+
+func (b *fakeBatch) CreateCronJob(cronJob *batchv1.CronJob) (*batchv1.CronJob, error) {
+	b.cronJobs[cronJob.Name] = cronJob
+	return cronJob, nil
+}
+
+func (b *fakeBatch) DeleteCronJob(name, namespace string) error {
+	if strings.HasSuffix(name, "-"+keyRotationMaintenanceType) {
+		return apierrors.NewNotFound(schema.GroupResource{Resource: "cronjobs"}, name)
+	}
+	b.deleted = append(b.deleted, namespace+"/"+name)
+	return nil
+}
+
+// fakeStork serves the BackupLocation.
+type fakeStork struct {
+	stork.Ops
+	backupLocation *storkapi.BackupLocation
+}
+
+func (s *fakeStork) GetBackupLocation(name, namespace string) (*storkapi.BackupLocation, error) {
+	return s.backupLocation.DeepCopy(), nil
+}
+
+func maintenanceType(cronJob *batchv1.CronJob) string {
+	cmd := cronJob.Spec.JobTemplate.Spec.Template.Spec.Containers[0].Command[3]
+	return cmd[strings.Index(cmd, "--maintenance-type ")+len("--maintenance-type "):]
+}
+
+func TestStartJob(t *testing.T) {
+	fc := &fakeCore{secrets: map[string]*corev1.Secret{
+		"kms":  {ObjectMeta: metav1.ObjectMeta{Name: "kms"}, Data: map[string][]byte{"v1": []byte(strings.Repeat("a", 32))}},
+		"cred": {ObjectMeta: metav1.ObjectMeta{Name: "cred"}, Data: map[string][]byte{"password": []byte("secret")}},
+	}}
+	fb := &fakeBatch{cronJobs: make(map[string]*batchv1.CronJob)}
+	fs := &fakeStork{backupLocation: &storkapi.BackupLocation{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "bl",
+			Namespace: "kube-system",
+			Annotations: map[string]string{
+				kms.ProviderAnnotation: kms.ProviderSecret,
+				kms.SecretAnnotation:   "kms",
+			},
+		},
+		Location: storkapi.BackupLocationItem{Type: storkapi.BackupLocationS3},
+	}}
+	coreops.SetInstance(fc)
+	apps.SetInstance(&fakeApps{})
+	batch.SetInstance(fb)
+	stork.SetInstance(fs)
+	t.Setenv("KOPIA-EXECUTOR-IMAGE-REGISTRY", "registry.example.com")
+	opts := []drivers.JobOption{
+		drivers.WithBackupLocationName("bl"),
+		drivers.WithBackupLocationNamespace("kube-system"),
+		drivers.WithCredSecretName("cred"),
+		drivers.WithCredSecretNamespace("kube-system"),
+		drivers.WithJobNamespace("kube-system"),
+	}
+
+	// The other maintenance types are scheduled with the full maintenance
+	id, err := Driver{}.StartJob(append(opts, drivers.WithMaintenanceType(fullMaintenanceType))...)
+	require.NoError(t, err)
+	require.Equal(t, "kube-system/repo-maintenance-bl", id)
+	require.Len(t, fb.cronJobs, 4)
+	for name, schedule := range map[string]string{
+		"repo-maintenance-bl":                   defaultFullSchedule,
+		"repo-maintenance-bl-key-rotation":      defaultKeyRotationSchedule,
+		"repo-maintenance-bl-repository-status": defaultRepositoryStatusSchedule,
+		"repo-maintenance-bl-orphan-gc":         defaultOrphanGCSchedule,
+	} {
+		require.Contains(t, fb.cronJobs, name)
+		require.Equal(t, schedule, fb.cronJobs[name].Spec.Schedule, name)
+		require.Equal(t, "cred", fb.cronJobs[name].Spec.JobTemplate.Spec.Template.Spec.Volumes[0].Secret.SecretName)
+	}
+	require.Equal(t, orphanGCMaintenanceType, maintenanceType(fb.cronJobs["repo-maintenance-bl-orphan-gc"]))
+
+	// The kms config is added to the maintenance credentials once
+	require.Equal(t, []string{"cred"}, fc.updated)
+	var config kms.Config
+	require.NoError(t, json.Unmarshal(fc.secrets["cred"].Data[kms.CredentialsKey], &config))
+	require.Equal(t, kms.ProviderSecret, config.Provider)
+	require.Equal(t, []byte("secret"), fc.secrets["cred"].Data["password"])
+
+	// Only the quick maintenance is scheduled with it
+	fb.cronJobs = make(map[string]*batchv1.CronJob)
+	_, err = Driver{}.StartJob(append(opts, drivers.WithJobName("quick-bl"), drivers.WithMaintenanceType(quickMaintenaceTye))...)
+	require.NoError(t, err)
+	require.Len(t, fb.cronJobs, 1)
+	require.Equal(t, defaultQuickSchedule, fb.cronJobs["quick-bl"].Spec.Schedule)
+	require.Equal(t, []string{"cred"}, fc.updated)
+
+	// No key rotation without a kms
+	fs.backupLocation.Annotations = nil
+	fb.cronJobs = make(map[string]*batchv1.CronJob)
+	_, err = Driver{}.StartJob(append(opts, drivers.WithMaintenanceType(fullMaintenanceType))...)
+	require.NoError(t, err)
+	require.Len(t, fb.cronJobs, 3)
+	require.NotContains(t, fb.cronJobs, "repo-maintenance-bl-key-rotation")
+
+	// The cron jobs scheduled with the full maintenance are deleted with it
+	require.NoError(t, Driver{}.DeleteJob(id))
+	require.Equal(t, []string{
+		"kube-system/repo-maintenance-bl",
+		"kube-system/repo-maintenance-bl-repository-status",
+		"kube-system/repo-maintenance-bl-orphan-gc",
+	}, fb.deleted)
+}
+
+func TestToExtraJobName(t *testing.T) {
+	require.Equal(t, "repo-maintenance-bl-orphan-gc", toExtraJobName("repo-maintenance-bl", orphanGCMaintenanceType))
+	name := toExtraJobName("repo-maintenance-"+strings.Repeat("b", 40), repositoryStatusMaintenanceType)
+	require.Len(t, name, cronJobNameMaxLength)
+	require.True(t, strings.HasSuffix(name, "-"+repositoryStatusMaintenanceType))
+	// No dash is left before the suffix
+	require.Equal(t, strings.Repeat("a", 41)+"-orphan-gc", toExtraJobName(strings.Repeat("a", 41)+"-bl", orphanGCMaintenanceType))
+}
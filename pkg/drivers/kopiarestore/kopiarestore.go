@@ -12,6 +12,7 @@ import (
 	"github.com/portworx/kdmp/pkg/jobratelimit"
 	kdmpops "github.com/portworx/kdmp/pkg/util/ops"
 	"github.com/portworx/sched-ops/k8s/batch"
+	"github.com/portworx/sched-ops/k8s/core"
 	"github.com/sirupsen/logrus"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -45,13 +46,20 @@ func (d Driver) StartJob(opts ...drivers.JobOption) (id string, err error) {
 	}
 	// Check whether there is slot to schedule restore job.
 	driverType := d.Name()
-	available, err := jobratelimit.CanJobBeScheduled(driverType)
-	if err != nil {
-		logrus.Errorf("%v", err)
-		return "", err
+	if !o.JobQueueAdmitted {
+		id := utils.NamespacedName(o.Namespace, o.DataExportName)
+		if err := jobratelimit.AdmitJob(id, driverType, o.Namespace); err != nil {
+			return "", err
+		}
 	}
-	if !available {
-		return "", utils.ErrOutOfJobResources
+	// A restore PVC in block volume mode is attached to the job as a raw
+	// block device, the block backups are written onto it.
+	if o.DestinationPVCName != "" {
+		pvc, err := core.Instance().GetPersistentVolumeClaim(o.DestinationPVCName, o.Namespace)
+		if err != nil {
+			return "", err
+		}
+		o.BlockMode = pvc.Spec.VolumeMode != nil && *pvc.Spec.VolumeMode == corev1.PersistentVolumeBlock
 	}
 	if err := d.validate(o); err != nil {
 		return "", err
@@ -100,6 +108,7 @@ func (d Driver) DeleteJob(id string) error {
 	if err = batch.Instance().DeleteJob(name, namespace); err != nil && !apierrors.IsNotFound(err) {
 		return err
 	}
+	jobratelimit.ReleaseJob(id)
 
 	return nil
 }
@@ -130,7 +139,7 @@ func (d Driver) JobStatus(id string) (*drivers.JobStatus, error) {
 	if mountFailed {
 		utils.DisplayJobpodLogandEvents(job.Name, job.Namespace)
 		errMsg := fmt.Sprintf("job [%v/%v] failed to mount pvc, please check job pod's description for more detail", namespace, name)
-		return utils.ToJobStatus(0, errMsg, batchv1.JobFailed), nil
+		return utils.ToNodeFailedJobStatus(errMsg, batchv1.JobFailed), nil
 	}
 	err = utils.JobNodeExists(job)
 	if err != nil {
@@ -172,6 +181,9 @@ func (d Driver) validate(o drivers.JobOpts) error {
 	if o.VolumeBackupNamespace == "" {
 		return fmt.Errorf("volumebackup namespace should be set")
 	}
+	if o.BlockMode && (len(o.IncludePaths) > 0 || o.RestoreSubPath != "") {
+		return fmt.Errorf("file restore is not supported on a pvc in block volume mode")
+	}
 	return nil
 }
 
@@ -191,7 +203,7 @@ func jobFor(
 		return nil, err
 	}
 
-	cmd := strings.Join([]string{
+	args := []string{
 		"/kopiaexecutor",
 		"restore",
 		"--volume-backup-name",
@@ -206,11 +218,23 @@ func jobFor(
 		jobOption.Namespace,
 		"--credentials",
 		jobOption.DataExportName,
-		"--target-path",
-		"/data",
 		"--snapshot-id",
 		vb.Status.SnapshotID,
-	}, " ")
+	}
+	if jobOption.BlockMode {
+		args = append(args, "--target-block-device", drivers.KopiaBlockDevicePath)
+	} else {
+		args = append(args, "--target-path", "/data")
+	}
+	// The paths are quoted so that the globs are not expanded by the shell.
+	for _, includePath := range jobOption.IncludePaths {
+		args = append(args, "--include-path", utils.ShellQuote(includePath))
+	}
+	if jobOption.RestoreSubPath != "" {
+		args = append(args, "--target-sub-path", utils.ShellQuote(jobOption.RestoreSubPath))
+	}
+	args = append(args, utils.SpeedLimitArgs(jobOption)...)
+	cmd := strings.Join(args, " ")
 
 	kopiaExecutorImage, imageRegistrySecret, err := utils.GetExecutorImageAndSecret(drivers.KopiaExecutorImage,
 		jobOption.KopiaImageExecutorSource,
@@ -295,6 +319,11 @@ func jobFor(
 			},
 		},
 	}
+	// In block mode the PVC is attached to the job as a raw block device
+	// instead of being mounted.
+	if jobOption.BlockMode {
+		utils.SetBlockVolumeDevice(job, "vol")
+	}
 	// Add security Context only if the PSA is enabled.
 	if jobOption.PodUserId != "" || jobOption.PodGroupId != "" {
 		job, err = utils.AddSecurityContextToJob(job, jobOption.PodUserId, jobOption.PodGroupId)
@@ -0,0 +1,69 @@
+package kopiaverify
+
+import (
+	"testing"
+
+	"github.com/portworx/kdmp/pkg/drivers"
+	"github.com/portworx/kdmp/pkg/drivers/utils"
+	"github.com/portworx/sched-ops/k8s/batch"
+	coreops "github.com/portworx/sched-ops/k8s/core"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// fakeBatch records the deleted jobs.
+type fakeBatch struct {
+	batch.Ops
+	deleted []string
+}
+
+func (b *fakeBatch) DeleteJob(name, namespace string) error {
+	b.deleted = append(b.deleted, namespace+"/"+name)
+	return nil
+}
+
+// fakeCore records the deleted PVCs and PVs, the PV is already gone.
+type fakeCore struct {
+	coreops.Ops
+	deleted []string
+}
+
+func (c *fakeCore) DeletePersistentVolumeClaim(name, namespace string) error {
+	c.deleted = append(c.deleted, namespace+"/"+name)
+	return nil
+}
+
+func (c *fakeCore) DeletePersistentVolume(name string) error {
+	c.deleted = append(c.deleted, name)
+	return apierrors.NewNotFound(schema.GroupResource{Resource: "persistentvolumes"}, name)
+}
+
+func TestDeleteJob(t *testing.T) {
+	fb, fc := &fakeBatch{}, &fakeCore{}
+	batch.SetInstance(fb)
+	coreops.SetInstance(fc)
+
+	require.NoError(t, Driver{}.DeleteJob(utils.NamespacedName("kube-system", "v-k1")))
+	require.Equal(t, []string{"kube-system/v-k1"}, fb.deleted)
+	require.Equal(t, []string{
+		"kube-system/" + utils.GetPvcNameForJob("v-k1"),
+		utils.GetPvNameForJob("v-k1"),
+	}, fc.deleted)
+}
+
+func TestNewVolumeBackup(t *testing.T) {
+	vb := newVolumeBackup(drivers.JobOpts{
+		SnapshotID:              "k1",
+		VolumeBackupName:        "backup",
+		VolumeBackupNamespace:   "app",
+		SourcePVCName:           "data",
+		SourcePVCNamespace:      "app",
+		BackupLocationName:      "bl",
+		BackupLocationNamespace: "kube-system",
+	})
+	require.Equal(t, "k1", vb.Status.SnapshotID)
+	require.Equal(t, "app-data", vb.Spec.Repository)
+	require.Equal(t, "bl", vb.Spec.BackupLocation.Name)
+	require.Equal(t, drivers.KopiaBackup, vb.Labels[drivers.DriverNameLabel])
+}
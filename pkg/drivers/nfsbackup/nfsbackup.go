@@ -95,7 +95,7 @@ func (d Driver) JobStatus(id string) (*drivers.JobStatus, error) {
 	if mountFailed {
 		utils.DisplayJobpodLogandEvents(job.Name, job.Namespace)
 		errMsg := fmt.Sprintf("job [%v/%v] failed while mounting NFS mount endpoint", namespace, name)
-		return utils.ToJobStatus(0, errMsg, batchv1.JobFailed), nil
+		return utils.ToNodeFailedJobStatus(errMsg, batchv1.JobFailed), nil
 	}
 
 	var jobStatus batchv1.JobConditionType
@@ -118,7 +118,7 @@ func (d Driver) JobStatus(id string) (*drivers.JobStatus, error) {
 	}
 	if nodeErr {
 		errMsg = fmt.Sprintf("Node [%v] on which job [%v/%v] schedules is NotReady", job.Spec.Template.Spec.NodeName, namespace, name)
-		return utils.ToJobStatus(0, errMsg, jobStatus), nil
+		return utils.ToNodeFailedJobStatus(errMsg, jobStatus), nil
 	}
 
 	res, err := kdmp.Instance().GetResourceBackup(name, namespace)
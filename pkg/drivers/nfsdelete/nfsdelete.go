@@ -40,14 +40,9 @@ func (d Driver) StartJob(opts ...drivers.JobOption) (id string, err error) {
 		}
 	}
 	// Check whether there is slot to schedule delete job.
-	available, err := jobratelimit.CanJobBeScheduled(d.Name())
-	if err != nil {
-		logrus.Errorf("%v", err)
+	if err := jobratelimit.AdmitJob(utils.NamespacedName(o.JobNamespace, o.JobName), d.Name(), o.JobNamespace); err != nil {
 		return "", err
 	}
-	if !available {
-		return "", utils.ErrOutOfJobResources
-	}
 
 	job, err := buildJob(o)
 	if err != nil {
@@ -89,6 +84,7 @@ func (d Driver) DeleteJob(id string) error {
 		return fmt.Errorf(errMsg)
 	}
 
+	jobratelimit.ReleaseJob(id)
 	return nil
 }
 
@@ -111,7 +107,7 @@ func (d Driver) JobStatus(id string) (*drivers.JobStatus, error) {
 	if mountFailed {
 		utils.DisplayJobpodLogandEvents(job.Name, job.Namespace)
 		errMsg := fmt.Sprintf("job [%v/%v] failed while mounting NFS mount endpoint", namespace, name)
-		return utils.ToJobStatus(0, errMsg, batchv1.JobFailed), nil
+		return utils.ToNodeFailedJobStatus(errMsg, batchv1.JobFailed), nil
 	}
 	err = utils.JobNodeExists(job)
 	if err != nil {
@@ -96,7 +96,7 @@ func (d Driver) JobStatus(id string) (*drivers.JobStatus, error) {
 	if mountFailed {
 		utils.DisplayJobpodLogandEvents(job.Name, job.Namespace)
 		errMsg := fmt.Sprintf("job [%v/%v] failed while mounting NFS mount endpoint", namespace, name)
-		return utils.ToJobStatus(0, errMsg, batchv1.JobFailed), nil
+		return utils.ToNodeFailedJobStatus(errMsg, batchv1.JobFailed), nil
 	}
 	var jobStatus batchv1.JobConditionType
 	if len(job.Status.Conditions) != 0 {
@@ -119,7 +119,7 @@ func (d Driver) JobStatus(id string) (*drivers.JobStatus, error) {
 	}
 	if nodeErr {
 		errMsg = fmt.Sprintf("Node [%v] on which job [%v/%v] schedules is NotReady", job.Spec.Template.Spec.NodeName, namespace, name)
-		return utils.ToJobStatus(0, errMsg, jobStatus), nil
+		return utils.ToNodeFailedJobStatus(errMsg, jobStatus), nil
 	}
 
 	res, err := kdmp.Instance().GetResourceBackup(name, namespace)
@@ -212,7 +212,7 @@ func jobForRestoreResource(
 		return nil, fmt.Errorf(errMsg)
 	}
 
-	cmd := strings.Join([]string{
+	args := []string{
 		"/nfsexecutor",
 		opType,
 		"--app-cr-name",
@@ -225,7 +225,19 @@ func jobForRestoreResource(
 		// resourcebackup CR namespace
 		"--rb-cr-namespace",
 		jobOption.ResoureBackupNamespace,
-	}, " ")
+	}
+	if jobOption.DryRun {
+		// Only the resources are planned, the volumes can't be restored
+		// without changing the cluster.
+		if opType != "restore" {
+			errMsg := fmt.Sprintf("dry-run is not supported in stage %v of applicationRestore CR[%v/%v]",
+				restoreCR.Status.Stage, jobOption.AppCRNamespace, jobOption.AppCRName)
+			logrus.Errorf("%v", errMsg)
+			return nil, fmt.Errorf(errMsg)
+		}
+		args = append(args, "--dry-run")
+	}
+	cmd := strings.Join(args, " ")
 
 	labels := addJobLabels(jobOption)
 
@@ -3,6 +3,7 @@ package drivers
 import (
 	"fmt"
 	"strings"
+	"time"
 )
 
 // JobOption is used for job configuration.
@@ -62,6 +63,58 @@ type JobOpts struct {
 	// psa specifc option to be used by job
 	PodUserId  string
 	PodGroupId string
+	// BlockMode attaches the PVC to the job as a raw block device, to back
+	// it up or to restore a block backup onto it.
+	BlockMode bool
+	// LiveBackup backs up the source PVC from the node of the running pod
+	// using it, instead of mounting it in the job.
+	LiveBackup bool
+	// VerifyFilesPercent is the percentage of files read back by a verify job.
+	VerifyFilesPercent int
+	// IncludePaths restricts a restore to the matching entries of the snapshot.
+	IncludePaths   []string
+	RestoreSubPath string
+	// ListFilesPath is the directory of the snapshot listed by a list files
+	// job, the whole snapshot if not set. The listing is written to the
+	// ListFilesConfigMapName ConfigMap of the job namespace.
+	ListFilesPath          string
+	ListFilesConfigMapName string
+	// MaxUploadSpeed and MaxDownloadSpeed limit the data transfer speed of a
+	// job in bytes per second, 0 is unlimited.
+	MaxUploadSpeed   int64
+	MaxDownloadSpeed int64
+	// JobQueueAdmitted is set if the job was admitted by the job queue of the
+	// caller, the drivers then skip their job limit check.
+	JobQueueAdmitted bool
+	// ClusterPair is the stork ClusterPair of the remote cluster holding the
+	// destination PVC of a cross-cluster migration.
+	ClusterPair             string
+	ClusterPairNamespace    string
+	DestinationPVCNamespace string
+	// BatchVolumes are the volumes backed up by a single backup job, the
+	// source PVC is not used if they are set. The credential secret of the
+	// job needs the data keys of all their repositories.
+	BatchVolumes []BatchVolume
+	// BatchWorkers is the number of volumes of a batch backed up concurrently.
+	BatchWorkers int
+	// DryRun is set for the restores that only report what they would do.
+	DryRun bool
+	// ObjectLockExpiry is the object-lock expiry recorded on the VolumeBackup
+	// of the snapshot deleted by a delete job.
+	ObjectLockExpiry time.Time
+}
+
+// BatchVolume is a volume of a batch backup job. All the PVCs of a batch are
+// mounted by the same job pod, they should not be used by pods of other
+// nodes, like the PVCs restored from snapshots.
+type BatchVolume struct {
+	// VolumeBackupName is the VolumeBackup of the snapshot of the volume.
+	VolumeBackupName string
+	// PVCName is the PVC backed up.
+	PVCName string
+	// RepoPVCName is the PVC the repository of the volume is named after, the
+	// PVC backed up may be a snapshot of it.
+	RepoPVCName string
 }
 
 // WithS3DisableSSL is job parameter
@@ -536,3 +589,169 @@ func WithPodGroupId(PodGroupId string) JobOption {
 		return nil
 	}
 }
+
+// WithBlockMode is job parameter.
+func WithBlockMode(blockMode bool) JobOption {
+	return func(opts *JobOpts) error {
+		opts.BlockMode = blockMode
+		return nil
+	}
+}
+
+// WithLiveBackup is job parameter.
+func WithLiveBackup(liveBackup bool) JobOption {
+	return func(opts *JobOpts) error {
+		opts.LiveBackup = liveBackup
+		return nil
+	}
+}
+
+// WithVerifyFilesPercent is job parameter.
+func WithVerifyFilesPercent(percent int) JobOption {
+	return func(opts *JobOpts) error {
+		if percent < 0 || percent > 100 {
+			return fmt.Errorf("verify files percent should be between 0 and 100")
+		}
+		opts.VerifyFilesPercent = percent
+		return nil
+	}
+}
+
+// WithIncludePaths is job parameter.
+func WithIncludePaths(paths []string) JobOption {
+	return func(opts *JobOpts) error {
+		for _, p := range paths {
+			if strings.TrimSpace(p) == "" {
+				return fmt.Errorf("include path should not be empty")
+			}
+		}
+		opts.IncludePaths = paths
+		return nil
+	}
+}
+
+// WithRestoreSubPath is job parameter.
+func WithRestoreSubPath(subPath string) JobOption {
+	return func(opts *JobOpts) error {
+		opts.RestoreSubPath = strings.TrimSpace(subPath)
+		return nil
+	}
+}
+
+// WithListFilesPath is job parameter.
+func WithListFilesPath(dirPath string) JobOption {
+	return func(opts *JobOpts) error {
+		opts.ListFilesPath = strings.TrimSpace(dirPath)
+		return nil
+	}
+}
+
+// WithListFilesConfigMapName is job parameter.
+func WithListFilesConfigMapName(name string) JobOption {
+	return func(opts *JobOpts) error {
+		opts.ListFilesConfigMapName = strings.TrimSpace(name)
+		return nil
+	}
+}
+
+// WithMaxUploadSpeed is job parameter.
+func WithMaxUploadSpeed(bytesPerSec int64) JobOption {
+	return func(opts *JobOpts) error {
+		if bytesPerSec < 0 {
+			return fmt.Errorf("max upload speed should not be negative")
+		}
+		opts.MaxUploadSpeed = bytesPerSec
+		return nil
+	}
+}
+
+// WithMaxDownloadSpeed is job parameter.
+func WithMaxDownloadSpeed(bytesPerSec int64) JobOption {
+	return func(opts *JobOpts) error {
+		if bytesPerSec < 0 {
+			return fmt.Errorf("max download speed should not be negative")
+		}
+		opts.MaxDownloadSpeed = bytesPerSec
+		return nil
+	}
+}
+
+// WithJobQueueAdmitted is job parameter.
+func WithJobQueueAdmitted(admitted bool) JobOption {
+	return func(opts *JobOpts) error {
+		opts.JobQueueAdmitted = admitted
+		return nil
+	}
+}
+
+// WithClusterPair is job parameter.
+func WithClusterPair(name string) JobOption {
+	return func(opts *JobOpts) error {
+		opts.ClusterPair = strings.TrimSpace(name)
+		return nil
+	}
+}
+
+// WithClusterPairNamespace is job parameter.
+func WithClusterPairNamespace(namespace string) JobOption {
+	return func(opts *JobOpts) error {
+		opts.ClusterPairNamespace = strings.TrimSpace(namespace)
+		return nil
+	}
+}
+
+// WithDestinationPVCNamespace is job parameter.
+func WithDestinationPVCNamespace(namespace string) JobOption {
+	return func(opts *JobOpts) error {
+		opts.DestinationPVCNamespace = strings.TrimSpace(namespace)
+		return nil
+	}
+}
+
+// WithBatchVolumes is job parameter.
+func WithBatchVolumes(volumes []BatchVolume) JobOption {
+	return func(opts *JobOpts) error {
+		names := make(map[string]bool)
+		for _, volume := range volumes {
+			if strings.TrimSpace(volume.VolumeBackupName) == "" || strings.TrimSpace(volume.PVCName) == "" {
+				return fmt.Errorf("volumebackup and pvc names of batch volumes should be set")
+			}
+			if names[volume.VolumeBackupName] {
+				return fmt.Errorf("volumebackup %s is set for several batch volumes", volume.VolumeBackupName)
+			}
+			names[volume.VolumeBackupName] = true
+			if volume.RepoPVCName == "" {
+				volume.RepoPVCName = volume.PVCName
+			}
+			opts.BatchVolumes = append(opts.BatchVolumes, volume)
+		}
+		return nil
+	}
+}
+
+// WithBatchWorkers is job parameter.
+func WithBatchWorkers(workers int) JobOption {
+	return func(opts *JobOpts) error {
+		if workers < 0 {
+			return fmt.Errorf("batch workers can not be negative")
+		}
+		opts.BatchWorkers = workers
+		return nil
+	}
+}
+
+// WithDryRun is job parameter.
+func WithDryRun(dryRun bool) JobOption {
+	return func(opts *JobOpts) error {
+		opts.DryRun = dryRun
+		return nil
+	}
+}
+
+// WithObjectLockExpiry is job parameter.
+func WithObjectLockExpiry(expiry time.Time) JobOption {
+	return func(opts *JobOpts) error {
+		opts.ObjectLockExpiry = expiry
+		return nil
+	}
+}
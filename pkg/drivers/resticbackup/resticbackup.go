@@ -141,12 +141,13 @@ func jobFor(
 	backuplocationName,
 	backuplocationNamespace string,
 	resources corev1.ResourceRequirements,
-	labels map[string]string) (*batchv1.Job, error) {
+	labels map[string]string,
+	extraArgs []string) (*batchv1.Job, error) {
 	backupName := jobName
 
 	labels = addJobLabels(labels)
 
-	cmd := strings.Join([]string{
+	args := []string{
 		"/resticexecutor",
 		"backup",
 		"--backup-location",
@@ -161,7 +162,8 @@ func jobFor(
 		filepath.Join(drivers.SecretMount, drivers.SecretKey),
 		"--source-path",
 		"/data",
-	}, " ")
+	}
+	cmd := strings.Join(append(args, extraArgs...), " ")
 
 	return &batchv1.Job{
 		ObjectMeta: metav1.ObjectMeta{
@@ -269,6 +271,7 @@ func buildJob(jobName string, o drivers.JobOpts) (*batchv1.Job, error) {
 			pods[0],
 			resources,
 			o.Labels,
+			utils.SpeedLimitArgs(o),
 		)
 	}
 
@@ -280,6 +283,7 @@ func buildJob(jobName string, o drivers.JobOpts) (*batchv1.Job, error) {
 		o.BackupLocationNamespace,
 		resources,
 		o.Labels,
+		utils.SpeedLimitArgs(o),
 	)
 }
 
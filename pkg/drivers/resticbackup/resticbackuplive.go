@@ -7,16 +7,11 @@ import (
 
 	"github.com/portworx/kdmp/pkg/drivers"
 	"github.com/portworx/kdmp/pkg/drivers/utils"
-	coreops "github.com/portworx/sched-ops/k8s/core"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
-var (
-	defaultPodsMountPath = "/var/lib/kubelet/pods"
-)
-
 func jobForLiveBackup(
 	jobName,
 	namespace,
@@ -25,8 +20,9 @@ func jobForLiveBackup(
 	backuplocationNamespace string,
 	mountPod corev1.Pod,
 	resources corev1.ResourceRequirements,
-	labels map[string]string) (*batchv1.Job, error) {
-	volDir, err := getVolumeDirectory(pvcName, namespace)
+	labels map[string]string,
+	extraArgs []string) (*batchv1.Job, error) {
+	volDir, err := utils.GetVolumeDirectory(pvcName, namespace)
 	if err != nil {
 		return nil, err
 	}
@@ -34,14 +30,14 @@ func jobForLiveBackup(
 	// pod volumes reside under /var/lib/kubelet/pods/<podUID>/volumes/<volumePlugin>/<volumeName> directory.
 	// mount /var/lib/kubelet/pods/<podUID>/volumes as a /data directory to a resticexecutor job and
 	// use /data/*/<volumeName> as a backup directory and determine volume plugin by resticexecutor.
-	podVolumesPath := fmt.Sprintf("%s/%s/volumes", defaultPodsMountPath, mountPod.UID)
+	podVolumesPath := utils.GetPodVolumesPath(mountPod)
 	backupPath := fmt.Sprintf("/data/*/%s", volDir)
 
 	backupName := jobName
 
 	labels = addJobLabels(labels)
 
-	cmd := strings.Join([]string{
+	args := []string{
 		"/resticexecutor",
 		"backup",
 		"--backup-location",
@@ -56,7 +52,8 @@ func jobForLiveBackup(
 		filepath.Join(drivers.SecretMount, drivers.SecretKey),
 		"--source-path-glob",
 		backupPath,
-	}, " ")
+	}
+	cmd := strings.Join(append(args, extraArgs...), " ")
 
 	return &batchv1.Job{
 		ObjectMeta: metav1.ObjectMeta{
@@ -122,24 +119,3 @@ func jobForLiveBackup(
 		},
 	}, nil
 }
-
-// getVolumeDirectory gets the name of the directory on the host, under /var/lib/kubelet/pods/<podUID>/volumes/,
-// where the specified volume lives. For volumes with a CSIVolumeSource, append "/mount" to the directory name.
-func getVolumeDirectory(pvcName, pvcNamespace string) (string, error) {
-	pvc, err := coreops.Instance().GetPersistentVolumeClaim(pvcName, pvcNamespace)
-	if err != nil {
-		return "", err
-	}
-
-	pv, err := coreops.Instance().GetPersistentVolume(pvc.Spec.VolumeName)
-	if err != nil {
-		return "", err
-	}
-
-	// PV's been created with a CSI source.
-	if pv.Spec.CSI != nil {
-		return pvc.Spec.VolumeName + "/mount", nil
-	}
-
-	return pvc.Spec.VolumeName, nil
-}
@@ -0,0 +1,383 @@
+package resticmaintenance
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/portworx/kdmp/pkg/drivers"
+	"github.com/portworx/kdmp/pkg/drivers/utils"
+	"github.com/portworx/kdmp/pkg/version"
+	"github.com/portworx/sched-ops/k8s/batch"
+	coreops "github.com/portworx/sched-ops/k8s/core"
+	"github.com/sirupsen/logrus"
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	resticMaintenanceJobPrefix        = "restic-repo-maintenance"
+	defaultFullSchedule               = "1 */23 * * *"
+	defaultQuickSchedule              = "1 */3 * * *"
+	fullMaintenanceType               = "full"
+	quickMaintenanceType              = "quick"
+	defaultFailedJobsHistoryLimit     = 1
+	defaultSuccessfulJobsHistoryLimit = 1
+)
+
+// Driver is a restic repository maintenance implementation
+type Driver struct{}
+
+// Name returns a name of the driver.
+func (d Driver) Name() string {
+	return drivers.ResticMaintenance
+}
+
+var maintenanceJobLock sync.Mutex
+
+// StartJob creates a cron job for restic repository maintenance
+// Note: Not added separate interface apis for cronjob. Reused job apis to start the cron job.
+func (d Driver) StartJob(opts ...drivers.JobOption) (id string, err error) {
+	fn := "StartJob:"
+	maintenanceJobLock.Lock()
+	defer maintenanceJobLock.Unlock()
+	o := drivers.JobOpts{}
+	for _, opt := range opts {
+		if opt != nil {
+			if err := opt(&o); err != nil {
+				return "", err
+			}
+		}
+	}
+	if err := d.validate(o); err != nil {
+		errMsg := fmt.Sprintf("validation failed for restic maintenance job for backuplocation [%v]: %v", o.BackupLocationName, err)
+		logrus.Infof("%s %v", fn, errMsg)
+		return "", fmt.Errorf(errMsg)
+	}
+	if o.JobNamespace == "" {
+		o.JobNamespace = o.BackupLocationNamespace
+	}
+	jobName := toJobName(o.JobName, o.BackupLocationName, o.MaintenanceType)
+
+	requiresV1, err := version.RequiresV1CronJob()
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := coreops.Instance().CreateSecret(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      jobName,
+			Namespace: o.JobNamespace,
+			Annotations: map[string]string{
+				utils.SkipResourceAnnotation: "true",
+			},
+		},
+		StringData: map[string]string{
+			drivers.SecretKey: drivers.SecretValue,
+		},
+	}); err != nil && !apierrors.IsAlreadyExists(err) {
+		return "", fmt.Errorf("create a secret for a restic password: %s", err)
+	}
+
+	if err := utils.SetupNFSServiceAccount(jobName, o.JobNamespace, roleFor()); err != nil {
+		errMsg := fmt.Sprintf("error creating service account %s/%s: %v", o.JobNamespace, jobName, err)
+		logrus.Errorf("%s %v", fn, errMsg)
+		return "", fmt.Errorf(errMsg)
+	}
+
+	job, err := buildJob(jobName, o, requiresV1)
+	if err != nil {
+		errMsg := fmt.Sprintf("building restic maintenance job [%s] for backuplocation [%v] failed: %v", jobName, o.BackupLocationName, err)
+		logrus.Errorf("%s %v", fn, errMsg)
+		return "", fmt.Errorf(errMsg)
+	}
+
+	if requiresV1 {
+		jobV1 := job.(*batchv1.CronJob)
+		_, err = batch.Instance().CreateCronJob(jobV1)
+	} else {
+		jobV1Beta1 := job.(*batchv1beta1.CronJob)
+		_, err = batch.Instance().CreateCronJobV1beta1(jobV1Beta1)
+	}
+	if err != nil && !apierrors.IsAlreadyExists(err) {
+		errMsg := fmt.Sprintf("creation of restic maintenance job [%s] for backuplocation [%v] failed: %v", jobName, o.BackupLocationName, err)
+		logrus.Errorf("%s %v", fn, errMsg)
+		return "", fmt.Errorf(errMsg)
+	}
+	logrus.Infof("%s created restic maintenance job [%s] for backuplocation [%v] successfully", fn, jobName, o.BackupLocationName)
+	return utils.NamespacedName(o.JobNamespace, jobName), nil
+}
+
+// DeleteJob deletes the maintenance cron job along with its password secret and service account.
+func (d Driver) DeleteJob(id string) error {
+	fn := "DeleteJob:"
+	namespace, name, err := utils.ParseJobID(id)
+	if err != nil {
+		logrus.Errorf("%s %v", fn, err)
+		return err
+	}
+
+	requiresV1, err := version.RequiresV1CronJob()
+	if err != nil {
+		return err
+	}
+
+	if requiresV1 {
+		err = batch.Instance().DeleteCronJob(name, namespace)
+	} else {
+		err = batch.Instance().DeleteCronJobV1beta1(name, namespace)
+	}
+	if err != nil && !apierrors.IsNotFound(err) {
+		errMsg := fmt.Sprintf("deletion of restic maintenance job [%s/%s] failed: %v", namespace, name, err)
+		logrus.Errorf("%s: %v", fn, errMsg)
+		return fmt.Errorf(errMsg)
+	}
+
+	if err := utils.CleanServiceAccount(name, namespace); err != nil {
+		errMsg := fmt.Sprintf("deletion of service account %s/%s failed: %v", namespace, name, err)
+		logrus.Errorf("%s: %v", fn, errMsg)
+		return fmt.Errorf(errMsg)
+	}
+
+	if err := coreops.Instance().DeleteSecret(name, namespace); err != nil && !apierrors.IsNotFound(err) {
+		errMsg := fmt.Sprintf("deletion of restic password secret %s/%s failed: %v", namespace, name, err)
+		logrus.Errorf("%s: %v", fn, errMsg)
+		return fmt.Errorf(errMsg)
+	}
+	logrus.Infof("deleted restic maintenance cron job [%s/%s] successfully", namespace, name)
+	return nil
+}
+
+// JobStatus returns a progress of the maintenance cron job.
+func (d Driver) JobStatus(id string) (*drivers.JobStatus, error) {
+	fn := "JobStatus"
+	namespace, name, err := utils.ParseJobID(id)
+	if err != nil {
+		return utils.ToJobStatus(0, err.Error(), batchv1.JobConditionType("")), nil
+	}
+
+	job, err := batch.Instance().GetJob(name, namespace)
+	if err != nil {
+		errMsg := fmt.Sprintf("failed to fetch restic maintenance [%s/%s] job: %v", namespace, name, err)
+		logrus.Errorf("%s: %v", fn, errMsg)
+		return nil, fmt.Errorf(errMsg)
+	}
+	var jobStatus batchv1.JobConditionType
+	if len(job.Status.Conditions) != 0 {
+		jobStatus = job.Status.Conditions[0].Type
+	}
+
+	if utils.IsJobFailed(job) {
+		utils.DisplayJobpodLogandEvents(job.Name, job.Namespace)
+		errMsg := fmt.Sprintf("check restic maintenance [%s/%s] job for details: %s", namespace, name, drivers.ErrJobFailed)
+		return utils.ToJobStatus(0, errMsg, jobStatus), nil
+	}
+	if utils.IsJobCompleted(job) {
+		return utils.ToJobStatus(drivers.TransferProgressCompleted, "", jobStatus), nil
+	}
+	return utils.ToJobStatus(0, "", jobStatus), nil
+}
+
+func (d Driver) validate(o drivers.JobOpts) error {
+	if o.BackupLocationName == "" {
+		return fmt.Errorf("backuplocation name should be set")
+	}
+	if o.BackupLocationNamespace == "" {
+		return fmt.Errorf("backuplocation namespace should be set")
+	}
+	if o.MaintenanceType != fullMaintenanceType && o.MaintenanceType != quickMaintenanceType {
+		return fmt.Errorf("maintenance type should be either %s or %s", fullMaintenanceType, quickMaintenanceType)
+	}
+	return nil
+}
+
+func jobFor(
+	jobOption drivers.JobOpts,
+	jobName string,
+	resources corev1.ResourceRequirements,
+	requiresV1 bool,
+) (interface{}, error) {
+	labels := addJobLabels(jobOption)
+	var successfulJobsHistoryLimit int32 = defaultSuccessfulJobsHistoryLimit
+	var failedJobsHistoryLimit int32 = defaultFailedJobsHistoryLimit
+
+	scheduleInterval := defaultQuickSchedule
+	if jobOption.MaintenanceType == fullMaintenanceType {
+		scheduleInterval = defaultFullSchedule
+	}
+
+	cmd := strings.Join([]string{
+		"/resticexecutor",
+		"maintenance",
+		"--backup-location",
+		jobOption.BackupLocationName,
+		"--namespace",
+		jobOption.BackupLocationNamespace,
+		"--secret-file-path",
+		filepath.Join(drivers.SecretMount, drivers.SecretKey),
+		"--maintenance-status-name",
+		jobOption.MaintenanceStatusName,
+		"--maintenance-status-namespace",
+		jobOption.MaintenanceStatusNamespace,
+		"--maintenance-type",
+		jobOption.MaintenanceType,
+	}, " ")
+
+	// Snapshots are only forgotten if a keep policy is configured.
+	forgetPolicy := strings.TrimSpace(utils.GetConfigValue(utils.KdmpConfigmapName, utils.KdmpConfigmapNamespace, drivers.ResticForgetPolicy))
+	if forgetPolicy != "" {
+		cmd = fmt.Sprintf("%s --forget-policy %s", cmd, utils.ShellQuote(forgetPolicy))
+	}
+
+	jobObjectMeta := metav1.ObjectMeta{
+		Name:      jobName,
+		Namespace: jobOption.JobNamespace,
+		Annotations: map[string]string{
+			utils.SkipResourceAnnotation: "true",
+		},
+		Labels: labels,
+	}
+
+	jobSpec := corev1.PodSpec{
+		RestartPolicy:      corev1.RestartPolicyOnFailure,
+		ImagePullSecrets:   utils.ToImagePullSecret(utils.ResticExecutorImageSecret()),
+		ServiceAccountName: jobName,
+		Containers: []corev1.Container{
+			{
+				Name:  "resticexecutor",
+				Image: utils.ResticExecutorImage(),
+				Command: []string{
+					"/bin/sh",
+					"-x",
+					"-c",
+					cmd,
+				},
+				Resources: resources,
+				VolumeMounts: []corev1.VolumeMount{
+					{
+						Name:      "secret",
+						MountPath: drivers.SecretMount,
+						ReadOnly:  true,
+					},
+				},
+			},
+		},
+		Volumes: []corev1.Volume{
+			{
+				Name: "secret",
+				VolumeSource: corev1.VolumeSource{
+					Secret: &corev1.SecretVolumeSource{
+						SecretName: jobName,
+					},
+				},
+			},
+		},
+	}
+
+	if requiresV1 {
+		return &batchv1.CronJob{
+			ObjectMeta: jobObjectMeta,
+			Spec: batchv1.CronJobSpec{
+				ConcurrencyPolicy:          batchv1.ForbidConcurrent,
+				Schedule:                   scheduleInterval,
+				SuccessfulJobsHistoryLimit: &successfulJobsHistoryLimit,
+				FailedJobsHistoryLimit:     &failedJobsHistoryLimit,
+				JobTemplate: batchv1.JobTemplateSpec{
+					ObjectMeta: jobObjectMeta,
+					Spec: batchv1.JobSpec{
+						BackoffLimit: &utils.JobPodBackOffLimit,
+						Template: corev1.PodTemplateSpec{
+							ObjectMeta: metav1.ObjectMeta{
+								Labels: labels,
+							},
+							Spec: jobSpec,
+						},
+					},
+				},
+			},
+		}, nil
+	}
+
+	return &batchv1beta1.CronJob{
+		ObjectMeta: jobObjectMeta,
+		Spec: batchv1beta1.CronJobSpec{
+			ConcurrencyPolicy:          batchv1beta1.ForbidConcurrent,
+			Schedule:                   scheduleInterval,
+			SuccessfulJobsHistoryLimit: &successfulJobsHistoryLimit,
+			FailedJobsHistoryLimit:     &failedJobsHistoryLimit,
+			JobTemplate: batchv1beta1.JobTemplateSpec{
+				ObjectMeta: jobObjectMeta,
+				Spec: batchv1.JobSpec{
+					BackoffLimit: &utils.JobPodBackOffLimit,
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Labels: labels,
+						},
+						Spec: jobSpec,
+					},
+				},
+			},
+		},
+	}, nil
+}
+
+func toJobName(jobName, backupLocation, maintenanceType string) string {
+	if jobName != "" {
+		return jobName
+	}
+	return fmt.Sprintf("%s-%s-%s", resticMaintenanceJobPrefix, maintenanceType, backupLocation)
+}
+
+func addJobLabels(jobOpts drivers.JobOpts) map[string]string {
+	labels := jobOpts.Labels
+	if labels == nil {
+		labels = make(map[string]string)
+	}
+
+	labels[drivers.DriverNameLabel] = drivers.ResticMaintenance
+	labels = utils.SetDisableIstioLabel(labels, jobOpts)
+	return labels
+}
+
+func buildJob(jobName string, jobOpts drivers.JobOpts, requiresV1 bool) (interface{}, error) {
+	resources, err := utils.ResticResourceRequirements()
+	if err != nil {
+		return nil, err
+	}
+
+	return jobFor(
+		jobOpts,
+		jobName,
+		resources,
+		requiresV1,
+	)
+}
+
+// roleFor returns a cluster role as the restic repositories of a backuplocation
+// are looked up from the VolumeBackups of all the namespaces.
+func roleFor() *rbacv1.ClusterRole {
+	return &rbacv1.ClusterRole{
+		Rules: []rbacv1.PolicyRule{
+			{
+				APIGroups: []string{"stork.libopenstorage.org"},
+				Resources: []string{"backuplocations"},
+				Verbs:     []string{"get", "list"},
+			},
+			{
+				APIGroups: []string{"kdmp.portworx.com"},
+				Resources: []string{"volumebackups"},
+				Verbs:     []string{"get", "list"},
+			},
+			{
+				APIGroups: []string{"kdmp.portworx.com"},
+				Resources: []string{"backuplocationmaintenances"},
+				Verbs:     []string{"get", "update"},
+			},
+		},
+	}
+}
@@ -66,7 +66,8 @@ func (d Driver) StartJob(opts ...drivers.JobOption) (id string, err error) {
 		vb.Spec.BackupLocation.Namespace,
 		vb.Spec.Repository,
 		resticSecretName,
-		o.Labels)
+		o.Labels,
+		utils.SpeedLimitArgs(o))
 	if err != nil {
 		return "", err
 	}
@@ -150,7 +151,8 @@ func jobFor(
 	backuplocationNamespace,
 	repository,
 	secretName string,
-	labels map[string]string) (*batchv1.Job, error) {
+	labels map[string]string,
+	extraArgs []string) (*batchv1.Job, error) {
 	labels = addJobLabels(labels)
 
 	resources, err := utils.ResticResourceRequirements()
@@ -163,7 +165,7 @@ func jobFor(
 		return nil, err
 	}
 
-	cmd := strings.Join([]string{
+	args := []string{
 		"/resticexecutor",
 		"restore",
 		"--backup-location",
@@ -176,7 +178,8 @@ func jobFor(
 		filepath.Join(drivers.SecretMount, drivers.SecretKey),
 		"--target-path",
 		"/data",
-	}, " ")
+	}
+	cmd := strings.Join(append(args, extraArgs...), " ")
 
 	return &batchv1.Job{
 		ObjectMeta: metav1.ObjectMeta{
@@ -0,0 +1,80 @@
+package rsync
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/portworx/kdmp/pkg/drivers"
+	"github.com/portworx/sched-ops/k8s/core"
+	"github.com/sirupsen/logrus"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// progressFlags make rsync report the progress of the whole transfer. The
+	// file list is built upfront, otherwise the percentage only covers the
+	// files scanned so far.
+	progressFlags = "--info=progress2 --no-inc-recursive"
+	// progressLogWindow is how far back the job logs are read, rsync updates
+	// the progress line every second or so.
+	progressLogWindow = 2 * time.Minute
+	// maxRunningProgress is reported until the job is completed, rsync
+	// reaches 100% before it has set the file attributes.
+	maxRunningProgress = 99
+)
+
+// progressRegexp matches the progress lines, like:
+//
+//	1,238,099  12%  10.29MB/s    0:00:01 (xfr#4, to-chk=10/15)
+var progressRegexp = regexp.MustCompile(`^\s*[\d,.]+[KMGTP]?\s+(\d{1,3})%`)
+
+// parseProgress returns the last progress percentage of the rsync output. The
+// progress lines are separated by carriage returns.
+func parseProgress(out string) (float64, bool) {
+	lines := strings.FieldsFunc(out, func(r rune) bool {
+		return r == '\r' || r == '\n'
+	})
+	for i := len(lines) - 1; i >= 0; i-- {
+		match := progressRegexp.FindStringSubmatch(lines[i])
+		if match == nil {
+			continue
+		}
+		percents, err := strconv.ParseFloat(match[1], 64)
+		if err != nil || percents > drivers.TransferProgressCompleted {
+			continue
+		}
+		return percents, true
+	}
+	return 0, false
+}
+
+// jobProgress returns the progress of a running rsync job from the logs of its
+// pod, 0 if unknown.
+func jobProgress(job *batchv1.Job) float64 {
+	pods, err := core.Instance().GetPods(job.Namespace, map[string]string{"job-name": job.Name})
+	if err != nil {
+		logrus.Debugf("failed to get the pods of job %s/%s: %v", job.Namespace, job.Name, err)
+		return 0
+	}
+	sinceSeconds := int64(progressLogWindow.Seconds())
+	for _, pod := range pods.Items {
+		if pod.Status.Phase != corev1.PodRunning {
+			continue
+		}
+		out, err := core.Instance().GetPodLog(pod.Name, pod.Namespace, &corev1.PodLogOptions{SinceSeconds: &sinceSeconds})
+		if err != nil {
+			logrus.Debugf("failed to get the log of pod %s/%s: %v", pod.Namespace, pod.Name, err)
+			continue
+		}
+		if percents, ok := parseProgress(out); ok {
+			if percents > maxRunningProgress {
+				percents = maxRunningProgress
+			}
+			return percents
+		}
+	}
+	return 0
+}
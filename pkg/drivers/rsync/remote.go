@@ -0,0 +1,513 @@
+package rsync
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/portworx/kdmp/pkg/drivers"
+	"github.com/portworx/kdmp/pkg/drivers/utils"
+	"github.com/portworx/sched-ops/k8s/batch"
+	"github.com/portworx/sched-ops/k8s/core"
+	"github.com/sirupsen/logrus"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+const (
+	rsyncPort   = 873
+	rsyncModule = "data"
+	rsyncUser   = "kdmp"
+	// passwordKey is the key of the rsync password in the client secret, it
+	// is also the environment variable read by the rsync client.
+	passwordKey = "RSYNC_PASSWORD"
+	configDir   = "/etc/rsyncd"
+
+	daemonLabel = "kdmp.portworx.com/rsync-daemon"
+	// clusterPairAnnotation and destinationNamespaceAnnotation are set on the
+	// client job to find the daemon resources on the remote cluster.
+	clusterPairAnnotation          = "kdmp.portworx.com/rsync-clusterpair"
+	destinationNamespaceAnnotation = "kdmp.portworx.com/rsync-destination-namespace"
+
+	// defaultRemoteFlags keep the partially transferred files so that a
+	// restarted job resumes them, and mirror the deleted files so that the
+	// last sync of a migration leaves an exact copy of the source.
+	defaultRemoteFlags = "-az --partial --delete"
+
+	// daemonAddressTimeout is the time the daemon has to get an address
+	// reachable from the source cluster, before its resources are removed
+	// and the job start fails.
+	daemonAddressTimeout = 5 * time.Minute
+)
+
+// remoteCluster holds the clients of the remote cluster of a ClusterPair.
+type remoteCluster struct {
+	core  *core.Client
+	batch *batch.Client
+}
+
+func getRemoteCluster(clusterPair, namespace string) (*remoteCluster, error) {
+	coreClient, err := utils.GetClusterPairClient(clusterPair, namespace)
+	if err != nil {
+		return nil, err
+	}
+	batchClient, err := utils.GetClusterPairBatchClient(clusterPair, namespace)
+	if err != nil {
+		return nil, err
+	}
+	return &remoteCluster{core: coreClient, batch: batchClient}, nil
+}
+
+// startRemoteJob starts a cross-cluster data transfer. A rsync daemon job using
+// the destination PVC is exposed by a service on the remote cluster of the
+// ClusterPair, and a client job pushes the source PVC to it.
+//
+// rsync only transfers the changed files, so the transfer can be repeated
+// while the application is running, and a last short sync done once it is
+// stopped.
+//
+// utils.ErrJobNotReady is returned while the daemon has no address yet, the
+// caller starts the job again later. The daemon resources are removed if the
+// job can't be started.
+func startRemoteJob(o drivers.JobOpts) (string, error) {
+	if o.DestinationPVCNamespace == "" {
+		return "", fmt.Errorf("destination pvc namespace should be set")
+	}
+	remote, err := getRemoteCluster(o.ClusterPair, o.ClusterPairNamespace)
+	if err != nil {
+		return "", err
+	}
+
+	labels := addJobLabels(o.Labels)
+	jobName := toJobName(o.SourcePVCName, o.DataExportUID)
+	jobID, err := createRemoteJob(remote, o, jobName, labels)
+	if err != nil && err != utils.ErrJobNotReady {
+		if cleanupErr := deleteRemoteResources(remote, jobName, o.DestinationPVCNamespace, o.Namespace); cleanupErr != nil {
+			logrus.Warnf("failed to clean up the resources of rsync job %s/%s: %v", o.Namespace, jobName, cleanupErr)
+		}
+	}
+	return jobID, err
+}
+
+func createRemoteJob(remote *remoteCluster, o drivers.JobOpts, jobName string, labels map[string]string) (string, error) {
+	password, err := setupClientSecret(jobName, o.Namespace, labels)
+	if err != nil {
+		return "", err
+	}
+	if err := startDaemon(remote, jobName, o.DestinationPVCNamespace, o.DestinationPVCName, password, labels); err != nil {
+		return "", err
+	}
+	addr, err := getDaemonAddress(remote, jobName, o.DestinationPVCNamespace, time.Now())
+	if err != nil {
+		return "", err
+	}
+
+	rsyncJob, err := remoteJobFor(o, jobName, addr, labels)
+	if err != nil {
+		return "", err
+	}
+	if _, err = batch.Instance().CreateJob(rsyncJob); err != nil && !errors.IsAlreadyExists(err) {
+		return "", err
+	}
+
+	return utils.NamespacedName(rsyncJob.Namespace, rsyncJob.Name), nil
+}
+
+// setupClientSecret creates the secret holding the rsync password, or returns
+// the password of the existing one.
+func setupClientSecret(name, namespace string, labels map[string]string) (string, error) {
+	secret, err := core.Instance().GetSecret(name, namespace)
+	if err == nil {
+		if len(secret.Data[passwordKey]) == 0 {
+			return "", fmt.Errorf("secret %s/%s has no rsync password", namespace, name)
+		}
+		return string(secret.Data[passwordKey]), nil
+	}
+	if !errors.IsNotFound(err) {
+		return "", fmt.Errorf("failed to get secret %s/%s: %v", namespace, name, err)
+	}
+
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate the rsync password: %v", err)
+	}
+	password := hex.EncodeToString(buf)
+	secret = &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    labels,
+		},
+		Data: map[string][]byte{
+			passwordKey: []byte(password),
+		},
+	}
+	if _, err := core.Instance().CreateSecret(secret); err != nil && !errors.IsAlreadyExists(err) {
+		return "", fmt.Errorf("failed to create secret %s/%s: %v", namespace, name, err)
+	}
+	return password, nil
+}
+
+// startDaemon starts the rsync daemon job and the service exposing it. The
+// daemon only accepts the clients authenticated with the password of its
+// secrets file, as the service may be reachable from outside of the cluster.
+// The data is not encrypted.
+func startDaemon(remote *remoteCluster, name, namespace, pvcName, password string, labels map[string]string) error {
+	if password == "" {
+		return fmt.Errorf("rsync daemon %s/%s needs a password", namespace, name)
+	}
+	serviceType := utils.RsyncServiceType()
+	switch serviceType {
+	case corev1.ServiceTypeClusterIP, corev1.ServiceTypeNodePort, corev1.ServiceTypeLoadBalancer:
+	default:
+		return fmt.Errorf("unsupported rsync service type %q", serviceType)
+	}
+	resources, err := utils.RsyncResourceRequirements()
+	if err != nil {
+		return err
+	}
+
+	config := fmt.Sprintf(`port = %d
+use chroot = no
+uid = 0
+gid = 0
+[%s]
+    path = /dst
+    read only = false
+    auth users = %s
+    secrets file = %s/rsyncd.secrets
+    strict modes = true
+`, rsyncPort, rsyncModule, rsyncUser, configDir)
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    labels,
+		},
+		Data: map[string][]byte{
+			"rsyncd.conf":    []byte(config),
+			"rsyncd.secrets": []byte(fmt.Sprintf("%s:%s\n", rsyncUser, password)),
+		},
+	}
+	if _, err := remote.core.CreateSecret(secret); err != nil && !errors.IsAlreadyExists(err) {
+		return fmt.Errorf("failed to create remote secret %s/%s: %v", namespace, name, err)
+	}
+
+	daemon := daemonJobFor(name, namespace, pvcName, labels, resources)
+	if _, err := remote.batch.CreateJob(daemon); err != nil && !errors.IsAlreadyExists(err) {
+		return fmt.Errorf("failed to create remote rsync daemon %s/%s: %v", namespace, name, err)
+	}
+
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    labels,
+		},
+		Spec: corev1.ServiceSpec{
+			Type:     serviceType,
+			Selector: map[string]string{daemonLabel: name},
+			Ports: []corev1.ServicePort{
+				{
+					Name:       "rsync",
+					Port:       rsyncPort,
+					TargetPort: intstr.FromInt(rsyncPort),
+				},
+			},
+		},
+	}
+	if _, err := remote.core.CreateService(service); err != nil && !errors.IsAlreadyExists(err) {
+		return fmt.Errorf("failed to create remote service %s/%s: %v", namespace, name, err)
+	}
+	return nil
+}
+
+// daemonJobFor returns the job running the rsync daemon, so that the daemon is
+// restarted on another node if its pod is lost. The daemon runs as root only
+// to keep the owners and the modes of the transferred files, all the other
+// capabilities are dropped.
+func daemonJobFor(name, namespace, pvcName string, labels map[string]string, resources corev1.ResourceRequirements) *batchv1.Job {
+	daemonLabels := make(map[string]string, len(labels)+1)
+	for k, v := range labels {
+		daemonLabels[k] = v
+	}
+	daemonLabels[daemonLabel] = name
+
+	// rsync refuses a secrets file readable by others
+	secretMode := int32(0400)
+	allowPrivilegeEscalation := false
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    daemonLabels,
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: &utils.JobPodBackOffLimit,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: daemonLabels,
+				},
+				Spec: corev1.PodSpec{
+					RestartPolicy:    corev1.RestartPolicyOnFailure,
+					ImagePullSecrets: utils.ToImagePullSecret(utils.RsyncImageSecret()),
+					Containers: []corev1.Container{
+						{
+							Name:  "rsync",
+							Image: utils.RsyncImage(),
+							Command: []string{
+								"rsync", "--daemon", "--no-detach", "--log-file=/dev/stdout",
+								"--config=" + configDir + "/rsyncd.conf",
+							},
+							Resources: resources,
+							SecurityContext: &corev1.SecurityContext{
+								AllowPrivilegeEscalation: &allowPrivilegeEscalation,
+								Capabilities: &corev1.Capabilities{
+									Drop: []corev1.Capability{"ALL"},
+									Add:  []corev1.Capability{"CHOWN", "DAC_OVERRIDE", "FOWNER", "FSETID", "SETGID", "SETUID"},
+								},
+							},
+							Ports: []corev1.ContainerPort{
+								{
+									Name:          "rsync",
+									ContainerPort: rsyncPort,
+								},
+							},
+							ReadinessProbe: &corev1.Probe{
+								ProbeHandler: corev1.ProbeHandler{
+									TCPSocket: &corev1.TCPSocketAction{
+										Port: intstr.FromInt(rsyncPort),
+									},
+								},
+							},
+							VolumeMounts: []corev1.VolumeMount{
+								{
+									Name:      "dst-vol",
+									MountPath: "/dst",
+								},
+								{
+									Name:      "config",
+									MountPath: configDir,
+									ReadOnly:  true,
+								},
+							},
+						},
+					},
+					Volumes: []corev1.Volume{
+						{
+							Name: "dst-vol",
+							VolumeSource: corev1.VolumeSource{
+								PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+									ClaimName: pvcName,
+								},
+							},
+						},
+						{
+							Name: "config",
+							VolumeSource: corev1.VolumeSource{
+								Secret: &corev1.SecretVolumeSource{
+									SecretName:  name,
+									DefaultMode: &secretMode,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// getDaemonAddress returns the address of the rsync daemon reachable from the
+// source cluster, depending on the service type. utils.ErrJobNotReady is
+// returned until the address is known, for at most daemonAddressTimeout.
+func getDaemonAddress(remote *remoteCluster, name, namespace string, now time.Time) (string, error) {
+	service, err := remote.core.GetService(name, namespace)
+	if err != nil {
+		return "", fmt.Errorf("failed to get remote service %s/%s: %v", namespace, name, err)
+	}
+	if len(service.Spec.Ports) == 0 {
+		return "", fmt.Errorf("remote service %s/%s has no port", namespace, name)
+	}
+	var host string
+	port := service.Spec.Ports[0].Port
+	switch service.Spec.Type {
+	case corev1.ServiceTypeLoadBalancer:
+		for _, ingress := range service.Status.LoadBalancer.Ingress {
+			if host = ingress.IP; host == "" {
+				host = ingress.Hostname
+			}
+			if host != "" {
+				break
+			}
+		}
+	case corev1.ServiceTypeNodePort:
+		pods, err := remote.core.GetPods(namespace, map[string]string{daemonLabel: name})
+		if err != nil {
+			return "", fmt.Errorf("failed to get the pods of remote rsync daemon %s/%s: %v", namespace, name, err)
+		}
+		for _, pod := range pods.Items {
+			if pod.Status.Phase == corev1.PodRunning && pod.Status.HostIP != "" {
+				host = pod.Status.HostIP
+				break
+			}
+		}
+		port = service.Spec.Ports[0].NodePort
+	default:
+		host = service.Spec.ClusterIP
+	}
+	if host != "" {
+		return net.JoinHostPort(host, strconv.Itoa(int(port))), nil
+	}
+	if now.Sub(service.CreationTimestamp.Time) > daemonAddressTimeout {
+		return "", fmt.Errorf("remote service %s/%s got no address in %v", namespace, name, daemonAddressTimeout)
+	}
+	logrus.Infof("waiting for the address of remote service %s/%s", namespace, name)
+	return "", utils.ErrJobNotReady
+}
+
+func remoteJobFor(o drivers.JobOpts, jobName, addr string, labels map[string]string) (*batchv1.Job, error) {
+	rsyncFlags := defaultRemoteFlags
+	if custom := utils.RsyncCommandFlags(); custom != "" {
+		rsyncFlags = custom
+	}
+	cmd := fmt.Sprintf("rsync %s %s /src/ rsync://%s@%s/%s", rsyncFlags, progressFlags, rsyncUser, addr, rsyncModule)
+
+	resources, err := utils.RsyncResourceRequirements()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := utils.SetupServiceAccount(jobName, o.Namespace, roleFor(utils.RsyncOpenshiftSCC())); err != nil {
+		return nil, err
+	}
+
+	nodeName, err := liveNodeName(o.SourcePVCName, o.Namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      jobName,
+			Namespace: o.Namespace,
+			Labels:    labels,
+			Annotations: map[string]string{
+				clusterPairAnnotation:          utils.NamespacedName(o.ClusterPairNamespace, o.ClusterPair),
+				destinationNamespaceAnnotation: o.DestinationPVCNamespace,
+			},
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: &utils.JobPodBackOffLimit,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: labels,
+				},
+				Spec: corev1.PodSpec{
+					RestartPolicy:      corev1.RestartPolicyOnFailure,
+					ImagePullSecrets:   utils.ToImagePullSecret(utils.RsyncImageSecret()),
+					ServiceAccountName: jobName,
+					NodeName:           nodeName,
+					Containers: []corev1.Container{
+						{
+							Name:      "rsync",
+							Image:     utils.RsyncImage(),
+							Command:   []string{"/bin/sh", "-x", "-c", cmd},
+							Resources: resources,
+							Env: []corev1.EnvVar{
+								{
+									Name: passwordKey,
+									ValueFrom: &corev1.EnvVarSource{
+										SecretKeyRef: &corev1.SecretKeySelector{
+											LocalObjectReference: corev1.LocalObjectReference{Name: jobName},
+											Key:                  passwordKey,
+										},
+									},
+								},
+							},
+							VolumeMounts: []corev1.VolumeMount{
+								{
+									Name:      "src-vol",
+									MountPath: "/src",
+									ReadOnly:  true,
+								},
+							},
+						},
+					},
+					Volumes: []corev1.Volume{
+						{
+							Name: "src-vol",
+							VolumeSource: corev1.VolumeSource{
+								PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+									ClaimName: o.SourcePVCName,
+									ReadOnly:  true,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}, nil
+}
+
+// liveNodeName returns the node of the running application pod using the
+// PVC, so that the job can mount it while the application is running.
+func liveNodeName(pvcName, namespace string) (string, error) {
+	pods, err := core.Instance().GetPodsUsingPVC(pvcName, namespace)
+	if err != nil {
+		return "", fmt.Errorf("failed to get the pods using pvc %s/%s: %v", namespace, pvcName, err)
+	}
+	for _, pod := range pods {
+		if _, ok := pod.Labels[drivers.DriverNameLabel]; ok {
+			continue
+		}
+		if pod.Status.Phase == corev1.PodRunning {
+			return pod.Spec.NodeName, nil
+		}
+	}
+	return "", nil
+}
+
+// cleanupRemote removes the rsync daemon of a cross-cluster job and the
+// client secret.
+func cleanupRemote(job *batchv1.Job) error {
+	clusterPair, ok := job.Annotations[clusterPairAnnotation]
+	if !ok {
+		return nil
+	}
+	cpNamespace, cpName, err := utils.ParseJobID(clusterPair)
+	if err != nil {
+		return fmt.Errorf("invalid clusterpair %q of job %s/%s: %v", clusterPair, job.Namespace, job.Name, err)
+	}
+	remote, err := getRemoteCluster(cpName, cpNamespace)
+	if err != nil {
+		return err
+	}
+
+	return deleteRemoteResources(remote, job.Name, job.Annotations[destinationNamespaceAnnotation], job.Namespace)
+}
+
+// deleteRemoteResources removes the rsync daemon job, its service and secret on
+// the remote cluster, and the client secret.
+func deleteRemoteResources(remote *remoteCluster, name, remoteNamespace, namespace string) error {
+	if err := remote.core.DeleteService(name, remoteNamespace); err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete remote service %s/%s: %v", remoteNamespace, name, err)
+	}
+	if err := remote.batch.DeleteJob(name, remoteNamespace); err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete remote rsync daemon %s/%s: %v", remoteNamespace, name, err)
+	}
+	if err := remote.core.DeleteSecret(name, remoteNamespace); err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete remote secret %s/%s: %v", remoteNamespace, name, err)
+	}
+	if err := core.Instance().DeleteSecret(name, namespace); err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete secret %s/%s: %v", namespace, name, err)
+	}
+	return nil
+}
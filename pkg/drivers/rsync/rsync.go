@@ -37,6 +37,9 @@ func (d Driver) StartJob(opts ...drivers.JobOption) (id string, err error) {
 	if err := d.validate(o); err != nil {
 		return "", err
 	}
+	if o.ClusterPair != "" {
+		return startRemoteJob(o)
+	}
 
 	rsyncJob, err := jobFor(o.SourcePVCName, o.DestinationPVCName, o.DataExportUID, o.Namespace, o.Labels)
 	if err != nil {
@@ -56,6 +59,16 @@ func (d Driver) DeleteJob(id string) error {
 		return err
 	}
 
+	job, err := batch.Instance().GetJob(name, namespace)
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	if err == nil {
+		if err = cleanupRemote(job); err != nil {
+			return err
+		}
+	}
+
 	if err = batch.Instance().DeleteJob(name, namespace); err != nil && !errors.IsNotFound(err) {
 		return err
 	}
@@ -99,8 +112,7 @@ func (d Driver) JobStatus(id string) (*drivers.JobStatus, error) {
 	}
 
 	if !utils.IsJobCompleted(job) {
-		// TODO: update progress
-		return utils.ToJobStatus(0, "", jobStatus), nil
+		return utils.ToJobStatus(jobProgress(job), "", jobStatus), nil
 	}
 
 	return utils.ToJobStatus(drivers.TransferProgressCompleted, "", batchv1.JobComplete), nil
@@ -120,7 +132,7 @@ func jobFor(srcVol, dstVol, dataexportUID, namespace string, labels map[string]s
 	if custom := utils.RsyncCommandFlags(); custom != "" {
 		rsyncFlags = custom
 	}
-	cmd := fmt.Sprintf("ls -la /src; ls -la /dst/; rsync %s /src/ /dst", rsyncFlags)
+	cmd := fmt.Sprintf("ls -la /src; ls -la /dst/; rsync %s %s /src/ /dst", rsyncFlags, progressFlags)
 
 	resources, err := utils.RsyncResourceRequirements()
 	if err != nil {
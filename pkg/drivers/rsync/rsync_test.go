@@ -4,8 +4,10 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/portworx/kdmp/pkg/drivers"
 	"github.com/portworx/kdmp/pkg/drivers/utils"
 	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
 )
 
 func TestToJobName(t *testing.T) {
@@ -43,3 +45,73 @@ func TestToJobName(t *testing.T) {
 
 	require.Equal(t, expectedJobName, actualJobName, "unexpected job name")
 }
+
+func TestParseProgress(t *testing.T) {
+	testCases := []struct {
+		name     string
+		out      string
+		progress float64
+		found    bool
+	}{
+		{
+			name: "progress2",
+			out: "sending incremental file list\n" +
+				"          0   0%    0.00kB/s    0:00:00 (xfr#0, to-chk=15/15)\r" +
+				"  1,238,099  12%   10.29MB/s    0:00:01 (xfr#4, to-chk=10/15)\r" +
+				" 10,318,099  57%   11.02MB/s    0:00:05 (xfr#9, to-chk=5/15)",
+			progress: 57,
+			found:    true,
+		},
+		{
+			name:     "human_readable",
+			out:      "          1.23G  45%  100.00MB/s    0:00:12 (xfr#3, to-chk=5/10)\n",
+			progress: 45,
+			found:    true,
+		},
+		{
+			name: "verbose",
+			out: "  1,238,099  12%   10.29MB/s    0:00:01 (xfr#4, to-chk=10/15)\n" +
+				"dir/file1\n" +
+				"dir/file2\n",
+			progress: 12,
+			found:    true,
+		},
+		{
+			name: "no_progress",
+			out:  "sending incremental file list\ndir/file1\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		progress, found := parseProgress(tc.out)
+		require.Equalf(t, tc.found, found, "TC: %s", tc.name)
+		require.Equalf(t, tc.progress, progress, "TC: %s", tc.name)
+	}
+}
+
+func TestDaemonJobFor(t *testing.T) {
+	labels := map[string]string{drivers.DriverNameLabel: drivers.Rsync}
+	job := daemonJobFor("import-rsync-data", "dst", "data", labels, corev1.ResourceRequirements{})
+
+	// The service selects the pods of the daemon job
+	require.Equal(t, "import-rsync-data", job.Spec.Template.Labels[daemonLabel])
+	require.Equal(t, drivers.Rsync, job.Spec.Template.Labels[drivers.DriverNameLabel])
+	require.NotContains(t, labels, daemonLabel)
+
+	podSpec := job.Spec.Template.Spec
+	require.Equal(t, corev1.RestartPolicyOnFailure, podSpec.RestartPolicy)
+	require.Equal(t, "data", podSpec.Volumes[0].PersistentVolumeClaim.ClaimName)
+	securityContext := podSpec.Containers[0].SecurityContext
+	require.NotNil(t, securityContext)
+	require.False(t, *securityContext.AllowPrivilegeEscalation)
+	require.Equal(t, []corev1.Capability{"ALL"}, securityContext.Capabilities.Drop)
+	require.NotContains(t, securityContext.Capabilities.Add, corev1.Capability("SYS_ADMIN"))
+}
+
+func TestRsyncServiceType(t *testing.T) {
+	// The daemon has to be reachable from the source cluster by default
+	require.Equal(t, corev1.ServiceTypeLoadBalancer, utils.RsyncServiceType())
+
+	t.Setenv(drivers.RsyncServiceTypeKey, "NodePort")
+	require.Equal(t, corev1.ServiceTypeNodePort, utils.RsyncServiceType())
+}
@@ -51,6 +51,8 @@ const (
 	// BurstKey - configmap burst key name
 	BurstKey                             = "K8S_BURST"
 	k8sMinVersionSASecretTokenNotSupport = "1.24"
+	// KubeletPodsPath is the directory of the pods on the nodes
+	KubeletPodsPath = "/var/lib/kubelet/pods"
 )
 
 var (
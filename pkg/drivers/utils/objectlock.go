@@ -0,0 +1,117 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	storkapi "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+)
+
+const (
+	// ObjectLockModeAnnotation is the BackupLocation annotation with the
+	// object-lock retention mode of the kopia repositories created on it,
+	// GOVERNANCE or COMPLIANCE. None disables the kdmp config default.
+	ObjectLockModeAnnotation = "kdmp.portworx.com/object-lock-mode"
+	// ObjectLockPeriodAnnotation is the BackupLocation annotation with the
+	// object-lock retention period, like 720h.
+	ObjectLockPeriodAnnotation = "kdmp.portworx.com/object-lock-period"
+	// ObjectLockModeKey is the kdmp config key with the default object-lock
+	// retention mode of the S3 BackupLocations.
+	ObjectLockModeKey = "KDMP_OBJECT_LOCK_MODE"
+	// ObjectLockPeriodKey is the kdmp config key with the default object-lock
+	// retention period of the S3 BackupLocations.
+	ObjectLockPeriodKey = "KDMP_OBJECT_LOCK_PERIOD"
+
+	// ObjectLockModeCredentialsKey is the key of the retention mode in the job
+	// credential secret.
+	ObjectLockModeCredentialsKey = "objectlockmode"
+	// ObjectLockPeriodCredentialsKey is the key of the retention period in the
+	// job credential secret.
+	ObjectLockPeriodCredentialsKey = "objectlockperiod"
+
+	// ObjectLockModeGovernance locks can be removed by users with the
+	// s3:BypassGovernanceRetention permission.
+	ObjectLockModeGovernance = "GOVERNANCE"
+	// ObjectLockModeCompliance locks can not be removed by any user.
+	ObjectLockModeCompliance = "COMPLIANCE"
+	objectLockModeNone       = "NONE"
+
+	// kopia extends the locks at each full maintenance, which requires the
+	// period to exceed the daily full maintenance interval by a day
+	minObjectLockPeriod = 48 * time.Hour
+)
+
+// ObjectLock is the retention of the objects of a kopia repository.
+type ObjectLock struct {
+	Mode   string
+	Period time.Duration
+}
+
+// Expiry returns the time the object-lock retention of a snapshot which ended
+// at endTime expires at. kopia locks the blobs for the period from their write,
+// the last ones being written at the end of the snapshot. The locks extended
+// by maintenance for the blobs shared with newer snapshots don't change it.
+func (l *ObjectLock) Expiry(endTime time.Time) time.Time {
+	return endTime.Add(l.Period)
+}
+
+// ParseObjectLock validates an object-lock retention mode and period. It
+// returns nil if the mode is empty or None.
+func ParseObjectLock(mode, period string) (*ObjectLock, error) {
+	mode = strings.ToUpper(strings.TrimSpace(mode))
+	switch mode {
+	case "", objectLockModeNone:
+		return nil, nil
+	case ObjectLockModeGovernance, ObjectLockModeCompliance:
+	default:
+		return nil, fmt.Errorf("invalid object-lock mode %q, it should be %s or %s", mode, ObjectLockModeGovernance, ObjectLockModeCompliance)
+	}
+	period = strings.TrimSpace(period)
+	if period == "" {
+		return nil, fmt.Errorf("object-lock period should be set with the %s mode", mode)
+	}
+	d, err := time.ParseDuration(period)
+	if err != nil {
+		return nil, fmt.Errorf("invalid object-lock period %q: %v", period, err)
+	}
+	if d < minObjectLockPeriod {
+		return nil, fmt.Errorf("object-lock period %q should be at least %v", period, minObjectLockPeriod)
+	}
+	return &ObjectLock{Mode: mode, Period: d}, nil
+}
+
+// ObjectLockFromBackupLocation returns the object-lock retention of the
+// kopia repositories of the BackupLocation, nil if they are not locked. The
+// BackupLocation annotations have precedence over the kdmp config. Only the
+// S3 BackupLocations support it.
+func ObjectLockFromBackupLocation(bl *storkapi.BackupLocation) (*ObjectLock, error) {
+	if bl.Location.Type != storkapi.BackupLocationS3 {
+		return nil, nil
+	}
+	mode, ok := bl.Annotations[ObjectLockModeAnnotation]
+	period := bl.Annotations[ObjectLockPeriodAnnotation]
+	if !ok {
+		mode = GetConfigValue(KdmpConfigmapName, KdmpConfigmapNamespace, ObjectLockModeKey)
+		if mode != "" && period == "" {
+			period = GetConfigValue(KdmpConfigmapName, KdmpConfigmapNamespace, ObjectLockPeriodKey)
+		}
+	}
+	lock, err := ParseObjectLock(mode, period)
+	if err != nil {
+		return nil, fmt.Errorf("invalid object-lock config of backuplocation %s/%s: %v", bl.Namespace, bl.Name, err)
+	}
+	return lock, nil
+}
+
+// AddObjectLockCredentials adds the object-lock retention of the
+// BackupLocation, if any, to the data of a job credential secret.
+func AddObjectLockCredentials(credentialData map[string][]byte, bl *storkapi.BackupLocation) error {
+	lock, err := ObjectLockFromBackupLocation(bl)
+	if err != nil || lock == nil {
+		return err
+	}
+	credentialData[ObjectLockModeCredentialsKey] = []byte(lock.Mode)
+	credentialData[ObjectLockPeriodCredentialsKey] = []byte(lock.Period.String())
+	return nil
+}
@@ -0,0 +1,58 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	storkapi "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestParseObjectLock(t *testing.T) {
+	lock, err := ParseObjectLock("compliance", "720h")
+	require.NoError(t, err)
+	require.Equal(t, &ObjectLock{Mode: ObjectLockModeCompliance, Period: 720 * time.Hour}, lock)
+
+	for _, mode := range []string{"", "None"} {
+		lock, err = ParseObjectLock(mode, "720h")
+		require.NoError(t, err)
+		require.Nil(t, lock)
+	}
+
+	for _, invalid := range [][2]string{
+		{"legal-hold", "720h"},
+		{ObjectLockModeGovernance, ""},
+		{ObjectLockModeGovernance, "30d"},
+		{ObjectLockModeGovernance, "24h"},
+	} {
+		_, err = ParseObjectLock(invalid[0], invalid[1])
+		require.Errorf(t, err, "mode %q period %q", invalid[0], invalid[1])
+	}
+}
+
+func TestAddObjectLockCredentials(t *testing.T) {
+	bl := &storkapi.BackupLocation{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "s3",
+			Namespace: "backup",
+			Annotations: map[string]string{
+				ObjectLockModeAnnotation:   "GOVERNANCE",
+				ObjectLockPeriodAnnotation: "168h",
+			},
+		},
+		Location: storkapi.BackupLocationItem{Type: storkapi.BackupLocationS3},
+	}
+	data := make(map[string][]byte)
+	require.NoError(t, AddObjectLockCredentials(data, bl))
+	require.Equal(t, "GOVERNANCE", string(data[ObjectLockModeCredentialsKey]))
+	lock, err := ParseObjectLock(string(data[ObjectLockModeCredentialsKey]), string(data[ObjectLockPeriodCredentialsKey]))
+	require.NoError(t, err)
+	require.Equal(t, 168*time.Hour, lock.Period)
+
+	// only S3 supports object-lock
+	bl.Location.Type = storkapi.BackupLocationNFS
+	data = make(map[string][]byte)
+	require.NoError(t, AddObjectLockCredentials(data, bl))
+	require.Empty(t, data)
+}
@@ -0,0 +1,164 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/portworx/kdmp/pkg/drivers"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// The speed limits are evaluated once, when the job is started, and the job
+// keeps its limit until it completes. A job started before a time window keeps
+// the limit it started with during the window, and a job started within a
+// window keeps the limit of the window after it ends.
+const (
+	// MaxUploadSpeedKey is the kdmp config key holding the upload speed limits
+	// of the backup jobs, applied at job start.
+	MaxUploadSpeedKey = "KDMP_MAX_UPLOAD_SPEED"
+	// MaxDownloadSpeedKey is the kdmp config key holding the download speed
+	// limits of the restore jobs, applied at job start.
+	MaxDownloadSpeedKey = "KDMP_MAX_DOWNLOAD_SPEED"
+)
+
+// speedLimitRule is a line of a speed limit policy:
+//
+//	[<backuplocation-namespace>/<backuplocation-name>] [<HH:MM>-<HH:MM>] <bytes per second>
+//
+// The speed is a resource quantity, like 50Mi. A speed of 0 is unlimited.
+type speedLimitRule struct {
+	backupLocation string
+	window         *timeWindow
+	limit          int64
+}
+
+// timeWindow is a time of day range in minutes, the end is excluded. A window
+// with the end before the start spans midnight.
+type timeWindow struct {
+	start int
+	end   int
+}
+
+func (w *timeWindow) contains(t time.Time) bool {
+	minute := t.Hour()*60 + t.Minute()
+	if w.start <= w.end {
+		return minute >= w.start && minute < w.end
+	}
+	return minute >= w.start || minute < w.end
+}
+
+// GetSpeedLimit returns the speed limit in bytes per second of a job using the
+// given BackupLocation at the given time, 0 if unlimited. The policies are
+// checked in order and the first one with a matching rule applies. Within a
+// policy the BackupLocation rules have precedence over the global ones and the
+// time window rules over the ones without window. The time is the start time
+// of the job, the limit is not changed while the job runs.
+func GetSpeedLimit(now time.Time, blNamespace, blName string, policies ...string) (int64, error) {
+	backupLocation := blNamespace + "/" + blName
+	now = now.UTC()
+	for _, policy := range policies {
+		rules, err := parseSpeedLimitPolicy(policy)
+		if err != nil {
+			return 0, err
+		}
+		var match *speedLimitRule
+		matchScore := -1
+		for i, rule := range rules {
+			score := 0
+			if rule.backupLocation != "" {
+				if rule.backupLocation != backupLocation {
+					continue
+				}
+				score += 2
+			}
+			if rule.window != nil {
+				if !rule.window.contains(now) {
+					continue
+				}
+				score++
+			}
+			// The last of the equally specific rules applies.
+			if score >= matchScore {
+				match = &rules[i]
+				matchScore = score
+			}
+		}
+		if match != nil {
+			return match.limit, nil
+		}
+	}
+	return 0, nil
+}
+
+func parseSpeedLimitPolicy(policy string) ([]speedLimitRule, error) {
+	var rules []speedLimitRule
+	for _, line := range strings.Split(policy, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		var rule speedLimitRule
+		for _, field := range fields[:len(fields)-1] {
+			switch {
+			case strings.Contains(field, "/") && rule.backupLocation == "":
+				rule.backupLocation = field
+			case strings.Contains(field, ":") && rule.window == nil:
+				window, err := parseTimeWindow(field)
+				if err != nil {
+					return nil, fmt.Errorf("invalid speed limit %q: %v", line, err)
+				}
+				rule.window = window
+			default:
+				return nil, fmt.Errorf("invalid speed limit %q: unexpected %q", line, field)
+			}
+		}
+		limit, err := resource.ParseQuantity(fields[len(fields)-1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid speed limit %q: %v", line, err)
+		}
+		if limit.Sign() < 0 {
+			return nil, fmt.Errorf("invalid speed limit %q: negative speed", line)
+		}
+		rule.limit = limit.Value()
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+func parseTimeWindow(val string) (*timeWindow, error) {
+	bounds := strings.Split(val, "-")
+	if len(bounds) != 2 {
+		return nil, fmt.Errorf("time window %q is not in the HH:MM-HH:MM format", val)
+	}
+	start, err := parseTimeOfDay(bounds[0])
+	if err != nil {
+		return nil, err
+	}
+	end, err := parseTimeOfDay(bounds[1])
+	if err != nil {
+		return nil, err
+	}
+	return &timeWindow{start: start, end: end}, nil
+}
+
+func parseTimeOfDay(val string) (int, error) {
+	t, err := time.Parse("15:04", val)
+	if err != nil {
+		return 0, fmt.Errorf("time %q is not in the HH:MM format", val)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// SpeedLimitArgs returns the executor flags limiting the speed of a job.
+func SpeedLimitArgs(o drivers.JobOpts) []string {
+	var args []string
+	if o.MaxUploadSpeed > 0 {
+		args = append(args, "--max-upload-speed", strconv.FormatInt(o.MaxUploadSpeed, 10))
+	}
+	if o.MaxDownloadSpeed > 0 {
+		args = append(args, "--max-download-speed", strconv.FormatInt(o.MaxDownloadSpeed, 10))
+	}
+	return args
+}
@@ -0,0 +1,105 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/portworx/kdmp/pkg/drivers"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetSpeedLimit(t *testing.T) {
+	policy := `100Mi
+22:00-06:00 0
+backup/s3 20Mi
+backup/s3 12:00-13:00 10Mi`
+
+	day := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	night := time.Date(2024, 1, 1, 23, 30, 0, 0, time.UTC)
+	noon := time.Date(2024, 1, 1, 12, 30, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name     string
+		now      time.Time
+		blName   string
+		policies []string
+		expected int64
+	}{
+		{
+			name:     "default",
+			now:      day,
+			blName:   "nfs",
+			policies: []string{policy},
+			expected: 100 * 1024 * 1024,
+		},
+		{
+			name:     "night_unlimited",
+			now:      night,
+			blName:   "nfs",
+			policies: []string{policy},
+			expected: 0,
+		},
+		{
+			name:     "backup_location",
+			now:      night,
+			blName:   "s3",
+			policies: []string{policy},
+			expected: 20 * 1024 * 1024,
+		},
+		{
+			name:     "backup_location_window",
+			now:      noon,
+			blName:   "s3",
+			policies: []string{policy},
+			expected: 10 * 1024 * 1024,
+		},
+		{
+			name:     "override",
+			now:      day,
+			blName:   "s3",
+			policies: []string{"1M", policy},
+			expected: 1000 * 1000,
+		},
+		{
+			name:     "override_not_matching",
+			now:      day,
+			blName:   "s3",
+			policies: []string{"22:00-06:00 1M", policy},
+			expected: 20 * 1024 * 1024,
+		},
+		{
+			name:     "no_policy",
+			now:      day,
+			blName:   "s3",
+			policies: []string{"", ""},
+			expected: 0,
+		},
+	}
+
+	for _, tc := range testCases {
+		limit, err := GetSpeedLimit(tc.now, "backup", tc.blName, tc.policies...)
+		require.NoErrorf(t, err, "TC: %s", tc.name)
+		require.Equalf(t, tc.expected, limit, "TC: %s", tc.name)
+	}
+}
+
+func TestGetSpeedLimitInvalid(t *testing.T) {
+	for _, policy := range []string{
+		"fast",
+		"-10Mi",
+		"22:00 10Mi",
+		"25:00-06:00 10Mi",
+		"backup/s3 backup/nfs 10Mi",
+		"10Mi 20Mi",
+	} {
+		_, err := GetSpeedLimit(time.Now(), "backup", "s3", policy)
+		require.Errorf(t, err, "policy: %q", policy)
+	}
+}
+
+func TestSpeedLimitArgs(t *testing.T) {
+	require.Empty(t, SpeedLimitArgs(drivers.JobOpts{}))
+	require.Equal(t,
+		[]string{"--max-upload-speed", "1024", "--max-download-speed", "2048"},
+		SpeedLimitArgs(drivers.JobOpts{MaxUploadSpeed: 1024, MaxDownloadSpeed: 2048}))
+}
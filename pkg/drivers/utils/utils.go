@@ -16,6 +16,7 @@ import (
 	"github.com/portworx/sched-ops/k8s/apps"
 	"github.com/portworx/sched-ops/k8s/batch"
 	"github.com/portworx/sched-ops/k8s/core"
+	storkops "github.com/portworx/sched-ops/k8s/stork"
 	"github.com/sirupsen/logrus"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -25,6 +26,8 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/utils/ptr"
 )
 
@@ -47,6 +50,9 @@ const (
 	imageSecretPrefix = "image-secret"
 	// CredSecret - credential secret prefix
 	CredSecret = "cred-secret"
+	// ReplicaCredSecret - prefix of the credential secret of the backuplocation
+	// the snapshots are replicated to
+	ReplicaCredSecret = "replica-cred-secret"
 	// ImageSecret - image secret prefix
 	ImageSecret = "image-secret"
 	// CertSecret - cert secret prefix
@@ -86,6 +92,8 @@ var (
 	ErrOutOfJobResources = errors.New("out of job resources")
 	// ErrJobAlreadyRunning - Already a job is running for the given instance of PVC
 	ErrJobAlreadyRunning = errors.New("job Already Running")
+	// ErrJobNotReady - The resources of the job are not ready yet, the job is started later
+	ErrJobNotReady = errors.New("job resources not ready")
 )
 var volumeAPICallBackoff = wait.Backoff{
 	Duration: volumeinitialDelay,
@@ -227,6 +235,14 @@ func FetchJobContainerRestartCount(j *batchv1.Job) (int32, error) {
 	return (pods.Items[0].Status.ContainerStatuses[0].RestartCount), nil
 }
 
+// ToNodeFailedJobStatus returns the status of a job which failed to mount its
+// volumes or whose node is not ready.
+func ToNodeFailedJobStatus(errMsg string, jobStatus batchv1.JobConditionType) *drivers.JobStatus {
+	status := ToJobStatus(0, errMsg, jobStatus)
+	status.NodeFailed = true
+	return status
+}
+
 // ToJobStatus returns a job status for provided parameters.
 func ToJobStatus(progress float64, errMsg string, jobStatus batchv1.JobConditionType) *drivers.JobStatus {
 	if drivers.IsTransferCompleted(progress) {
@@ -395,6 +411,12 @@ func GetKopiaExecutorImageRegistryAndSecret(source, sourceNs string) (string, st
 
 }
 
+// GetKopiaRepositoryPath returns the path of the kopia repository of a PVC in
+// a backuplocation, as recorded on its VolumeBackups.
+func GetKopiaRepositoryPath(pvcName, namespace string) string {
+	return fmt.Sprintf("generic-backup/%s-%s/", namespace, pvcName)
+}
+
 // CreateNfsSecret creates the NFS secret which will be mounted by job pod and accessed accordingly
 func CreateNfsSecret(secretName string, backupLocation *storkapi.BackupLocation, namespace string, labels map[string]string) error {
 	credentialData := make(map[string][]byte)
@@ -469,6 +491,49 @@ func RsyncOpenshiftSCC() string {
 	return strings.TrimSpace(os.Getenv(drivers.RsyncOpenshiftSCC))
 }
 
+// RsyncServiceType returns the type of the service exposing the rsync daemon
+// of a cross-cluster migration. The default LoadBalancer service is reachable
+// from the source cluster, a ClusterIP one needs a network connecting the
+// clusters.
+func RsyncServiceType() corev1.ServiceType {
+	if custom := strings.TrimSpace(os.Getenv(drivers.RsyncServiceTypeKey)); custom != "" {
+		return corev1.ServiceType(custom)
+	}
+	return corev1.ServiceTypeLoadBalancer
+}
+
+// GetClusterPairClient returns a client for the remote cluster of the stork
+// ClusterPair.
+func GetClusterPairClient(name, namespace string) (*core.Client, error) {
+	config, err := getClusterPairConfig(name, namespace)
+	if err != nil {
+		return nil, err
+	}
+	return core.NewForConfig(config)
+}
+
+// GetClusterPairBatchClient returns a batch client for the remote cluster of
+// the stork ClusterPair.
+func GetClusterPairBatchClient(name, namespace string) (*batch.Client, error) {
+	config, err := getClusterPairConfig(name, namespace)
+	if err != nil {
+		return nil, err
+	}
+	return batch.NewForConfig(config)
+}
+
+func getClusterPairConfig(name, namespace string) (*rest.Config, error) {
+	clusterPair, err := storkops.Instance().GetClusterPair(name, namespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get clusterpair %s/%s: %v", namespace, name, err)
+	}
+	config, err := clientcmd.NewDefaultClientConfig(clusterPair.Spec.Config, &clientcmd.ConfigOverrides{}).ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the remote cluster config of clusterpair %s/%s: %v", namespace, name, err)
+	}
+	return config, nil
+}
+
 // ToImagePullSecret converts a secret name to the ImagePullSecret struct.
 func ToImagePullSecret(name string) []corev1.LocalObjectReference {
 	if name == "" {
@@ -616,6 +681,11 @@ func GetValidLabel(labelVal string) string {
 	return labelVal
 }
 
+// ShellQuote - quotes the value to be passed as a single argument in the job pod "sh -c" command.
+func ShellQuote(val string) string {
+	return "'" + strings.ReplaceAll(val, "'", `'\''`) + "'"
+}
+
 // CreateImageRegistrySecret - will create the image registry secret in the given job pod namespace
 func CreateImageRegistrySecret(sourceName, destName, sourceNamespace, destNamespace string) error {
 	// Read the image secret from the stork deployment namespace
@@ -653,6 +723,11 @@ func GetCredSecretName(name string) string {
 	return CredSecret + "-" + name
 }
 
+// GetReplicaCredSecretName - get the credential secret name of the replica backuplocation
+func GetReplicaCredSecretName(name string) string {
+	return ReplicaCredSecret + "-" + name
+}
+
 // GetImageSecretName - get image secret name
 func GetImageSecretName(name string) string {
 	return ImageSecret + "-" + name
@@ -1016,6 +1091,25 @@ func GetShortUID(uid string) string {
 	return uid[:8]
 }
 
+// SetBlockVolumeDevice attaches the volume of the first container of the job
+// as the raw block device drivers.KopiaBlockDevicePath instead of mounting it.
+func SetBlockVolumeDevice(job *batchv1.Job, volumeName string) {
+	container := &job.Spec.Template.Spec.Containers[0]
+	var volumeMounts []corev1.VolumeMount
+	for _, volumeMount := range container.VolumeMounts {
+		if volumeMount.Name != volumeName {
+			volumeMounts = append(volumeMounts, volumeMount)
+		}
+	}
+	container.VolumeMounts = volumeMounts
+	container.VolumeDevices = []corev1.VolumeDevice{
+		{
+			Name:       volumeName,
+			DevicePath: drivers.KopiaBlockDevicePath,
+		},
+	}
+}
+
 // Add container security Context to job pod if the PSA is enabled.
 // If static uids like kdmpJobUid or kdmpJobGid is used that means
 // these are dummy UIDs used for backing up resources to backuplocation
@@ -1178,3 +1272,30 @@ func GetAccessModeFromPvc(srcPvcName, srcPvcNameSpace string) ([]corev1.Persiste
 	accessModes := srcPvc.Status.AccessModes
 	return accessModes, nil
 }
+
+// GetPodVolumesPath returns the directory on the host of the volumes of a pod,
+// /var/lib/kubelet/pods/<podUID>/volumes.
+func GetPodVolumesPath(pod corev1.Pod) string {
+	return fmt.Sprintf("%s/%s/volumes", KubeletPodsPath, pod.UID)
+}
+
+// GetVolumeDirectory gets the name of the directory on the host, under /var/lib/kubelet/pods/<podUID>/volumes/,
+// where the specified volume lives. For volumes with a CSIVolumeSource, append "/mount" to the directory name.
+func GetVolumeDirectory(pvcName, pvcNamespace string) (string, error) {
+	pvc, err := core.Instance().GetPersistentVolumeClaim(pvcName, pvcNamespace)
+	if err != nil {
+		return "", err
+	}
+
+	pv, err := core.Instance().GetPersistentVolume(pvc.Spec.VolumeName)
+	if err != nil {
+		return "", err
+	}
+
+	// PV's been created with a CSI source.
+	if pv.Spec.CSI != nil {
+		return pvc.Spec.VolumeName + "/mount", nil
+	}
+
+	return pvc.Spec.VolumeName, nil
+}
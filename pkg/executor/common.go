@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"math"
 	"os"
 	"path/filepath"
 	"reflect"
@@ -12,12 +13,12 @@ import (
 	"time"
 
 	storkapi "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
-	"github.com/libopenstorage/stork/pkg/crypto"
 	"github.com/libopenstorage/stork/pkg/log"
 	storkutils "github.com/libopenstorage/stork/pkg/utils"
 	kdmpapi "github.com/portworx/kdmp/pkg/apis/kdmp/v1alpha1"
 	"github.com/portworx/kdmp/pkg/drivers"
 	"github.com/portworx/kdmp/pkg/drivers/utils"
+	"github.com/portworx/kdmp/pkg/kms"
 	kdmpops "github.com/portworx/kdmp/pkg/util/ops"
 	"github.com/portworx/sched-ops/k8s/core"
 	kdmpschedops "github.com/portworx/sched-ops/k8s/kdmp"
@@ -33,23 +34,30 @@ import (
 const (
 	amazonS3Endpoint      = "s3.amazonaws.com"
 	googleAccountFilePath = "/root/.gce_credentials"
-	accessKeypath         = "/etc/cred-secret/accessKey"
-	secretAccessKeyPath   = "/etc/cred-secret/secretAccessKey"
-	bucketPath            = "/etc/cred-secret/path"
-	endpointPath          = "/etc/cred-secret/endpoint"
-	sseTypePath           = "/etc/cred-secret/sse"
-	passwordPath          = "/etc/cred-secret/password"
-	regionPath            = "/etc/cred-secret/region"
-	disableSslPath        = "/etc/cred-secret/disablessl"
 	// AccountKeyPath gce account key path
-	AccountKeyPath         = "/etc/cred-secret/accountkey"
-	projectIDKeypath       = "/etc/cred-secret/projectid"
-	storageAccountNamePath = "/etc/cred-secret/storageaccountname"
-	storageAccountKeyPath  = "/etc/cred-secret/storageaccountkey"
-	environmentPath        = "/etc/cred-secret/environment"
+	AccountKeyPath = "/etc/cred-secret/accountkey"
+	// Files of the credentials secret mounted in the job pods
+	accessKeyFile          = "accessKey"
+	secretAccessKeyFile    = "secretAccessKey"
+	bucketFile             = "path"
+	endpointFile           = "endpoint"
+	sseTypeFile            = "sse"
+	passwordFile           = "password"
+	kmsFile                = kms.CredentialsKey
+	dataKeysFile           = kms.DataKeysCredentialsKey
+	objectLockModeFile     = utils.ObjectLockModeCredentialsKey
+	objectLockPeriodFile   = utils.ObjectLockPeriodCredentialsKey
+	regionFile             = "region"
+	disableSslFile         = "disablessl"
+	accountKeyFile         = "accountkey"
+	projectIDKeyFile       = "projectid"
+	storageAccountNameFile = "storageaccountname"
+	storageAccountKeyFile  = "storageaccountkey"
+	environmentFile        = "environment"
+	typeFile               = "type"
 	// ServerAddr & SubPath needed for NFS based backuplocation
-	serverAddr = "/etc/cred-secret/serverAddr"
-	subPath    = "/etc/cred-secret/subPath"
+	serverAddrFile = "serverAddr"
+	subPathFile    = "subPath"
 
 	// DefaultTimeout Max time a command will be retired before failing
 	DefaultTimeout = 1 * time.Minute
@@ -58,6 +66,10 @@ const (
 	retrySleep        = 10 * time.Second
 	maxRetry          = 10
 	lastKnownErrorLen = 1000
+	// MaxTransferErrors is the number of entry errors kept in the transfer stats.
+	MaxTransferErrors = 10
+	// maxTransferErrorLen is the max length of an entry error in the transfer stats.
+	maxTransferErrorLen = 256
 )
 
 // BackupTool backup tool
@@ -93,6 +105,9 @@ type S3Config struct {
 	Region     string
 	DisableSSL bool
 	SseType    string
+	// ObjectLock is the retention of the repository objects, nil if the
+	// bucket is not object-locked.
+	ObjectLock *utils.ObjectLock
 }
 
 // AzureConfig specifies the config required to connect to Azure Blob Storage
@@ -106,6 +121,9 @@ type AzureConfig struct {
 type GoogleConfig struct {
 	ProjectID  string
 	AccountKey string
+	// AccountKeyPath is the path of the mounted account key file, the
+	// package AccountKeyPath if empty
+	AccountKeyPath string
 }
 
 // NfsConfig specifies the config required to connect to NFS Baqckuplocation
@@ -132,8 +150,20 @@ type Repository struct {
 	NfsConfig *NfsConfig
 	// Password repository password
 	Password string
+	// LocationPassword is the BackupLocation repository password, Password is
+	// set to the repository data key when the BackupLocation has a KMS.
+	LocationPassword string
+	// KMS is the KMS config of the BackupLocation, only passed to the
+	// maintenance jobs.
+	KMS *kms.Config
+	// DataKeys are the data keys of the repositories of the job, unwrapped by
+	// the controller.
+	DataKeys kms.DataKeys
 	// Type objectstore type
 	Type storkapi.BackupLocationType
+	// ConfigDir is the kopia config directory of the repository connection,
+	// the default one if empty.
+	ConfigDir string
 }
 
 // Status is the current status of the command being executed
@@ -148,10 +178,26 @@ type Status struct {
 	SnapshotID string
 	// SnapshotIDs is the list of Snapshot Ids existing in the repository
 	SnapshotIDs []string
+	// SnapshotStartTimes is the start time of the snapshots in SnapshotIDs
+	SnapshotStartTimes map[string]time.Time
+	// SnapshotEndTimes is the end time of the snapshots in SnapshotIDs
+	SnapshotEndTimes map[string]time.Time
+	// SnapshotRootIDs is the object ID of the root directory of the snapshots
+	// in SnapshotIDs
+	SnapshotRootIDs map[string]string
+	// Entries is the list of files and directories in a snapshot
+	Entries []string
 	// Done indicates if the operation has completed
 	Done bool
 	// LastKnownError is the last known error of the command
 	LastKnownError error
+	// Stats are the statistics of a completed backup
+	Stats *kdmpapi.TransferStats
+	// RepositoryUsage is the content and storage usage of a repository
+	RepositoryUsage *kdmpapi.RepositoryUsage
+	// ObjectLockExpiry is the object-lock expiry of a completed backup of an
+	// object-locked repository
+	ObjectLockExpiry *metav1.Time
 }
 
 // Error is the error returned by the command
@@ -279,8 +325,14 @@ func parseGce(repoName string, backupLocation storkapi.BackupLocationItem) (*Rep
 
 // ParseCloudCred parsing cloud credentials
 func ParseCloudCred() (*Repository, error) {
+	return ParseCloudCredFromDir(drivers.KopiaCredSecretMount)
+}
+
+// ParseCloudCredFromDir parses the cloud credentials of the secret mounted at
+// dir, the jobs accessing two backuplocations mount the second one elsewhere.
+func ParseCloudCredFromDir(dir string) (*Repository, error) {
 	// Read the BL type
-	fPath := drivers.KopiaCredSecretMount + "/" + "type"
+	fPath := filepath.Join(dir, typeFile)
 	blType, err := os.ReadFile(fPath)
 	if err != nil {
 		errMsg := fmt.Sprintf("failed reading data from file %s : %s", fPath, err)
@@ -292,28 +344,56 @@ func ParseCloudCred() (*Repository, error) {
 	var rErr error
 	switch storkapi.BackupLocationType(blType) {
 	case storkapi.BackupLocationS3:
-		repository, rErr = parseS3Creds()
+		repository, rErr = parseS3Creds(dir)
 	case storkapi.BackupLocationGoogle:
-		repository, rErr = parseGoogleCreds()
+		repository, rErr = parseGoogleCreds(dir)
 	case storkapi.BackupLocationAzure:
-		repository, rErr = parseAzureCreds()
+		repository, rErr = parseAzureCreds(dir)
 	case storkapi.BackupLocationNFS:
-		repository, rErr = parseNfsCreds()
+		repository, rErr = parseNfsCreds(dir)
 	}
 	if rErr != nil {
 		return nil, rErr
 	}
 
-	password, err := os.ReadFile(passwordPath)
+	password, err := os.ReadFile(filepath.Join(dir, passwordFile))
 	if err != nil {
-		errMsg := fmt.Sprintf("failed reading data from file %s : %s", passwordPath, err)
+		errMsg := fmt.Sprintf("failed reading data from file %s : %s", filepath.Join(dir, passwordFile), err)
 		logrus.Errorf("%v", errMsg)
 		return nil, fmt.Errorf(errMsg)
 	}
 	repository.Password = string(password)
-	bucket, err := os.ReadFile(bucketPath)
+	repository.LocationPassword = string(password)
+	kmsConfig, err := os.ReadFile(filepath.Join(dir, kmsFile))
+	if err != nil && !os.IsNotExist(err) {
+		errMsg := fmt.Sprintf("failed reading data from file %s : %s", filepath.Join(dir, kmsFile), err)
+		logrus.Errorf("%v", errMsg)
+		return nil, fmt.Errorf(errMsg)
+	}
+	if err == nil {
+		repository.KMS = &kms.Config{}
+		if err := json.Unmarshal(kmsConfig, repository.KMS); err != nil {
+			errMsg := fmt.Sprintf("failed parsing kms config from file %s : %s", filepath.Join(dir, kmsFile), err)
+			logrus.Errorf("%v", errMsg)
+			return nil, fmt.Errorf(errMsg)
+		}
+	}
+	dataKeys, err := os.ReadFile(filepath.Join(dir, dataKeysFile))
+	if err != nil && !os.IsNotExist(err) {
+		errMsg := fmt.Sprintf("failed reading data from file %s : %s", filepath.Join(dir, dataKeysFile), err)
+		logrus.Errorf("%v", errMsg)
+		return nil, fmt.Errorf(errMsg)
+	}
+	if err == nil {
+		if err := json.Unmarshal(dataKeys, &repository.DataKeys); err != nil {
+			errMsg := fmt.Sprintf("failed parsing data keys from file %s : %s", filepath.Join(dir, dataKeysFile), err)
+			logrus.Errorf("%v", errMsg)
+			return nil, fmt.Errorf(errMsg)
+		}
+	}
+	bucket, err := os.ReadFile(filepath.Join(dir, bucketFile))
 	if err != nil {
-		errMsg := fmt.Sprintf("failed reading data from file %s : %s", bucketPath, err)
+		errMsg := fmt.Sprintf("failed reading data from file %s : %s", filepath.Join(dir, bucketFile), err)
 		logrus.Errorf("%v", errMsg)
 		return nil, fmt.Errorf(errMsg)
 	}
@@ -327,44 +407,44 @@ func ParseCloudCred() (*Repository, error) {
 	return repository, rErr
 }
 
-func parseS3Creds() (*Repository, error) {
+func parseS3Creds(dir string) (*Repository, error) {
 	repository := &Repository{
 		S3Config: &S3Config{},
 	}
-	accessKey, err := os.ReadFile(accessKeypath)
+	accessKey, err := os.ReadFile(filepath.Join(dir, accessKeyFile))
 	if err != nil {
-		errMsg := fmt.Sprintf("failed reading data from file %s : %s", accessKeypath, err)
+		errMsg := fmt.Sprintf("failed reading data from file %s : %s", filepath.Join(dir, accessKeyFile), err)
 		logrus.Errorf("%v", errMsg)
 		return nil, fmt.Errorf(errMsg)
 	}
 
-	secretAccessKey, err := os.ReadFile(secretAccessKeyPath)
+	secretAccessKey, err := os.ReadFile(filepath.Join(dir, secretAccessKeyFile))
 	if err != nil {
-		errMsg := fmt.Sprintf("failed reading data from file %s : %s", secretAccessKeyPath, err)
+		errMsg := fmt.Sprintf("failed reading data from file %s : %s", filepath.Join(dir, secretAccessKeyFile), err)
 		logrus.Errorf("%v", errMsg)
 		return nil, fmt.Errorf(errMsg)
 	}
 
-	endpoint, err := os.ReadFile(endpointPath)
+	endpoint, err := os.ReadFile(filepath.Join(dir, endpointFile))
 	if err != nil {
-		errMsg := fmt.Sprintf("failed reading data from file %s : %s", endpointPath, err)
+		errMsg := fmt.Sprintf("failed reading data from file %s : %s", filepath.Join(dir, endpointFile), err)
 		logrus.Errorf("%v", errMsg)
 		return nil, fmt.Errorf(errMsg)
 	}
 
-	sseType, err := os.ReadFile(sseTypePath)
+	sseType, err := os.ReadFile(filepath.Join(dir, sseTypeFile))
 	if err != nil {
-		// sseTypePath field in the secret is optional, So ignoring if the file does not exists.
+		// sse field in the secret is optional, So ignoring if the file does not exists.
 		if !os.IsNotExist(err) {
-			errMsg := fmt.Sprintf("failed reading data from file %s : %s", sseTypePath, err)
+			errMsg := fmt.Sprintf("failed reading data from file %s : %s", filepath.Join(dir, sseTypeFile), err)
 			logrus.Errorf("%v", errMsg)
 			return nil, fmt.Errorf(errMsg)
 		}
 	}
 
-	disableSsl, err := os.ReadFile(disableSslPath)
+	disableSsl, err := os.ReadFile(filepath.Join(dir, disableSslFile))
 	if err != nil {
-		errMsg := fmt.Sprintf("failed reading data from file %s : %s", disableSslPath, err)
+		errMsg := fmt.Sprintf("failed reading data from file %s : %s", filepath.Join(dir, disableSslFile), err)
 		logrus.Errorf("%v", errMsg)
 		return nil, fmt.Errorf(errMsg)
 	}
@@ -381,31 +461,50 @@ func parseS3Creds() (*Repository, error) {
 	repository.S3Config.SseType = string(sseType)
 	repository.S3Config.DisableSSL = isSsl
 	repository.Type = storkapi.BackupLocationS3
-	region, err := os.ReadFile(regionPath)
+	region, err := os.ReadFile(filepath.Join(dir, regionFile))
 	if err != nil {
-		errMsg := fmt.Sprintf("failed reading data from file %s : %s", regionPath, err)
+		errMsg := fmt.Sprintf("failed reading data from file %s : %s", filepath.Join(dir, regionFile), err)
 		logrus.Errorf("%v", errMsg)
 		return nil, fmt.Errorf(errMsg)
 	}
 	repository.S3Config.Region = string(region)
 
+	// object-lock fields in the secret are optional
+	lockMode, err := os.ReadFile(filepath.Join(dir, objectLockModeFile))
+	if err != nil && !os.IsNotExist(err) {
+		errMsg := fmt.Sprintf("failed reading data from file %s : %s", filepath.Join(dir, objectLockModeFile), err)
+		logrus.Errorf("%v", errMsg)
+		return nil, fmt.Errorf(errMsg)
+	}
+	lockPeriod, err := os.ReadFile(filepath.Join(dir, objectLockPeriodFile))
+	if err != nil && !os.IsNotExist(err) {
+		errMsg := fmt.Sprintf("failed reading data from file %s : %s", filepath.Join(dir, objectLockPeriodFile), err)
+		logrus.Errorf("%v", errMsg)
+		return nil, fmt.Errorf(errMsg)
+	}
+	repository.S3Config.ObjectLock, err = utils.ParseObjectLock(string(lockMode), string(lockPeriod))
+	if err != nil {
+		logrus.Errorf("%v", err)
+		return nil, err
+	}
+
 	return repository, nil
 }
 
-func parseNfsCreds() (*Repository, error) {
+func parseNfsCreds(dir string) (*Repository, error) {
 	repository := &Repository{
 		NfsConfig: &NfsConfig{},
 	}
 	repository.Type = storkapi.BackupLocationNFS
-	sa, err := os.ReadFile(serverAddr)
+	sa, err := os.ReadFile(filepath.Join(dir, serverAddrFile))
 	if err != nil {
-		errMsg := fmt.Sprintf("failed reading data from file %s : %s", serverAddr, err)
+		errMsg := fmt.Sprintf("failed reading data from file %s : %s", filepath.Join(dir, serverAddrFile), err)
 		logrus.Errorf("%v", errMsg)
 		return nil, fmt.Errorf(errMsg)
 	}
-	bucket, err := os.ReadFile(bucketPath)
+	bucket, err := os.ReadFile(filepath.Join(dir, bucketFile))
 	if err != nil {
-		errMsg := fmt.Sprintf("failed reading data from file %s : %s", subPath, err)
+		errMsg := fmt.Sprintf("failed reading data from file %s : %s", filepath.Join(dir, subPathFile), err)
 		logrus.Errorf("%v", errMsg)
 		return nil, fmt.Errorf(errMsg)
 	}
@@ -415,53 +514,54 @@ func parseNfsCreds() (*Repository, error) {
 	return repository, nil
 }
 
-func parseGoogleCreds() (*Repository, error) {
+func parseGoogleCreds(dir string) (*Repository, error) {
 	repository := &Repository{
 		GoogleConfig: &GoogleConfig{},
 	}
 
-	projectID, err := os.ReadFile(projectIDKeypath)
+	projectID, err := os.ReadFile(filepath.Join(dir, projectIDKeyFile))
 	if err != nil {
-		errMsg := fmt.Sprintf("failed reading data from file %s : %s", projectIDKeypath, err)
+		errMsg := fmt.Sprintf("failed reading data from file %s : %s", filepath.Join(dir, projectIDKeyFile), err)
 		logrus.Errorf("%v", errMsg)
 		return nil, fmt.Errorf(errMsg)
 	}
-	accountKey, err := os.ReadFile(AccountKeyPath)
+	accountKey, err := os.ReadFile(filepath.Join(dir, accountKeyFile))
 	if err != nil {
-		errMsg := fmt.Sprintf("failed reading data from file %s : %s", AccountKeyPath, err)
+		errMsg := fmt.Sprintf("failed reading data from file %s : %s", filepath.Join(dir, accountKeyFile), err)
 		logrus.Errorf("%v", errMsg)
 		return nil, fmt.Errorf(errMsg)
 	}
 
 	repository.Type = storkapi.BackupLocationGoogle
 	repository.GoogleConfig.AccountKey = string(accountKey)
+	repository.GoogleConfig.AccountKeyPath = filepath.Join(dir, accountKeyFile)
 	repository.GoogleConfig.ProjectID = string(projectID)
 
 	return repository, nil
 }
 
-func parseAzureCreds() (*Repository, error) {
+func parseAzureCreds(dir string) (*Repository, error) {
 	repository := &Repository{
 		AzureConfig: &AzureConfig{},
 	}
 
-	storageAccountName, err := os.ReadFile(storageAccountNamePath)
+	storageAccountName, err := os.ReadFile(filepath.Join(dir, storageAccountNameFile))
 	if err != nil {
-		errMsg := fmt.Sprintf("failed reading data from file %s : %s", storageAccountNamePath, err)
+		errMsg := fmt.Sprintf("failed reading data from file %s : %s", filepath.Join(dir, storageAccountNameFile), err)
 		logrus.Errorf("%v", errMsg)
 		return nil, fmt.Errorf(errMsg)
 	}
 
-	storageAccountKey, err := os.ReadFile(storageAccountKeyPath)
+	storageAccountKey, err := os.ReadFile(filepath.Join(dir, storageAccountKeyFile))
 	if err != nil {
-		errMsg := fmt.Sprintf("failed reading data from file %s : %s", storageAccountKeyPath, err)
+		errMsg := fmt.Sprintf("failed reading data from file %s : %s", filepath.Join(dir, storageAccountKeyFile), err)
 		logrus.Errorf("%v", errMsg)
 		return nil, fmt.Errorf(errMsg)
 	}
 
-	environment, err := os.ReadFile(environmentPath)
+	environment, err := os.ReadFile(filepath.Join(dir, environmentFile))
 	if err != nil {
-		errMsg := fmt.Sprintf("failed reading data from file %s: %s", environmentPath, err)
+		errMsg := fmt.Sprintf("failed reading data from file %s: %s", filepath.Join(dir, environmentFile), err)
 		logrus.Errorf("%v", errMsg)
 		return nil, fmt.Errorf(errMsg)
 	}
@@ -513,6 +613,12 @@ func WriteVolumeBackupStatus(
 	vb.Status.TotalBytes = status.TotalBytes
 	vb.Status.TotalBytesProcessed = status.TotalBytesProcessed
 	vb.Status.SnapshotID = status.SnapshotID
+	if status.Stats != nil {
+		vb.Status.Stats = status.Stats
+	}
+	if status.ObjectLockExpiry != nil {
+		vb.Status.ObjectLockExpiry = status.ObjectLockExpiry
+	}
 	if status.LastKnownError != nil {
 		// If the length of LastKnownError string is less than or equal to 1000 chars
 		// add it directly to volumebackup status, else add it job pod log.
@@ -532,6 +638,77 @@ func WriteVolumeBackupStatus(
 	return nil
 }
 
+// SetTransferRates sets the deduplication ratio and the throughput of the
+// stats of a backup of totalBytes.
+func SetTransferRates(stats *kdmpapi.TransferStats, totalBytes uint64) {
+	if stats.UploadedBytes > 0 {
+		stats.DeduplicationRatio = math.Round(float64(totalBytes)/float64(stats.UploadedBytes)*100) / 100
+	}
+	if stats.StartTime != nil && stats.EndTime != nil {
+		if seconds := stats.EndTime.Sub(stats.StartTime.Time).Seconds(); seconds > 0 {
+			stats.Throughput = uint64(float64(totalBytes) / seconds)
+		}
+	}
+}
+
+// AddTransferError adds the error of an entry to the stats, up to
+// MaxTransferErrors errors.
+func AddTransferError(stats *kdmpapi.TransferStats, path, errMsg string) {
+	if len(stats.Errors) >= MaxTransferErrors {
+		return
+	}
+	if len(errMsg) > maxTransferErrorLen {
+		errMsg = errMsg[:maxTransferErrorLen]
+	}
+	stats.Errors = append(stats.Errors, kdmpapi.TransferError{Path: path, Error: errMsg})
+}
+
+// AddVolumeBackupReplica records the VolumeBackup the snapshot was replicated
+// to in the status of the source VolumeBackup.
+func AddVolumeBackupReplica(volumeBackupName, namespace string, replica kdmpapi.DataExportObjectReference) error {
+	vb, err := kdmpops.Instance().GetVolumeBackup(context.Background(), volumeBackupName, namespace)
+	if err != nil {
+		return fmt.Errorf("get %s/%s VolumeBackup: %v", volumeBackupName, namespace, err)
+	}
+	for _, r := range vb.Status.Replicas {
+		if r.Name == replica.Name && r.Namespace == replica.Namespace {
+			return nil
+		}
+	}
+	vb.Status.Replicas = append(vb.Status.Replicas, replica)
+	if _, err = kdmpops.Instance().UpdateVolumeBackup(context.Background(), vb); err != nil {
+		return fmt.Errorf("update %s/%s VolumeBackup: %v", volumeBackupName, namespace, err)
+	}
+	return nil
+}
+
+// WriteVolumeBackupVerificationStatus writes a snapshot verification status to the VolumeBackup crd.
+func WriteVolumeBackupVerificationStatus(
+	verification *kdmpapi.VolumeBackupVerification,
+	volumeBackupName,
+	namespace string,
+) error {
+	if volumeBackupName == "" {
+		return nil
+	}
+
+	vb, err := kdmpops.Instance().GetVolumeBackup(context.Background(), volumeBackupName, namespace)
+	if err != nil {
+		return fmt.Errorf("get %s/%s VolumeBackup: %v", volumeBackupName, namespace, err)
+	}
+
+	if len(verification.Reason) > lastKnownErrorLen {
+		logrus.Errorf("verification failure for the volumebackup CR [%v]: %v", volumeBackupName, verification.Reason)
+		verification.Reason = fmt.Sprintf("volumeBackupName [%v] verification failed, reason is large to display. Please check the job pod log", volumeBackupName)
+	}
+	vb.Status.Verification = verification
+
+	if _, err = kdmpops.Instance().UpdateVolumeBackup(context.Background(), vb); err != nil {
+		return fmt.Errorf("update %s/%s VolumeBackup: %v", volumeBackupName, namespace, err)
+	}
+	return nil
+}
+
 // UpdateStatusInResourceBackup -- Updating resourceBackup status
 func UpdateStatusInResourceBackup(
 	newRbStatus kdmpapi.ResourceBackupProgressStatus,
@@ -606,8 +783,32 @@ func UpdateResourceBackupStatus(
 	return nil
 }
 
-// CreateVolumeBackup creates volumebackup CRD
-func CreateVolumeBackup(name, namespace, repository, blName, blNamespace string) error {
+// CreateVolumeBackup creates volumebackup CRD, labelled with the name of the
+// driver of the backup.
+func CreateVolumeBackup(name, namespace, driverName, repository, blName, blNamespace string) error {
+	return createVolumeBackup(name, namespace, driverName, kdmpapi.VolumeBackupSpec{
+		Repository: repository,
+		BackupLocation: kdmpapi.DataExportObjectReference{
+			Name:      blName,
+			Namespace: blNamespace,
+		},
+	})
+}
+
+// CreateReplicaVolumeBackup creates a VolumeBackup CR for the copy of the
+// snapshot of the source VolumeBackup replicated to another backuplocation.
+func CreateReplicaVolumeBackup(name, namespace, repository, blName, blNamespace string, source kdmpapi.DataExportObjectReference) error {
+	return createVolumeBackup(name, namespace, drivers.KopiaBackup, kdmpapi.VolumeBackupSpec{
+		Repository: repository,
+		BackupLocation: kdmpapi.DataExportObjectReference{
+			Name:      blName,
+			Namespace: blNamespace,
+		},
+		ReplicaOf: &source,
+	})
+}
+
+func createVolumeBackup(name, namespace, driverName string, spec kdmpapi.VolumeBackupSpec) error {
 	new := &kdmpapi.VolumeBackup{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      name,
@@ -615,14 +816,11 @@ func CreateVolumeBackup(name, namespace, repository, blName, blNamespace string)
 			Annotations: map[string]string{
 				utils.SkipResourceAnnotation: "true",
 			},
-		},
-		Spec: kdmpapi.VolumeBackupSpec{
-			Repository: repository,
-			BackupLocation: kdmpapi.DataExportObjectReference{
-				Name:      blName,
-				Namespace: blNamespace,
+			Labels: map[string]string{
+				drivers.DriverNameLabel: driverName,
 			},
 		},
+		Spec: spec,
 	}
 
 	vb, err := kdmpops.Instance().GetVolumeBackup(context.Background(), name, namespace)
@@ -733,13 +931,17 @@ func createNamespaces(backup *storkapi.ApplicationBackup,
 		logrus.Errorf("%s: error parsing cloud cred: %v", funct, err)
 		return err
 	}
-	bkpDir := filepath.Join(repo.Path, backup.Status.BackupPath)
 	restoreLocation, err := storkops.Instance().GetBackupLocation(backup.Spec.BackupLocation, backupLocationNamespace)
 	if err != nil {
 		return err
 	}
+	store, err := NewResourceStore(repo, restoreLocation.Location.EncryptionV2Key)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
 
-	nsData, err := DownloadObject(bkpDir, NamespacesFile, restoreLocation.Location.EncryptionV2Key)
+	nsData, err := store.Download(backup.Status.BackupPath, NamespacesFile)
 	if err != nil {
 		return err
 	}
@@ -861,13 +1063,5 @@ func DownloadObject(
 		return nil, fmt.Errorf("getting file content of %s failed: %v", filePath, err)
 	}
 
-	if len(encryptionKey) > 0 {
-		var decryptData []byte
-		if decryptData, err = crypto.Decrypt(data, encryptionKey); err != nil {
-			logrus.Errorf("nfs downloadObject: decrypt failed :%v, returning data direclty", err)
-			return data, nil
-		}
-		return decryptData, nil
-	}
-	return data, nil
+	return decryptObject(data, encryptionKey), nil
 }
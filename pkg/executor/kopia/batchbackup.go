@@ -0,0 +1,144 @@
+package kopia
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/portworx/kdmp/pkg/drivers"
+	"github.com/portworx/kdmp/pkg/executor"
+	kdmpops "github.com/portworx/kdmp/pkg/util/ops"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/errors"
+)
+
+const (
+	// batchConfigDir is the directory of the kopia configs of the volumes of
+	// a batch, each volume is connected to its own repository.
+	batchConfigDir = "/tmp/kopia-batch"
+)
+
+// batchVolume is a volume of a batch backup given as
+// <volume-backup-name>:<repository>:<source-path>.
+type batchVolume struct {
+	volumeBackupName string
+	repository       string
+	sourcePath       string
+}
+
+func parseBatchVolumes(specs []string) ([]batchVolume, error) {
+	var volumes []batchVolume
+	names := make(map[string]bool)
+	for _, spec := range specs {
+		parts := strings.SplitN(spec, ":", 3)
+		if len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+			return nil, fmt.Errorf("invalid volume %q, expected <volume-backup-name>:<repository>:<source-path>", spec)
+		}
+		if names[parts[0]] {
+			return nil, fmt.Errorf("volumebackup %s is set for several volumes", parts[0])
+		}
+		names[parts[0]] = true
+		volumes = append(volumes, batchVolume{
+			volumeBackupName: parts[0],
+			repository:       parts[1],
+			sourcePath:       parts[2],
+		})
+	}
+	return volumes, nil
+}
+
+// runBatchBackup snapshots several volumes, at most workers at the same time.
+// The credentials are read once and each volume is snapshotted to its own
+// repository with its own VolumeBackup. The volumes whose VolumeBackup already
+// has a snapshot are skipped, as the job pod is restarted when one of the
+// volumes fails.
+func runBatchBackup(specs []string, workers int) error {
+	fn := "runBatchBackup"
+	volumes, err := parseBatchVolumes(specs)
+	if err != nil {
+		return err
+	}
+	if workers < 1 {
+		return fmt.Errorf("workers should be at least 1, got %d", workers)
+	}
+
+	repo, rErr := executor.ParseCloudCred()
+	var pending []*backupVolume
+	for i, volume := range volumes {
+		vb, err := kdmpops.Instance().GetVolumeBackup(context.Background(), volume.volumeBackupName, bkpNamespace)
+		if err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("get volumebackup %s/%s: %v", bkpNamespace, volume.volumeBackupName, err)
+		}
+		if err == nil && vb.Status.SnapshotID != "" {
+			logrus.Infof("%s: volumebackup %s/%s already has snapshot %s", fn, bkpNamespace, vb.Name, vb.Status.SnapshotID)
+			continue
+		}
+
+		var repoName string
+		if repo != nil {
+			repoName = repositoryPath(volume.repository)
+		}
+		if err := executor.CreateVolumeBackup(
+			volume.volumeBackupName,
+			bkpNamespace,
+			drivers.KopiaBackup,
+			repoName,
+			backupLocationName,
+			backupLocationNamespace,
+		); err != nil {
+			logrus.Errorf("%s: %v", fn, err)
+			return err
+		}
+		vol := &backupVolume{
+			volumeBackupName: volume.volumeBackupName,
+			sourcePath:       volume.sourcePath,
+		}
+		if rErr != nil {
+			if statusErr := vol.writeStatus(&executor.Status{LastKnownError: rErr}); statusErr != nil {
+				return statusErr
+			}
+			continue
+		}
+		volumeRepo := *repo
+		volumeRepo.Name = repoName
+		volumeRepo.ConfigDir = filepath.Join(batchConfigDir, strconv.Itoa(i))
+		vol.repository = &volumeRepo
+		pending = append(pending, vol)
+	}
+	if rErr != nil {
+		return fmt.Errorf("parse backuplocation: %s", rErr)
+	}
+
+	errs := make([]error, len(pending))
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers && w < len(pending); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				errs[i] = snapshotVolume(pending[i])
+			}
+		}()
+	}
+	for i := range pending {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+
+	var failed []string
+	for i, err := range errs {
+		if err != nil {
+			logrus.Errorf("%s: backup of volumebackup %s/%s failed: %v", fn, bkpNamespace, pending[i].volumeBackupName, err)
+			failed = append(failed, pending[i].volumeBackupName)
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("backup of %d out of %d volumes failed: %s", len(failed), len(volumes), strings.Join(failed, ", "))
+	}
+	return nil
+}
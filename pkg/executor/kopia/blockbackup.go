@@ -0,0 +1,39 @@
+package kopia
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/portworx/kdmp/pkg/kopia"
+)
+
+const (
+	// blockImageFile is the name of the single file holding the content of
+	// the block device in a block snapshot.
+	blockImageFile = "block.img"
+	// blockSnapshotSource is used as the kopia snapshot source for all the block
+	// backups, as the job pod hostname changes for every backup.
+	blockSnapshotSource = "kdmp@block:/data"
+)
+
+var (
+	blockDevice string
+)
+
+// setBlockDeviceInput streams the block device into the backup command as
+// the blockImageFile of the snapshot. The device is read once and split by
+// kopia, so that only the changed data is uploaded and nothing is staged on
+// the local disk. The returned file has to be closed once the command is done.
+func setBlockDeviceInput(backupCmd *kopia.Command) (*os.File, error) {
+	dev, err := os.Open(blockDevice)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open block device %s: %v", blockDevice, err)
+	}
+	backupCmd.Stdin = dev
+	backupCmd.AddFlag("--stdin-file")
+	backupCmd.AddFlag(blockImageFile)
+	backupCmd.AddFlag("--override-source")
+	backupCmd.AddFlag(blockSnapshotSource)
+
+	return dev, nil
+}
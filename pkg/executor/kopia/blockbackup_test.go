@@ -0,0 +1,55 @@
+package kopia
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/portworx/kdmp/pkg/kopia"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetBlockDeviceInput(t *testing.T) {
+	data := []byte("block device content")
+	source := filepath.Join(t.TempDir(), "source")
+	require.NoError(t, os.WriteFile(source, data, 0600))
+
+	device := blockDevice
+	defer func() { blockDevice = device }()
+
+	// The device is the input of the backup, snapshotted as a single file of
+	// the block snapshot source
+	blockDevice = source
+	backupCmd, err := kopia.GetBackupCommand("", "repo", "", "", os.TempDir())
+	require.NoError(t, err)
+	dev, err := setBlockDeviceInput(backupCmd)
+	require.NoError(t, err)
+	defer dev.Close()
+	require.Equal(t, []string{"--stdin-file", blockImageFile, "--override-source", blockSnapshotSource}, backupCmd.Flags)
+	execCmd := backupCmd.BackupCmd()
+	require.Equal(t, dev, execCmd.Stdin)
+	require.Equal(t, os.TempDir(), execCmd.Dir)
+
+	blockDevice = filepath.Join(t.TempDir(), "missing")
+	_, err = setBlockDeviceInput(backupCmd)
+	require.Error(t, err)
+}
+
+func TestBlockWriterProgress(t *testing.T) {
+	var dev bytes.Buffer
+	writer := &blockWriter{dev: &dev}
+	require.Equal(t, float64(0), writer.progress(100))
+
+	n, err := writer.Write(make([]byte, 25))
+	require.NoError(t, err)
+	require.Equal(t, 25, n)
+	require.Equal(t, float64(25), writer.progress(100))
+	require.Equal(t, 25, dev.Len())
+
+	// Completion is only reported once the restore is done
+	_, err = writer.Write(make([]byte, 75))
+	require.NoError(t, err)
+	require.Equal(t, float64(99), writer.progress(100))
+	require.Equal(t, float64(0), writer.progress(0))
+}
@@ -0,0 +1,135 @@
+package kopia
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/portworx/kdmp/pkg/executor"
+	"github.com/portworx/kdmp/pkg/kopia"
+	"github.com/sirupsen/logrus"
+)
+
+// blockWriter counts the bytes written to the block device for the progress
+// of the restore.
+type blockWriter struct {
+	dev     io.Writer
+	written int64
+}
+
+func (w *blockWriter) Write(p []byte) (int, error) {
+	n, err := w.dev.Write(p)
+	atomic.AddInt64(&w.written, int64(n))
+	return n, err
+}
+
+func (w *blockWriter) progress(deviceSize int64) float64 {
+	if deviceSize <= 0 {
+		return 0
+	}
+	progress := float64(atomic.LoadInt64(&w.written)) * 100 / float64(deviceSize)
+	// The backup can be smaller than the device, 100% is only reported once
+	// the restore is done.
+	if progress > 99 {
+		progress = 99
+	}
+	return progress
+}
+
+// runBlockRestore streams the image of a block backup onto a raw block device.
+func runBlockRestore(repository *executor.Repository, snapshotID, device string) error {
+	fn := "runBlockRestore"
+	logrus.Infof("%s: restoring snapshot %s onto block device %s", fn, snapshotID, device)
+	status := &executor.Status{SnapshotID: snapshotID}
+	err := restoreBlockDevice(snapshotID, device, func(progress float64) {
+		status.ProgressPercentage = progress
+		if err := executor.WriteVolumeBackupStatus(status, volumeBackupName, restoreNamespace); err != nil {
+			logrus.Errorf("%s: failed to write a VolumeBackup status: %v", fn, err)
+		}
+	})
+
+	status.Done = true
+	status.LastKnownError = err
+	if err == nil {
+		status.ProgressPercentage = 100
+	}
+	if statusErr := executor.WriteVolumeBackupStatus(status, volumeBackupName, restoreNamespace); statusErr != nil {
+		logrus.Errorf("%s: failed to write a VolumeBackup status: %v", fn, statusErr)
+	}
+	if err != nil {
+		return err
+	}
+	logrus.Infof("%s: block restore successful from snapshot %s", fn, snapshotID)
+	return nil
+}
+
+func restoreBlockDevice(snapshotID, device string, progress func(float64)) error {
+	rootID, err := getSnapshotRootID(snapshotID)
+	if err != nil {
+		return err
+	}
+
+	dev, err := os.OpenFile(device, os.O_WRONLY, 0)
+	if err != nil {
+		return fmt.Errorf("failed to open block device %s: %v", device, err)
+	}
+	defer dev.Close()
+	deviceSize, err := dev.Seek(0, io.SeekEnd)
+	if err != nil {
+		return fmt.Errorf("failed to get size of block device %s: %v", device, err)
+	}
+	if _, err := dev.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to seek block device %s: %v", device, err)
+	}
+
+	// kopia show takes an object ID and not a snapshot ID, the image is read
+	// from the root directory of the snapshot.
+	showCmd, err := kopia.GetShowCommand(rootID + "/" + blockImageFile)
+	if err != nil {
+		return err
+	}
+	writer := &blockWriter{dev: dev}
+	showExecutor := kopia.NewShowExecutor(showCmd, writer)
+	if err := showExecutor.Run(); err != nil {
+		return fmt.Errorf("failed to run show command: %v", err)
+	}
+	for {
+		time.Sleep(progressCheckInterval)
+		status, err := showExecutor.Status()
+		if err != nil {
+			return err
+		}
+		if status.LastKnownError != nil {
+			return fmt.Errorf("failed to restore the block image of snapshot %s, it may not be a block backup: %v",
+				snapshotID, status.LastKnownError)
+		}
+		if status.Done {
+			break
+		}
+		progress(writer.progress(deviceSize))
+	}
+
+	if err := dev.Sync(); err != nil {
+		return fmt.Errorf("failed to sync block device %s: %v", device, err)
+	}
+	return nil
+}
+
+// getSnapshotRootID returns the object ID of the root directory of a snapshot.
+func getSnapshotRootID(snapshotID string) (string, error) {
+	listCmd, err := kopia.GetListCommand()
+	if err != nil {
+		return "", err
+	}
+	status, err := runKopiaListCommand(listCmd)
+	if err != nil {
+		return "", err
+	}
+	rootID := status.SnapshotRootIDs[snapshotID]
+	if rootID == "" {
+		return "", fmt.Errorf("snapshot %s not found", snapshotID)
+	}
+	return rootID, nil
+}
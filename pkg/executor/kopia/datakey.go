@@ -0,0 +1,231 @@
+package kopia
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	storkv1 "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"github.com/libopenstorage/stork/pkg/objectstore"
+	"github.com/portworx/kdmp/pkg/executor"
+	"github.com/portworx/kdmp/pkg/kms"
+	"github.com/sirupsen/logrus"
+	"gocloud.dev/blob"
+	"gocloud.dev/gcerrors"
+)
+
+const dataKeyFile = kms.DataKeyFile
+
+var errNoDataKey = kms.ErrNoDataKey
+
+// setupRepositoryPassword sets the password of the repository to its data key
+// if the BackupLocation has a KMS. The repositories created before the KMS was
+// configured keep using the BackupLocation password.
+//
+// The data keys are unwrapped by the controller. Only the maintenance jobs get
+// the KMS config, to unwrap them.
+func setupRepositoryPassword(repository *executor.Repository) error {
+	_, err := setupPassword(repository, false)
+	return err
+}
+
+// setupNewRepositoryPassword is setupRepositoryPassword for a repository about
+// to be created. If the repository has no data key yet, one is generated and
+// returned wrapped. It is only stored with createDataKey once the repository
+// is created, so that an existing repository never gets a key not matching its
+// password.
+func setupNewRepositoryPassword(repository *executor.Repository) (string, error) {
+	return setupPassword(repository, true)
+}
+
+func setupPassword(repository *executor.Repository, create bool) (string, error) {
+	if repository.KMS == nil {
+		return setupDataKeyPassword(repository, create)
+	}
+	provider, err := kms.New(repository.KMS)
+	if err != nil {
+		return "", fmt.Errorf("invalid kms config: %v", err)
+	}
+
+	wrapped, err := readDataKey(repository)
+	if err == errNoDataKey {
+		if !create || nfsRepositoryExists(repository) {
+			logrus.Warnf("repository %s has no data key, using the backuplocation password", repository.Name)
+			repository.Password = repository.LocationPassword
+			return "", nil
+		}
+		key, wrapped, err := kms.NewDataKey(provider)
+		if err != nil {
+			return "", err
+		}
+		logrus.Infof("generated the data key of repository %s", repository.Name)
+		repository.Password = kms.Password(key)
+		return wrapped, nil
+	} else if err != nil {
+		return "", err
+	}
+
+	key, err := provider.Unwrap(wrapped)
+	if err != nil {
+		return "", fmt.Errorf("failed to unwrap the data key of repository %s: %v", repository.Name, err)
+	}
+	repository.Password = kms.Password(key)
+	return "", nil
+}
+
+// setupDataKeyPassword is setupPassword with the data key passed by the
+// controller. A data key to store is only passed if the repository had none.
+func setupDataKeyPassword(repository *executor.Repository, create bool) (string, error) {
+	dataKey, ok := repository.DataKeys.Get(repository.Name)
+	if !ok {
+		return "", nil
+	}
+	_, err := readDataKey(repository)
+	if err == errNoDataKey {
+		if !create || dataKey.Wrapped == "" || nfsRepositoryExists(repository) {
+			logrus.Warnf("repository %s has no data key, using the backuplocation password", repository.Name)
+			repository.Password = repository.LocationPassword
+			return "", nil
+		}
+		logrus.Infof("using the new data key of repository %s", repository.Name)
+		repository.Password = dataKey.Password
+		return dataKey.Wrapped, nil
+	} else if err != nil {
+		return "", err
+	}
+	repository.Password = dataKey.Password
+	return "", nil
+}
+
+// rotateDataKey wraps the data key of the repository with the current key of
+// the KMS. The data key itself is unchanged, so is the repository.
+func rotateDataKey(repository *executor.Repository) error {
+	if repository.KMS == nil {
+		return fmt.Errorf("backuplocation has no kms")
+	}
+	provider, err := kms.New(repository.KMS)
+	if err != nil {
+		return fmt.Errorf("invalid kms config: %v", err)
+	}
+	wrapped, err := readDataKey(repository)
+	if err != nil {
+		return err
+	}
+	rewrapped, err := kms.Rewrap(provider, wrapped)
+	if err != nil {
+		return fmt.Errorf("failed to rewrap the data key of repository %s: %v", repository.Name, err)
+	}
+	return writeDataKey(repository, rewrapped)
+}
+
+func nfsRepositoryExists(repository *executor.Repository) bool {
+	if repository.Type != storkv1.BackupLocationNFS {
+		return false
+	}
+	_, err := os.Stat(filepath.Join(repository.Path, repository.Name, kopiaNFSRepositoryFile))
+	return err == nil
+}
+
+func readDataKey(repository *executor.Repository) (string, error) {
+	var data []byte
+	var err error
+	if repository.Type == storkv1.BackupLocationNFS {
+		data, err = os.ReadFile(filepath.Join(repository.Path, repository.Name, dataKeyFile))
+		if os.IsNotExist(err) {
+			return "", errNoDataKey
+		}
+	} else {
+		var bucket *blob.Bucket
+		bucket, err = dataKeyBucket(repository)
+		if err != nil {
+			return "", err
+		}
+		data, err = bucket.ReadAll(context.TODO(), dataKeyFile)
+		if gcerrors.Code(err) == gcerrors.NotFound {
+			return "", errNoDataKey
+		}
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read the data key of repository %s: %v", repository.Name, err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+func writeDataKey(repository *executor.Repository, wrapped string) error {
+	if repository.Type == storkv1.BackupLocationNFS {
+		dir := filepath.Join(repository.Path, repository.Name)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create repository dir %s: %v", dir, err)
+		}
+		// write and rename, so that the key is never partially written
+		tmpFile := filepath.Join(dir, dataKeyFile+".tmp")
+		if err := os.WriteFile(tmpFile, []byte(wrapped), 0600); err != nil {
+			return fmt.Errorf("failed to write the data key of repository %s: %v", repository.Name, err)
+		}
+		if err := os.Rename(tmpFile, filepath.Join(dir, dataKeyFile)); err != nil {
+			return fmt.Errorf("failed to write the data key of repository %s: %v", repository.Name, err)
+		}
+		return nil
+	}
+
+	bucket, err := dataKeyBucket(repository)
+	if err != nil {
+		return err
+	}
+	if err := bucket.WriteAll(context.TODO(), dataKeyFile, []byte(wrapped), nil); err != nil {
+		return fmt.Errorf("failed to write the data key of repository %s: %v", repository.Name, err)
+	}
+	return nil
+}
+
+// createDataKey stores the data key of a new repository. An existing data key
+// is never replaced.
+func createDataKey(repository *executor.Repository, wrapped string) error {
+	if repository.Type == storkv1.BackupLocationNFS {
+		dir := filepath.Join(repository.Path, repository.Name)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create repository dir %s: %v", dir, err)
+		}
+		// write and link, so that the key is never partially written and the
+		// link fails if the key exists
+		tmpFile := filepath.Join(dir, dataKeyFile+".tmp")
+		if err := os.WriteFile(tmpFile, []byte(wrapped), 0600); err != nil {
+			return fmt.Errorf("failed to write the data key of repository %s: %v", repository.Name, err)
+		}
+		defer os.Remove(tmpFile)
+		if err := os.Link(tmpFile, filepath.Join(dir, dataKeyFile)); err != nil {
+			return fmt.Errorf("failed to write the data key of repository %s: %v", repository.Name, err)
+		}
+		return nil
+	}
+
+	bucket, err := dataKeyBucket(repository)
+	if err != nil {
+		return err
+	}
+	exists, err := bucket.Exists(context.TODO(), dataKeyFile)
+	if err != nil {
+		return fmt.Errorf("failed to check the data key of repository %s: %v", repository.Name, err)
+	}
+	if exists {
+		return fmt.Errorf("repository %s already has a data key", repository.Name)
+	}
+	if err := bucket.WriteAll(context.TODO(), dataKeyFile, []byte(wrapped), nil); err != nil {
+		return fmt.Errorf("failed to write the data key of repository %s: %v", repository.Name, err)
+	}
+	return nil
+}
+
+func dataKeyBucket(repository *executor.Repository) (*blob.Bucket, error) {
+	bl, err := executor.BuildStorkBackupLocation(repository)
+	if err != nil {
+		return nil, err
+	}
+	bucket, err := objectstore.GetBucket(bl)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the bucket of repository %s: %v", repository.Name, err)
+	}
+	return blob.PrefixedBucket(bucket, repository.Name), nil
+}
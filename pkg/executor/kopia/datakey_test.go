@@ -0,0 +1,63 @@
+package kopia
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	storkv1 "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"github.com/portworx/kdmp/pkg/executor"
+	"github.com/portworx/kdmp/pkg/kms"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateDataKey(t *testing.T) {
+	repository := &executor.Repository{
+		Type: storkv1.BackupLocationNFS,
+		Path: t.TempDir(),
+		Name: "generic-backup/app-data/",
+	}
+	require.NoError(t, createDataKey(repository, "key1"))
+	wrapped, err := readDataKey(repository)
+	require.NoError(t, err)
+	require.Equal(t, "key1", wrapped)
+
+	// An existing key is never replaced
+	require.Error(t, createDataKey(repository, "key2"))
+	wrapped, err = readDataKey(repository)
+	require.NoError(t, err)
+	require.Equal(t, "key1", wrapped)
+	_, err = os.Stat(filepath.Join(repository.Path, repository.Name, dataKeyFile+".tmp"))
+	require.True(t, os.IsNotExist(err))
+}
+
+func TestSetupDataKeyPassword(t *testing.T) {
+	repository := &executor.Repository{
+		Type:             storkv1.BackupLocationNFS,
+		Path:             t.TempDir(),
+		Name:             "generic-backup/app-data/",
+		LocationPassword: "location",
+		DataKeys: kms.DataKeys{
+			"generic-backup/app-data": {Password: "datakey", Wrapped: "wrapped"},
+		},
+	}
+
+	// A new data key is only used for a new repository
+	wrapped, err := setupPassword(repository, false)
+	require.NoError(t, err)
+	require.Empty(t, wrapped)
+	require.Equal(t, "location", repository.Password)
+	wrapped, err = setupPassword(repository, true)
+	require.NoError(t, err)
+	require.Equal(t, "wrapped", wrapped)
+	require.Equal(t, "datakey", repository.Password)
+
+	// Once stored, the data key is used without being stored again
+	require.NoError(t, createDataKey(repository, wrapped))
+	repository.Password = ""
+	repository.DataKeys = kms.DataKeys{"generic-backup/app-data": {Password: "datakey"}}
+	wrapped, err = setupPassword(repository, true)
+	require.NoError(t, err)
+	require.Empty(t, wrapped)
+	require.Equal(t, "datakey", repository.Password)
+}
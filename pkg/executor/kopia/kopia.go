@@ -14,6 +14,8 @@ var (
 	credentials             string
 	backupLocationName      string
 	backupLocationNamespace string
+	maxUploadSpeed          int64
+	maxDownloadSpeed        int64
 )
 
 // NewCommand returns a kopia command wrapper
@@ -29,12 +31,17 @@ func NewCommand() *cobra.Command {
 	cmds.PersistentFlags().StringVar(&backupLocationName, "backup-location", "", "Name of the BackupLocation object, used for authentication")
 	cmds.PersistentFlags().StringVar(&backupLocationNamespace, "backup-location-namespace", "", "Namespace of BackupLocation object, used for authentication")
 	cmds.PersistentFlags().StringVar(&volumeBackupName, "volume-backup-name", "", "Provided VolumeBackup CRD will be updated with the latest backup progress details")
+	cmds.PersistentFlags().Int64Var(&maxUploadSpeed, "max-upload-speed", 0, "Maximum upload speed to the repository in bytes per second, unlimited if not set")
+	cmds.PersistentFlags().Int64Var(&maxDownloadSpeed, "max-download-speed", 0, "Maximum download speed from the repository in bytes per second, unlimited if not set")
 
 	cmds.AddCommand(
 		newBackupCommand(),
 		newRestoreCommand(),
 		newDeleteCommand(),
+		newVerifyCommand(),
+		newListFilesCommand(),
 		newMaintenanceCommand(),
+		newReplicateCommand(),
 	)
 	cmds.PersistentFlags().AddGoFlagSet(flag.CommandLine)
 	err := flag.CommandLine.Parse([]string{})
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"strconv"
 	"time"
 
 	storkv1 "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
@@ -15,12 +16,12 @@ import (
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"gocloud.dev/blob"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/kubectl/pkg/cmd/util"
 )
 
 const (
 	progressCheckInterval    = 5 * time.Second
+	restoreFileCheckInterval = 500 * time.Millisecond
 	genericBackupDir         = "generic-backup"
 	kopiaRepositoryFile      = "kopia.repository"
 	annualSnapshots          = "2147483647"
@@ -31,12 +32,17 @@ const (
 	latestSnapshots          = "2147483647"
 	azureChinaStorageDomain  = "blob.core.chinacloudapi.cn"
 	azurePublicStorageDomain = "blob.core.windows.net"
+	// liveSnapshotSource is used as the kopia snapshot source for the live
+	// backups, read from the pod volumes directory of the node, so that kopia
+	// finds the previous snapshot and doesn't read the unchanged files again.
+	liveSnapshotSource = "kdmp@live:/data"
 )
 
 var (
 	bkpNamespace    string
 	compression     string
 	excludeFileList string
+	liveBackup      bool
 )
 
 var (
@@ -52,11 +58,30 @@ func newBackupCommand() *cobra.Command {
 	var (
 		sourcePath     string
 		sourcePathGlob string
+		volumes        []string
+		workers        int
 	)
 	backupCommand := &cobra.Command{
 		Use:   "backup",
 		Short: "Start a kopia backup",
 		Run: func(c *cobra.Command, args []string) {
+			if len(volumes) > 0 {
+				if blockDevice != "" || sourcePath != "" || sourcePathGlob != "" || volumeBackupName != "" || liveBackup {
+					util.CheckErr(fmt.Errorf("--volume can't be used with --block-device, --source-path, --source-path-glob, --volume-backup-name or --live"))
+					return
+				}
+				executor.HandleErr(runBatchBackup(volumes, workers))
+				return
+			}
+			if blockDevice != "" && liveBackup {
+				util.CheckErr(fmt.Errorf("--block-device can't be used with --live"))
+				return
+			}
+			// In block mode the device is streamed to kopia, the source path
+			// is only the working dir of the backup command.
+			if blockDevice != "" {
+				sourcePath = os.TempDir()
+			}
 			srcPath, err := executor.GetSourcePath(sourcePath, sourcePathGlob)
 			if err != nil {
 				util.CheckErr(err)
@@ -71,10 +96,26 @@ func newBackupCommand() *cobra.Command {
 	backupCommand.Flags().StringVar(&sourcePathGlob, "source-path-glob", "", "The regexp should match only one path that will be used for backup")
 	backupCommand.Flags().StringVar(&compression, "compression", "", "Compression type to be used")
 	backupCommand.Flags().StringVar(&excludeFileList, "exclude-file-list", "", " list of dir names that need to be exclude in the kopia snapshot")
+	backupCommand.Flags().StringVar(&blockDevice, "block-device", "", "Raw block device to backup, only the data changed since the previous snapshot is uploaded")
+	backupCommand.Flags().StringArrayVar(&volumes, "volume", nil, "Volume to backup as <volume-backup-name>:<repository>:<source-path>, can be repeated to backup several volumes in a batch")
+	backupCommand.Flags().IntVar(&workers, "workers", 4, "Number of volumes of a batch backed up concurrently")
+	backupCommand.Flags().BoolVar(&liveBackup, "live", false, "The source path is the volume of a running pod, read from the pod volumes directory of its node")
 
 	return backupCommand
 }
 
+// backupVolume is a volume snapshotted by the backup command.
+type backupVolume struct {
+	// volumeBackupName is the VolumeBackup updated with the backup status.
+	volumeBackupName string
+	repository       *executor.Repository
+	sourcePath       string
+}
+
+func (v *backupVolume) writeStatus(status *executor.Status) error {
+	return executor.WriteVolumeBackupStatus(status, v.volumeBackupName, bkpNamespace)
+}
+
 func runBackup(sourcePath string) error {
 	// Parse using the mounted secrets
 	fn := "runBackup"
@@ -92,6 +133,7 @@ func runBackup(sourcePath string) error {
 		if err := executor.CreateVolumeBackup(
 			volumeBackupName,
 			bkpNamespace,
+			drivers.KopiaBackup,
 			repoName,
 			backupLocationName,
 			backupLocationNamespace,
@@ -110,6 +152,21 @@ func runBackup(sourcePath string) error {
 		}
 		return fmt.Errorf("parse backuplocation: %s", rErr)
 	}
+
+	return snapshotVolume(&backupVolume{
+		volumeBackupName: volumeBackupName,
+		repository:       repo,
+		sourcePath:       sourcePath,
+	})
+}
+
+// snapshotVolume creates the repository of the volume if needed, connects to
+// it and snapshots the volume.
+func snapshotVolume(vol *backupVolume) error {
+	fn := "snapshotVolume"
+	repo := vol.repository
+	sourcePath := vol.sourcePath
+
 	// kopia doesn't have a way to know if repository is already initialized.
 	// Repository create needs to run only first time.
 	// Check if kopia.repository exists
@@ -125,7 +182,7 @@ func runBackup(sourcePath string) error {
 	}
 	var exists = false
 	if storkv1.BackupLocationType(blType) != storkv1.BackupLocationNFS {
-		exists, err = isRepositoryExists(repo)
+		exists, err = isRepositoryExists(vol)
 		if err != nil {
 			errMsg := fmt.Sprintf("repository exists check for repo %s failed: %v", repo.Name, err)
 			logrus.Errorf("%s: %v", fn, errMsg)
@@ -133,13 +190,13 @@ func runBackup(sourcePath string) error {
 		}
 	}
 	if !exists {
-		if err = runKopiaCreateRepo(repo); err != nil {
+		if err = runKopiaCreateRepo(vol); err != nil {
 			errMsg := fmt.Sprintf("repository %s creation failed", repo.Name)
 			logrus.Errorf("%s: %v", fn, errMsg)
 			return fmt.Errorf("%s: %v", errMsg, err)
 		}
 
-		if err = setGlobalPolicy(); err != nil {
+		if err = setGlobalPolicy(vol); err != nil {
 			errMsg := fmt.Sprintf("setting global policy for repository %s failed: %v", repo.Name, err)
 			logrus.Errorf("%s: %v", fn, errMsg)
 			return fmt.Errorf(errMsg)
@@ -150,7 +207,7 @@ func runBackup(sourcePath string) error {
 		status := &executor.Status{
 			LastKnownError: err,
 		}
-		if err = executor.WriteVolumeBackupStatus(status, volumeBackupName, bkpNamespace); err != nil {
+		if err = vol.writeStatus(status); err != nil {
 			errMsg := fmt.Sprintf("failed to write a VolumeBackup status: %v", err)
 			logrus.Errorf("%v", errMsg)
 			return fmt.Errorf(errMsg)
@@ -161,7 +218,7 @@ func runBackup(sourcePath string) error {
 	}
 	// if compression is not set in config map, it means no need of enabling compression
 	if compression != "" {
-		if err = runKopiaCompression(repo, sourcePath); err != nil {
+		if err = runKopiaCompression(vol); err != nil {
 			errMsg := fmt.Sprintf("compression failed for path %s: %v", sourcePath, err)
 			logrus.Errorf("%s: %v", fn, errMsg)
 			return fmt.Errorf(errMsg)
@@ -169,15 +226,15 @@ func runBackup(sourcePath string) error {
 	}
 
 	// if excludeFileList is not set in config map, it means no need to exclude any dir in the snapshot.
-	if excludeFileList != "" {
-		if err = runKopiaExcludeFileList(repo, sourcePath); err != nil {
+	if excludeFileList != "" && blockDevice == "" {
+		if err = runKopiaExcludeFileList(vol); err != nil {
 			errMsg := fmt.Sprintf("setting exclude file list failed for path %s: %v", sourcePath, err)
 			logrus.Errorf("%s: %v", fn, errMsg)
 			return fmt.Errorf(errMsg)
 		}
 	}
 
-	if err = runKopiaBackup(repo, sourcePath); err != nil {
+	if err = runKopiaBackup(vol); err != nil {
 		errMsg := fmt.Sprintf("backup failed for repository %s: %v", repo.Name, err)
 		logrus.Errorf("%s: %v", fn, errMsg)
 		return fmt.Errorf(errMsg)
@@ -186,6 +243,20 @@ func runBackup(sourcePath string) error {
 	return nil
 }
 
+// populateSpeedLimits adds the throttling flags, which are stored by kopia in
+// the config file and then apply to all the commands of the repository.
+func populateSpeedLimits(cmd *kopia.Command) *kopia.Command {
+	if maxUploadSpeed > 0 {
+		cmd.AddFlag("--max-upload-speed")
+		cmd.AddFlag(strconv.FormatInt(maxUploadSpeed, 10))
+	}
+	if maxDownloadSpeed > 0 {
+		cmd.AddFlag("--max-download-speed")
+		cmd.AddFlag(strconv.FormatInt(maxDownloadSpeed, 10))
+	}
+	return cmd
+}
+
 func populateS3AccessDetails(initCmd *kopia.Command, repository *executor.Repository) *kopia.Command {
 	// kopia is not honouring env variabels set in the pod so passing them as flags
 	initCmd.AddArg("--endpoint")
@@ -206,8 +277,12 @@ func populateS3AccessDetails(initCmd *kopia.Command, repository *executor.Reposi
 }
 
 func populateGCEAccessDetails(initCmd *kopia.Command, repository *executor.Repository) *kopia.Command {
+	accountKeyPath := repository.GoogleConfig.AccountKeyPath
+	if accountKeyPath == "" {
+		accountKeyPath = executor.AccountKeyPath
+	}
 	initCmd.AddArg("--credentials-file")
-	initCmd.AddArg(executor.AccountKeyPath)
+	initCmd.AddArg(accountKeyPath)
 
 	return initCmd
 }
@@ -233,13 +308,26 @@ func populateAzureccessDetails(initCmd *kopia.Command, repository *executor.Repo
 	return initCmd
 }
 
-func runKopiaCreateRepo(repository *executor.Repository) error {
+func runKopiaCreateRepo(vol *backupVolume) error {
 	var err error
+	repository := vol.repository
 	var repoCreateCmd *kopia.Command
 
 	logrus.Infof("Repository creation started")
+	if err := validateObjectLock(repository); err != nil {
+		return err
+	}
+	wrapped, err := setupNewRepositoryPassword(repository)
+	if err != nil {
+		return err
+	}
 	switch repository.Type {
 	case storkv1.BackupLocationS3:
+		var retentionMode, retentionPeriod string
+		if lock := repository.S3Config.ObjectLock; lock != nil {
+			retentionMode = lock.Mode
+			retentionPeriod = lock.Period.String()
+		}
 		repoCreateCmd, err = kopia.GetCreateCommand(
 			repository.Path,
 			repository.Name,
@@ -247,6 +335,8 @@ func runKopiaCreateRepo(repository *executor.Repository) error {
 			kopiaProviderType[repository.Type],
 			repository.S3Config.Region,
 			repository.S3Config.DisableSSL,
+			retentionMode,
+			retentionPeriod,
 		)
 	default:
 		repoCreateCmd, err = kopia.GetCreateCommand(
@@ -256,6 +346,8 @@ func runKopiaCreateRepo(repository *executor.Repository) error {
 			kopiaProviderType[repository.Type],
 			"",
 			false,
+			"",
+			"",
 		)
 	}
 	if err != nil {
@@ -271,6 +363,8 @@ func runKopiaCreateRepo(repository *executor.Repository) error {
 	case storkv1.BackupLocationAzure:
 		repoCreateCmd = populateAzureccessDetails(repoCreateCmd, repository)
 	}
+	repoCreateCmd = populateSpeedLimits(repoCreateCmd)
+	repoCreateCmd.ConfigDir = repository.ConfigDir
 
 	initExecutor := kopia.NewCreateExecutor(repoCreateCmd)
 	if err := initExecutor.Run(); err != nil {
@@ -278,6 +372,7 @@ func runKopiaCreateRepo(repository *executor.Repository) error {
 		return err
 	}
 
+	var repoExists bool
 	t := func() (interface{}, bool, error) {
 		status, err := initExecutor.Status()
 		if err != nil {
@@ -285,25 +380,18 @@ func runKopiaCreateRepo(repository *executor.Repository) error {
 		}
 		if status.LastKnownError != nil {
 			if status.LastKnownError != kopia.ErrAlreadyRepoExist {
-				if err = executor.WriteVolumeBackupStatus(
-					status,
-					volumeBackupName,
-					bkpNamespace,
-				); err != nil {
+				if err = vol.writeStatus(status); err != nil {
 					errMsg := fmt.Sprintf("failed to write a VolumeBackup status: %v", err)
 					logrus.Errorf("%v", errMsg)
 					return "", true, fmt.Errorf(errMsg)
 				}
 				return "", true, status.LastKnownError
 			}
+			repoExists = true
 			status.LastKnownError = nil
 		}
 
-		if err = executor.WriteVolumeBackupStatus(
-			status,
-			volumeBackupName,
-			bkpNamespace,
-		); err != nil {
+		if err = vol.writeStatus(status); err != nil {
 			errMsg := fmt.Sprintf("failed to write a VolumeBackup status: %v", err)
 			logrus.Errorf("%v", errMsg)
 			return "", true, fmt.Errorf(errMsg)
@@ -318,23 +406,50 @@ func runKopiaCreateRepo(repository *executor.Repository) error {
 		logrus.Errorf("repository %s creation failed: %v", repository.Name, err)
 		return err
 	}
+	if repoExists {
+		// The repository was not created with the new data key, it is opened
+		// with its own key or the backuplocation password.
+		logrus.Infof("Repository %s already exists", repository.Name)
+		return setupRepositoryPassword(repository)
+	}
+	if wrapped != "" {
+		if err := createDataKey(repository, wrapped); err != nil {
+			logrus.Errorf("repository %s created but storing its data key failed: %v", repository.Name, err)
+			return err
+		}
+	}
 	logrus.Infof("Repository creation successful")
 
 	return nil
 }
 
-func runKopiaBackup(repository *executor.Repository, sourcePath string) error {
-	logrus.Infof("Backup started")
+func runKopiaBackup(vol *backupVolume) error {
+	logrus.Infof("Backup of %s started", vol.sourcePath)
+	repository := vol.repository
 	backupCmd, err := kopia.GetBackupCommand(
 		repository.Path,
 		repository.Name,
 		repository.Password,
 		string(repository.Type),
-		sourcePath,
+		vol.sourcePath,
 	)
 	if err != nil {
 		return err
 	}
+	backupCmd.ConfigDir = repository.ConfigDir
+	if blockDevice != "" {
+		dev, err := setBlockDeviceInput(backupCmd)
+		if err != nil {
+			if statusErr := vol.writeStatus(&executor.Status{LastKnownError: err}); statusErr != nil {
+				logrus.Errorf("failed to write a VolumeBackup status: %v", statusErr)
+			}
+			return err
+		}
+		defer dev.Close()
+	} else if liveBackup {
+		backupCmd.AddFlag("--override-source")
+		backupCmd.AddFlag(liveSnapshotSource)
+	}
 	// This is needed to handle case where after kopia repo create was successful and
 	// the pod got terminated. Now user triggers another backup, so we need to pass
 	// credentials for "snapshot create".
@@ -350,11 +465,10 @@ func runKopiaBackup(repository *executor.Repository, sourcePath string) error {
 		if err != nil {
 			return err
 		}
-		if err = executor.WriteVolumeBackupStatus(
-			status,
-			volumeBackupName,
-			bkpNamespace,
-		); err != nil {
+		if status.Done && status.LastKnownError == nil {
+			status.ObjectLockExpiry = backupLockExpiry(repository, status.Stats)
+		}
+		if err = vol.writeStatus(status); err != nil {
 			errMsg := fmt.Sprintf("failed to write a VolumeBackup status: %v", err)
 			logrus.Errorf("%v", errMsg)
 			continue
@@ -367,7 +481,7 @@ func runKopiaBackup(repository *executor.Repository, sourcePath string) error {
 		}
 
 	}
-	logrus.Infof("Backup successful")
+	logrus.Infof("Backup of %s successful", vol.sourcePath)
 	return nil
 }
 
@@ -375,6 +489,12 @@ func runKopiaRepositoryConnect(repository *executor.Repository) error {
 	var err error
 	var connectCmd *kopia.Command
 	logrus.Infof("Repository connect started")
+	if err := validateObjectLock(repository); err != nil {
+		return err
+	}
+	if err := setupRepositoryPassword(repository); err != nil {
+		return err
+	}
 	switch repository.Type {
 	case storkv1.BackupLocationS3:
 		connectCmd, err = kopia.GetConnectCommand(
@@ -407,6 +527,8 @@ func runKopiaRepositoryConnect(repository *executor.Repository) error {
 	case storkv1.BackupLocationAzure:
 		connectCmd = populateAzureccessDetails(connectCmd, repository)
 	}
+	connectCmd = populateSpeedLimits(connectCmd)
+	connectCmd.ConfigDir = repository.ConfigDir
 	connectExecutor := kopia.NewConnectExecutor(connectCmd)
 	if err := connectExecutor.Run(); err != nil {
 		err = fmt.Errorf("failed to run repository connect  command: %v", err)
@@ -436,12 +558,13 @@ func runKopiaRepositoryConnect(repository *executor.Repository) error {
 	return nil
 }
 
-func setGlobalPolicy() error {
+func setGlobalPolicy(vol *backupVolume) error {
 	logrus.Infof("Setting global policy")
 	policyCmd, err := kopia.SetGlobalPolicyCommand()
 	if err != nil {
 		return err
 	}
+	policyCmd.ConfigDir = vol.repository.ConfigDir
 	// As we don't want kopia maintenance to kick in and trigger global policy on default values
 	// for the repository, setting them to very high values
 	policyCmd = addPolicySetting(policyCmd)
@@ -458,11 +581,7 @@ func setGlobalPolicy() error {
 			return "", false, err
 		}
 		if status.LastKnownError != nil {
-			if err = executor.WriteVolumeBackupStatus(
-				status,
-				volumeBackupName,
-				bkpNamespace,
-			); err != nil {
+			if err = vol.writeStatus(status); err != nil {
 				errMsg := fmt.Sprintf("failed to write a VolumeBackup status: %v", err)
 				logrus.Errorf("%v", errMsg)
 				return "", true, fmt.Errorf(errMsg)
@@ -486,8 +605,9 @@ func setGlobalPolicy() error {
 	return nil
 }
 
-func runKopiaExcludeFileList(repository *executor.Repository, sourcePath string) error {
+func runKopiaExcludeFileList(vol *backupVolume) error {
 	logrus.Infof("setting exclude file list for the snapshot")
+	sourcePath := policySource(vol)
 	excludeFileListCmd, err := kopia.GetExcludeFileListCommand(
 		sourcePath,
 		excludeFileList,
@@ -495,6 +615,7 @@ func runKopiaExcludeFileList(repository *executor.Repository, sourcePath string)
 	if err != nil {
 		return err
 	}
+	excludeFileListCmd.ConfigDir = vol.repository.ConfigDir
 	excludeFileListExecutor := kopia.NewExcludeFileListExecutor(excludeFileListCmd)
 	if err := excludeFileListExecutor.Run(); err != nil {
 		err = fmt.Errorf("failed to run exclude file list command: %v", err)
@@ -506,11 +627,7 @@ func runKopiaExcludeFileList(repository *executor.Repository, sourcePath string)
 			return "", true, err
 		}
 		if status.LastKnownError != nil {
-			if err = executor.WriteVolumeBackupStatus(
-				status,
-				volumeBackupName,
-				bkpNamespace,
-			); err != nil {
+			if err = vol.writeStatus(status); err != nil {
 				errMsg := fmt.Sprintf("failed to write a VolumeBackup status: %v", err)
 				logrus.Errorf("%v", errMsg)
 				return "", true, fmt.Errorf(errMsg)
@@ -532,8 +649,9 @@ func runKopiaExcludeFileList(repository *executor.Repository, sourcePath string)
 	return nil
 }
 
-func runKopiaCompression(repository *executor.Repository, sourcePath string) error {
+func runKopiaCompression(vol *backupVolume) error {
 	logrus.Infof("Compression started")
+	sourcePath := policySource(vol)
 	compressionCmd, err := kopia.GetCompressionCommand(
 		sourcePath,
 		compression,
@@ -541,6 +659,7 @@ func runKopiaCompression(repository *executor.Repository, sourcePath string) err
 	if err != nil {
 		return err
 	}
+	compressionCmd.ConfigDir = vol.repository.ConfigDir
 	compressionExecutor := kopia.NewCompressionExecutor(compressionCmd)
 	if err := compressionExecutor.Run(); err != nil {
 		err = fmt.Errorf("failed to run compression command: %v", err)
@@ -552,11 +671,7 @@ func runKopiaCompression(repository *executor.Repository, sourcePath string) err
 			return "", true, err
 		}
 		if status.LastKnownError != nil {
-			if err = executor.WriteVolumeBackupStatus(
-				status,
-				volumeBackupName,
-				bkpNamespace,
-			); err != nil {
+			if err = vol.writeStatus(status); err != nil {
 				errMsg := fmt.Sprintf("failed to write a VolumeBackup status: %v", err)
 				logrus.Errorf("%v", errMsg)
 				return "", true, fmt.Errorf(errMsg)
@@ -578,53 +693,28 @@ func runKopiaCompression(repository *executor.Repository, sourcePath string) err
 	return nil
 }
 
+// policySource returns the kopia source the policies of a volume snapshot are
+// set on.
+func policySource(vol *backupVolume) string {
+	if liveBackup {
+		return liveSnapshotSource
+	}
+	return vol.sourcePath
+}
+
 // Under backuplocation path, following path would be created
 // <bucket>/generic-backup/<ns - pvc>
 func frameBackupPath() string {
-	return genericBackupDir + "/" + kopiaRepo + "/"
+	return repositoryPath(kopiaRepo)
 }
 
-func buildStorkBackupLocation(repository *executor.Repository) (*storkv1.BackupLocation, error) {
-	var backupType storkv1.BackupLocationType
-	backupLocation := &storkv1.BackupLocation{
-		ObjectMeta: metav1.ObjectMeta{},
-		Location:   storkv1.BackupLocationItem{},
-	}
-
-	switch repository.Type {
-	case storkv1.BackupLocationS3:
-		backupType = storkv1.BackupLocationS3
-		backupLocation.Location.S3Config = &storkv1.S3Config{
-			AccessKeyID:     repository.S3Config.AccessKeyID,
-			SecretAccessKey: repository.S3Config.SecretAccessKey,
-			Endpoint:        repository.S3Config.Endpoint,
-			Region:          repository.S3Config.Region,
-			DisableSSL:      repository.S3Config.DisableSSL,
-		}
-	case storkv1.BackupLocationGoogle:
-		backupType = storkv1.BackupLocationGoogle
-		backupLocation.Location.GoogleConfig = &storkv1.GoogleConfig{
-			ProjectID:  repository.GoogleConfig.ProjectID,
-			AccountKey: repository.GoogleConfig.AccountKey,
-		}
-	case storkv1.BackupLocationAzure:
-		backupType = storkv1.BackupLocationAzure
-		backupLocation.Location.AzureConfig = &storkv1.AzureConfig{
-			StorageAccountName: repository.AzureConfig.StorageAccountName,
-			StorageAccountKey:  repository.AzureConfig.StorageAccountKey,
-			Environment:        storkv1.AzureEnvironment(repository.AzureConfig.Environment),
-		}
-	}
-
-	backupLocation.Location.Path = repository.Path
-	backupLocation.ObjectMeta.Name = repository.Name
-	backupLocation.Location.Type = backupType
-
-	return backupLocation, nil
+func repositoryPath(repoName string) string {
+	return genericBackupDir + "/" + repoName + "/"
 }
 
-func isRepositoryExists(repository *executor.Repository) (bool, error) {
-	bl, err := buildStorkBackupLocation(repository)
+func isRepositoryExists(vol *backupVolume) (bool, error) {
+	repository := vol.repository
+	bl, err := executor.BuildStorkBackupLocation(repository)
 	if err != nil {
 		logrus.Errorf("%v", err)
 		return false, err
@@ -637,7 +727,7 @@ func isRepositoryExists(repository *executor.Repository) (bool, error) {
 			status := &executor.Status{
 				LastKnownError: repoExistsError,
 			}
-			if err = executor.WriteVolumeBackupStatus(status, volumeBackupName, bkpNamespace); err != nil {
+			if err = vol.writeStatus(status); err != nil {
 				errMsg := fmt.Sprintf("failed to write a VolumeBackup status: %v", err)
 				logrus.Errorf("%v", errMsg)
 				return "", true, fmt.Errorf(errMsg)
@@ -651,7 +741,7 @@ func isRepositoryExists(repository *executor.Repository) (bool, error) {
 			status := &executor.Status{
 				LastKnownError: repoExistsError,
 			}
-			if err = executor.WriteVolumeBackupStatus(status, volumeBackupName, bkpNamespace); err != nil {
+			if err = vol.writeStatus(status); err != nil {
 				errMsg := fmt.Sprintf("failed to write a VolumeBackup status: %v", err)
 				logrus.Errorf("%v", errMsg)
 				return "", true, fmt.Errorf(errMsg)
@@ -10,6 +10,7 @@ import (
 	kdmpapi "github.com/portworx/kdmp/pkg/apis/kdmp/v1alpha1"
 	"github.com/portworx/kdmp/pkg/executor"
 	"github.com/portworx/kdmp/pkg/kopia"
+	kdmpShedOps "github.com/portworx/sched-ops/k8s/kdmp"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 )
@@ -82,7 +83,7 @@ func runDelete(snapshotID, volumeBackupDeleteName, volumeBackupDeleteNamespace s
 		return fmt.Errorf(errMsg)
 	}
 
-	snapshotList, err := runKopiaSnapshotList(repo)
+	snapshots, endTimes, err := runKopiaSnapshotTimes(repo)
 	if err != nil {
 		errMsg := fmt.Sprintf("snapshot list failed: %v", err)
 		logrus.Errorf("%s: %v", fn, errMsg)
@@ -94,19 +95,29 @@ func runDelete(snapshotID, volumeBackupDeleteName, volumeBackupDeleteNamespace s
 		return fmt.Errorf(errMsg)
 	}
 
-	var snapshotIdFound bool
-	for _, data := range snapshotList {
-		if data == snapshotID {
-			snapshotIdFound = true
-			break
-		}
-	}
-
-	if !snapshotIdFound {
+	if _, snapshotIdFound := snapshots[snapshotID]; !snapshotIdFound {
 		logrus.Warnf("the snapshot ID [%v] does not exist in the backup location and thus cannot be deleted", snapshotID)
 		return nil
 	}
 
+	vd, err := kdmpShedOps.Instance().GetVolumeBackupDelete(volumeBackupDeleteName, volumeBackupDeleteNamespace)
+	if err != nil {
+		errMsg := fmt.Sprintf("failed in getting VolumeBackupDelete CR [%s:%s]: %v", volumeBackupDeleteName, volumeBackupDeleteNamespace, err)
+		logrus.Errorf("%s: %v", fn, errMsg)
+		return fmt.Errorf(errMsg)
+	}
+	expiry := snapshotExpiry(repo, vd.Spec.ObjectLockExpiry, endTimes[snapshotID])
+	if time.Now().Before(expiry) {
+		errMsg := fmt.Sprintf("snapshot [%v] is object-locked until %v", snapshotID, expiry.UTC().Format(time.RFC3339))
+		logrus.Errorf("%s: %v", fn, errMsg)
+		if err := executor.WriteVolumeBackupDeleteStatus(kdmpapi.VolumeBackupDeleteStatusLocked, errMsg, volumeBackupDeleteName, volumeBackupDeleteNamespace); err != nil {
+			errMsg := fmt.Sprintf("failed in updating VolumeBackupDelete CR [%s:%s]: %v", volumeBackupDeleteName, volumeBackupDeleteNamespace, err)
+			logrus.Errorf("%v", errMsg)
+			return fmt.Errorf(errMsg)
+		}
+		return fmt.Errorf(errMsg)
+	}
+
 	if err := runKopiaDelete(repo, snapshotID); err != nil {
 		errMsg := fmt.Sprintf("snapshot [%v] delete failed: %v", snapshotID, err)
 		logrus.Errorf("%s: %v", fn, errMsg)
@@ -157,16 +168,33 @@ func runKopiaDelete(repository *executor.Repository, snapshotID string) error {
 	return nil
 }
 
-func runKopiaSnapshotList(repository *executor.Repository) ([]string, error) {
+// runKopiaSnapshotList returns the start time of the snapshots of the
+// repository by snapshot ID.
+func runKopiaSnapshotList(repository *executor.Repository) (map[string]time.Time, error) {
+	startTimes, _, err := runKopiaSnapshotTimes(repository)
+	return startTimes, err
+}
+
+// runKopiaSnapshotTimes returns the start and end time of the snapshots of the
+// repository by snapshot ID.
+func runKopiaSnapshotTimes(repository *executor.Repository) (map[string]time.Time, map[string]time.Time, error) {
 	var err error
 	var listCmd *kopia.Command
 	logrus.Infof("Executing kopia snapshot list command")
 	listCmd, err = kopia.GetListCommand()
 
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
+	status, err := runKopiaListCommand(listCmd)
+	if err != nil {
+		return nil, nil, err
+	}
+	return status.SnapshotStartTimes, status.SnapshotEndTimes, nil
+}
+
+func runKopiaListCommand(listCmd *kopia.Command) (*executor.Status, error) {
 	listExecutor := kopia.NewListExecutor(listCmd)
 	if err := listExecutor.Run(); err != nil {
 		err = fmt.Errorf("failed to run snapshot list command: %v", err)
@@ -185,7 +213,7 @@ func runKopiaSnapshotList(repository *executor.Repository) ([]string, error) {
 
 		if status.Done {
 			logrus.Infof("kopia snapshot list command executed successfully")
-			return status.SnapshotIDs, nil
+			return status, nil
 		}
 	}
 }
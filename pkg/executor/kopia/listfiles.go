@@ -0,0 +1,157 @@
+package kopia
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/portworx/kdmp/pkg/drivers/utils"
+	"github.com/portworx/kdmp/pkg/executor"
+	"github.com/portworx/kdmp/pkg/kopia"
+	coreops "github.com/portworx/sched-ops/k8s/core"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kubectl/pkg/cmd/util"
+)
+
+const (
+	listFilesKey          = "files"
+	listFilesSnapshotKey  = "snapshotID"
+	listFilesTruncatedKey = "truncated"
+	// maxListFilesSize keeps the listing below the 1MiB object size limit.
+	maxListFilesSize = 900 * 1024
+)
+
+func newListFilesCommand() *cobra.Command {
+	var (
+		snapshotID         string
+		dirPath            string
+		configMapName      string
+		configMapNamespace string
+	)
+	listFilesCommand := &cobra.Command{
+		Use:   "listfiles",
+		Short: "list the files of a backup snapshot",
+		Run: func(c *cobra.Command, args []string) {
+			if len(snapshotID) == 0 {
+				util.CheckErr(fmt.Errorf("snapshot-id has to be provided for kopia listfiles"))
+				return
+			}
+			if len(configMapName) == 0 || len(configMapNamespace) == 0 {
+				util.CheckErr(fmt.Errorf("configmap-name and configmap-namespace have to be provided for kopia listfiles"))
+				return
+			}
+			executor.HandleErr(runListFiles(snapshotID, dirPath, configMapName, configMapNamespace))
+		},
+	}
+	listFilesCommand.Flags().StringVar(&snapshotID, "snapshot-id", "", "Snapshot id of the backup to be listed")
+	listFilesCommand.Flags().StringVar(&dirPath, "path", "", "Directory of the snapshot to be listed, the whole snapshot is listed if not set")
+	listFilesCommand.Flags().StringVar(&configMapName, "configmap-name", "", "Name of the ConfigMap the listing is written to")
+	listFilesCommand.Flags().StringVar(&configMapNamespace, "configmap-namespace", "", "Namespace of the ConfigMap the listing is written to")
+	return listFilesCommand
+}
+
+func runListFiles(snapshotID, dirPath, configMapName, configMapNamespace string) error {
+	fn := "runListFiles:"
+	// Parse using the mounted secrets
+	repo, err := executor.ParseCloudCred()
+	if err != nil {
+		errMsg := fmt.Sprintf("parse backuplocation failed: %s", err)
+		logrus.Errorf("%s %v", fn, errMsg)
+		return fmt.Errorf(errMsg)
+	}
+	repo.Name = kopiaRepo
+
+	if err := runKopiaRepositoryConnect(repo); err != nil {
+		errMsg := fmt.Sprintf("repository [%v] connect failed: %v", repo.Name, err)
+		logrus.Errorf("%s %v", fn, errMsg)
+		return fmt.Errorf(errMsg)
+	}
+
+	entries, err := runKopiaListFiles(snapshotID, dirPath)
+	if err != nil {
+		errMsg := fmt.Sprintf("listing files of snapshot [%v] failed: %v", snapshotID, err)
+		logrus.Errorf("%s %v", fn, errMsg)
+		return fmt.Errorf(errMsg)
+	}
+
+	if err := writeListFiles(entries, snapshotID, configMapName, configMapNamespace); err != nil {
+		errMsg := fmt.Sprintf("writing files of snapshot [%v] to configmap [%v/%v] failed: %v", snapshotID, configMapNamespace, configMapName, err)
+		logrus.Errorf("%s %v", fn, errMsg)
+		return fmt.Errorf(errMsg)
+	}
+	logrus.Infof("%s listed %d entries of snapshot [%v] in configmap [%v/%v]", fn, len(entries), snapshotID, configMapNamespace, configMapName)
+	return nil
+}
+
+func runKopiaListFiles(snapshotID, dirPath string) ([]string, error) {
+	listCmd, err := kopia.GetListFilesCommand(snapshotID, dirPath)
+	if err != nil {
+		return nil, err
+	}
+
+	listExecutor := kopia.NewListFilesExecutor(listCmd)
+	if err := listExecutor.Run(); err != nil {
+		return nil, fmt.Errorf("failed to run ls command: %v", err)
+	}
+	for {
+		time.Sleep(progressCheckInterval)
+		status, err := listExecutor.Status()
+		if err != nil {
+			return nil, err
+		}
+		if status.LastKnownError != nil {
+			return nil, status.LastKnownError
+		}
+		if status.Done {
+			return status.Entries, nil
+		}
+	}
+}
+
+// writeListFiles stores the entries, one per line, in the ConfigMap. The
+// listing is truncated if it does not fit in a ConfigMap.
+func writeListFiles(entries []string, snapshotID, name, namespace string) error {
+	var b strings.Builder
+	truncated := false
+	for _, entry := range entries {
+		if b.Len()+len(entry)+1 > maxListFilesSize {
+			truncated = true
+			break
+		}
+		b.WriteString(entry)
+		b.WriteString("\n")
+	}
+	if truncated {
+		logrus.Warnf("listing of snapshot [%v] is truncated to %d bytes", snapshotID, b.Len())
+	}
+	data := map[string]string{
+		listFilesKey:          b.String(),
+		listFilesSnapshotKey:  snapshotID,
+		listFilesTruncatedKey: fmt.Sprintf("%t", truncated),
+	}
+
+	cm, err := coreops.Instance().GetConfigMap(name, namespace)
+	if apierrors.IsNotFound(err) {
+		_, err = coreops.Instance().CreateConfigMap(&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+				Annotations: map[string]string{
+					utils.SkipResourceAnnotation: "true",
+				},
+			},
+			Data: data,
+		})
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	cm.Data = data
+	_, err = coreops.Instance().UpdateConfigMap(cm)
+	return err
+}
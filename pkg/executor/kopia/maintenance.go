@@ -6,6 +6,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	storkapi "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
@@ -27,10 +28,13 @@ var (
 )
 
 const (
-	fullMaintenanceType    = "full"
-	quickMaintenaceTye     = "quick"
-	cacheDir               = "/tmp"
-	kopiaNFSRepositoryFile = "kopia.repository.f"
+	fullMaintenanceType = "full"
+	quickMaintenaceTye  = "quick"
+	// keyRotationMaintenanceType rewraps the repository data keys with the
+	// current key of the BackupLocation KMS.
+	keyRotationMaintenanceType = "key-rotation"
+	cacheDir                   = "/tmp"
+	kopiaNFSRepositoryFile     = "kopia.repository.f"
 )
 
 func newMaintenanceCommand() *cobra.Command {
@@ -50,7 +54,9 @@ func newMaintenanceCommand() *cobra.Command {
 	maintenanceCommand.Flags().StringVar(&credSecretNamespace, "cred-secret-namespace", "", "cred secret namespace for the repository to run maintenance command")
 	maintenanceCommand.Flags().StringVar(&maintenanceStatusName, "maintenance-status-name", "", "backuplocation maintenance status CR name, where repo maintenance status will be stored")
 	maintenanceCommand.Flags().StringVar(&maintenanceStatusNamespace, "maintenance-status-namespace", "", "backuplocation maintenance status CR namespace, where repo maintenance status will be stored")
-	maintenanceCommand.Flags().StringVar(&maintenanceType, "maintenance-type", "", "full - will run full maintenance and quick - will run quick maintenance")
+	maintenanceCommand.Flags().StringVar(&maintenanceType, "maintenance-type", "", "full - will run full maintenance, quick - will run quick maintenance, key-rotation - will rotate the repository data keys, repository-status - will report the repository usage and orphan-gc - will report the orphaned repositories and snapshots")
+	maintenanceCommand.Flags().BoolVar(&orphanGCDelete, "orphan-gc-delete", false, "orphan-gc deletes the orphaned repositories and snapshots older than the grace period")
+	maintenanceCommand.Flags().DurationVar(&orphanGCGracePeriod, "orphan-gc-grace-period", 0, "age under which the orphaned repositories and snapshots are not deleted, required with --orphan-gc-delete")
 	return maintenanceCommand
 }
 
@@ -107,6 +113,11 @@ func updateBackupLocationMaintenace(
 			backupLocationMaintenance.Status.FullMaintenanceRepoStatus = make(map[string]kdmp_api.RepoMaintenanceStatus)
 		}
 		backupLocationMaintenance.Status.FullMaintenanceRepoStatus[repoName] = repoStatus
+	} else if maintenanceType == keyRotationMaintenanceType {
+		if backupLocationMaintenance.Status.KeyRotationRepoStatus == nil {
+			backupLocationMaintenance.Status.KeyRotationRepoStatus = make(map[string]kdmp_api.RepoMaintenanceStatus)
+		}
+		backupLocationMaintenance.Status.KeyRotationRepoStatus[repoName] = repoStatus
 	} else {
 		if backupLocationMaintenance.Status.QuickMaintenanceRepoStatus == nil {
 			backupLocationMaintenance.Status.QuickMaintenanceRepoStatus = make(map[string]kdmp_api.RepoMaintenanceStatus)
@@ -156,7 +167,7 @@ func runMaintenance(maintenanceType string) error {
 			}
 		}
 	} else {
-		bl, err := buildStorkBackupLocation(repo)
+		bl, err := executor.BuildStorkBackupLocation(repo)
 		if err != nil {
 			logrus.Errorf("%v", err)
 			return err
@@ -181,8 +192,27 @@ func runMaintenance(maintenanceType string) error {
 		}
 	}
 
+	if maintenanceType == orphanGCMaintenanceType {
+		return runOrphanGCMaintenance(repo, repoList)
+	}
+	var repoNamespaces map[string][]string
+	if maintenanceType == repositoryStatusMaintenanceType {
+		var err error
+		if repoNamespaces, err = getRepositoryNamespaces(); err != nil {
+			logrus.Errorf("%s %v", fn, err)
+			return err
+		}
+	}
 	for _, repoName := range repoList {
 		repo.Name = getBackupPathWithRepoName(repoName)
+		if maintenanceType == keyRotationMaintenanceType {
+			runKeyRotation(repo)
+			continue
+		}
+		if maintenanceType == repositoryStatusMaintenanceType {
+			runRepositoryStatus(repo, repoNamespaces[strings.Trim(repo.Name, "/")])
+			continue
+		}
 		if err := runKopiaRepositoryConnect(repo); err != nil {
 			errMsg := fmt.Sprintf("repository [%v] connect failed: %v", repo.Name, err)
 			logrus.Errorf("%s: %v", fn, errMsg)
@@ -246,6 +276,25 @@ func runMaintenance(maintenanceType string) error {
 	return nil
 }
 
+func runKeyRotation(repository *executor.Repository) {
+	fn := "runKeyRotation:"
+	status := kdmp_api.RepoMaintenanceStatusSuccess
+	var reason string
+	if err := rotateDataKey(repository); err == errNoDataKey {
+		reason = "repository has no data key, it uses the backuplocation password"
+		logrus.Infof("%s skipping repo [%v]: %v", fn, repository.Name, reason)
+	} else if err != nil {
+		logrus.Errorf("%s data key rotation failed for repo [%v]: %v", fn, repository.Name, err)
+		status = kdmp_api.RepoMaintenanceStatusFailed
+		reason = err.Error()
+	} else {
+		logrus.Infof("%s data key rotation completed successfully for repository [%v]", fn, repository.Name)
+	}
+	if statusErr := updateBackupLocationMaintenace(keyRotationMaintenanceType, status, repository.Name, reason); statusErr != nil {
+		logrus.Warnf("update of %s maintenance status for repo [%v] failed: %v", keyRotationMaintenanceType, repository.Name, statusErr)
+	}
+}
+
 func returnDirList(parentDir string) ([]string, error) {
 	var files []string
 	fileInfo, err := os.ReadDir(parentDir)
@@ -347,6 +396,13 @@ func runKopiaMaintenanceSet(repository *executor.Repository) error {
 		logrus.Errorf("%s %v", fn, errMsg)
 		return fmt.Errorf(errMsg)
 	}
+	if repository.Type == storkapi.BackupLocationS3 && repository.S3Config.ObjectLock != nil {
+		// Extend the locks of the blobs still in use at each full maintenance,
+		// the blobs of the deleted snapshots are left to expire. The snapshot
+		// deletions only wait for the expiry recorded at backup time, the
+		// blobs shared with newer snapshots stay locked as long as those.
+		maintenanceSetCmd.AddFlag("--extend-object-locks=true")
+	}
 	initExecutor := kopia.NewMaintenanceSetExecutor(maintenanceSetCmd)
 	if err := initExecutor.Run(); err != nil {
 		errMsg := fmt.Sprintf("running maintenance set command for failed: %v", err)
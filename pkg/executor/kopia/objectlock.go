@@ -0,0 +1,112 @@
+package kopia
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	awscreds "github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	storkv1 "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	kdmpapi "github.com/portworx/kdmp/pkg/apis/kdmp/v1alpha1"
+	"github.com/portworx/kdmp/pkg/executor"
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const amazonS3Endpoint = "s3.amazonaws.com"
+
+// validateObjectLock checks that the bucket of an object-locked repository
+// has versioning and object-lock enabled. Without them kopia would write
+// blobs that can be deleted or overwritten.
+func validateObjectLock(repository *executor.Repository) error {
+	if repository.Type != storkv1.BackupLocationS3 || repository.S3Config.ObjectLock == nil {
+		return nil
+	}
+	client, err := s3Client(repository.S3Config)
+	if err != nil {
+		return fmt.Errorf("failed to get the s3 client of bucket %s: %v", repository.Path, err)
+	}
+	if err := checkBucketObjectLock(client, repository.Path); err != nil {
+		logrus.Errorf("validateObjectLock: %v", err)
+		return err
+	}
+	return nil
+}
+
+func checkBucketObjectLock(client s3iface.S3API, bucket string) error {
+	versioning, err := client.GetBucketVersioning(&s3.GetBucketVersioningInput{
+		Bucket: aws.String(bucket),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get the versioning of bucket %s: %v", bucket, err)
+	}
+	if aws.StringValue(versioning.Status) != s3.BucketVersioningStatusEnabled {
+		return fmt.Errorf("bucket %s is configured with object-lock retention but its versioning is not enabled", bucket)
+	}
+	lock, err := client.GetObjectLockConfiguration(&s3.GetObjectLockConfigurationInput{
+		Bucket: aws.String(bucket),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get the object-lock configuration of bucket %s: %v", bucket, err)
+	}
+	if lock.ObjectLockConfiguration == nil ||
+		aws.StringValue(lock.ObjectLockConfiguration.ObjectLockEnabled) != s3.ObjectLockEnabledEnabled {
+		return fmt.Errorf("bucket %s is configured with object-lock retention but its object-lock is not enabled", bucket)
+	}
+	return nil
+}
+
+func s3Client(config *executor.S3Config) (s3iface.S3API, error) {
+	// the AWS SDK picks the endpoint of the region if none is set
+	endpoint := config.Endpoint
+	if endpoint == amazonS3Endpoint {
+		endpoint = ""
+	}
+	sess, err := session.NewSession(&aws.Config{
+		Endpoint:         aws.String(endpoint),
+		Credentials:      awscreds.NewStaticCredentials(config.AccessKeyID, config.SecretAccessKey, ""),
+		Region:           aws.String(config.Region),
+		DisableSSL:       aws.Bool(config.DisableSSL),
+		S3ForcePathStyle: aws.Bool(true),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s3.New(sess), nil
+}
+
+// snapshotExpiry returns the time the object-lock retention of a snapshot
+// expires at, zero if the repository is not object-locked. The expiry recorded
+// on the VolumeBackup at backup time is used if known, the other snapshots get
+// the same one from their end time. The end time of an incomplete snapshot is
+// zero, its blobs may have been written up to now.
+func snapshotExpiry(repository *executor.Repository, recorded *metav1.Time, endTime time.Time) time.Time {
+	if recorded != nil {
+		return recorded.Time
+	}
+	if repository.Type != storkv1.BackupLocationS3 || repository.S3Config.ObjectLock == nil {
+		return time.Time{}
+	}
+	if endTime.IsZero() {
+		endTime = time.Now()
+	}
+	return repository.S3Config.ObjectLock.Expiry(endTime)
+}
+
+// backupLockExpiry returns the object-lock expiry to record on the VolumeBackup
+// of a completed backup, nil if the repository is not object-locked.
+func backupLockExpiry(repository *executor.Repository, stats *kdmpapi.TransferStats) *metav1.Time {
+	endTime := time.Now()
+	if stats != nil && stats.EndTime != nil {
+		endTime = stats.EndTime.Time
+	}
+	expiry := snapshotExpiry(repository, nil, endTime)
+	if expiry.IsZero() {
+		return nil
+	}
+	t := metav1.NewTime(expiry)
+	return &t
+}
@@ -0,0 +1,80 @@
+package kopia
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	storkv1 "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	kdmpapi "github.com/portworx/kdmp/pkg/apis/kdmp/v1alpha1"
+	"github.com/portworx/kdmp/pkg/drivers/utils"
+	"github.com/portworx/kdmp/pkg/executor"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// fakeS3 serves the configuration of a single bucket, the other methods are
+// not used.
+type fakeS3 struct {
+	s3iface.S3API
+	versioning string
+	objectLock string
+}
+
+func (f *fakeS3) GetBucketVersioning(*s3.GetBucketVersioningInput) (*s3.GetBucketVersioningOutput, error) {
+	return &s3.GetBucketVersioningOutput{Status: aws.String(f.versioning)}, nil
+}
+
+func (f *fakeS3) GetObjectLockConfiguration(*s3.GetObjectLockConfigurationInput) (*s3.GetObjectLockConfigurationOutput, error) {
+	if f.objectLock == "" {
+		return &s3.GetObjectLockConfigurationOutput{}, nil
+	}
+	return &s3.GetObjectLockConfigurationOutput{
+		ObjectLockConfiguration: &s3.ObjectLockConfiguration{ObjectLockEnabled: aws.String(f.objectLock)},
+	}, nil
+}
+
+func TestCheckBucketObjectLock(t *testing.T) {
+	client := &fakeS3{}
+	err := checkBucketObjectLock(client, "bucket")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "versioning is not enabled")
+
+	client.versioning = s3.BucketVersioningStatusEnabled
+	err = checkBucketObjectLock(client, "bucket")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "object-lock is not enabled")
+
+	client.objectLock = s3.ObjectLockEnabledEnabled
+	require.NoError(t, checkBucketObjectLock(client, "bucket"))
+}
+
+func TestSnapshotExpiry(t *testing.T) {
+	repository := &executor.Repository{
+		Type: storkv1.BackupLocationS3,
+		S3Config: &executor.S3Config{
+			ObjectLock: &utils.ObjectLock{Mode: utils.ObjectLockModeCompliance, Period: 72 * time.Hour},
+		},
+	}
+	end := time.Now().Add(-time.Hour).Truncate(time.Second)
+
+	// The expiry recorded at backup time is the one computed from the end time
+	recorded := backupLockExpiry(repository, &kdmpapi.TransferStats{EndTime: &metav1.Time{Time: end}})
+	require.NotNil(t, recorded)
+	require.True(t, recorded.Equal(&metav1.Time{Time: end.Add(72 * time.Hour)}))
+	require.True(t, snapshotExpiry(repository, nil, end).Equal(recorded.Time))
+
+	// The recorded expiry has precedence
+	later := metav1.NewTime(end.Add(100 * time.Hour))
+	require.True(t, snapshotExpiry(repository, &later, end).Equal(later.Time))
+
+	// An incomplete snapshot may have blobs written up to now
+	require.True(t, snapshotExpiry(repository, nil, time.Time{}).After(end.Add(72*time.Hour)))
+
+	// Never locked if the repository is not object-locked
+	repository.S3Config.ObjectLock = nil
+	require.True(t, snapshotExpiry(repository, nil, end).IsZero())
+	require.Nil(t, backupLockExpiry(repository, nil))
+}
@@ -0,0 +1,419 @@
+package kopia
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	storkapi "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"github.com/libopenstorage/stork/pkg/objectstore"
+	kdmp_api "github.com/portworx/kdmp/pkg/apis/kdmp/v1alpha1"
+	"github.com/portworx/kdmp/pkg/executor"
+	kdmpops "github.com/portworx/kdmp/pkg/util/ops"
+	kdmpShedOps "github.com/portworx/sched-ops/k8s/kdmp"
+	"github.com/portworx/sched-ops/k8s/stork"
+	"github.com/sirupsen/logrus"
+	"gocloud.dev/blob"
+	"gocloud.dev/blob/fileblob"
+	"gocloud.dev/gcerrors"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// orphanGCMaintenanceType reports the repositories and snapshots which are
+// not referenced by any backup, and deletes them when enabled.
+const orphanGCMaintenanceType = "orphan-gc"
+
+var (
+	orphanGCDelete      bool
+	orphanGCGracePeriod time.Duration
+)
+
+// applicationBackupsDepth is the depth of the ApplicationBackup directories
+// of a BackupLocation, <namespace>/<name>/<uid>/.
+const applicationBackupsDepth = 3
+
+// connectRepository, listSnapshotTimes and deleteSnapshot are replaced in the
+// tests.
+var (
+	connectRepository = runKopiaRepositoryConnect
+	listSnapshotTimes = runKopiaSnapshotTimes
+	deleteSnapshot    = runKopiaDelete
+)
+
+// backupReferences are the repositories and the snapshots referenced by the
+// VolumeBackups and the ApplicationBackups of the cluster, and by the
+// ApplicationBackups recorded in the BackupLocation.
+type backupReferences struct {
+	repositories map[string]bool
+	snapshots    map[string]bool
+	// complete is false if the ApplicationBackups of the cluster couldn't be
+	// listed or a recorded one couldn't be read, the orphans are then only
+	// reported.
+	complete bool
+}
+
+func (r *backupReferences) addApplicationBackup(backup *storkapi.ApplicationBackup) {
+	for _, vol := range backup.Status.Volumes {
+		if vol == nil {
+			continue
+		}
+		r.repositories[getBackupPathWithRepoName(fmt.Sprintf("%s-%s", vol.Namespace, vol.PersistentVolumeClaim))] = true
+		if vol.BackupID != "" {
+			r.snapshots[vol.BackupID] = true
+		}
+	}
+}
+
+func getBackupReferences(repository *executor.Repository) (*backupReferences, error) {
+	refs := &backupReferences{
+		repositories: make(map[string]bool),
+		snapshots:    make(map[string]bool),
+		complete:     true,
+	}
+	volumeBackups, err := kdmpops.Instance().ListVolumeBackups(context.TODO(), "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the volumebackups: %v", err)
+	}
+	for _, vb := range volumeBackups.Items {
+		refs.repositories[strings.Trim(vb.Spec.Repository, "/")] = true
+		if vb.Status.SnapshotID != "" {
+			refs.snapshots[vb.Status.SnapshotID] = true
+		}
+	}
+	applicationBackups, err := stork.Instance().ListApplicationBackups("", metav1.ListOptions{})
+	if k8serrors.IsNotFound(err) {
+		logrus.Warnf("the applicationbackups can't be listed, the orphans are only reported: %v", err)
+		refs.complete = false
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to list the applicationbackups: %v", err)
+	} else {
+		for i := range applicationBackups.Items {
+			refs.addApplicationBackup(&applicationBackups.Items[i])
+		}
+	}
+
+	bucket, err := backupLocationBucket(repository)
+	if err != nil {
+		return nil, err
+	}
+	defer bucket.Close()
+	if err := addRecordedBackups(refs, bucket, "", applicationBackupsDepth); err != nil {
+		return nil, fmt.Errorf("failed to read the applicationbackups of the backuplocation: %v", err)
+	}
+	return refs, nil
+}
+
+// addRecordedBackups adds the references of the ApplicationBackups recorded
+// in the BackupLocation, the backups of the other clusters and the ones whose
+// object was deleted. The directories depth levels below prefix are the
+// ones of the backups.
+func addRecordedBackups(refs *backupReferences, bucket *blob.Bucket, prefix string, depth int) error {
+	if depth == 0 {
+		data, err := bucket.ReadAll(context.TODO(), prefix+executor.MetadataObjectName)
+		if gcerrors.Code(err) == gcerrors.NotFound {
+			// Not a backup, or one which isn't completed
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read %v: %v", prefix+executor.MetadataObjectName, err)
+		}
+		backup := &storkapi.ApplicationBackup{}
+		if err := json.Unmarshal(data, backup); err != nil {
+			// The maintenance jobs don't have the encryption key of the
+			// BackupLocation, the references of the backup are unknown.
+			logrus.Warnf("failed to parse %v, it may be encrypted, the orphans are only reported: %v", prefix+executor.MetadataObjectName, err)
+			refs.complete = false
+			return nil
+		}
+		refs.addApplicationBackup(backup)
+		return nil
+	}
+	iterator := bucket.List(&blob.ListOptions{
+		Prefix:    prefix,
+		Delimiter: "/",
+	})
+	for {
+		object, err := iterator.Next(context.TODO())
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if !object.IsDir || object.Key == genericBackupDir+"/" {
+			continue
+		}
+		if err := addRecordedBackups(refs, bucket, object.Key, depth-1); err != nil {
+			return err
+		}
+	}
+}
+
+// findOrphans returns the orphaned snapshots of a repository, nil if it has
+// none and is referenced, and the IDs of the ones older than the grace
+// period. An orphaned repository can be deleted once all its snapshots are,
+// and if it has none, once it is older than the grace period.
+func findOrphans(
+	repoName string,
+	snapshots map[string]time.Time,
+	created time.Time,
+	refs *backupReferences,
+	now time.Time,
+	gracePeriod time.Duration,
+) (*kdmp_api.OrphanedRepository, []string, bool) {
+	orphan := &kdmp_api.OrphanedRepository{
+		Repository: repoName,
+		Orphaned:   !refs.repositories[strings.Trim(repoName, "/")],
+	}
+	var expired []string
+	for id, startTime := range snapshots {
+		if refs.snapshots[id] {
+			continue
+		}
+		orphan.Snapshots = append(orphan.Snapshots, kdmp_api.OrphanedSnapshot{
+			ID:        id,
+			StartTime: metav1.NewTime(startTime),
+		})
+		if now.Sub(startTime) >= gracePeriod {
+			expired = append(expired, id)
+		}
+	}
+	if !orphan.Orphaned && len(orphan.Snapshots) == 0 {
+		return nil, nil, false
+	}
+	sort.Slice(orphan.Snapshots, func(i, j int) bool {
+		return orphan.Snapshots[i].StartTime.Before(&orphan.Snapshots[j].StartTime)
+	})
+	sort.Strings(expired)
+	deleteRepo := orphan.Orphaned && len(expired) == len(snapshots)
+	if len(snapshots) == 0 {
+		deleteRepo = deleteRepo && now.Sub(created) >= gracePeriod
+	}
+	return orphan, expired, deleteRepo
+}
+
+// runOrphanGC returns the orphans of a repository and deletes the expired
+// ones when enabled.
+func runOrphanGC(repository *executor.Repository, refs *backupReferences) *kdmp_api.OrphanedRepository {
+	fn := "runOrphanGC:"
+	if err := connectRepository(repository); err != nil {
+		logrus.Errorf("%s repository [%v] connect failed: %v", fn, repository.Name, err)
+		return &kdmp_api.OrphanedRepository{
+			Repository: repository.Name,
+			Reason:     fmt.Sprintf("repository connect failed: %v", err),
+		}
+	}
+	// Delete the kopia config files as the next connect command may fail because of this
+	defer func() {
+		if err := cleanKopiaConfigContents(); err != nil {
+			logrus.Errorf("failed to remove config contents from directory %s: %v", cacheDir, err)
+		}
+	}()
+
+	snapshots, endTimes, err := listSnapshotTimes(repository)
+	if err != nil {
+		logrus.Errorf("%s snapshot list of repo [%v] failed: %v", fn, repository.Name, err)
+		return &kdmp_api.OrphanedRepository{
+			Repository: repository.Name,
+			Reason:     fmt.Sprintf("snapshot list failed: %v", err),
+		}
+	}
+	var created time.Time
+	if len(snapshots) == 0 {
+		if created, err = getRepositoryCreationTime(repository); err != nil {
+			// The repository is too recent to be deleted.
+			logrus.Warnf("%s failed to get the creation time of repo [%v]: %v", fn, repository.Name, err)
+			created = time.Now()
+		}
+	}
+	orphan, expired, deleteRepo := findOrphans(repository.Name, snapshots, created, refs, time.Now(), orphanGCGracePeriod)
+	if orphan == nil || !orphanGCDelete {
+		return orphan
+	}
+
+	deleted := make(map[string]bool)
+	for _, id := range expired {
+		// The deletion of a locked snapshot is deferred to a later run. The
+		// orphans have no VolumeBackup, their expiry is the one of their end
+		// time.
+		expiry := snapshotExpiry(repository, nil, endTimes[id])
+		if time.Now().Before(expiry) {
+			logrus.Infof("%s snapshot [%v] of repo [%v] is object-locked until %v", fn, id, repository.Name, expiry)
+			orphan.Reason = fmt.Sprintf("snapshot %v is object-locked until %v", id, expiry.UTC().Format(time.RFC3339))
+			deleteRepo = false
+			continue
+		}
+		if err := deleteSnapshot(repository, id); err != nil {
+			logrus.Errorf("%s deleting snapshot [%v] of repo [%v] failed: %v", fn, id, repository.Name, err)
+			orphan.Reason = fmt.Sprintf("deleting snapshot %v failed: %v", id, err)
+			deleteRepo = false
+			break
+		}
+		deleted[id] = true
+	}
+	for i := range orphan.Snapshots {
+		orphan.Snapshots[i].Deleted = deleted[orphan.Snapshots[i].ID]
+	}
+	if !deleteRepo {
+		return orphan
+	}
+	if repository.S3Config != nil && repository.S3Config.ObjectLock != nil {
+		orphan.Reason = "the repository isn't deleted as its objects are locked"
+		return orphan
+	}
+	if err := deleteRepository(repository); err != nil {
+		logrus.Errorf("%s deleting repo [%v] failed: %v", fn, repository.Name, err)
+		orphan.Reason = fmt.Sprintf("deleting the repository failed: %v", err)
+		return orphan
+	}
+	logrus.Infof("%s deleted orphaned repository [%v]", fn, repository.Name)
+	orphan.Deleted = true
+	return orphan
+}
+
+// runOrphanGCMaintenance reports the orphans of the repositories in the
+// BackupLocationMaintenance status. The deletion needs an explicit grace
+// period as the snapshots of the running backups aren't referenced yet.
+func runOrphanGCMaintenance(repository *executor.Repository, repoList []string) error {
+	fn := "runOrphanGCMaintenance:"
+	status := &kdmp_api.OrphanGCStatus{
+		LastRunTimestamp: metav1.Now(),
+		Status:           kdmp_api.RepoMaintenanceStatusSuccess,
+		DeleteEnabled:    orphanGCDelete,
+		GracePeriod:      metav1.Duration{Duration: orphanGCGracePeriod},
+	}
+	if orphanGCDelete && orphanGCGracePeriod <= 0 {
+		logrus.Warnf("%s the orphans are only reported, deleting them needs a grace period", fn)
+		status.DeleteEnabled = false
+		orphanGCDelete = false
+	}
+	refs, err := getBackupReferences(repository)
+	if err != nil {
+		logrus.Errorf("%s %v", fn, err)
+		status.Status = kdmp_api.RepoMaintenanceStatusFailed
+		status.Reason = err.Error()
+		if statusErr := updateOrphanGCStatus(status); statusErr != nil {
+			logrus.Warnf("update of %s maintenance status failed: %v", orphanGCMaintenanceType, statusErr)
+		}
+		return err
+	}
+	if orphanGCDelete && !refs.complete {
+		status.DeleteEnabled = false
+		orphanGCDelete = false
+	}
+	for _, repoName := range repoList {
+		repository.Name = getBackupPathWithRepoName(repoName)
+		orphan := runOrphanGC(repository, refs)
+		if orphan == nil {
+			continue
+		}
+		if orphan.Reason != "" {
+			status.Status = kdmp_api.RepoMaintenanceStatusFailed
+			status.Reason = fmt.Sprintf("%v: %v", orphan.Repository, orphan.Reason)
+		}
+		status.Repositories = append(status.Repositories, *orphan)
+	}
+	return updateOrphanGCStatus(status)
+}
+
+// backupLocationBucket returns the bucket of the objects of a BackupLocation,
+// a directory bucket for an NFS share.
+func backupLocationBucket(repository *executor.Repository) (*blob.Bucket, error) {
+	if repository.Type == storkapi.BackupLocationNFS {
+		bucket, err := fileblob.OpenBucket(repository.Path, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open the nfs share %s: %v", repository.Path, err)
+		}
+		return bucket, nil
+	}
+	bl, err := executor.BuildStorkBackupLocation(repository)
+	if err != nil {
+		return nil, err
+	}
+	bucket, err := objectstore.GetBucket(bl)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the bucket %s: %v", repository.Path, err)
+	}
+	return bucket, nil
+}
+
+// repositoryBucket returns the bucket of the objects of a repository.
+func repositoryBucket(repository *executor.Repository) (*blob.Bucket, error) {
+	bucket, err := backupLocationBucket(repository)
+	if err != nil {
+		return nil, err
+	}
+	// The trailing "/" keeps the repositories with the same name prefix out.
+	return blob.PrefixedBucket(bucket, strings.TrimSuffix(repository.Name, "/")+"/"), nil
+}
+
+func getRepositoryCreationTime(repository *executor.Repository) (time.Time, error) {
+	if repository.Type == storkapi.BackupLocationNFS {
+		info, err := os.Stat(filepath.Join(repository.Path, repository.Name, kopiaNFSRepositoryFile))
+		if err != nil {
+			return time.Time{}, err
+		}
+		return info.ModTime(), nil
+	}
+	bucket, err := repositoryBucket(repository)
+	if err != nil {
+		return time.Time{}, err
+	}
+	attrs, err := bucket.Attributes(context.TODO(), kopiaRepositoryFile)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return attrs.ModTime, nil
+}
+
+// deleteRepository deletes all the objects of a repository.
+func deleteRepository(repository *executor.Repository) error {
+	if strings.Trim(strings.TrimPrefix(repository.Name, genericBackupDir), "/") == "" {
+		return fmt.Errorf("invalid repository name %q", repository.Name)
+	}
+	if repository.Type == storkapi.BackupLocationNFS {
+		return os.RemoveAll(filepath.Join(repository.Path, repository.Name))
+	}
+	bucket, err := repositoryBucket(repository)
+	if err != nil {
+		return err
+	}
+	iterator := bucket.List(nil)
+	for {
+		object, err := iterator.Next(context.TODO())
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := bucket.Delete(context.TODO(), object.Key); err != nil {
+			return fmt.Errorf("failed to delete %v: %v", object.Key, err)
+		}
+	}
+}
+
+func updateOrphanGCStatus(status *kdmp_api.OrphanGCStatus) error {
+	fn := "updateOrphanGCStatus"
+	backupLocationMaintenance, err := kdmpShedOps.Instance().GetBackupLocationMaintenance(maintenanceStatusName, maintenanceStatusNamespace)
+	if err != nil {
+		errMsg := fmt.Sprintf("failed in getting backuplocationmaintenance CR [%v:%v]: %v", maintenanceStatusNamespace, maintenanceStatusName, err)
+		logrus.Errorf("%s %v", fn, errMsg)
+		return fmt.Errorf(errMsg)
+	}
+	backupLocationMaintenance.Status.OrphanGCStatus = status
+	_, err = kdmpShedOps.Instance().UpdateBackupLocationMaintenance(backupLocationMaintenance)
+	if err != nil {
+		errMsg := fmt.Sprintf("failed in updating backuplocation maintenace CR [%v:%v]: %v", maintenanceStatusNamespace, maintenanceStatusName, err)
+		logrus.Errorf("%s %v", fn, errMsg)
+		return fmt.Errorf(errMsg)
+	}
+	return nil
+}
@@ -0,0 +1,237 @@
+package kopia
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	storkapi "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	kdmp_api "github.com/portworx/kdmp/pkg/apis/kdmp/v1alpha1"
+	"github.com/portworx/kdmp/pkg/executor"
+	kdmpops "github.com/portworx/kdmp/pkg/util/ops"
+	"github.com/portworx/sched-ops/k8s/stork"
+	"github.com/stretchr/testify/require"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// fakeKdmp lists a set of VolumeBackups.
+type fakeKdmp struct {
+	kdmpops.Ops
+	volumeBackups []kdmp_api.VolumeBackup
+}
+
+func (f *fakeKdmp) ListVolumeBackups(ctx context.Context, namespace string) (*kdmp_api.VolumeBackupList, error) {
+	return &kdmp_api.VolumeBackupList{Items: f.volumeBackups}, nil
+}
+
+// fakeStork lists a set of ApplicationBackups, or fails with err.
+type fakeStork struct {
+	stork.Ops
+	applicationBackups []storkapi.ApplicationBackup
+	err                error
+}
+
+func (f *fakeStork) ListApplicationBackups(namespace string, filterOptions metav1.ListOptions) (*storkapi.ApplicationBackupList, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &storkapi.ApplicationBackupList{Items: f.applicationBackups}, nil
+}
+
+func newApplicationBackup(name, pvc, backupID string) storkapi.ApplicationBackup {
+	return storkapi.ApplicationBackup{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "app"},
+		Status: storkapi.ApplicationBackupStatus{
+			Volumes: []*storkapi.ApplicationBackupVolumeInfo{
+				{Namespace: "app", PersistentVolumeClaim: pvc, BackupID: backupID},
+				nil,
+			},
+		},
+	}
+}
+
+func writeTestFile(t *testing.T, path string, data []byte) {
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, data, 0644))
+}
+
+func TestFindOrphans(t *testing.T) {
+	now := time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)
+	grace := 48 * time.Hour
+	refs := &backupReferences{
+		repositories: map[string]bool{"generic-backup/ns-data": true},
+		snapshots:    map[string]bool{"k1": true},
+	}
+	snapshots := map[string]time.Time{
+		"k1": now.Add(-10 * 24 * time.Hour),
+		"k2": now.Add(-5 * 24 * time.Hour),
+		"k3": now.Add(-time.Hour),
+	}
+
+	// The snapshots of a referenced repository
+	orphan, expired, deleteRepo := findOrphans("generic-backup/ns-data/", snapshots, time.Time{}, refs, now, grace)
+	require.False(t, orphan.Orphaned)
+	require.Len(t, orphan.Snapshots, 2)
+	require.Equal(t, "k2", orphan.Snapshots[0].ID)
+	require.Equal(t, []string{"k2"}, expired)
+	require.False(t, deleteRepo)
+
+	// A referenced repository with no orphans
+	orphan, _, _ = findOrphans("generic-backup/ns-data/", map[string]time.Time{"k1": now}, time.Time{}, refs, now, grace)
+	require.Nil(t, orphan)
+
+	// An orphaned repository is deleted with its last snapshot
+	orphan, expired, deleteRepo = findOrphans("generic-backup/old-data/", map[string]time.Time{"k2": snapshots["k2"]}, time.Time{}, refs, now, grace)
+	require.True(t, orphan.Orphaned)
+	require.Equal(t, []string{"k2"}, expired)
+	require.True(t, deleteRepo)
+	orphan, expired, deleteRepo = findOrphans("generic-backup/old-data/", snapshots, time.Time{}, refs, now, grace)
+	require.Len(t, orphan.Snapshots, 2)
+	require.Equal(t, []string{"k2"}, expired)
+	require.False(t, deleteRepo)
+
+	// An empty orphaned repository is deleted after the grace period
+	_, _, deleteRepo = findOrphans("generic-backup/new-data/", nil, now.Add(-time.Hour), refs, now, grace)
+	require.False(t, deleteRepo)
+	_, _, deleteRepo = findOrphans("generic-backup/new-data/", nil, now.Add(-72*time.Hour), refs, now, grace)
+	require.True(t, deleteRepo)
+}
+
+func TestGetBackupReferences(t *testing.T) {
+	dir := t.TempDir()
+	repository := &executor.Repository{Type: storkapi.BackupLocationNFS, Path: dir}
+	recorded, err := json.Marshal(newApplicationBackup("old", "logs", "k3"))
+	require.NoError(t, err)
+	writeTestFile(t, filepath.Join(dir, "app", "old", "uid-1", executor.MetadataObjectName), recorded)
+	// A backup in progress and a kopia repository aren't read
+	writeTestFile(t, filepath.Join(dir, "app", "running", "uid-2", executor.ResourcesFile), []byte("[]"))
+	writeTestFile(t, filepath.Join(dir, genericBackupDir, "app-data", "uid-3", executor.MetadataObjectName), []byte("not json"))
+
+	kdmpops.SetInstance(&fakeKdmp{volumeBackups: []kdmp_api.VolumeBackup{{
+		Spec:   kdmp_api.VolumeBackupSpec{Repository: "generic-backup/app-data/"},
+		Status: kdmp_api.VolumeBackupStatus{SnapshotID: "k1"},
+	}}})
+	storkOps := &fakeStork{applicationBackups: []storkapi.ApplicationBackup{newApplicationBackup("new", "cache", "k2")}}
+	stork.SetInstance(storkOps)
+
+	refs, err := getBackupReferences(repository)
+	require.NoError(t, err)
+	require.True(t, refs.complete)
+	require.Equal(t, map[string]bool{
+		"generic-backup/app-data":  true,
+		"generic-backup/app-cache": true,
+		"generic-backup/app-logs":  true,
+	}, refs.repositories)
+	require.Equal(t, map[string]bool{"k1": true, "k2": true, "k3": true}, refs.snapshots)
+
+	// Without the ApplicationBackups of the cluster the orphans are only
+	// reported
+	storkOps.err = k8serrors.NewNotFound(schema.GroupResource{Group: "stork.libopenstorage.org", Resource: "applicationbackups"}, "")
+	refs, err = getBackupReferences(repository)
+	require.NoError(t, err)
+	require.False(t, refs.complete)
+	require.True(t, refs.snapshots["k3"])
+	require.False(t, refs.snapshots["k2"])
+
+	// A recorded backup which can't be read, like an encrypted one, is
+	// skipped and the orphans are only reported
+	storkOps.err = nil
+	writeTestFile(t, filepath.Join(dir, "app", "encrypted", "uid-4", executor.MetadataObjectName), []byte("not json"))
+	refs, err = getBackupReferences(repository)
+	require.NoError(t, err)
+	require.False(t, refs.complete)
+	require.True(t, refs.snapshots["k2"])
+	require.True(t, refs.snapshots["k3"])
+}
+
+func TestRunOrphanGC(t *testing.T) {
+	now := time.Now()
+	dir := t.TempDir()
+	repository := &executor.Repository{Type: storkapi.BackupLocationNFS, Path: dir, Name: "generic-backup/old-data/"}
+	repoDir := filepath.Join(dir, repository.Name)
+	writeTestFile(t, filepath.Join(repoDir, kopiaNFSRepositoryFile), []byte("{}"))
+
+	var (
+		connectErr error
+		snapshots  map[string]time.Time
+		deleted    []string
+		deleteErr  error
+	)
+	connect, list, del := connectRepository, listSnapshotTimes, deleteSnapshot
+	gcDelete, grace := orphanGCDelete, orphanGCGracePeriod
+	defer func() {
+		connectRepository, listSnapshotTimes, deleteSnapshot = connect, list, del
+		orphanGCDelete, orphanGCGracePeriod = gcDelete, grace
+	}()
+	connectRepository = func(*executor.Repository) error {
+		return connectErr
+	}
+	listSnapshotTimes = func(*executor.Repository) (map[string]time.Time, map[string]time.Time, error) {
+		return snapshots, snapshots, nil
+	}
+	deleteSnapshot = func(repository *executor.Repository, id string) error {
+		if deleteErr != nil {
+			return deleteErr
+		}
+		deleted = append(deleted, id)
+		delete(snapshots, id)
+		return nil
+	}
+	orphanGCGracePeriod = 48 * time.Hour
+	refs := &backupReferences{
+		repositories: map[string]bool{},
+		snapshots:    map[string]bool{"k1": true},
+		complete:     true,
+	}
+
+	// A failed connect is reported
+	connectErr = fmt.Errorf("invalid password")
+	orphan := runOrphanGC(repository, refs)
+	require.Contains(t, orphan.Reason, "invalid password")
+	connectErr = nil
+
+	// The orphans are only reported unless deleting is enabled
+	snapshots = map[string]time.Time{
+		"k1": now.Add(-10 * 24 * time.Hour),
+		"k2": now.Add(-5 * 24 * time.Hour),
+		"k3": now.Add(-time.Hour),
+	}
+	orphanGCDelete = false
+	orphan = runOrphanGC(repository, refs)
+	require.True(t, orphan.Orphaned)
+	require.Len(t, orphan.Snapshots, 2)
+	require.Empty(t, deleted)
+
+	// A failed snapshot deletion keeps the repository
+	orphanGCDelete = true
+	deleteErr = fmt.Errorf("permission denied")
+	orphan = runOrphanGC(repository, refs)
+	require.Contains(t, orphan.Reason, "deleting snapshot k2 failed")
+	require.False(t, orphan.Deleted)
+	deleteErr = nil
+
+	// The expired orphans are deleted, the repository is kept while it has
+	// a referenced or recent snapshot
+	orphan = runOrphanGC(repository, refs)
+	require.Empty(t, orphan.Reason)
+	require.Equal(t, []string{"k2"}, deleted)
+	require.True(t, orphan.Snapshots[0].Deleted)
+	require.False(t, orphan.Snapshots[1].Deleted)
+	require.False(t, orphan.Deleted)
+	require.DirExists(t, repoDir)
+
+	// and deleted with its last snapshot
+	delete(snapshots, "k1")
+	snapshots["k3"] = now.Add(-72 * time.Hour)
+	orphan = runOrphanGC(repository, refs)
+	require.Empty(t, orphan.Reason)
+	require.Equal(t, []string{"k2", "k3"}, deleted)
+	require.True(t, orphan.Deleted)
+	require.NoDirExists(t, repoDir)
+}
@@ -0,0 +1,241 @@
+package kopia
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	storkv1 "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	kdmpapi "github.com/portworx/kdmp/pkg/apis/kdmp/v1alpha1"
+	"github.com/portworx/kdmp/pkg/drivers"
+	"github.com/portworx/kdmp/pkg/executor"
+	"github.com/portworx/kdmp/pkg/kopia"
+	kdmpops "github.com/portworx/kdmp/pkg/util/ops"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kubectl/pkg/cmd/util"
+)
+
+func newReplicateCommand() *cobra.Command {
+	var (
+		volumeBackupNamespace       string
+		sourceVolumeBackupName      string
+		sourceVolumeBackupNamespace string
+	)
+	replicateCommand := &cobra.Command{
+		Use:   "replicate",
+		Short: "replicate the repository of a backup to another backuplocation",
+		Run: func(c *cobra.Command, args []string) {
+			if len(backupLocationName) == 0 {
+				util.CheckErr(fmt.Errorf("backup-location has to be provided for kopia replication"))
+				return
+			}
+			if len(sourceVolumeBackupName) == 0 || len(sourceVolumeBackupNamespace) == 0 {
+				util.CheckErr(fmt.Errorf("source-volume-backup-name and source-volume-backup-namespace have to be provided for kopia replication"))
+				return
+			}
+			executor.HandleErr(runReplicate(sourceVolumeBackupName, sourceVolumeBackupNamespace, volumeBackupNamespace))
+		},
+	}
+	replicateCommand.Flags().StringVar(&volumeBackupNamespace, "volume-backup-namespace", "", "Namespace of the VolumeBackup CR created for the replicated snapshot")
+	replicateCommand.Flags().StringVar(&sourceVolumeBackupName, "source-volume-backup-name", "", "Name of the VolumeBackup CR of the snapshot to replicate")
+	replicateCommand.Flags().StringVar(&sourceVolumeBackupNamespace, "source-volume-backup-namespace", "", "Namespace of the VolumeBackup CR of the snapshot to replicate")
+	return replicateCommand
+}
+
+// runReplicate copies the repository of the source VolumeBackup to the
+// backuplocation mounted at the replica credentials mount. The repository
+// keeps its name, so the replica VolumeBackup can be restored like any other.
+func runReplicate(sourceVolumeBackupName, sourceVolumeBackupNamespace, volumeBackupNamespace string) error {
+	fn := "runReplicate:"
+	sourceVB, err := kdmpops.Instance().GetVolumeBackup(context.Background(), sourceVolumeBackupName, sourceVolumeBackupNamespace)
+	if err != nil {
+		errMsg := fmt.Sprintf("failed in getting source VolumeBackup CR [%s/%s]: %v", sourceVolumeBackupNamespace, sourceVolumeBackupName, err)
+		logrus.Errorf("%s %v", fn, errMsg)
+		return fmt.Errorf(errMsg)
+	}
+	if kopiaRepo == "" {
+		kopiaRepo = sourceVB.Spec.Repository
+	}
+	source := kdmpapi.DataExportObjectReference{
+		Name:      sourceVolumeBackupName,
+		Namespace: sourceVolumeBackupNamespace,
+	}
+	if err := executor.CreateReplicaVolumeBackup(
+		volumeBackupName,
+		volumeBackupNamespace,
+		kopiaRepo,
+		backupLocationName,
+		backupLocationNamespace,
+		source,
+	); err != nil {
+		logrus.Errorf("%s %v", fn, err)
+		return err
+	}
+
+	lockExpiry, err := replicateRepository()
+	if err != nil {
+		if statusErr := executor.WriteVolumeBackupStatus(
+			&executor.Status{LastKnownError: err},
+			volumeBackupName,
+			volumeBackupNamespace,
+		); statusErr != nil {
+			errMsg := fmt.Sprintf("failed in updating VolumeBackup CR [%s/%s]: %v", volumeBackupNamespace, volumeBackupName, statusErr)
+			logrus.Errorf("%s %v", fn, errMsg)
+			return fmt.Errorf(errMsg)
+		}
+		return err
+	}
+
+	status := &executor.Status{
+		ProgressPercentage:  drivers.TransferProgressCompleted,
+		TotalBytes:          sourceVB.Status.TotalBytes,
+		TotalBytesProcessed: sourceVB.Status.TotalBytes,
+		SnapshotID:          sourceVB.Status.SnapshotID,
+		Done:                true,
+		ObjectLockExpiry:    lockExpiry,
+	}
+	if err := executor.WriteVolumeBackupStatus(status, volumeBackupName, volumeBackupNamespace); err != nil {
+		errMsg := fmt.Sprintf("failed in updating VolumeBackup CR [%s/%s]: %v", volumeBackupNamespace, volumeBackupName, err)
+		logrus.Errorf("%s %v", fn, errMsg)
+		return fmt.Errorf(errMsg)
+	}
+	replica := kdmpapi.DataExportObjectReference{
+		Name:      volumeBackupName,
+		Namespace: volumeBackupNamespace,
+	}
+	if err := executor.AddVolumeBackupReplica(sourceVolumeBackupName, sourceVolumeBackupNamespace, replica); err != nil {
+		logrus.Errorf("%s %v", fn, err)
+		return err
+	}
+	logrus.Infof("%s snapshot [%v] replicated to backuplocation [%s/%s]", fn, sourceVB.Status.SnapshotID, backupLocationNamespace, backupLocationName)
+	return nil
+}
+
+// replicateRepository returns the object-lock expiry of the replica, its blobs
+// being written now.
+func replicateRepository() (*metav1.Time, error) {
+	fn := "replicateRepository:"
+	// Parse using the mounted secrets
+	src, err := executor.ParseCloudCred()
+	if err != nil {
+		errMsg := fmt.Sprintf("failed in parsing source backuplocation: %s", err)
+		logrus.Errorf("%s %v", fn, errMsg)
+		return nil, fmt.Errorf(errMsg)
+	}
+	dst, err := executor.ParseCloudCredFromDir(drivers.KopiaReplicaCredSecretMount)
+	if err != nil {
+		errMsg := fmt.Sprintf("failed in parsing destination backuplocation: %s", err)
+		logrus.Errorf("%s %v", fn, errMsg)
+		return nil, fmt.Errorf(errMsg)
+	}
+	src.Name = kopiaRepo
+	dst.Name = kopiaRepo
+
+	if src.Type == storkv1.BackupLocationNFS {
+		if !isNfsKopiaRepositoryFileExists(src) {
+			errMsg := fmt.Sprintf("kopia repository file is not found in the NFS backuplocation for repo [%v]", src.Name)
+			logrus.Errorf("%s %v", fn, errMsg)
+			return nil, fmt.Errorf(errMsg)
+		}
+	}
+
+	if err := runKopiaRepositoryConnect(src); err != nil {
+		errMsg := fmt.Sprintf("repository [%v] connect failed: %v", src.Name, err)
+		logrus.Errorf("%s %v", fn, errMsg)
+		return nil, fmt.Errorf(errMsg)
+	}
+
+	wrapped, err := replicaDataKey(src, dst)
+	if err != nil {
+		errMsg := fmt.Sprintf("repository [%v] can not be opened with the destination backuplocation: %v", src.Name, err)
+		logrus.Errorf("%s %v", fn, errMsg)
+		return nil, fmt.Errorf(errMsg)
+	}
+
+	if err := runKopiaSyncTo(dst); err != nil {
+		errMsg := fmt.Sprintf("repository [%v] sync failed: %v", src.Name, err)
+		logrus.Errorf("%s %v", fn, errMsg)
+		return nil, fmt.Errorf(errMsg)
+	}
+
+	// The data key is written after the sync, which copies the one of the
+	// source along with the repository blobs.
+	if wrapped != "" {
+		if err := writeDataKey(dst, wrapped); err != nil {
+			logrus.Errorf("%s %v", fn, err)
+			return nil, err
+		}
+	}
+	return backupLockExpiry(dst, nil), nil
+}
+
+// replicaDataKey returns the data key of the source repository wrapped with the
+// KMS of the destination backuplocation by the controller, or an empty string
+// if the source repository has no data key. In that case the replica is opened
+// with the destination backuplocation password, which has to be the source one.
+func replicaDataKey(src, dst *executor.Repository) (string, error) {
+	if dataKey, ok := dst.DataKeys.Get(dst.Name); ok {
+		return dataKey.Wrapped, nil
+	}
+	if src.Password != dst.LocationPassword {
+		return "", fmt.Errorf("the repository password of the backuplocations differ")
+	}
+	return "", nil
+}
+
+func runKopiaSyncTo(repository *executor.Repository) error {
+	fn := "runKopiaSyncTo:"
+	logrus.Infof("kopia repository sync to [%v] started", repository.Path)
+	var region string
+	var disableSsl bool
+	if repository.Type == storkv1.BackupLocationS3 {
+		region = repository.S3Config.Region
+		disableSsl = repository.S3Config.DisableSSL
+	}
+	syncCmd, err := kopia.GetSyncToCommand(
+		repository.Path,
+		repository.Name,
+		kopiaProviderType[repository.Type],
+		region,
+		disableSsl,
+	)
+	if err != nil {
+		errMsg := fmt.Sprintf("getting repository sync-to command failed: %v", err)
+		logrus.Errorf("%s %v", fn, errMsg)
+		return fmt.Errorf(errMsg)
+	}
+	switch repository.Type {
+	case storkv1.BackupLocationS3:
+		syncCmd = populateS3AccessDetails(syncCmd, repository)
+	case storkv1.BackupLocationGoogle:
+		syncCmd = populateGCEAccessDetails(syncCmd, repository)
+	case storkv1.BackupLocationAzure:
+		syncCmd = populateAzureccessDetails(syncCmd, repository)
+	}
+	syncExecutor := kopia.NewSyncToExecutor(syncCmd)
+	if err := syncExecutor.Run(); err != nil {
+		errMsg := fmt.Sprintf("running repository sync-to command failed: %v", err)
+		logrus.Errorf("%s %v", fn, errMsg)
+		return fmt.Errorf(errMsg)
+	}
+
+	// The whole repository is copied on the first replication, so there is
+	// no timeout here.
+	for {
+		time.Sleep(progressCheckInterval)
+		status, err := syncExecutor.Status()
+		if err != nil {
+			return err
+		}
+		if status.LastKnownError != nil {
+			return status.LastKnownError
+		}
+		if status.Done {
+			break
+		}
+	}
+	logrus.Infof("kopia repository sync to [%v] done", repository.Path)
+	return nil
+}
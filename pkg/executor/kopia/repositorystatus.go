@@ -0,0 +1,183 @@
+package kopia
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	kdmp_api "github.com/portworx/kdmp/pkg/apis/kdmp/v1alpha1"
+	"github.com/portworx/kdmp/pkg/drivers/utils"
+	"github.com/portworx/kdmp/pkg/executor"
+	"github.com/portworx/kdmp/pkg/kopia"
+	kdmpops "github.com/portworx/kdmp/pkg/util/ops"
+	kdmpShedOps "github.com/portworx/sched-ops/k8s/kdmp"
+	"github.com/sirupsen/logrus"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+const (
+	// repositoryStatusMaintenanceType reports the usage of the repositories
+	// in RepositoryStatus CRs.
+	repositoryStatusMaintenanceType = "repository-status"
+
+	repositoryStatusMaintenanceLabel = "kdmp.portworx.com/backuplocation-maintenance"
+	repositoryStatusRepositoryLabel  = "kdmp.portworx.com/repository"
+)
+
+// getRepositoryNamespaces returns the namespaces of the VolumeBackups of each
+// repository.
+func getRepositoryNamespaces() (map[string][]string, error) {
+	volumeBackups, err := kdmpops.Instance().ListVolumeBackups(context.TODO(), "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the volumebackups: %v", err)
+	}
+	seen := make(map[string]bool)
+	namespaces := make(map[string][]string)
+	for _, vb := range volumeBackups.Items {
+		repoName := strings.Trim(vb.Spec.Repository, "/")
+		if key := repoName + "/" + vb.Namespace; !seen[key] {
+			seen[key] = true
+			namespaces[repoName] = append(namespaces[repoName], vb.Namespace)
+		}
+	}
+	for _, list := range namespaces {
+		sort.Strings(list)
+	}
+	return namespaces, nil
+}
+
+// runRepositoryStatus collects the usage of a repository and writes it in
+// its RepositoryStatus CR. The usage of the last successful run is kept if
+// it fails.
+func runRepositoryStatus(repository *executor.Repository, namespaces []string) {
+	fn := "runRepositoryStatus:"
+	status := kdmp_api.RepositoryStatusStatus{
+		Status:              kdmp_api.RepoMaintenanceStatusSuccess,
+		LastUpdateTimestamp: metav1.Now(),
+		Namespaces:          namespaces,
+	}
+	if err := runKopiaRepositoryConnect(repository); err != nil {
+		logrus.Errorf("%s repository [%v] connect failed: %v", fn, repository.Name, err)
+		status.Status = kdmp_api.RepoMaintenanceStatusFailed
+		status.Reason = fmt.Sprintf("repository connect failed: %v", err)
+	} else {
+		usage, err := runKopiaRepositoryStatus(repository)
+		if err != nil {
+			logrus.Errorf("%s collecting the usage of repo [%v] failed: %v", fn, repository.Name, err)
+			status.Status = kdmp_api.RepoMaintenanceStatusFailed
+			status.Reason = err.Error()
+		}
+		status.Usage = usage
+		// Delete the kopia config files as the next connect command may fail because of this
+		if err := cleanKopiaConfigContents(); err != nil {
+			logrus.Errorf("failed to remove config contents from directory %s: %v", cacheDir, err)
+		}
+	}
+	if err := updateRepositoryStatus(repository.Name, status); err != nil {
+		logrus.Warnf("%s update of the repository status of repo [%v] failed: %v", fn, repository.Name, err)
+		return
+	}
+	logrus.Infof("%s repository status updated for repository [%v]", fn, repository.Name)
+}
+
+func runKopiaRepositoryStatus(repository *executor.Repository) (*kdmp_api.RepositoryUsage, error) {
+	fn := "runKopiaRepositoryStatus:"
+	statusCmd, err := kopia.GetRepositoryStatusCommand()
+	if err != nil {
+		errMsg := fmt.Sprintf("getting repository status command for [%v] failed: %v", repository.Name, err)
+		logrus.Errorf("%s %v", fn, errMsg)
+		return nil, fmt.Errorf(errMsg)
+	}
+	statusExecutor := kopia.NewRepositoryStatusExecutor(statusCmd)
+	if err := statusExecutor.Run(); err != nil {
+		errMsg := fmt.Sprintf("running repository status command for [%v] failed: %v", repository.Name, err)
+		logrus.Errorf("%s %v", fn, errMsg)
+		return nil, fmt.Errorf(errMsg)
+	}
+	for {
+		time.Sleep(progressCheckInterval)
+		status, err := statusExecutor.Status()
+		if err != nil {
+			return nil, err
+		}
+		if status.LastKnownError != nil {
+			return nil, status.LastKnownError
+		}
+		if status.Done {
+			return status.RepositoryUsage, nil
+		}
+	}
+}
+
+// updateRepositoryStatus creates or updates the RepositoryStatus CR of a
+// repository, owned by the BackupLocationMaintenance CR.
+func updateRepositoryStatus(repoName string, status kdmp_api.RepositoryStatusStatus) error {
+	fn := "updateRepositoryStatus"
+	name := getRepositoryStatusName(repoName)
+	repositoryStatus, err := kdmpops.Instance().GetRepositoryStatus(context.TODO(), name, maintenanceStatusNamespace)
+	if k8serrors.IsNotFound(err) {
+		backupLocationMaintenance, err := kdmpShedOps.Instance().GetBackupLocationMaintenance(maintenanceStatusName, maintenanceStatusNamespace)
+		if err != nil {
+			errMsg := fmt.Sprintf("failed in getting backuplocationmaintenance CR [%v:%v]: %v", maintenanceStatusNamespace, maintenanceStatusName, err)
+			logrus.Errorf("%s %v", fn, errMsg)
+			return fmt.Errorf(errMsg)
+		}
+		repositoryStatus = &kdmp_api.RepositoryStatus{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: maintenanceStatusNamespace,
+				Labels: map[string]string{
+					repositoryStatusMaintenanceLabel: utils.GetValidLabel(maintenanceStatusName),
+					repositoryStatusRepositoryLabel:  utils.GetValidLabel(strings.Trim(strings.TrimPrefix(repoName, genericBackupDir), "/")),
+				},
+				OwnerReferences: []metav1.OwnerReference{{
+					APIVersion: kdmp_api.SchemeGroupVersion.String(),
+					Kind:       "BackupLocationMaintenance",
+					Name:       backupLocationMaintenance.Name,
+					UID:        backupLocationMaintenance.UID,
+				}},
+			},
+			Spec: kdmp_api.RepositoryStatusSpec{
+				BackupLocationName: backupLocationMaintenance.Spec.BackuplocationName,
+				Repository:         repoName,
+			},
+		}
+		repositoryStatus, err = kdmpops.Instance().CreateRepositoryStatus(context.TODO(), repositoryStatus)
+		if err != nil {
+			errMsg := fmt.Sprintf("failed in creating repositorystatus CR [%v:%v]: %v", maintenanceStatusNamespace, name, err)
+			logrus.Errorf("%s %v", fn, errMsg)
+			return fmt.Errorf(errMsg)
+		}
+	} else if err != nil {
+		errMsg := fmt.Sprintf("failed in getting repositorystatus CR [%v:%v]: %v", maintenanceStatusNamespace, name, err)
+		logrus.Errorf("%s %v", fn, errMsg)
+		return fmt.Errorf(errMsg)
+	}
+	if status.Usage == nil {
+		status.Usage = repositoryStatus.Status.Usage
+	}
+	repositoryStatus.Status = status
+	if _, err = kdmpops.Instance().UpdateRepositoryStatus(context.TODO(), repositoryStatus); err != nil {
+		errMsg := fmt.Sprintf("failed in updating repositorystatus CR [%v:%v]: %v", maintenanceStatusNamespace, name, err)
+		logrus.Errorf("%s %v", fn, errMsg)
+		return fmt.Errorf(errMsg)
+	}
+	return nil
+}
+
+// getRepositoryStatusName returns the name of the RepositoryStatus CR of a
+// repository, the repository name is replaced by its hash when it is too
+// long.
+func getRepositoryStatusName(repoName string) string {
+	repoName = strings.Trim(strings.TrimPrefix(repoName, genericBackupDir), "/")
+	name := maintenanceStatusName + "-" + repoName
+	if len(name) <= validation.DNS1123SubdomainMaxLength {
+		return name
+	}
+	return fmt.Sprintf("%s-%x", maintenanceStatusName, sha256.Sum256([]byte(repoName)))[:validation.DNS1123SubdomainMaxLength]
+}
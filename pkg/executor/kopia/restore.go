@@ -2,6 +2,9 @@ package kopia
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/portworx/kdmp/pkg/executor"
@@ -18,8 +21,11 @@ var (
 
 func newRestoreCommand() *cobra.Command {
 	var (
-		targetPath string
-		snapshotID string
+		targetPath    string
+		targetSubPath string
+		snapshotID    string
+		includePaths  []string
+		targetDevice  string
 	)
 	restoreCommand := &cobra.Command{
 		Use:   "restore",
@@ -29,17 +35,32 @@ func newRestoreCommand() *cobra.Command {
 				util.CheckErr(fmt.Errorf("backup-location has to be provided for kopia restores"))
 				return
 			}
+			if len(targetDevice) != 0 {
+				if len(includePaths) != 0 || len(targetSubPath) != 0 {
+					util.CheckErr(fmt.Errorf("include-path and target-sub-path can't be used with target-block-device"))
+					return
+				}
+				executor.HandleErr(runRestore(snapshotID, "", targetDevice, appRestoreCR, nil))
+				return
+			}
 			if len(targetPath) == 0 {
 				util.CheckErr(fmt.Errorf("target-path argument is required for kopia restores"))
 				return
 			}
-			executor.HandleErr(runRestore(snapshotID, targetPath, appRestoreCR))
+			if strings.HasPrefix(filepath.Clean(targetSubPath), "..") {
+				util.CheckErr(fmt.Errorf("target-sub-path should be within the target-path"))
+				return
+			}
+			executor.HandleErr(runRestore(snapshotID, filepath.Join(targetPath, targetSubPath), "", appRestoreCR, includePaths))
 		},
 	}
 	restoreCommand.Flags().StringVarP(&restoreNamespace, "restore-namespace", "", "", "Namespace for restore command")
 	restoreCommand.Flags().StringVar(&targetPath, "target-path", "", "Destination path for kopia restore")
 	restoreCommand.Flags().StringVar(&snapshotID, "snapshot-id", "", "Snapshot id of the restore")
 	restoreCommand.Flags().StringVar(&appRestoreCR, "app-restore-cr", "", "ApplicationRestore CR name")
+	restoreCommand.Flags().StringVar(&targetSubPath, "target-sub-path", "", "Directory within the target-path the snapshot is restored into")
+	restoreCommand.Flags().StringVar(&targetDevice, "target-block-device", "", "Raw block device a block backup is restored onto, instead of target-path")
+	restoreCommand.Flags().StringArrayVar(&includePaths, "include-path", nil, "Path or glob pattern of the snapshot entries to restore, the whole snapshot is restored if not set. Can be repeated")
 
 	return restoreCommand
 }
@@ -49,7 +70,9 @@ func newRestoreCommand() *cobra.Command {
 func runRestore(
 	snapshotID string,
 	targetPath string,
+	targetDevice string,
 	appRestoreCR string,
+	includePaths []string,
 ) error {
 	logrus.Infof("Restore started from snapshotID: %s", snapshotID)
 	// Parse using the mounted secrets
@@ -86,7 +109,27 @@ func runRestore(
 		return fmt.Errorf(errMsg)
 	}
 
-	if err = runKopiaRestore(repo, targetPath, snapshotID); err != nil {
+	if targetDevice != "" {
+		if err = runBlockRestore(repo, snapshotID, targetDevice); err != nil {
+			errMsg := fmt.Sprintf("block restore failed: %v", err)
+			logrus.Errorf("%s: %v", fn, errMsg)
+			return fmt.Errorf(errMsg)
+		}
+		return nil
+	}
+
+	if err = os.MkdirAll(targetPath, 0755); err != nil {
+		errMsg := fmt.Sprintf("failed to create restore target dir %s: %v", targetPath, err)
+		logrus.Errorf("%s: %v", fn, errMsg)
+		return fmt.Errorf(errMsg)
+	}
+
+	if len(includePaths) > 0 {
+		err = runKopiaFileRestore(repo, targetPath, snapshotID, includePaths)
+	} else {
+		err = runKopiaRestore(repo, targetPath, snapshotID)
+	}
+	if err != nil {
 		errMsg := fmt.Sprintf("restore failed: %v", err)
 		logrus.Errorf("%s: %v", fn, errMsg)
 		return fmt.Errorf(errMsg)
@@ -139,3 +182,77 @@ func runKopiaRestore(repository *executor.Repository, targetPath, snapshotID str
 	logrus.Infof("kopia restore successful from snapshot %s", snapshotID)
 	return nil
 }
+
+// runKopiaFileRestore restores only the entries of the snapshot matching the
+// include paths. The entries keep their path relative to the volume root.
+func runKopiaFileRestore(repository *executor.Repository, targetPath, snapshotID string, includePaths []string) error {
+	fn := "runKopiaFileRestore"
+	logrus.Infof("kopia file restore started from snapshot %s for paths %v", snapshotID, includePaths)
+	status := &executor.Status{SnapshotID: snapshotID}
+	entries, err := runKopiaListFiles(snapshotID, "")
+	if err == nil {
+		entries, err = kopia.MatchEntries(entries, includePaths)
+	}
+	if err == nil && len(entries) == 0 {
+		err = fmt.Errorf("none of the paths %v found in snapshot %s", includePaths, snapshotID)
+	}
+
+	for _, entry := range entries {
+		if err != nil {
+			break
+		}
+		target := filepath.Join(targetPath, entry)
+		if err = os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			err = fmt.Errorf("failed to create dir for %s: %v", target, err)
+			break
+		}
+		logrus.Infof("%s: restoring %s", fn, entry)
+		err = runKopiaRestoreFile(repository, snapshotID, entry, target)
+	}
+
+	status.Done = true
+	status.LastKnownError = err
+	if statusErr := executor.WriteVolumeBackupStatus(status, volumeBackupName, restoreNamespace); statusErr != nil {
+		logrus.Errorf("%s: failed to write a VolumeBackup status: %v", fn, statusErr)
+	}
+	if err != nil {
+		return err
+	}
+	logrus.Infof("kopia file restore successful from snapshot %s, restored %d entries", snapshotID, len(entries))
+	return nil
+}
+
+func runKopiaRestoreFile(repository *executor.Repository, snapshotID, filePath, targetFile string) error {
+	restoreCmd, err := kopia.GetRestoreFileCommand(
+		repository.Path,
+		repository.Name,
+		repository.Password,
+		string(repository.Type),
+		snapshotID,
+		filePath,
+		targetFile,
+	)
+	if err != nil {
+		return err
+	}
+
+	restoreExecutor := kopia.NewRestoreExecutor(restoreCmd)
+	if err := restoreExecutor.Run(); err != nil {
+		return fmt.Errorf("failed to run restore command: %v", err)
+	}
+	for {
+		// A single file is usually restored in less than the progress
+		// interval of the whole snapshot restores.
+		time.Sleep(restoreFileCheckInterval)
+		status, err := restoreExecutor.Status()
+		if err != nil {
+			return err
+		}
+		if status.LastKnownError != nil {
+			return status.LastKnownError
+		}
+		if status.Done {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package kopia
+
+import (
+	"fmt"
+	"time"
+
+	storkv1 "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	kdmpapi "github.com/portworx/kdmp/pkg/apis/kdmp/v1alpha1"
+	"github.com/portworx/kdmp/pkg/executor"
+	"github.com/portworx/kdmp/pkg/kopia"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kubectl/pkg/cmd/util"
+)
+
+func newVerifyCommand() *cobra.Command {
+	var (
+		snapshotID            string
+		volumeBackupNamespace string
+		verifyFilesPercent    int
+	)
+	verifyCommand := &cobra.Command{
+		Use:   "verify",
+		Short: "verify the integrity of a backup snapshot",
+		Run: func(c *cobra.Command, args []string) {
+			if len(snapshotID) == 0 {
+				util.CheckErr(fmt.Errorf("snapshot-id has to be provided for kopia verify"))
+				return
+			}
+			executor.HandleErr(runVerify(snapshotID, verifyFilesPercent, volumeBackupNamespace))
+		},
+	}
+	verifyCommand.Flags().StringVar(&snapshotID, "snapshot-id", "", "snapshot ID for kopia backup snapshot that need to be verified")
+	verifyCommand.Flags().StringVar(&volumeBackupNamespace, "volume-backup-namespace", "", "Namespace of the VolumeBackup CR where the verification status will be stored")
+	verifyCommand.Flags().IntVar(&verifyFilesPercent, "verify-files-percent", 0, "Percentage of files whose content is read back, only the snapshot metadata is verified if not set")
+	return verifyCommand
+}
+
+func runVerify(snapshotID string, verifyFilesPercent int, volumeBackupNamespace string) error {
+	fn := "runVerify:"
+	verification := &kdmpapi.VolumeBackupVerification{
+		Status:             kdmpapi.VolumeBackupVerificationStatusInProgress,
+		SnapshotID:         snapshotID,
+		VerifyFilesPercent: verifyFilesPercent,
+	}
+	if err := executor.WriteVolumeBackupVerificationStatus(verification, volumeBackupName, volumeBackupNamespace); err != nil {
+		logrus.Errorf("%s %v", fn, err)
+		return err
+	}
+
+	if err := verifySnapshot(snapshotID, verifyFilesPercent); err != nil {
+		verification.Status = kdmpapi.VolumeBackupVerificationStatusFailed
+		verification.Reason = err.Error()
+		verification.LastVerifiedTimestamp = metav1.Now()
+		if statusErr := executor.WriteVolumeBackupVerificationStatus(verification, volumeBackupName, volumeBackupNamespace); statusErr != nil {
+			errMsg := fmt.Sprintf("failed in updating VolumeBackup CR [%s:%s]: %v", volumeBackupName, volumeBackupNamespace, statusErr)
+			logrus.Errorf("%v", errMsg)
+			return fmt.Errorf(errMsg)
+		}
+		return err
+	}
+
+	verification.Status = kdmpapi.VolumeBackupVerificationStatusSuccess
+	verification.LastVerifiedTimestamp = metav1.Now()
+	if err := executor.WriteVolumeBackupVerificationStatus(verification, volumeBackupName, volumeBackupNamespace); err != nil {
+		errMsg := fmt.Sprintf("failed in updating VolumeBackup CR [%s:%s]: %v", volumeBackupName, volumeBackupNamespace, err)
+		logrus.Errorf("%v", errMsg)
+		return fmt.Errorf(errMsg)
+	}
+	logrus.Infof("%s snapshot [%v] verified successfully", fn, snapshotID)
+	return nil
+}
+
+func verifySnapshot(snapshotID string, verifyFilesPercent int) error {
+	fn := "verifySnapshot:"
+	// Parse using the mounted secrets
+	repo, err := executor.ParseCloudCred()
+	if err != nil {
+		errMsg := fmt.Sprintf("failed in parsing backuplocation: %s", err)
+		logrus.Errorf("%s %v", fn, errMsg)
+		return fmt.Errorf(errMsg)
+	}
+
+	repo.Name = frameBackupPath()
+
+	if repo.Type == storkv1.BackupLocationNFS {
+		if !isNfsKopiaRepositoryFileExists(repo) {
+			errMsg := fmt.Sprintf("kopia repository file is not found in the NFS backuplocation for repo [%v]", repo.Name)
+			logrus.Errorf("%s %v", fn, errMsg)
+			return fmt.Errorf(errMsg)
+		}
+	}
+
+	if err := runKopiaRepositoryConnect(repo); err != nil {
+		errMsg := fmt.Sprintf("repository [%v] connect failed: %v", repo.Name, err)
+		logrus.Errorf("%s %v", fn, errMsg)
+		return fmt.Errorf(errMsg)
+	}
+
+	if err := runKopiaVerify(snapshotID, verifyFilesPercent); err != nil {
+		errMsg := fmt.Sprintf("snapshot [%v] verify failed: %v", snapshotID, err)
+		logrus.Errorf("%s %v", fn, errMsg)
+		return fmt.Errorf(errMsg)
+	}
+
+	return nil
+}
+
+func runKopiaVerify(snapshotID string, verifyFilesPercent int) error {
+	fn := "runKopiaVerify:"
+	logrus.Infof("kopia verify started for snapshot [%v] with %d percent of files read", snapshotID, verifyFilesPercent)
+	verifyCmd, err := kopia.GetVerifyCommand(snapshotID, verifyFilesPercent)
+	if err != nil {
+		errMsg := fmt.Sprintf("getting verify backup snapshot command for snapshot ID [%v] failed: %v", snapshotID, err)
+		logrus.Errorf("%s %v", fn, errMsg)
+		return fmt.Errorf(errMsg)
+	}
+	verifyExecutor := kopia.NewVerifyExecutor(verifyCmd)
+	if err := verifyExecutor.Run(); err != nil {
+		errMsg := fmt.Sprintf("running verify backup snapshot command for snapshotID [%v] failed: %v", snapshotID, err)
+		logrus.Errorf("%s %v", fn, errMsg)
+		return fmt.Errorf(errMsg)
+	}
+
+	for {
+		time.Sleep(progressCheckInterval)
+		status, err := verifyExecutor.Status()
+		if err != nil {
+			return err
+		}
+		if status.LastKnownError != nil {
+			return status.LastKnownError
+		}
+
+		if status.Done {
+			break
+		}
+	}
+	logrus.Infof("successfully verified snapshot with ID : [%v]", snapshotID)
+	return nil
+}
@@ -17,6 +17,7 @@ var (
 	rbCrNamespace        string
 	deCrName             string
 	deCrNamespace        string
+	dryRun               bool
 )
 
 // NewCommand returns a kopia command wrapper
@@ -15,7 +15,6 @@ import (
 	"github.com/libopenstorage/stork/drivers/volume/csi"
 	stork_api "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
 	"github.com/libopenstorage/stork/pkg/applicationmanager/controllers"
-	"github.com/libopenstorage/stork/pkg/crypto"
 	"github.com/libopenstorage/stork/pkg/k8sutils"
 	"github.com/libopenstorage/stork/pkg/log"
 	"github.com/libopenstorage/stork/pkg/resourcecollector"
@@ -126,59 +125,55 @@ func uploadBkpResource(
 	}
 
 	logrus.Infof("backup.ObjectMeta.Name: %v, string(backup.ObjectMeta.UID %v", backup.ObjectMeta.Name, string(backup.ObjectMeta.UID))
-	bkpDir := filepath.Join(repo.Path, bkpNamespace, backup.ObjectMeta.Name, string(backup.ObjectMeta.UID))
+	// The directories are relative to the root of the backup location
+	bkpDir := filepath.Join(bkpNamespace, backup.ObjectMeta.Name, string(backup.ObjectMeta.UID))
 	logrus.Infof("bkpDir: %v", bkpDir)
-	if err := os.MkdirAll(bkpDir, 0777); err != nil {
-		errMsg := fmt.Sprintf("%s: error creating backup dir: %v", funct, err)
-		logrus.Errorf(errMsg)
-		return fmt.Errorf(errMsg)
-	}
-	// First create the required directory
 	encryptionKey, err := getEncryptionKey(bkpNamespace, backup)
 	if err != nil {
 		logrus.Errorf("%s err: %v", funct, err)
 		return err
 	}
-	err = uploadCSISnapshots(bkpNamespace, backup, bkpDir, encryptionKey)
+	store, err := executor.NewResourceStore(repo, encryptionKey)
+	if err != nil {
+		errMsg := fmt.Sprintf("%s: error opening backup location %s: %v", funct, repo.Path, err)
+		logrus.Errorf(errMsg)
+		return fmt.Errorf(errMsg)
+	}
+	defer store.Close()
+	err = uploadCSISnapshots(bkpNamespace, backup, bkpDir, store)
 	if err != nil {
 		errMsg := fmt.Sprintf("%s: error uploading CSI snapshot file %v", funct, err)
 		logrus.Errorf(errMsg)
 		return fmt.Errorf(errMsg)
 	}
-	err = uploadStorageClasses(bkpNamespace, backup, bkpDir, encryptionKey)
+	err = uploadStorageClasses(bkpNamespace, backup, bkpDir, store)
 	if err != nil {
 		errMsg := fmt.Sprintf("%s: error uploading storageclasses %v", funct, err)
 		logrus.Errorf(errMsg)
 		return fmt.Errorf(errMsg)
 	}
-	err = uploadResource(bkpNamespace, backup, bkpDir, encryptionKey)
+	err = uploadResource(bkpNamespace, backup, bkpDir, store)
 	if err != nil {
 		errMsg := fmt.Sprintf("%s: error uploading resources: %v", funct, err)
 		logrus.Errorf(errMsg)
 		return fmt.Errorf(errMsg)
 	}
-	err = uploadNamespaces(bkpNamespace, backup, bkpDir, encryptionKey)
+	err = uploadNamespaces(bkpNamespace, backup, bkpDir, store)
 	if err != nil {
 		errMsg := fmt.Sprintf("%s: error uploading namespace resource %v", funct, err)
 		logrus.Errorf(errMsg)
 		return fmt.Errorf(errMsg)
 	}
 
-	csiGenericBackupDirectory := filepath.Join(repo.Path, volumeSnapShotCRDirectory)
+	csiGenericBackupDirectory := volumeSnapShotCRDirectory
 	logrus.Infof("csi generic backup directory: %v", csiGenericBackupDirectory)
-	if err := os.MkdirAll(csiGenericBackupDirectory, 0777); err != nil {
-		errMsg := fmt.Sprintf("%s: error creating csi generic backup directory: %v", funct, err)
-		logrus.Errorf(errMsg)
-		return fmt.Errorf(errMsg)
-	}
-
-	err = uploadCSISnapshotInfoForPVCs(bkpNamespace, backup, csiGenericBackupDirectory, encryptionKey)
+	err = uploadCSISnapshotInfoForPVCs(bkpNamespace, backup, csiGenericBackupDirectory, store)
 	if err != nil {
 		errMsg := fmt.Sprintf("%s: error uploading csi snapshot info for pvcs for generic backup %v", funct, err)
 		logrus.Errorf(errMsg)
 		return fmt.Errorf(errMsg)
 	}
-	err = uploadMetadatResources(bkpNamespace, backup, bkpDir, encryptionKey)
+	err = uploadMetadatResources(bkpNamespace, backup, bkpDir, store)
 	if err != nil {
 		errMsg := fmt.Sprintf("%s: error uploading metadata resource %v", funct, err)
 		logrus.Errorf(errMsg)
@@ -192,7 +187,7 @@ func uploadResource(
 	bkpNamespace string,
 	backup *stork_api.ApplicationBackup,
 	resourcePath string,
-	encryptionKey string,
+	store executor.ResourceStore,
 ) error {
 	funct := "uploadResource"
 	rc := initResourceCollector()
@@ -250,11 +245,73 @@ func uploadResource(
 		_, objectMap, _, _ = resourcecollector.GetVMIncludeResourceInfoList(vmList, objectMap, nsMap, true)
 
 	}
+	// Let's skip the PV and PVCs which got in failed state to be uploaded to backup location
+	failedVolInfoMap := make(map[string]stork_api.ApplicationBackupStatusType)
+	for _, vol := range backup.Status.Volumes {
+		if vol.Status == stork_api.ApplicationBackupStatusFailed {
+			failedVolInfoMap[vol.Volume] = vol.Status
+		}
+	}
+
+	// The resources are written as compressed chunks as they are collected, so
+	// that only one batch of resources and one chunk are held at a time.
+	writer := executor.NewResourceChunkWriter(executor.DefaultResourceChunkSize, func(name string, data []byte) error {
+		return store.Upload(resourcePath, name, data)
+	})
+	rancherProjects := make(map[string]string)
+	objectCount := 0
+	addObjects := func(objects []runtime.Unstructured) error {
+		// get and update rancher project details
+		if len(backup.Spec.PlatformCredential) != 0 {
+			if err := controllers.UpdateRancherProjectDetails(backup, objects); err != nil {
+				log.ApplicationBackupLog(backup).Errorf("error in updating the rancher project details - err: %v", err)
+				return err
+			}
+			for project, name := range backup.Spec.RancherProjects {
+				rancherProjects[project] = name
+			}
+		}
+		// Go through all the objects from the list
+		for _, obj := range objects {
+			objectType, err := meta.TypeAccessor(obj)
+			if err != nil {
+				return err
+			}
+			if objectType.GetKind() == "PersistentVolumeClaim" {
+				var pvc v1.PersistentVolumeClaim
+				// Find the matching object - PVC, and if it's state is undesired skip it.
+				if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.UnstructuredContent(), &pvc); err != nil {
+					return fmt.Errorf("error converting to persistent colume claim: %v", err)
+				}
+				if _, ok := failedVolInfoMap[pvc.Spec.VolumeName]; ok {
+					continue
+				}
+			} else if objectType.GetKind() == "PersistentVolume" {
+				var pv v1.PersistentVolume
+				// Again find persistent volume and check if it is in failed or skipped state. If yes, skip it.
+				if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.UnstructuredContent(), &pv); err != nil {
+					return fmt.Errorf("error converting to persistent volume: %v", err)
+				}
+				// Check if it already exsists in the map.
+				if _, ok := failedVolInfoMap[pv.Name]; ok {
+					continue
+				}
+			}
+			if err := writer.Add(obj); err != nil {
+				logrus.Errorf("%s: fail to write resource detail to backup location %v", funct, err)
+				return err
+			}
+			gvk := obj.GetObjectKind().GroupVersionKind()
+			resKinds[gvk.Kind] = gvk.Version
+			objectCount++
+		}
+		return nil
+	}
+
 	namespacelist := backup.Spec.Namespaces
 	// GetResources takes more time, if we have more number of namespaces
 	// So, submitting it in batches and in between each batch,
 	// updating the LastUpdateTimestamp to show that backup is progressing
-	allObjects := make([]runtime.Unstructured, 0)
 	optionalBackupResources := []string{"Job"}
 	for i := 0; i < len(namespacelist); i += backupResourcesBatchCount {
 		batch := namespacelist[i:min(i+backupResourcesBatchCount, len(namespacelist))]
@@ -293,7 +350,9 @@ func uploadResource(
 				log.ApplicationBackupLog(backup).Errorf("Error getting resources: %v", err)
 				return err
 			}
-			allObjects = append(allObjects, objects...)
+			if err := addObjects(objects); err != nil {
+				return err
+			}
 		}
 
 		if len(resourceTypeNsBatch) != 0 {
@@ -306,79 +365,27 @@ func uploadResource(
 							log.ApplicationBackupLog(backup).Errorf("Error getting resources: %v", err)
 							return err
 						}
-						allObjects = append(allObjects, objects.Items...)
+						if err := addObjects(objects.Items); err != nil {
+							return err
+						}
 					}
 				}
 			}
 		}
 	}
-	// get and update rancher project details
-	if len(backup.Spec.PlatformCredential) != 0 {
-		if err = controllers.UpdateRancherProjectDetails(backup, allObjects); err != nil {
-			log.ApplicationBackupLog(backup).Errorf("error in updating the rancher project details - err: %v", err)
-			return err
-		}
+	// The rancher projects are collected for each batch of resources
+	if len(rancherProjects) != 0 {
+		backup.Spec.RancherProjects = rancherProjects
 	}
 
-	// Let's skip the PV and PVCs which got in failed state to be uploaded to backup location
-	processPartialObjects := make([]runtime.Unstructured, 0)
-	failedVolInfoMap := make(map[string]stork_api.ApplicationBackupStatusType)
-	for _, vol := range backup.Status.Volumes {
-		if vol.Status == stork_api.ApplicationBackupStatusFailed {
-			failedVolInfoMap[vol.Volume] = vol.Status
-		}
-	}
-	// Go through all the objects from the list
-	for _, obj := range allObjects {
-		objectType, err := meta.TypeAccessor(obj)
-		if err != nil {
-			return err
-		}
-		if objectType.GetKind() == "PersistentVolumeClaim" {
-			var pvc v1.PersistentVolumeClaim
-			// Find the matching object - PVC, and if it's state is undesired skip it.
-			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.UnstructuredContent(), &pvc); err != nil {
-				return fmt.Errorf("error converting to persistent colume claim: %v", err)
-			}
-			if _, ok := failedVolInfoMap[pvc.Spec.VolumeName]; ok {
-				continue
-			}
-		} else if objectType.GetKind() == "PersistentVolume" {
-			var pv v1.PersistentVolume
-			// Again find persistent volume and check if it is in failed or skipped state. If yes, skip it.
-			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.UnstructuredContent(), &pv); err != nil {
-				return fmt.Errorf("error converting to persistent volume: %v", err)
-			}
-			// Check if it already exsists in the map.
-			if _, ok := failedVolInfoMap[pv.Name]; ok {
-				continue
-			}
-		}
-		processPartialObjects = append(processPartialObjects, obj)
-	}
-
-	// Update obj list - allObjects with skipped pv and pvcs
-	allObjects = processPartialObjects
-
-	// TODO: Need to create directory with UID GUID needed
-	// for nfs share
-	jsonBytes, err := json.MarshalIndent(allObjects, "", " ")
-	if err != nil {
-		logrus.Errorf("%s: Failing to json Marshal for resources in NFS path: %v", funct, err)
-		return err
-	}
-
-	err = uploadData(resourcePath, jsonBytes, resourcesFile, encryptionKey)
+	manifest, err := writer.Close()
 	if err != nil {
 		logrus.Errorf("%s: fail to write resource detail to backup location %v", funct, err)
 		return err
 	}
-	for _, obj := range allObjects {
-		gvk := obj.GetObjectKind().GroupVersionKind()
-		resKinds[gvk.Kind] = gvk.Version
-	}
+	logrus.Infof("%s: wrote %d resources in %d chunks", funct, objectCount, len(manifest.Chunks))
 	// upload CRD to backuplocation
-	err = uploadCRDResources(resKinds, resourcePath, backup, encryptionKey)
+	err = uploadCRDResources(resKinds, resourcePath, backup, store)
 	if err != nil {
 		return err
 	}
@@ -389,7 +396,7 @@ func uploadStorageClasses(
 	bkpNamespace string,
 	backup *stork_api.ApplicationBackup,
 	resourcePath string,
-	encryptionKey string,
+	store executor.ResourceStore,
 ) error {
 	funct := "uploadStorageClasses"
 	storageClassAdded := make(map[string]bool)
@@ -426,7 +433,7 @@ func uploadStorageClasses(
 	if err != nil {
 		return err
 	}
-	err = uploadData(resourcePath, scJSONBytes, storageClassFile, encryptionKey)
+	err = store.Upload(resourcePath, storageClassFile, scJSONBytes)
 	if err != nil {
 		logrus.Errorf("%s err: %v", funct, err)
 		return err
@@ -438,7 +445,7 @@ func uploadCSISnapshots(
 	bkpNamespace string,
 	backup *stork_api.ApplicationBackup,
 	resourcePath string,
-	encryptionKey string,
+	store executor.ResourceStore,
 ) error {
 	funct := "uploadStorageClasses"
 	// snapshot.json changes
@@ -553,7 +560,7 @@ func uploadCSISnapshots(
 	if err != nil {
 		return err
 	}
-	err = uploadData(resourcePath, csiBackupBytes, csi.SnapshotObjectName, encryptionKey)
+	err = store.Upload(resourcePath, csi.SnapshotObjectName, csiBackupBytes)
 	if err != nil {
 		logrus.Errorf("%s err: %v", funct, err)
 		return err
@@ -566,7 +573,7 @@ func uploadCSISnapshotInfoForPVCs(
 	bkpNamespace string,
 	backup *stork_api.ApplicationBackup,
 	resourcePath string,
-	encryptionKey string,
+	store executor.ResourceStore,
 ) error {
 	funct := "uploadCSISnapshotInfoForPVCs"
 	backupUID := getAnnotationValueFromApplicationBackup(backup, backupObjectUIDKeyInBackupCR)
@@ -576,7 +583,7 @@ func uploadCSISnapshotInfoForPVCs(
 			if len(volumeSnapshot) > 0 {
 				_, volumeSnapshot := splitVolumeSnapshotInfoInVolumeInfo(volumeSnapshot)
 				timestampEpoch := strconv.FormatInt(backup.GetObjectMeta().GetCreationTimestamp().Unix(), 10)
-				err := uploadSnapshotObjectsForPVC(volumeSnapshot, volInfo.Namespace, getCSICRUploadDirectory(resourcePath, volInfo.PersistentVolumeClaimUID), getVSFileName(backupUID, timestampEpoch), encryptionKey)
+				err := uploadSnapshotObjectsForPVC(volumeSnapshot, volInfo.Namespace, getCSICRUploadDirectory(resourcePath, volInfo.PersistentVolumeClaimUID), getVSFileName(backupUID, timestampEpoch), store)
 				if err != nil {
 					logrus.Errorf("%s err: %v", funct, err)
 					return err
@@ -604,7 +611,7 @@ func uploadSnapshotObjectsForPVC(
 	namespace string,
 	resourcePath string,
 	objectName string,
-	encryptionKey string,
+	store executor.ResourceStore,
 ) error {
 	funct := "uploadSnapshotObjectsForPVC"
 	snapshotter, err := snapshotter.NewCSIDriver()
@@ -706,7 +713,7 @@ func uploadSnapshotObjectsForPVC(
 	if err != nil {
 		return err
 	}
-	err = uploadData(resourcePath, csiBackupBytes, objectName, encryptionKey)
+	err = store.Upload(resourcePath, objectName, csiBackupBytes)
 	if err != nil {
 		logrus.Errorf("%s err: %v", funct, err)
 		return err
@@ -741,7 +748,7 @@ func uploadNamespaces(
 	bkpNamespace string,
 	backup *stork_api.ApplicationBackup,
 	resourcePath string,
-	encryptionKey string,
+	store executor.ResourceStore,
 ) error {
 	funct := "uploadNamespaces"
 	var namespaces []*v1.Namespace
@@ -761,7 +768,7 @@ func uploadNamespaces(
 		return err
 	}
 
-	err = uploadData(resourcePath, jsonBytes, namespacesFile, encryptionKey)
+	err = store.Upload(resourcePath, namespacesFile, jsonBytes)
 	if err != nil {
 		logrus.Errorf("%s err: %v", funct, err)
 		return err
@@ -774,7 +781,7 @@ func uploadCRDResources(
 	resKinds map[string]string,
 	resourcePath string,
 	backup *stork_api.ApplicationBackup,
-	encryptionKey string,
+	store executor.ResourceStore,
 ) error {
 	funct := "uploadCRDResources"
 	crdList, err := storkops.Instance().ListApplicationRegistrations()
@@ -827,7 +834,7 @@ func uploadCRDResources(
 			return err
 		}
 
-		err = uploadData(resourcePath, jsonBytes, crdFile, encryptionKey)
+		err = store.Upload(resourcePath, crdFile, jsonBytes)
 		if err != nil {
 			logrus.Errorf("%s err: %v", funct, err)
 			return err
@@ -869,7 +876,7 @@ func uploadCRDResources(
 		logrus.Errorf("%s err: %v", funct, err)
 		return err
 	}
-	err = uploadData(resourcePath, jsonBytes, crdFile, encryptionKey)
+	err = store.Upload(resourcePath, crdFile, jsonBytes)
 	if err != nil {
 		logrus.Errorf("%s err: %v", funct, err)
 		return err
@@ -889,7 +896,7 @@ func uploadMetadatResources(
 	bkpNamespace string,
 	backup *stork_api.ApplicationBackup,
 	resourcePath string,
-	encryptionKey string,
+	store executor.ResourceStore,
 ) error {
 	funct := "uploadMetadatResources"
 	// In the in-memory copy alone, we will update the backup status to success.
@@ -909,47 +916,11 @@ func uploadMetadatResources(
 		return err
 	}
 
-	err = uploadData(resourcePath, jsonBytes, metadataObjectName, encryptionKey)
-	if err != nil {
-		logrus.Errorf("%s err: %v", funct, err)
-		return err
-	}
-	return nil
-}
-
-func uploadData(
-	resourcePath string,
-	data []byte,
-	resourceFileName string,
-	encryptionKey string,
-) error {
-	var err error
-	var encryptedData []byte
-	funct := "uploadData"
-
-	logrus.Infof("resourcepath needs to be created: %v", resourcePath)
-	if err := os.MkdirAll(resourcePath, 0777); err != nil {
-		errMsg := fmt.Sprintf("%s: error creating resourcepath: %v", funct, err)
-		logrus.Errorf(errMsg)
-		return fmt.Errorf(errMsg)
-	}
-
-	filePath := filepath.Join(resourcePath, resourceFileName)
-	// Encrypt data before writing with passed encryption key
-	if encryptionKey != "" {
-		if encryptedData, err = crypto.Encrypt(data, encryptionKey); err != nil {
-			logrus.Errorf("nfs %s: encryption failed :%v, writing unencrypted data", funct, err)
-			return err
-		}
-		data = encryptedData
-	}
-	//TODO: Writing with 777 permision .. Any security implication ???
-	err = os.WriteFile(filePath, data, 0777)
+	err = store.Upload(resourcePath, metadataObjectName, jsonBytes)
 	if err != nil {
 		logrus.Errorf("%s err: %v", funct, err)
 		return err
 	}
-
 	return nil
 }
 
@@ -252,6 +252,9 @@ func createS3Secret(secretName string, backupLocation *storkapi.BackupLocation,
 	credentialData["type"] = []byte(backupLocation.Location.Type)
 	credentialData["password"] = []byte(backupLocation.Location.RepositoryPassword)
 	credentialData["disablessl"] = []byte(strconv.FormatBool(backupLocation.Location.S3Config.DisableSSL))
+	if err := utils.AddObjectLockCredentials(credentialData, backupLocation); err != nil {
+		return err
+	}
 	err := utils.CreateJobSecret(secretName, namespace, credentialData, labels)
 
 	return err
@@ -1,7 +1,6 @@
 package nfs
 
 import (
-	"os"
 	"path/filepath"
 
 	"github.com/portworx/kdmp/pkg/executor"
@@ -33,9 +32,14 @@ func deleteResources(
 		logrus.Errorf("%s: error parsing cloud cred: %v", funct, rErr)
 		return rErr
 	}
-	resourceDir := filepath.Join(repo.Path, bkpNamespace, applicationCRName)
+	store, err := executor.NewResourceStore(repo, "")
+	if err != nil {
+		logrus.Errorf("%s: error opening backup location %s: %v", funct, repo.Path, err)
+		return err
+	}
+	defer store.Close()
 
-	if err := os.RemoveAll(resourceDir); err != nil {
+	if err := store.DeleteAll(filepath.Join(bkpNamespace, applicationCRName)); err != nil {
 		logrus.Errorf("%s: error deleting resources: %v", funct, err)
 	}
 
@@ -0,0 +1,196 @@
+package nfs
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	storkapi "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"github.com/libopenstorage/stork/pkg/log"
+	"github.com/libopenstorage/stork/pkg/resourcecollector"
+	kdmpapi "github.com/portworx/kdmp/pkg/apis/kdmp/v1alpha1"
+	"github.com/portworx/kdmp/pkg/executor"
+	"github.com/portworx/sched-ops/k8s/core"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/kubernetes/pkg/registry/core/service/portallocator"
+)
+
+var dryRunAll = []string{metav1.DryRunAll}
+
+// planResources reports in the ResourceBackup CR what the restore would do
+// with each resource. The resources are prepared as for the restore and then
+// sent as server-side dry-run requests, nothing is changed in the cluster.
+func planResources(
+	restore *storkapi.ApplicationRestore,
+	rb *kdmpapi.ResourceBackup,
+	objects []runtime.Unstructured,
+) error {
+	resourceCollector := initResourceCollector()
+	dynamicInterface, err := getDynamicInterface()
+	if err != nil {
+		return err
+	}
+	pvNameMappings, err := getPVNameMappings(restore, objects)
+	if err != nil {
+		return err
+	}
+	opts := getApplyOptions(restore)
+
+	rb.Status.ResourceApplyStage = kdmpapi.ResourceApplyPhasePreparing
+	rb, err = executor.UpdateStatusInResourceBackup(rb.Status, rb.Name, rb.Namespace)
+	if err != nil {
+		logrus.Errorf("failed to update resorucebackup[%v/%v] status: %v", rbCrNamespace, rbCrName, err)
+	}
+	objects, err = prepareResources(restore, &resourceCollector, objects, pvNameMappings, &opts)
+	if err != nil {
+		return err
+	}
+	missingNamespaces, err := getMissingNamespaces(restore)
+	if err != nil {
+		return err
+	}
+
+	resources := make([]*kdmpapi.ResourceRestoreResourceInfo, 0, len(objects))
+	for _, o := range objects {
+		plan, reason, err := planResource(dynamicInterface, restore, o, missingNamespaces, &opts)
+		if err != nil {
+			return err
+		}
+		metadata, err := meta.Accessor(o)
+		if err != nil {
+			return err
+		}
+		gvk := o.GetObjectKind().GroupVersionKind()
+		log.ApplicationRestoreLog(restore).Infof("Dry-run %v %v/%v: %v %v", gvk.Kind, metadata.GetNamespace(), metadata.GetName(), plan, reason)
+		resources = append(resources, &kdmpapi.ResourceRestoreResourceInfo{
+			ObjectInfo: kdmpapi.ObjectInfo{
+				Name:      metadata.GetName(),
+				Namespace: metadata.GetNamespace(),
+				GroupVersionKind: metav1.GroupVersionKind{
+					Group:   gvk.Group,
+					Version: gvk.Version,
+					Kind:    gvk.Kind,
+				},
+			},
+			Plan:   plan,
+			Reason: reason,
+		})
+	}
+	rb.Status.Resources = resources
+	rb.Status.RestoredResourceCount = 0
+	return updateResourceBackupResources(restore, rb)
+}
+
+// getMissingNamespaces returns the namespaces the restore would create.
+func getMissingNamespaces(restore *storkapi.ApplicationRestore) (map[string]bool, error) {
+	missing := make(map[string]bool)
+	for _, namespace := range restore.Spec.NamespaceMapping {
+		_, err := core.Instance().GetNamespace(namespace)
+		if errors.IsNotFound(err) {
+			missing[namespace] = true
+		} else if err != nil {
+			return nil, fmt.Errorf("error getting namespace %s: %v", namespace, err)
+		}
+	}
+	return missing, nil
+}
+
+// planResource returns what the restore would do with a resource and why.
+func planResource(
+	dynamicInterface dynamic.Interface,
+	restore *storkapi.ApplicationRestore,
+	object runtime.Unstructured,
+	missingNamespaces map[string]bool,
+	opts *resourcecollector.Options,
+) (kdmpapi.ResourceRestorePlan, string, error) {
+	metadata, err := meta.Accessor(object)
+	if err != nil {
+		return "", "", err
+	}
+	// The requests would fail on the namespace, not on the resource
+	if missingNamespaces[metadata.GetNamespace()] {
+		return kdmpapi.ResourceRestorePlanCreate,
+			fmt.Sprintf("Namespace %s would be created, the resource could not be validated", metadata.GetNamespace()), nil
+	}
+
+	dynamicClient, err := getDynamicClient(dynamicInterface, object)
+	if err != nil {
+		return "", "", err
+	}
+	obj := object.(*unstructured.Unstructured)
+	_, err = dynamicClient.Create(context.TODO(), obj, metav1.CreateOptions{DryRun: dryRunAll})
+	if err == nil {
+		return kdmpapi.ResourceRestorePlanCreate, "", nil
+	}
+	if strings.Contains(err.Error(), portallocator.ErrAllocated.Error()) {
+		return kdmpapi.ResourceRestorePlanCreate, "Node port is already allocated, the service would be created with a new node port", nil
+	}
+	if errors.IsNotFound(err) {
+		// The CRDs aren't created by a dry-run
+		return kdmpapi.ResourceRestorePlanCreate, "Resource type is not known to the cluster, it would be created with the CRDs of the backup", nil
+	}
+	if !errors.IsAlreadyExists(err) {
+		return kdmpapi.ResourceRestorePlanWouldFail, fmt.Sprintf("Error applying resource: %v", err), nil
+	}
+
+	// These resources are merged with the existing ones whatever the
+	// ReplacePolicy, as done by the resource collector.
+	if isMergedResource(obj.GetKind(), opts) {
+		return kdmpapi.ResourceRestorePlanReplace, "Resource would be merged with the existing one", nil
+	}
+	if restore.Spec.ReplacePolicy != storkapi.ApplicationRestoreReplacePolicyDelete {
+		return kdmpapi.ResourceRestorePlanSkipExisting, "Resource restore skipped as it was already present and ReplacePolicy is set to Retain", nil
+	}
+
+	err = dynamicClient.Delete(context.TODO(), metadata.GetName(), metav1.DeleteOptions{DryRun: dryRunAll})
+	if err != nil && !errors.IsNotFound(err) {
+		return kdmpapi.ResourceRestorePlanWouldFail, fmt.Sprintf("Error deleting existing resource: %v", err), nil
+	}
+	reason := "Existing resource would be deleted and created again"
+	existing, err := dynamicClient.Get(context.TODO(), metadata.GetName(), metav1.GetOptions{})
+	if err == nil {
+		// An update shows the fields that differ from the existing resource
+		// and can't be changed in place.
+		update := obj.DeepCopy()
+		update.SetResourceVersion(existing.GetResourceVersion())
+		if _, err := dynamicClient.Update(context.TODO(), update, metav1.UpdateOptions{DryRun: dryRunAll}); err != nil {
+			reason = fmt.Sprintf("%s, it can't be updated in place: %v", reason, err)
+		}
+	}
+	return kdmpapi.ResourceRestorePlanReplace, reason, nil
+}
+
+func isMergedResource(kind string, opts *resourcecollector.Options) bool {
+	switch kind {
+	case "ClusterRoleBinding", "ServiceAccount":
+		return true
+	case "NetworkPolicy", "Deployment", "StatefulSet", "DeploymentConfig",
+		"IBPPeer", "IBPCA", "IBPConsole", "IBPOrderer", "ReplicaSet":
+		return opts != nil && len(opts.RancherProjectMappings) != 0
+	}
+	return false
+}
+
+func getDynamicClient(
+	dynamicInterface dynamic.Interface,
+	object runtime.Unstructured,
+) (dynamic.ResourceInterface, error) {
+	metadata, err := meta.Accessor(object)
+	if err != nil {
+		return nil, err
+	}
+	objectType, err := meta.TypeAccessor(object)
+	if err != nil {
+		return nil, err
+	}
+
+	resource := resourcecollector.GetDefaultRuleSet().Pluralize(strings.ToLower(objectType.GetKind()))
+	return dynamicInterface.Resource(
+		object.GetObjectKind().GroupVersionKind().GroupVersion().WithResource(resource)).Namespace(metadata.GetNamespace()), nil
+}
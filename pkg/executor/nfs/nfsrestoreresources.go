@@ -4,7 +4,6 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
@@ -19,6 +18,7 @@ import (
 	kdmpapi "github.com/portworx/kdmp/pkg/apis/kdmp/v1alpha1"
 	"github.com/portworx/kdmp/pkg/drivers/utils"
 	"github.com/portworx/kdmp/pkg/executor"
+	kdmpopts "github.com/portworx/kdmp/pkg/util/ops"
 	coreapi "k8s.io/kubernetes/pkg/apis/core"
 
 	//"github.com/portworx/sched-ops/k8s/core"
@@ -56,13 +56,18 @@ func downloadStorageClass(
 		logrus.Errorf("%s: error parsing cloud cred: %v", funct, err)
 		return nil, err
 	}
-	bkpDir := filepath.Join(repo.Path, backup.Status.BackupPath)
+	bkpDir := backup.Status.BackupPath
 
 	restoreLocation, err := storkops.Instance().GetBackupLocation(backup.Spec.BackupLocation, namespace)
 	if err != nil {
 		return nil, err
 	}
-	data, err := executor.DownloadObject(bkpDir, "storageclass.json", restoreLocation.Location.EncryptionV2Key)
+	store, err := executor.NewResourceStore(repo, restoreLocation.Location.EncryptionV2Key)
+	if err != nil {
+		return nil, err
+	}
+	defer store.Close()
+	data, err := store.Download(bkpDir, storageClassFile)
 	if err != nil {
 		return nil, fmt.Errorf("error downloading storageclass: %v", err)
 	}
@@ -91,13 +96,14 @@ func newRestoreResourcesCommand() *cobra.Command {
 		Use:   "restore",
 		Short: "Start a resource backup to nfs target",
 		Run: func(c *cobra.Command, args []string) {
-			executor.HandleErr(restoreAndApplyResources(applicationrestoreCR, restoreNamespace, rbCrName, rbCrNamespace))
+			executor.HandleErr(restoreAndApplyResources(applicationrestoreCR, restoreNamespace, rbCrName, rbCrNamespace, dryRun))
 		},
 	}
 	restoreCommand.Flags().StringVarP(&restoreNamespace, "restore-namespace", "", "", "Namespace for restore CR")
 	restoreCommand.Flags().StringVarP(&applicationrestoreCR, "app-cr-name", "", "", "application restore CR name")
 	restoreCommand.Flags().StringVarP(&rbCrName, "rb-cr-name", "", "", "Name for resourcebackup CR to update job status")
 	restoreCommand.Flags().StringVarP(&rbCrNamespace, "rb-cr-namespace", "", "", "Namespace for resourcebackup CR to update job status")
+	restoreCommand.Flags().BoolVarP(&dryRun, "dry-run", "", false, "Report in the resourcebackup CR what the restore would do with each resource, without changing the cluster")
 
 	return restoreCommand
 }
@@ -107,9 +113,14 @@ func restoreAndApplyResources(
 	restoreNamespace string,
 	rbCrName string,
 	rbCrNamespace string,
+	dryRun bool,
 ) error {
 
-	err := executor.CreateNamespacesFromMapping(applicationCRName, restoreNamespace)
+	var err error
+	// The namespaces are created by the restore, a dry-run only reports them.
+	if !dryRun {
+		err = executor.CreateNamespacesFromMapping(applicationCRName, restoreNamespace)
+	}
 	if err != nil {
 		//update resourcebackup CR with status and reason
 		logrus.Errorf("restore resources for [%v/%v] failed with error: %v", rbCrNamespace, rbCrName, err.Error())
@@ -125,7 +136,7 @@ func restoreAndApplyResources(
 		}
 		return err
 	}
-	err = restoreResources(applicationCRName, restoreNamespace, rbCrName, rbCrNamespace)
+	err = restoreResources(applicationCRName, restoreNamespace, rbCrName, rbCrNamespace, dryRun)
 	if err != nil {
 		//update resourcebackup CR with status and reason
 		logrus.Errorf("restore resources for [%v/%v] failed with error: %v", rbCrNamespace, rbCrName, err.Error())
@@ -161,6 +172,7 @@ func restoreResources(
 	restoreNamespace string,
 	rbCrName string,
 	rbCrNamespace string,
+	dryRun bool,
 ) error {
 	restore, err := storkops.Instance().GetApplicationRestore(applicationCRName, restoreNamespace)
 	if err != nil {
@@ -179,26 +191,53 @@ func restoreResources(
 		log.ApplicationRestoreLog(restore).Errorf("Error getting backup: %v", err)
 		return err
 	}
-	objects, err := downloadResources(backup, restore.Spec.BackupLocation, restore.Namespace)
+	// A dry-run doesn't create the CRDs
+	objects, err := downloadResources(backup, restore.Spec.BackupLocation, restore.Namespace, !dryRun)
 	if err != nil {
 		log.ApplicationRestoreLog(restore).Errorf("Error downloading resources: %v", err)
 		return err
 	}
 
+	if err := transformResources(restore, objects); err != nil {
+		log.ApplicationRestoreLog(restore).Errorf("Error transforming resources: %v", err)
+		return err
+	}
+
+	if dryRun {
+		return planResources(restore, rb, objects)
+	}
 	if err := applyResources(restore, rb, objects); err != nil {
 		return err
 	}
 	return nil
 }
 
+// transformResources applies the RestoreTransformation referenced by the
+// restore, if any, to the resources.
+func transformResources(
+	restore *storkapi.ApplicationRestore,
+	objects []runtime.Unstructured,
+) error {
+	name := restore.Annotations[kdmpapi.RestoreTransformationAnnotation]
+	if name == "" {
+		return nil
+	}
+	transformation, err := kdmpopts.Instance().GetRestoreTransformation(context.TODO(), name, restore.Namespace)
+	if err != nil {
+		return fmt.Errorf("error getting restoretransformation %s/%s: %v", restore.Namespace, name, err)
+	}
+	log.ApplicationRestoreLog(restore).Infof("Applying restoretransformation %s/%s to the resources", restore.Namespace, name)
+	return executor.TransformResources(&transformation.Spec, objects)
+}
+
 func downloadCRD(
+	store executor.ResourceStore,
 	resourcePath string,
 	resourceFileName string,
-	encryptionKey string,
 ) error {
 	var crds []*apiextensionsv1beta1.CustomResourceDefinition
 	var crdsV1 []*apiextensionsv1.CustomResourceDefinition
-	crdData, err := executor.DownloadObject(resourcePath, resourceFileName, encryptionKey)
+	crdData, err := store.Download(resourcePath, resourceFileName)
 	if err != nil {
 		return err
 	}
@@ -282,6 +321,7 @@ func downloadResources(
 	backup *storkapi.ApplicationBackup,
 	backupLocation string,
 	namespace string,
+	createCRDs bool,
 ) ([]runtime.Unstructured, error) {
 	funct := "downloadResources"
 	repo, err := executor.ParseCloudCred()
@@ -289,28 +329,65 @@ func downloadResources(
 		logrus.Errorf("%s: error parsing cloud cred: %v", funct, err)
 		return nil, err
 	}
-	bkpDir := filepath.Join(repo.Path, backup.Status.BackupPath)
+	bkpDir := backup.Status.BackupPath
 
 	restoreLocation, err := storkops.Instance().GetBackupLocation(backup.Spec.BackupLocation, namespace)
 	if err != nil {
 		return nil, err
 	}
+	store, err := executor.NewResourceStore(repo, restoreLocation.Location.EncryptionV2Key)
+	if err != nil {
+		return nil, err
+	}
+	defer store.Close()
 	// create CRD resource first
-	if err := downloadCRD(bkpDir, crdFile, restoreLocation.Location.EncryptionV2Key); err != nil {
-		return nil, fmt.Errorf("error downloading CRDs: %v", err)
+	if createCRDs {
+		if err := downloadCRD(store, bkpDir, crdFile); err != nil {
+			return nil, fmt.Errorf("error downloading CRDs: %v", err)
+		}
 	}
-	data, err := executor.DownloadObject(bkpDir, resourcesFile, restoreLocation.Location.EncryptionV2Key)
+	return downloadResourceObjects(store, bkpDir)
+}
+
+// downloadResourceObjects reads the resources of a backup, from the chunks of
+// the manifest for the format version 2 backups and from the resources file for
+// the older ones. The chunks are read one at a time, only their resources are
+// kept as they are all needed to order the restore.
+func downloadResourceObjects(store executor.ResourceStore, bkpDir string) ([]runtime.Unstructured, error) {
+	exists, err := store.Exists(bkpDir, executor.ResourcesManifestFile)
 	if err != nil {
-		return nil, fmt.Errorf("error downloading resources: %v", err)
+		return nil, fmt.Errorf("error checking resources manifest: %v", err)
+	}
+	runtimeObjects := make([]runtime.Unstructured, 0)
+	if !exists {
+		data, err := store.Download(bkpDir, resourcesFile)
+		if err != nil {
+			return nil, fmt.Errorf("error downloading resources: %v", err)
+		}
+		objects := make([]*unstructured.Unstructured, 0)
+		if err = json.Unmarshal(data, &objects); err != nil {
+			return nil, err
+		}
+		for _, o := range objects {
+			runtimeObjects = append(runtimeObjects, o)
+		}
+		return runtimeObjects, nil
 	}
 
-	objects := make([]*unstructured.Unstructured, 0)
-	if err = json.Unmarshal(data, &objects); err != nil {
-		return nil, err
+	manifest, err := store.Download(bkpDir, executor.ResourcesManifestFile)
+	if err != nil {
+		return nil, fmt.Errorf("error downloading resources manifest: %v", err)
 	}
-	runtimeObjects := make([]runtime.Unstructured, 0)
-	for _, o := range objects {
-		runtimeObjects = append(runtimeObjects, o)
+	err = executor.ReadResourceChunks(manifest, func(name string) ([]byte, error) {
+		return store.Download(bkpDir, name)
+	}, func(objects []*unstructured.Unstructured) error {
+		for _, o := range objects {
+			runtimeObjects = append(runtimeObjects, o)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error downloading resources: %v", err)
 	}
 	return runtimeObjects, nil
 }
@@ -546,23 +623,7 @@ func updateResourceStatus(
 	return tempResourceList, nil
 }
 
-func applyResources(
-	restore *storkapi.ApplicationRestore,
-	rb *kdmpapi.ResourceBackup,
-	objects []runtime.Unstructured,
-) error {
-	fn := "nfsExecutorApplyResource"
-	resourceCollector := initResourceCollector()
-	dynamicInterface, err := getDynamicInterface()
-	if err != nil {
-		return err
-	}
-	pvNameMappings, err := getPVNameMappings(restore, objects)
-	if err != nil {
-		return err
-	}
-	objectMap := storkapi.CreateObjectsMap(restore.Spec.IncludeResources)
-	tempObjects := make([]runtime.Unstructured, 0)
+func getApplyOptions(restore *storkapi.ApplicationRestore) resourcecollector.Options {
 	var opts resourcecollector.Options
 	if len(restore.Spec.RancherProjectMapping) != 0 {
 		rancherProjectMapping := getRancherProjectMapping(restore)
@@ -570,12 +631,20 @@ func applyResources(
 			RancherProjectMappings: rancherProjectMapping,
 		}
 	}
+	return opts
+}
 
-	rb.Status.ResourceApplyStage = kdmpapi.ResourceApplyPhasePreparing
-	rb, err = executor.UpdateStatusInResourceBackup(rb.Status, rb.Name, rb.Namespace)
-	if err != nil {
-		logrus.Errorf("failed to update resorucebackup[%v/%v] status after hitting error in create namespace : %v", rbCrNamespace, rbCrName, err)
-	}
+// prepareResources transforms the resources for the restore and returns the
+// ones to apply.
+func prepareResources(
+	restore *storkapi.ApplicationRestore,
+	resourceCollector *resourcecollector.ResourceCollector,
+	objects []runtime.Unstructured,
+	pvNameMappings map[string]string,
+	opts *resourcecollector.Options,
+) ([]runtime.Unstructured, error) {
+	objectMap := storkapi.CreateObjectsMap(restore.Spec.IncludeResources)
+	tempObjects := make([]runtime.Unstructured, 0)
 	for _, o := range objects {
 		skip, err := resourceCollector.PrepareResourceForApply(
 			o,
@@ -586,21 +655,45 @@ func applyResources(
 			pvNameMappings,
 			restore.Spec.IncludeOptionalResourceTypes,
 			restore.Status.Volumes,
-			&opts,
+			opts,
 			restore.Spec.BackupLocation,
 			restore.Namespace,
 		)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		if !skip {
 			tempObjects = append(tempObjects, o)
 		}
 	}
-	objects = tempObjects
 
 	// skip CSI PV/PVCs before applying
-	objects, err = removeCSIVolumesBeforeApply(restore, objects)
+	return removeCSIVolumesBeforeApply(restore, tempObjects)
+}
+
+func applyResources(
+	restore *storkapi.ApplicationRestore,
+	rb *kdmpapi.ResourceBackup,
+	objects []runtime.Unstructured,
+) error {
+	fn := "nfsExecutorApplyResource"
+	resourceCollector := initResourceCollector()
+	dynamicInterface, err := getDynamicInterface()
+	if err != nil {
+		return err
+	}
+	pvNameMappings, err := getPVNameMappings(restore, objects)
+	if err != nil {
+		return err
+	}
+	opts := getApplyOptions(restore)
+
+	rb.Status.ResourceApplyStage = kdmpapi.ResourceApplyPhasePreparing
+	rb, err = executor.UpdateStatusInResourceBackup(rb.Status, rb.Name, rb.Namespace)
+	if err != nil {
+		logrus.Errorf("failed to update resorucebackup[%v/%v] status after hitting error in create namespace : %v", rbCrNamespace, rbCrName, err)
+	}
+	objects, err = prepareResources(restore, &resourceCollector, objects, pvNameMappings, &opts)
 	if err != nil {
 		return err
 	}
@@ -701,6 +794,12 @@ func applyResources(
 	// append the temp slice to the final restore resouce list,
 	rb.Status.Resources = append(rb.Status.Resources, tempResourceList...)
 	rb.Status.RestoredResourceCount = int64(len(rb.Status.Resources))
+	return updateResourceBackupResources(restore, rb)
+}
+
+// updateResourceBackupResources updates the resources of the ResourceBackup
+// CR, stripping them if the CR is larger than the large resource size limit.
+func updateResourceBackupResources(restore *storkapi.ApplicationRestore, rb *kdmpapi.ResourceBackup) error {
 	// Need to strip the Resources array, large resource scenario can fail due to etcd size limit
 	rbCrSize, err := storkutils.GetSizeOfObject(rb)
 	if err != nil {
@@ -732,7 +831,7 @@ func applyResources(
 	}
 	_, err = kdmpschedops.Instance().UpdateResourceBackup(rb)
 	if err != nil {
-		errMsg := fmt.Sprintf("applyResources: error updating ResourceBackup CR[%v/%v]: %v", rb.Namespace, rb.Name, err)
+		errMsg := fmt.Sprintf("updateResourceBackupResources: error updating ResourceBackup CR[%v/%v]: %v", rb.Namespace, rb.Name, err)
 		logrus.Infof("%v", errMsg)
 		return fmt.Errorf(errMsg)
 	}
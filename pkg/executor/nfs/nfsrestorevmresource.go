@@ -72,12 +72,17 @@ func restoreVMResources(
 		log.ApplicationRestoreLog(restore).Errorf(errMsg)
 		return updateStatusOnError(fmt.Errorf(errMsg))
 	}
-	objects, err := downloadResources(backup, restore.Spec.BackupLocation, restore.Namespace)
+	objects, err := downloadResources(backup, restore.Spec.BackupLocation, restore.Namespace, true)
 	if err != nil {
 		errMsg := fmt.Sprintf("error downloading resources from backupLocation [%v/%v]: %v", rbCrNamespace, rbCrName, err)
 		log.ApplicationRestoreLog(restore).Errorf(errMsg)
 		return updateStatusOnError(fmt.Errorf(errMsg))
 	}
+	if err := transformResources(restore, objects); err != nil {
+		errMsg := fmt.Sprintf("error transforming resources for [%v/%v]: %v", rbCrNamespace, rbCrName, err)
+		log.ApplicationRestoreLog(restore).Errorf(errMsg)
+		return updateStatusOnError(fmt.Errorf(errMsg))
+	}
 	// get objectMap of includeResources
 	objectMap := storkapi.CreateObjectsMap(restore.Spec.IncludeResources)
 	includeResourceList, err := resourcecollector.GetVMResourcesFromResourceObject(objects, objectMap)
@@ -96,11 +96,16 @@ func restoreVolResourcesAndApply(
 	}
 	// Iterate over all the vol info from the backup spec
 
-	objects, err := downloadResources(backup, restore.Spec.BackupLocation, restore.Namespace)
+	objects, err := downloadResources(backup, restore.Spec.BackupLocation, restore.Namespace, true)
 	if err != nil {
 		log.ApplicationRestoreLog(restore).Errorf("Error downloading resources: %v", err)
 		return err
 	}
+	// The PVCs are created with the storage classes of the transformation
+	if err := transformResources(restore, objects); err != nil {
+		log.ApplicationRestoreLog(restore).Errorf("Error transforming resources: %v", err)
+		return err
+	}
 	var isCsiDriver bool
 	for _, volume := range backup.Status.Volumes {
 		if volume.DriverName == "csi" {
@@ -0,0 +1,200 @@
+package executor
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	// ResourcesManifestFile lists the resource chunks of a format version 2
+	// backup. Backups without it are format version 1, with all the resources
+	// in ResourcesFile.
+	ResourcesManifestFile = "resources-manifest.json"
+	// ResourcesFormatVersion is the format version of the resource backups
+	ResourcesFormatVersion = 2
+	// DefaultResourceChunkSize is the size of the resources of a chunk before
+	// compression
+	DefaultResourceChunkSize = 32 * 1024 * 1024
+	// resourceChunkCompression is the compression of the chunks
+	resourceChunkCompression = "gzip"
+)
+
+// ResourcesManifest describes the resource chunks of a backup.
+type ResourcesManifest struct {
+	FormatVersion int             `json:"formatVersion"`
+	Compression   string          `json:"compression"`
+	Chunks        []ResourceChunk `json:"chunks"`
+}
+
+// ResourceChunk is a compressed JSON list of resources.
+type ResourceChunk struct {
+	Name    string `json:"name"`
+	Objects int    `json:"objects"`
+	Size    int64  `json:"size"`
+	// SHA256 is the digest of the compressed chunk
+	SHA256 string `json:"sha256"`
+}
+
+// ResourceChunkWriter writes resources as chunks of a bounded size, keeping
+// only the chunk being written in memory. The chunks and the manifest are
+// written with the upload function, the manifest last once all the chunks are
+// written.
+type ResourceChunkWriter struct {
+	chunkSize int
+	upload    func(name string, data []byte) error
+	manifest  ResourcesManifest
+	buf       bytes.Buffer
+	gz        *gzip.Writer
+	objects   int
+	size      int
+}
+
+// NewResourceChunkWriter returns a writer of chunks of at most chunkSize bytes
+// of resources before compression. A single resource larger than chunkSize is
+// written in its own chunk.
+func NewResourceChunkWriter(chunkSize int, upload func(name string, data []byte) error) *ResourceChunkWriter {
+	if chunkSize <= 0 {
+		chunkSize = DefaultResourceChunkSize
+	}
+	return &ResourceChunkWriter{
+		chunkSize: chunkSize,
+		upload:    upload,
+		manifest: ResourcesManifest{
+			FormatVersion: ResourcesFormatVersion,
+			Compression:   resourceChunkCompression,
+		},
+	}
+}
+
+// Add adds a resource to the current chunk.
+func (w *ResourceChunkWriter) Add(obj interface{}) error {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return fmt.Errorf("marshalling resource: %v", err)
+	}
+	if w.objects > 0 && w.size+len(data)+1 > w.chunkSize {
+		if err := w.flush(); err != nil {
+			return err
+		}
+	}
+	sep := []byte(",")
+	if w.objects == 0 {
+		w.buf.Reset()
+		w.gz = gzip.NewWriter(&w.buf)
+		sep = []byte("[")
+	}
+	if _, err := w.gz.Write(sep); err != nil {
+		return err
+	}
+	if _, err := w.gz.Write(data); err != nil {
+		return err
+	}
+	w.objects++
+	w.size += len(data) + 1
+	return nil
+}
+
+// Close writes the last chunk and the manifest.
+func (w *ResourceChunkWriter) Close() (*ResourcesManifest, error) {
+	if err := w.flush(); err != nil {
+		return nil, err
+	}
+	data, err := json.MarshalIndent(w.manifest, "", " ")
+	if err != nil {
+		return nil, err
+	}
+	if err := w.upload(ResourcesManifestFile, data); err != nil {
+		return nil, fmt.Errorf("writing resources manifest: %v", err)
+	}
+	return &w.manifest, nil
+}
+
+func (w *ResourceChunkWriter) flush() error {
+	if w.objects == 0 {
+		return nil
+	}
+	if _, err := w.gz.Write([]byte("]")); err != nil {
+		return err
+	}
+	if err := w.gz.Close(); err != nil {
+		return err
+	}
+	data := w.buf.Bytes()
+	digest := sha256.Sum256(data)
+	chunk := ResourceChunk{
+		Name:    fmt.Sprintf("resources-%05d.json.gz", len(w.manifest.Chunks)),
+		Objects: w.objects,
+		Size:    int64(len(data)),
+		SHA256:  hex.EncodeToString(digest[:]),
+	}
+	if err := w.upload(chunk.Name, data); err != nil {
+		return fmt.Errorf("writing resource chunk %s: %v", chunk.Name, err)
+	}
+	w.manifest.Chunks = append(w.manifest.Chunks, chunk)
+	w.objects = 0
+	w.size = 0
+	w.gz = nil
+	return nil
+}
+
+// ReadResourceChunks reads the resources of the chunks of a manifest with the
+// download function, failing if the digest of a chunk doesn't match the
+// manifest. The resources are passed to the add function chunk by chunk, only
+// one chunk is held in memory at a time.
+func ReadResourceChunks(
+	manifestData []byte,
+	download func(name string) ([]byte, error),
+	add func(objects []*unstructured.Unstructured) error,
+) error {
+	manifest := ResourcesManifest{}
+	if err := json.Unmarshal(manifestData, &manifest); err != nil {
+		return fmt.Errorf("decoding resources manifest: %v", err)
+	}
+	if manifest.FormatVersion != ResourcesFormatVersion {
+		return fmt.Errorf("unsupported resources format version %d", manifest.FormatVersion)
+	}
+	if manifest.Compression != resourceChunkCompression {
+		return fmt.Errorf("unsupported resources compression %q", manifest.Compression)
+	}
+
+	for _, chunk := range manifest.Chunks {
+		objects, err := readResourceChunk(chunk, download)
+		if err != nil {
+			return err
+		}
+		if err := add(objects); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func readResourceChunk(chunk ResourceChunk, download func(name string) ([]byte, error)) ([]*unstructured.Unstructured, error) {
+	data, err := download(chunk.Name)
+	if err != nil {
+		return nil, err
+	}
+	digest := sha256.Sum256(data)
+	if hex.EncodeToString(digest[:]) != chunk.SHA256 {
+		return nil, fmt.Errorf("resource chunk %s is corrupted: digest mismatch", chunk.Name)
+	}
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("decompressing resource chunk %s: %v", chunk.Name, err)
+	}
+	defer gz.Close()
+	objects := make([]*unstructured.Unstructured, 0, chunk.Objects)
+	if err := json.NewDecoder(gz).Decode(&objects); err != nil {
+		return nil, fmt.Errorf("decoding resource chunk %s: %v", chunk.Name, err)
+	}
+	if len(objects) != chunk.Objects {
+		return nil, fmt.Errorf("resource chunk %s has %d resources, expected %d", chunk.Name, len(objects), chunk.Objects)
+	}
+	return objects, nil
+}
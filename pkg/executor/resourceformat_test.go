@@ -0,0 +1,90 @@
+package executor
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func configMap(i int) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion("v1")
+	obj.SetKind("ConfigMap")
+	obj.SetNamespace("ns")
+	obj.SetName(fmt.Sprintf("cm-%d", i))
+	return obj
+}
+
+func TestResourceChunks(t *testing.T) {
+	files := make(map[string][]byte)
+	upload := func(name string, data []byte) error {
+		files[name] = append([]byte(nil), data...)
+		return nil
+	}
+	download := func(name string) ([]byte, error) {
+		data, ok := files[name]
+		if !ok {
+			return nil, fmt.Errorf("%s not found", name)
+		}
+		return data, nil
+	}
+
+	writer := NewResourceChunkWriter(1024, upload)
+	for i := 0; i < 50; i++ {
+		require.NoError(t, writer.Add(configMap(i)))
+	}
+	manifest, err := writer.Close()
+	require.NoError(t, err)
+	require.Greater(t, len(manifest.Chunks), 1)
+	require.Contains(t, files, ResourcesManifestFile)
+
+	// the resources are read chunk by chunk
+	var objects []*unstructured.Unstructured
+	var chunks int
+	err = ReadResourceChunks(files[ResourcesManifestFile], download, func(chunkObjects []*unstructured.Unstructured) error {
+		require.Equal(t, manifest.Chunks[chunks].Objects, len(chunkObjects))
+		chunks++
+		objects = append(objects, chunkObjects...)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, len(manifest.Chunks), chunks)
+	require.Len(t, objects, 50)
+	for i, obj := range objects {
+		require.Equal(t, configMap(i).GetName(), obj.GetName())
+	}
+
+	// a corrupted chunk is detected, the chunks before it are read
+	chunk := manifest.Chunks[1].Name
+	files[chunk][len(files[chunk])-1] ^= 0xff
+	chunks = 0
+	err = ReadResourceChunks(files[ResourcesManifestFile], download, func([]*unstructured.Unstructured) error {
+		chunks++
+		return nil
+	})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), chunk)
+	require.Equal(t, 1, chunks)
+}
+
+func TestResourceChunksEmpty(t *testing.T) {
+	files := make(map[string][]byte)
+	writer := NewResourceChunkWriter(0, func(name string, data []byte) error {
+		files[name] = data
+		return nil
+	})
+	manifest, err := writer.Close()
+	require.NoError(t, err)
+	require.Empty(t, manifest.Chunks)
+
+	err = ReadResourceChunks(files[ResourcesManifestFile], nil, func([]*unstructured.Unstructured) error {
+		require.FailNow(t, "no chunk expected")
+		return nil
+	})
+	require.NoError(t, err)
+
+	err = ReadResourceChunks([]byte(`{"formatVersion": 3}`), nil, nil)
+	require.Error(t, err)
+}
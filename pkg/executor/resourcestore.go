@@ -0,0 +1,226 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+
+	storkapi "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"github.com/libopenstorage/stork/pkg/crypto"
+	"github.com/libopenstorage/stork/pkg/objectstore"
+	"github.com/sirupsen/logrus"
+	"gocloud.dev/blob"
+	"gocloud.dev/gcerrors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ResourceStore reads and writes the objects of the resource backups of a
+// backup location. The directories are relative to the root of the backup
+// location and the objects are encrypted with the encryption key of the store
+// if set.
+type ResourceStore interface {
+	// Upload writes an object
+	Upload(dir, name string, data []byte) error
+	// Download reads an object
+	Download(dir, name string) ([]byte, error)
+	// Exists returns whether an object exists
+	Exists(dir, name string) (bool, error)
+	// DeleteAll deletes the objects of a directory and of its subdirectories
+	DeleteAll(dir string) error
+	// Close releases the resources of the store
+	Close() error
+}
+
+// NewResourceStore returns the resource store of the backup location of a
+// repository, the NFS share mounted at the repository path or the bucket of
+// an object store.
+func NewResourceStore(repository *Repository, encryptionKey string) (ResourceStore, error) {
+	if repository.Type == storkapi.BackupLocationNFS {
+		return &fileResourceStore{
+			root:          repository.Path,
+			encryptionKey: encryptionKey,
+		}, nil
+	}
+	bl, err := BuildStorkBackupLocation(repository)
+	if err != nil {
+		return nil, err
+	}
+	bucket, err := objectstore.GetBucket(bl)
+	if err != nil {
+		return nil, fmt.Errorf("getting bucket %s failed: %v", repository.Path, err)
+	}
+	return NewBucketResourceStore(bucket, encryptionKey), nil
+}
+
+// NewBucketResourceStore returns a resource store writing to a bucket.
+func NewBucketResourceStore(bucket *blob.Bucket, encryptionKey string) ResourceStore {
+	return &bucketResourceStore{
+		bucket:        bucket,
+		encryptionKey: encryptionKey,
+	}
+}
+
+// BuildStorkBackupLocation returns a BackupLocation with the object store
+// config of a repository.
+func BuildStorkBackupLocation(repository *Repository) (*storkapi.BackupLocation, error) {
+	var backupType storkapi.BackupLocationType
+	backupLocation := &storkapi.BackupLocation{
+		ObjectMeta: metav1.ObjectMeta{},
+		Location:   storkapi.BackupLocationItem{},
+	}
+
+	switch repository.Type {
+	case storkapi.BackupLocationS3:
+		backupType = storkapi.BackupLocationS3
+		backupLocation.Location.S3Config = &storkapi.S3Config{
+			AccessKeyID:     repository.S3Config.AccessKeyID,
+			SecretAccessKey: repository.S3Config.SecretAccessKey,
+			Endpoint:        repository.S3Config.Endpoint,
+			Region:          repository.S3Config.Region,
+			DisableSSL:      repository.S3Config.DisableSSL,
+		}
+	case storkapi.BackupLocationGoogle:
+		backupType = storkapi.BackupLocationGoogle
+		backupLocation.Location.GoogleConfig = &storkapi.GoogleConfig{
+			ProjectID:  repository.GoogleConfig.ProjectID,
+			AccountKey: repository.GoogleConfig.AccountKey,
+		}
+	case storkapi.BackupLocationAzure:
+		backupType = storkapi.BackupLocationAzure
+		backupLocation.Location.AzureConfig = &storkapi.AzureConfig{
+			StorageAccountName: repository.AzureConfig.StorageAccountName,
+			StorageAccountKey:  repository.AzureConfig.StorageAccountKey,
+			Environment:        storkapi.AzureEnvironment(repository.AzureConfig.Environment),
+		}
+	}
+
+	backupLocation.Location.Path = repository.Path
+	backupLocation.ObjectMeta.Name = repository.Name
+	backupLocation.Location.Type = backupType
+
+	return backupLocation, nil
+}
+
+func encryptObject(data []byte, encryptionKey string) ([]byte, error) {
+	if encryptionKey == "" {
+		return data, nil
+	}
+	encryptedData, err := crypto.Encrypt(data, encryptionKey)
+	if err != nil {
+		return nil, fmt.Errorf("encryption failed: %v", err)
+	}
+	return encryptedData, nil
+}
+
+// decryptObject returns the data as is if it can't be decrypted, for the
+// objects written without encryption.
+func decryptObject(data []byte, encryptionKey string) []byte {
+	if encryptionKey == "" {
+		return data
+	}
+	decryptData, err := crypto.Decrypt(data, encryptionKey)
+	if err != nil {
+		logrus.Errorf("decrypt failed :%v, returning data direclty", err)
+		return data
+	}
+	return decryptData
+}
+
+// The resources of the backups are only read and written by the jobs, the
+// other users of the NFS share have no access to them.
+const (
+	resourceDirMode  = 0750
+	resourceFileMode = 0640
+)
+
+type fileResourceStore struct {
+	root          string
+	encryptionKey string
+}
+
+func (s *fileResourceStore) Upload(dir, name string, data []byte) error {
+	resourcePath := filepath.Join(s.root, dir)
+	if err := os.MkdirAll(resourcePath, resourceDirMode); err != nil {
+		return fmt.Errorf("error creating resourcepath: %v", err)
+	}
+	data, err := encryptObject(data, s.encryptionKey)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(resourcePath, name), data, resourceFileMode)
+}
+
+func (s *fileResourceStore) Download(dir, name string) ([]byte, error) {
+	return DownloadObject(filepath.Join(s.root, dir), name, s.encryptionKey)
+}
+
+func (s *fileResourceStore) Exists(dir, name string) (bool, error) {
+	_, err := os.Stat(filepath.Join(s.root, dir, name))
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (s *fileResourceStore) DeleteAll(dir string) error {
+	return os.RemoveAll(filepath.Join(s.root, dir))
+}
+
+func (s *fileResourceStore) Close() error {
+	return nil
+}
+
+type bucketResourceStore struct {
+	bucket        *blob.Bucket
+	encryptionKey string
+}
+
+func (s *bucketResourceStore) Upload(dir, name string, data []byte) error {
+	data, err := encryptObject(data, s.encryptionKey)
+	if err != nil {
+		return err
+	}
+	key := path.Join(dir, name)
+	if err := s.bucket.WriteAll(context.TODO(), key, data, nil); err != nil {
+		return fmt.Errorf("writing object %s failed: %v", key, err)
+	}
+	return nil
+}
+
+func (s *bucketResourceStore) Download(dir, name string) ([]byte, error) {
+	key := path.Join(dir, name)
+	data, err := s.bucket.ReadAll(context.TODO(), key)
+	if err != nil {
+		return nil, fmt.Errorf("getting object %s failed: %v", key, err)
+	}
+	return decryptObject(data, s.encryptionKey), nil
+}
+
+func (s *bucketResourceStore) Exists(dir, name string) (bool, error) {
+	return s.bucket.Exists(context.TODO(), path.Join(dir, name))
+}
+
+func (s *bucketResourceStore) DeleteAll(dir string) error {
+	iterator := s.bucket.List(&blob.ListOptions{
+		Prefix: dir + "/",
+	})
+	for {
+		object, err := iterator.Next(context.TODO())
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("listing objects of %s failed: %v", dir, err)
+		}
+		if err := s.bucket.Delete(context.TODO(), object.Key); err != nil && gcerrors.Code(err) != gcerrors.NotFound {
+			return fmt.Errorf("deleting object %s failed: %v", object.Key, err)
+		}
+	}
+}
+
+func (s *bucketResourceStore) Close() error {
+	return s.bucket.Close()
+}
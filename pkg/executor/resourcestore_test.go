@@ -0,0 +1,85 @@
+package executor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	storkapi "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"github.com/stretchr/testify/require"
+	"gocloud.dev/blob/fileblob"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestBucketResourceStore(t *testing.T) {
+	bucket, err := fileblob.OpenBucket(t.TempDir(), nil)
+	require.NoError(t, err)
+	store := NewBucketResourceStore(bucket, "key")
+	defer store.Close()
+
+	require.NoError(t, store.Upload("ns/backup/uid", NamespacesFile, []byte(`[]`)))
+	require.NoError(t, store.Upload("ns/backup/uid", CrdFile, []byte(`[]`)))
+	require.NoError(t, store.Upload("ns/other/uid", NamespacesFile, []byte(`[]`)))
+
+	// the objects are encrypted
+	raw, err := bucket.ReadAll(context.TODO(), "ns/backup/uid/"+NamespacesFile)
+	require.NoError(t, err)
+	require.NotEqual(t, []byte(`[]`), raw)
+	data, err := store.Download("ns/backup/uid", NamespacesFile)
+	require.NoError(t, err)
+	require.Equal(t, []byte(`[]`), data)
+
+	exists, err := store.Exists("ns/backup/uid", ResourcesManifestFile)
+	require.NoError(t, err)
+	require.False(t, exists)
+	_, err = store.Download("ns/backup/uid", ResourcesManifestFile)
+	require.Error(t, err)
+
+	// the resources chunks are written and read through the store
+	writer := NewResourceChunkWriter(0, func(name string, data []byte) error {
+		return store.Upload("ns/backup/uid", name, data)
+	})
+	require.NoError(t, writer.Add(configMap(0)))
+	_, err = writer.Close()
+	require.NoError(t, err)
+	manifest, err := store.Download("ns/backup/uid", ResourcesManifestFile)
+	require.NoError(t, err)
+	var objects int
+	err = ReadResourceChunks(manifest, func(name string) ([]byte, error) {
+		return store.Download("ns/backup/uid", name)
+	}, func(chunkObjects []*unstructured.Unstructured) error {
+		objects += len(chunkObjects)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, objects)
+
+	require.NoError(t, store.DeleteAll("ns/backup"))
+	exists, err = store.Exists("ns/backup/uid", NamespacesFile)
+	require.NoError(t, err)
+	require.False(t, exists)
+	exists, err = store.Exists("ns/other/uid", NamespacesFile)
+	require.NoError(t, err)
+	require.True(t, exists)
+}
+
+func TestFileResourceStore(t *testing.T) {
+	root := t.TempDir()
+	store, err := NewResourceStore(&Repository{Type: storkapi.BackupLocationNFS, Path: root}, "key")
+	require.NoError(t, err)
+	defer store.Close()
+
+	require.NoError(t, store.Upload("ns/backup/uid", NamespacesFile, []byte(`[]`)))
+	data, err := store.Download("ns/backup/uid", NamespacesFile)
+	require.NoError(t, err)
+	require.Equal(t, []byte(`[]`), data)
+
+	// the resources are not readable by the other users of the share
+	info, err := os.Stat(filepath.Join(root, "ns/backup/uid"))
+	require.NoError(t, err)
+	require.Zero(t, info.Mode().Perm()&0027)
+	info, err = os.Stat(filepath.Join(root, "ns/backup/uid", NamespacesFile))
+	require.NoError(t, err)
+	require.Zero(t, info.Mode().Perm()&0137)
+}
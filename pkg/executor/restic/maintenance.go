@@ -0,0 +1,210 @@
+package restic
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	kdmp_api "github.com/portworx/kdmp/pkg/apis/kdmp/v1alpha1"
+	"github.com/portworx/kdmp/pkg/drivers"
+	"github.com/portworx/kdmp/pkg/executor"
+	"github.com/portworx/kdmp/pkg/restic"
+	kdmpops "github.com/portworx/kdmp/pkg/util/ops"
+	kdmpShedOps "github.com/portworx/sched-ops/k8s/kdmp"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kubectl/pkg/cmd/util"
+)
+
+const (
+	fullMaintenanceType  = "full"
+	quickMaintenanceType = "quick"
+)
+
+var (
+	maintenanceStatusName      string
+	maintenanceStatusNamespace string
+)
+
+func newMaintenanceCommand() *cobra.Command {
+	var (
+		maintenanceType string
+		forgetPolicy    string
+	)
+	maintenanceCommand := &cobra.Command{
+		Use:   "maintenance",
+		Short: "maintenance for restic repositories",
+		Run: func(c *cobra.Command, args []string) {
+			if len(backupLocationName) == 0 {
+				util.CheckErr(fmt.Errorf("backup-location has to be provided for restic maintenance"))
+				return
+			}
+			if maintenanceType != fullMaintenanceType && maintenanceType != quickMaintenanceType {
+				util.CheckErr(fmt.Errorf("maintenance-type should be either %s or %s", fullMaintenanceType, quickMaintenanceType))
+				return
+			}
+			executor.HandleErr(runMaintenance(maintenanceType, strings.Fields(forgetPolicy)))
+		},
+	}
+	maintenanceCommand.Flags().StringVar(&maintenanceStatusName, "maintenance-status-name", "", "backuplocation maintenance status CR name, where repo maintenance status will be stored")
+	maintenanceCommand.Flags().StringVar(&maintenanceStatusNamespace, "maintenance-status-namespace", "", "backuplocation maintenance status CR namespace, where repo maintenance status will be stored")
+	maintenanceCommand.Flags().StringVar(&maintenanceType, "maintenance-type", "", "full - will run forget with prune, if a forget policy is set, and check, quick - will only run check")
+	maintenanceCommand.Flags().StringVar(&forgetPolicy, "forget-policy", "", "restic forget keep policy flags, eg: \"--keep-last 10\". Snapshots are kept if not set")
+	return maintenanceCommand
+}
+
+// getRepoList returns the restic repositories created in the backuplocation.
+// Every restic backup records its repository in the VolumeBackup CR.
+func getRepoList() ([]string, error) {
+	vbList, err := kdmpops.Instance().ListVolumeBackups(context.Background(), "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list volumebackups: %v", err)
+	}
+	return resticRepos(vbList.Items), nil
+}
+
+// resticRepos returns the repositories of the restic VolumeBackups of the
+// backuplocation, the kopia repositories of the same backuplocation are
+// skipped.
+func resticRepos(volumeBackups []kdmp_api.VolumeBackup) []string {
+	repos := make(map[string]bool)
+	for _, vb := range volumeBackups {
+		if vb.Spec.BackupLocation.Name != backupLocationName || vb.Spec.BackupLocation.Namespace != namespace {
+			continue
+		}
+		if vb.Labels[drivers.DriverNameLabel] != drivers.ResticBackup {
+			continue
+		}
+		if vb.Spec.Repository != "" {
+			repos[vb.Spec.Repository] = true
+		}
+	}
+	repoList := make([]string, 0, len(repos))
+	for repo := range repos {
+		repoList = append(repoList, repo)
+	}
+	sort.Strings(repoList)
+	return repoList
+}
+
+func runMaintenance(maintenanceType string, forgetPolicy []string) error {
+	fn := "runMaintenance:"
+	repoList, err := getRepoList()
+	if err != nil {
+		logrus.Errorf("%s %v", fn, err)
+		return err
+	}
+	if maintenanceType == fullMaintenanceType && len(forgetPolicy) == 0 {
+		logrus.Infof("%s no forget policy set, snapshots are kept and only checked", fn)
+	}
+	if len(repoList) == 0 {
+		logrus.Infof("%s no restic repositories found for backuplocation [%v/%v]", fn, namespace, backupLocationName)
+		return nil
+	}
+
+	for _, repoName := range repoList {
+		repo, err := executor.ParseBackupLocation(repoName, backupLocationName, namespace, backupLocationFile)
+		if err != nil {
+			errMsg := fmt.Sprintf("parse backuplocation for repo [%v] failed: %v", repoName, err)
+			logrus.Errorf("%s %v", fn, errMsg)
+			updateMaintenanceStatus(maintenanceType, kdmp_api.RepoMaintenanceStatusFailed, repoName, errMsg)
+			continue
+		}
+
+		// Without a keep policy forget removes no snapshot and prune would
+		// have nothing to reclaim, the full maintenance only checks the repo.
+		if maintenanceType == fullMaintenanceType && len(forgetPolicy) > 0 {
+			forgetCmd, err := restic.GetForgetCommand(repo.Path, secretFilePath, forgetPolicy)
+			if err == nil {
+				err = runResticMaintenanceCmd(forgetCmd, repo.AuthEnv)
+			}
+			if err != nil {
+				errMsg := fmt.Sprintf("forget command failed for repo [%v]: %v", repoName, err)
+				logrus.Errorf("%s %v", fn, errMsg)
+				updateMaintenanceStatus(maintenanceType, kdmp_api.RepoMaintenanceStatusFailed, repoName, errMsg)
+				continue
+			}
+		}
+
+		checkCmd, err := restic.GetCheckCommand(repo.Path, secretFilePath)
+		if err == nil {
+			err = runResticMaintenanceCmd(checkCmd, repo.AuthEnv)
+		}
+		if err != nil {
+			errMsg := fmt.Sprintf("check command failed for repo [%v]: %v", repoName, err)
+			logrus.Errorf("%s %v", fn, errMsg)
+			updateMaintenanceStatus(maintenanceType, kdmp_api.RepoMaintenanceStatusFailed, repoName, errMsg)
+			continue
+		}
+
+		updateMaintenanceStatus(maintenanceType, kdmp_api.RepoMaintenanceStatusSuccess, repoName, "")
+		logrus.Infof("%s %s maintenance completed successfully for repository [%v]", fn, maintenanceType, repoName)
+	}
+
+	return nil
+}
+
+func runResticMaintenanceCmd(cmd *restic.Command, env []string) error {
+	cmd.AddEnv(env)
+	maintenanceExecutor := restic.NewMaintenanceExecutor(cmd)
+	if err := maintenanceExecutor.Run(); err != nil {
+		return fmt.Errorf("failed to run %s command: %v", cmd.Name, err)
+	}
+	for {
+		time.Sleep(progressCheckInterval)
+		status, err := maintenanceExecutor.Status()
+		if err != nil {
+			return err
+		}
+		if status.LastKnownError != nil {
+			return status.LastKnownError
+		}
+		if status.Done {
+			break
+		}
+	}
+
+	return nil
+}
+
+// updateMaintenanceStatus records the result of the maintenance of a repository
+// in the BackupLocationMaintenance CR. Failure to update is only logged, so that
+// the maintenance of the other repositories goes on.
+func updateMaintenanceStatus(
+	maintenanceType string,
+	status kdmp_api.RepoMaintenanceStatusType,
+	repoName string,
+	reason string,
+) {
+	fn := "updateMaintenanceStatus:"
+	if maintenanceStatusName == "" {
+		return
+	}
+	backupLocationMaintenance, err := kdmpShedOps.Instance().GetBackupLocationMaintenance(maintenanceStatusName, maintenanceStatusNamespace)
+	if err != nil {
+		logrus.Warnf("%s failed in getting backuplocationmaintenance CR [%v:%v]: %v", fn, maintenanceStatusNamespace, maintenanceStatusName, err)
+		return
+	}
+	repoStatus := kdmp_api.RepoMaintenanceStatus{
+		LastRunTimestamp: metav1.Now(),
+		Status:           status,
+		Reason:           reason,
+	}
+	if maintenanceType == fullMaintenanceType {
+		if backupLocationMaintenance.Status.FullMaintenanceRepoStatus == nil {
+			backupLocationMaintenance.Status.FullMaintenanceRepoStatus = make(map[string]kdmp_api.RepoMaintenanceStatus)
+		}
+		backupLocationMaintenance.Status.FullMaintenanceRepoStatus[repoName] = repoStatus
+	} else {
+		if backupLocationMaintenance.Status.QuickMaintenanceRepoStatus == nil {
+			backupLocationMaintenance.Status.QuickMaintenanceRepoStatus = make(map[string]kdmp_api.RepoMaintenanceStatus)
+		}
+		backupLocationMaintenance.Status.QuickMaintenanceRepoStatus[repoName] = repoStatus
+	}
+	if _, err = kdmpShedOps.Instance().UpdateBackupLocationMaintenance(backupLocationMaintenance); err != nil {
+		logrus.Warnf("%s failed in updating backuplocationmaintenance CR [%v:%v]: %v", fn, maintenanceStatusNamespace, maintenanceStatusName, err)
+	}
+}
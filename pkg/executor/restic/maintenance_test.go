@@ -0,0 +1,38 @@
+package restic
+
+import (
+	"testing"
+
+	kdmp_api "github.com/portworx/kdmp/pkg/apis/kdmp/v1alpha1"
+	"github.com/portworx/kdmp/pkg/drivers"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestResticRepos(t *testing.T) {
+	blName, blNamespace := backupLocationName, namespace
+	defer func() { backupLocationName, namespace = blName, blNamespace }()
+	backupLocationName, namespace = "bl", "kube-system"
+
+	newVolumeBackup := func(driverName, repository, bl string) kdmp_api.VolumeBackup {
+		return kdmp_api.VolumeBackup{
+			ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{drivers.DriverNameLabel: driverName}},
+			Spec: kdmp_api.VolumeBackupSpec{
+				Repository:     repository,
+				BackupLocation: kdmp_api.DataExportObjectReference{Name: bl, Namespace: "kube-system"},
+			},
+		}
+	}
+	unlabelled := newVolumeBackup("", "app-unlabelled", "bl")
+	unlabelled.Labels = nil
+	volumeBackups := []kdmp_api.VolumeBackup{
+		newVolumeBackup(drivers.ResticBackup, "app-data", "bl"),
+		newVolumeBackup(drivers.ResticBackup, "app-data", "bl"),
+		newVolumeBackup(drivers.ResticBackup, "app-cache", "bl"),
+		newVolumeBackup(drivers.ResticBackup, "app-logs", "other"),
+		newVolumeBackup(drivers.ResticBackup, "", "bl"),
+		newVolumeBackup(drivers.KopiaBackup, "generic-backup/app-data/", "bl"),
+		unlabelled,
+	}
+	require.Equal(t, []string{"app-cache", "app-data"}, resticRepos(volumeBackups))
+}
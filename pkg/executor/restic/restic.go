@@ -2,7 +2,9 @@ package restic
 
 import (
 	"flag"
+	"strconv"
 
+	"github.com/portworx/kdmp/pkg/restic"
 	"github.com/spf13/cobra"
 	"k8s.io/kubectl/pkg/cmd/util"
 )
@@ -14,6 +16,8 @@ var (
 	backupLocationFile string
 	volumeBackupName   string
 	resticRepo         string
+	maxUploadSpeed     int64
+	maxDownloadSpeed   int64
 )
 
 // NewCommand returns a restic command wrapper
@@ -28,10 +32,13 @@ func NewCommand() *cobra.Command {
 	cmds.PersistentFlags().StringVar(&backupLocationFile, "backup-location-file", "", "Path to the BackupLocation object, used for authentication")
 	cmds.PersistentFlags().StringVar(&resticRepo, "repository", "", "Name of the restic repository. If provided it will overwrite the BackupLocation one")
 	cmds.PersistentFlags().StringVarP(&secretFilePath, "secret-file-path", "s", "", "Path of the secret file used for locking/unlocking restic reposiories")
+	cmds.PersistentFlags().Int64Var(&maxUploadSpeed, "max-upload-speed", 0, "Maximum upload speed to the repository in bytes per second, unlimited if not set")
+	cmds.PersistentFlags().Int64Var(&maxDownloadSpeed, "max-download-speed", 0, "Maximum download speed from the repository in bytes per second, unlimited if not set")
 
 	cmds.AddCommand(
 		newBackupCommand(),
 		newRestoreCommand(),
+		newMaintenanceCommand(),
 	)
 	cmds.PersistentFlags().AddGoFlagSet(flag.CommandLine)
 	err := flag.CommandLine.Parse([]string{})
@@ -42,3 +49,17 @@ func NewCommand() *cobra.Command {
 
 	return cmds
 }
+
+// addSpeedLimits adds the restic throttling flags. Restic takes the limits in
+// KiB per second, they are rounded up to not turn a small limit in unlimited.
+func addSpeedLimits(cmd *restic.Command) *restic.Command {
+	if maxUploadSpeed > 0 {
+		cmd.AddFlag("--limit-upload")
+		cmd.AddFlag(strconv.FormatInt((maxUploadSpeed+1023)/1024, 10))
+	}
+	if maxDownloadSpeed > 0 {
+		cmd.AddFlag("--limit-download")
+		cmd.AddFlag(strconv.FormatInt((maxDownloadSpeed+1023)/1024, 10))
+	}
+	return cmd
+}
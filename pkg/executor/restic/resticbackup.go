@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/portworx/kdmp/pkg/drivers"
 	"github.com/portworx/kdmp/pkg/executor"
 	"github.com/portworx/kdmp/pkg/restic"
 	"github.com/sirupsen/logrus"
@@ -60,6 +61,7 @@ func runBackup(sourcePath string) error {
 		if err = executor.CreateVolumeBackup(
 			volumeBackupName,
 			namespace,
+			drivers.ResticBackup,
 			repo.Name,
 			backupLocationName,
 			namespace,
@@ -124,6 +126,7 @@ func runResticBackup(sourcePath, repositoryName string, env []string) error {
 		return err
 	}
 	backupCmd.AddEnv(env)
+	addSpeedLimits(backupCmd)
 	backupExecutor := restic.NewBackupExecutor(backupCmd)
 	if err := backupExecutor.Run(); err != nil {
 		err = fmt.Errorf("failed to run backup command: %v", err)
@@ -46,6 +46,7 @@ func runRestore(snapshotID, targetPath string) error {
 		return err
 	}
 	backupCmd.AddEnv(repo.AuthEnv)
+	addSpeedLimits(backupCmd)
 	backupExecutor := restic.NewRestoreExecutor(backupCmd)
 	if err := backupExecutor.Run(); err != nil {
 		err = fmt.Errorf("failed to run backup command: %v", err)
@@ -0,0 +1,426 @@
+package executor
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+
+	kdmpapi "github.com/portworx/kdmp/pkg/apis/kdmp/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	utiljson "k8s.io/apimachinery/pkg/util/json"
+)
+
+const storageClassAnnotation = "volume.beta.kubernetes.io/storage-class"
+
+// podSpecPaths are the paths of the pod specs of the workloads
+var podSpecPaths = [][]string{
+	{"spec"},
+	{"spec", "template", "spec"},
+	{"spec", "jobTemplate", "spec", "template", "spec"},
+}
+
+// TransformResources applies the rules of a RestoreTransformation, in order,
+// to the resources they select.
+func TransformResources(spec *kdmpapi.RestoreTransformationSpec, objects []runtime.Unstructured) error {
+	for i := range spec.Rules {
+		rule := &spec.Rules[i]
+		selector, err := newRuleSelector(&rule.Selector)
+		if err != nil {
+			return fmt.Errorf("invalid selector of rule %d: %v", i, err)
+		}
+		fields, err := parseFieldTransformations(rule.Fields)
+		if err != nil {
+			return fmt.Errorf("invalid fields of rule %d: %v", i, err)
+		}
+		for _, o := range objects {
+			obj := &unstructured.Unstructured{Object: o.UnstructuredContent()}
+			if !selector.matches(obj) {
+				continue
+			}
+			if err := transformResource(rule, fields, obj); err != nil {
+				return fmt.Errorf("error applying rule %d to %v %v/%v: %v", i, obj.GetKind(), obj.GetNamespace(), obj.GetName(), err)
+			}
+			o.SetUnstructuredContent(obj.Object)
+		}
+	}
+	return nil
+}
+
+type ruleSelector struct {
+	*kdmpapi.RestoreTransformationSelector
+	labels labels.Selector
+}
+
+func newRuleSelector(selector *kdmpapi.RestoreTransformationSelector) (*ruleSelector, error) {
+	for _, pattern := range selector.Names {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid name pattern %q: %v", pattern, err)
+		}
+	}
+	s := &ruleSelector{RestoreTransformationSelector: selector}
+	if selector.LabelSelector != nil {
+		var err error
+		if s.labels, err = metav1.LabelSelectorAsSelector(selector.LabelSelector); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+func (s *ruleSelector) matches(obj *unstructured.Unstructured) bool {
+	gvk := obj.GroupVersionKind()
+	group := s.Group
+	if group == "core" {
+		group = ""
+	}
+	if (group != "" && group != gvk.Group) ||
+		(s.Version != "" && s.Version != gvk.Version) ||
+		(s.Kind != "" && s.Kind != gvk.Kind) {
+		return false
+	}
+	if len(s.Namespaces) != 0 && !contains(s.Namespaces, obj.GetNamespace()) {
+		return false
+	}
+	if len(s.Names) != 0 {
+		matched := false
+		for _, pattern := range s.Names {
+			if ok, _ := path.Match(pattern, obj.GetName()); ok {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return s.labels == nil || s.labels.Matches(labels.Set(obj.GetLabels()))
+}
+
+func contains(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func transformResource(rule *kdmpapi.RestoreTransformationRule, fields []fieldTransformation, obj *unstructured.Unstructured) error {
+	if len(rule.StorageClassMapping) != 0 {
+		if err := mapStorageClasses(obj, rule.StorageClassMapping); err != nil {
+			return err
+		}
+	}
+	if len(rule.ImageRegistryMapping) != 0 {
+		if err := mapImages(obj, rule.ImageRegistryMapping); err != nil {
+			return err
+		}
+	}
+	if len(rule.IngressHostMapping) != 0 && obj.GetKind() == "Ingress" {
+		if err := mapIngressHosts(obj, rule.IngressHostMapping); err != nil {
+			return err
+		}
+	}
+	if rule.Replicas != nil {
+		if _, found, _ := unstructured.NestedFieldNoCopy(obj.Object, "spec", "replicas"); found {
+			if err := unstructured.SetNestedField(obj.Object, *rule.Replicas, "spec", "replicas"); err != nil {
+				return err
+			}
+		}
+	}
+	if len(rule.Labels) != 0 || len(rule.RemoveLabels) != 0 {
+		obj.SetLabels(updateStringMap(obj.GetLabels(), rule.Labels, rule.RemoveLabels))
+	}
+	if len(rule.Annotations) != 0 || len(rule.RemoveAnnotations) != 0 {
+		obj.SetAnnotations(updateStringMap(obj.GetAnnotations(), rule.Annotations, rule.RemoveAnnotations))
+	}
+	for _, field := range fields {
+		if err := setField(obj.Object, field.path, field.value, field.Remove); err != nil {
+			return fmt.Errorf("path %s: %v", field.Path, err)
+		}
+	}
+	return nil
+}
+
+func updateStringMap(values, set map[string]string, remove []string) map[string]string {
+	if values == nil {
+		values = make(map[string]string)
+	}
+	for k, v := range set {
+		values[k] = v
+	}
+	for _, k := range remove {
+		delete(values, k)
+	}
+	return values
+}
+
+// mapString replaces the string at the fields path of the object if it is
+// mapped.
+func mapString(obj map[string]interface{}, mapping map[string]string, fields ...string) error {
+	value, found, err := unstructured.NestedString(obj, fields...)
+	if err != nil || !found {
+		return err
+	}
+	if newValue, ok := mapping[value]; ok {
+		return unstructured.SetNestedField(obj, newValue, fields...)
+	}
+	return nil
+}
+
+// nestedObjects returns the objects of the list at the fields path, the
+// changes made to them are made to the object.
+func nestedObjects(obj map[string]interface{}, fields ...string) []map[string]interface{} {
+	value, found, _ := unstructured.NestedFieldNoCopy(obj, fields...)
+	if !found {
+		return nil
+	}
+	list, _ := value.([]interface{})
+	objects := make([]map[string]interface{}, 0, len(list))
+	for _, item := range list {
+		if o, ok := item.(map[string]interface{}); ok {
+			objects = append(objects, o)
+		}
+	}
+	return objects
+}
+
+func mapStorageClasses(obj *unstructured.Unstructured, mapping map[string]string) error {
+	switch obj.GetKind() {
+	case "PersistentVolumeClaim":
+		if err := mapString(obj.Object, mapping, "spec", "storageClassName"); err != nil {
+			return err
+		}
+		return mapString(obj.Object, mapping, "metadata", "annotations", storageClassAnnotation)
+	case "PersistentVolume":
+		return mapString(obj.Object, mapping, "spec", "storageClassName")
+	case "StatefulSet":
+		for _, template := range nestedObjects(obj.Object, "spec", "volumeClaimTemplates") {
+			if err := mapString(template, mapping, "spec", "storageClassName"); err != nil {
+				return err
+			}
+			if err := mapString(template, mapping, "metadata", "annotations", storageClassAnnotation); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func mapImages(obj *unstructured.Unstructured, mapping map[string]string) error {
+	for _, podSpecPath := range podSpecPaths {
+		podSpec, found, _ := unstructured.NestedFieldNoCopy(obj.Object, podSpecPath...)
+		if !found {
+			continue
+		}
+		spec, ok := podSpec.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for _, containers := range []string{"containers", "initContainers", "ephemeralContainers"} {
+			for _, container := range nestedObjects(spec, containers) {
+				image, found, err := unstructured.NestedString(container, "image")
+				if err != nil {
+					return err
+				}
+				if found {
+					container["image"] = mapImage(image, mapping)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// mapImage replaces the longest mapped prefix of the image.
+func mapImage(image string, mapping map[string]string) string {
+	prefix := ""
+	for from := range mapping {
+		if len(from) > len(prefix) && (image == from || strings.HasPrefix(image, strings.TrimSuffix(from, "/")+"/")) {
+			prefix = from
+		}
+	}
+	if prefix == "" {
+		return image
+	}
+	return strings.TrimSuffix(mapping[prefix], "/") + strings.TrimPrefix(image, strings.TrimSuffix(prefix, "/"))
+}
+
+func mapIngressHosts(obj *unstructured.Unstructured, mapping map[string]string) error {
+	for _, rule := range nestedObjects(obj.Object, "spec", "rules") {
+		if err := mapString(rule, mapping, "host"); err != nil {
+			return err
+		}
+	}
+	for _, tls := range nestedObjects(obj.Object, "spec", "tls") {
+		hosts, found, err := unstructured.NestedStringSlice(tls, "hosts")
+		if err != nil || !found {
+			return err
+		}
+		for i, host := range hosts {
+			if newHost, ok := mapping[host]; ok {
+				hosts[i] = newHost
+			}
+		}
+		if err := unstructured.SetNestedStringSlice(tls, hosts, "hosts"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type fieldTransformation struct {
+	kdmpapi.FieldTransformation
+	path  []pathElement
+	value interface{}
+}
+
+func parseFieldTransformations(fields []kdmpapi.FieldTransformation) ([]fieldTransformation, error) {
+	parsed := make([]fieldTransformation, 0, len(fields))
+	for _, field := range fields {
+		elements, err := parseFieldPath(field.Path)
+		if err != nil {
+			return nil, fmt.Errorf("path %s: %v", field.Path, err)
+		}
+		var value interface{}
+		if !field.Remove {
+			if err := utiljson.Unmarshal([]byte(field.Value), &value); err != nil {
+				value = field.Value
+			}
+		}
+		parsed = append(parsed, fieldTransformation{
+			FieldTransformation: field,
+			path:                elements,
+			value:               value,
+		})
+	}
+	return parsed, nil
+}
+
+// pathElement is a key, an index or a wildcard of a field path
+type pathElement struct {
+	key      string
+	index    int
+	isIndex  bool
+	wildcard bool
+}
+
+// parseFieldPath parses the child, index and wildcard operators of a JSONPath,
+// like {.spec.containers[*].image} or .metadata.labels['app.kubernetes.io/name'].
+func parseFieldPath(p string) ([]pathElement, error) {
+	p = strings.TrimSpace(p)
+	if strings.HasPrefix(p, "{") && strings.HasSuffix(p, "}") {
+		p = p[1 : len(p)-1]
+	}
+	p = strings.TrimPrefix(p, "$")
+	if p != "" && p[0] != '.' && p[0] != '[' {
+		p = "." + p
+	}
+
+	elements := make([]pathElement, 0)
+	for i := 0; i < len(p); {
+		switch p[i] {
+		case '.':
+			i++
+			j := i
+			for j < len(p) && p[j] != '.' && p[j] != '[' {
+				j++
+			}
+			if j == i {
+				return nil, fmt.Errorf("empty key at %d", i)
+			}
+			elements = append(elements, pathElement{key: p[i:j]})
+			i = j
+		case '[':
+			end := strings.IndexByte(p[i:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated [ at %d", i)
+			}
+			inner := p[i+1 : i+end]
+			i += end + 1
+			switch {
+			case inner == "*":
+				elements = append(elements, pathElement{wildcard: true})
+			case len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0]:
+				elements = append(elements, pathElement{key: inner[1 : len(inner)-1]})
+			default:
+				index, err := strconv.Atoi(inner)
+				if err != nil || index < 0 {
+					return nil, fmt.Errorf("invalid index %q", inner)
+				}
+				elements = append(elements, pathElement{index: index, isIndex: true})
+			}
+		default:
+			return nil, fmt.Errorf("unexpected %q at %d", p[i], i)
+		}
+	}
+	if len(elements) == 0 {
+		return nil, fmt.Errorf("empty path")
+	}
+	return elements, nil
+}
+
+// setField sets or removes the field at the path of the node. The missing
+// objects of the path are created when setting, the list items can't be
+// removed.
+func setField(node interface{}, elements []pathElement, value interface{}, remove bool) error {
+	e := elements[0]
+	last := len(elements) == 1
+
+	if e.isIndex || e.wildcard {
+		list, ok := node.([]interface{})
+		if !ok {
+			return fmt.Errorf("not a list")
+		}
+		if last && remove {
+			return fmt.Errorf("removing list items is not supported")
+		}
+		first, end := 0, len(list)
+		if e.isIndex {
+			if e.index >= len(list) {
+				if remove {
+					return nil
+				}
+				return fmt.Errorf("index %d out of range", e.index)
+			}
+			first, end = e.index, e.index+1
+		}
+		for i := first; i < end; i++ {
+			if last {
+				list[i] = runtime.DeepCopyJSONValue(value)
+			} else if err := setField(list[i], elements[1:], value, remove); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	obj, ok := node.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("%s: not an object", e.key)
+	}
+	if last {
+		if remove {
+			delete(obj, e.key)
+		} else {
+			obj[e.key] = runtime.DeepCopyJSONValue(value)
+		}
+		return nil
+	}
+	child, found := obj[e.key]
+	if !found || child == nil {
+		if remove {
+			return nil
+		}
+		if next := elements[1]; next.isIndex || next.wildcard {
+			return fmt.Errorf("%s: not found", e.key)
+		}
+		child = make(map[string]interface{})
+		obj[e.key] = child
+	}
+	return setField(child, elements[1:], value, remove)
+}
@@ -0,0 +1,130 @@
+package executor
+
+import (
+	"testing"
+
+	kdmpapi "github.com/portworx/kdmp/pkg/apis/kdmp/v1alpha1"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func newObject(apiVersion, kind, namespace, name string, content map[string]interface{}) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: content}
+	obj.SetAPIVersion(apiVersion)
+	obj.SetKind(kind)
+	obj.SetNamespace(namespace)
+	obj.SetName(name)
+	return obj
+}
+
+func TestTransformResources(t *testing.T) {
+	deployment := newObject("apps/v1", "Deployment", "ns", "web", map[string]interface{}{
+		"spec": map[string]interface{}{
+			"replicas": int64(3),
+			"template": map[string]interface{}{
+				"spec": map[string]interface{}{
+					"containers": []interface{}{
+						map[string]interface{}{"name": "web", "image": "registry.old.io/team/web:1.0"},
+						map[string]interface{}{"name": "proxy", "image": "docker.io/envoy:1.2"},
+					},
+				},
+			},
+		},
+	})
+	deployment.SetLabels(map[string]string{"app": "web", "zone": "east"})
+	pvc := newObject("v1", "PersistentVolumeClaim", "ns", "data", map[string]interface{}{
+		"spec": map[string]interface{}{"storageClassName": "gp2"},
+	})
+	ingress := newObject("networking.k8s.io/v1", "Ingress", "ns", "web", map[string]interface{}{
+		"spec": map[string]interface{}{
+			"rules": []interface{}{map[string]interface{}{"host": "web.east.example.com"}},
+			"tls":   []interface{}{map[string]interface{}{"hosts": []interface{}{"web.east.example.com", "other.example.com"}}},
+		},
+	})
+	other := newObject("apps/v1", "Deployment", "other", "web", map[string]interface{}{
+		"spec": map[string]interface{}{"replicas": int64(3)},
+	})
+	objects := []runtime.Unstructured{deployment, pvc, ingress, other}
+
+	replicas := int64(0)
+	spec := &kdmpapi.RestoreTransformationSpec{
+		Rules: []kdmpapi.RestoreTransformationRule{
+			{
+				Selector:             kdmpapi.RestoreTransformationSelector{Namespaces: []string{"ns"}},
+				StorageClassMapping:  map[string]string{"gp2": "standard"},
+				ImageRegistryMapping: map[string]string{"registry.old.io": "registry.new.io/mirror"},
+				IngressHostMapping:   map[string]string{"web.east.example.com": "web.west.example.com"},
+			},
+			{
+				Selector: kdmpapi.RestoreTransformationSelector{
+					GroupVersionKind: metav1.GroupVersionKind{Group: "apps", Kind: "Deployment"},
+					Names:            []string{"w*"},
+					LabelSelector:    &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+				},
+				Replicas:     &replicas,
+				Labels:       map[string]string{"zone": "west"},
+				Annotations:  map[string]string{"restored": "true"},
+				RemoveLabels: []string{"app"},
+				Fields: []kdmpapi.FieldTransformation{
+					{Path: "{.spec.template.spec.containers[*].imagePullPolicy}", Value: "Always"},
+					{Path: ".spec.template.spec.containers[1].resources", Value: `{"limits": {"cpu": 1}}`},
+					{Path: "spec.template.metadata.labels['app.kubernetes.io/name']", Value: "web"},
+					{Path: ".spec.paused", Remove: true},
+				},
+			},
+		},
+	}
+	require.NoError(t, TransformResources(spec, objects))
+
+	containers, _, _ := unstructured.NestedSlice(deployment.Object, "spec", "template", "spec", "containers")
+	require.Equal(t, "registry.new.io/mirror/team/web:1.0", containers[0].(map[string]interface{})["image"])
+	require.Equal(t, "docker.io/envoy:1.2", containers[1].(map[string]interface{})["image"])
+	for _, c := range containers {
+		require.Equal(t, "Always", c.(map[string]interface{})["imagePullPolicy"])
+	}
+	cpu, _, _ := unstructured.NestedInt64(containers[1].(map[string]interface{}), "resources", "limits", "cpu")
+	require.Equal(t, int64(1), cpu)
+	name, _, _ := unstructured.NestedString(deployment.Object, "spec", "template", "metadata", "labels", "app.kubernetes.io/name")
+	require.Equal(t, "web", name)
+	count, _, _ := unstructured.NestedInt64(deployment.Object, "spec", "replicas")
+	require.Equal(t, int64(0), count)
+	require.Equal(t, map[string]string{"zone": "west"}, deployment.GetLabels())
+	require.Equal(t, map[string]string{"restored": "true"}, deployment.GetAnnotations())
+
+	storageClass, _, _ := unstructured.NestedString(pvc.Object, "spec", "storageClassName")
+	require.Equal(t, "standard", storageClass)
+
+	rules, _, _ := unstructured.NestedSlice(ingress.Object, "spec", "rules")
+	require.Equal(t, "web.west.example.com", rules[0].(map[string]interface{})["host"])
+	tls, _, _ := unstructured.NestedSlice(ingress.Object, "spec", "tls")
+	require.Equal(t, []interface{}{"web.west.example.com", "other.example.com"}, tls[0].(map[string]interface{})["hosts"])
+
+	// the deployment without the label isn't selected
+	count, _, _ = unstructured.NestedInt64(other.Object, "spec", "replicas")
+	require.Equal(t, int64(3), count)
+}
+
+func TestTransformResourcesErrors(t *testing.T) {
+	obj := newObject("v1", "ConfigMap", "ns", "cm", map[string]interface{}{
+		"data": map[string]interface{}{"key": "value"},
+	})
+	for _, path := range []string{"", ".data[", ".data..key", "[x]"} {
+		spec := &kdmpapi.RestoreTransformationSpec{
+			Rules: []kdmpapi.RestoreTransformationRule{{
+				Fields: []kdmpapi.FieldTransformation{{Path: path, Value: "v"}},
+			}},
+		}
+		require.Error(t, TransformResources(spec, []runtime.Unstructured{obj}), path)
+	}
+
+	spec := &kdmpapi.RestoreTransformationSpec{
+		Rules: []kdmpapi.RestoreTransformationRule{{
+			Fields: []kdmpapi.FieldTransformation{{Path: ".data.key[0]", Value: "v"}},
+		}},
+	}
+	err := TransformResources(spec, []runtime.Unstructured{obj})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "ConfigMap ns/cm")
+}
@@ -23,6 +23,10 @@ const (
 	DeleteJobLimitKey = "KDMP_DELETE_JOB_LIMIT"
 	// MaintenanceJobLimitKey - maintenance job limit configmap key
 	MaintenanceJobLimitKey = "KDMP_MAINTENANCE_JOB_LIMIT"
+	// VerifyJobLimitKey - verify job limit configmap key
+	VerifyJobLimitKey = "KDMP_VERIFY_JOB_LIMIT"
+	// ReplicateJobLimitKey - replicate job limit configmap key
+	ReplicateJobLimitKey = "KDMP_REPLICATE_JOB_LIMIT"
 	// PvcNameKey - PVC name label key
 	PvcNameKey = "kdmp.portworx.com/pvc-name"
 	// PvcUIDKey - PVC UID label key
@@ -35,6 +39,10 @@ const (
 	DefaultDeleteJobLimit = 5
 	// DefaultMaintenanceJobLimit - default maintenance job limit value
 	DefaultMaintenanceJobLimit = 5
+	// DefaultVerifyJobLimit - default verify job limit value
+	DefaultVerifyJobLimit = 5
+	// DefaultReplicateJobLimit - default replicate job limit value
+	DefaultReplicateJobLimit = 5
 	// DefaultJobLimit - default job limit value
 	DefaultJobLimit = 5
 )
@@ -50,8 +58,12 @@ func getJobLimitConfigmapKey(driverName string) (string, error) {
 		return DeleteJobLimitKey, nil
 	case drivers.NFSDelete:
 		return DeleteJobLimitKey, nil
-	case drivers.KopiaMaintenance:
+	case drivers.KopiaMaintenance, drivers.ResticMaintenance:
 		return MaintenanceJobLimitKey, nil
+	case drivers.KopiaVerify, drivers.KopiaListFiles:
+		return VerifyJobLimitKey, nil
+	case drivers.KopiaReplicate:
+		return ReplicateJobLimitKey, nil
 	default:
 		return "", fmt.Errorf("invalid driver name %v", driverName)
 	}
@@ -59,35 +71,79 @@ func getJobLimitConfigmapKey(driverName string) (string, error) {
 
 // getJobCountByType takes the jobType as a param and returns the count of jobs matching the label in all namespaces
 func getJobCountByType(jobType string) (int, error) {
+	counts, err := countJobs(drivers.DriverNameLabel + "=" + jobType)
+	if err != nil {
+		return 0, err
+	}
+	return counts[jobType], nil
+}
 
-	labelSelector := drivers.DriverNameLabel + "=" + jobType
+// GetJobCountByDriver returns the count of the jobs which are not completed
+// yet in all namespaces, by driver name.
+func GetJobCountByDriver() (map[string]int, error) {
+	return countJobs(drivers.DriverNameLabel)
+}
+
+func countJobs(labelSelector string) (map[string]int, error) {
 	options := metav1.ListOptions{
 		LabelSelector: labelSelector,
 	}
 	getAllNamespaces := getAllNamespaces()
-	var count int
+	counts := make(map[string]int)
 	for _, item := range getAllNamespaces.Items {
-		jobCount := 0
 		allJobs, err := batch.Instance().ListAllJobs(item.Name, options)
 		if err != nil {
 			errMsg := fmt.Sprintf("failed to get job list: %v", err)
 			log.Errorf("%v", errMsg)
-			return 0, fmt.Errorf("%v", errMsg)
+			return nil, fmt.Errorf("%v", errMsg)
 		}
-		jobCount = len(allJobs.Items)
 		// Check if any of the job is already in completed state.
 		// If complemented, exclude them from counting.
 		for _, job := range allJobs.Items {
-			for _, condition := range job.Status.Conditions {
-				if condition.Type == batchv1.JobComplete && condition.Status == corev1.ConditionTrue {
-					jobCount = jobCount - 1
-					break
-				}
+			if !isJobCompleted(&job) {
+				counts[job.Labels[drivers.DriverNameLabel]] += jobVolumes(&job)
 			}
 		}
-		count = count + jobCount
 	}
-	return count, nil
+	return counts, nil
+}
+
+// CountJobsByDriver returns the count of the jobs which are not completed
+// yet, by driver name.
+func CountJobsByDriver(jobs []batchv1.Job) map[string]int {
+	counts := make(map[string]int)
+	// Check if any of the job is already in completed state.
+	// If complemented, exclude them from counting.
+	for i := range jobs {
+		if !isJobCompleted(&jobs[i]) {
+			counts[jobs[i].Labels[drivers.DriverNameLabel]]++
+		}
+	}
+	return counts
+}
+
+// jobVolumes returns the number of volumes of a job, a batch job counts
+// against the job limit by volume.
+func jobVolumes(job *batchv1.Job) int {
+	value, ok := job.Labels[drivers.BatchVolumesLabel]
+	if !ok {
+		return 1
+	}
+	volumes, err := strconv.Atoi(value)
+	if err != nil || volumes < 1 {
+		log.Warnf("invalid %v label %q of job %v/%v", drivers.BatchVolumesLabel, value, job.Namespace, job.Name)
+		return 1
+	}
+	return volumes
+}
+
+func isJobCompleted(job *batchv1.Job) bool {
+	for _, condition := range job.Status.Conditions {
+		if condition.Type == batchv1.JobComplete && condition.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
 }
 
 func getAllNamespaces() *corev1.NamespaceList {
@@ -109,8 +165,12 @@ func getDefaultJobLimit(jobType string) int {
 		return DefaultDeleteJobLimit
 	case drivers.NFSDelete:
 		return DefaultDeleteJobLimit
-	case drivers.KopiaMaintenance:
+	case drivers.KopiaMaintenance, drivers.ResticMaintenance:
 		return DefaultMaintenanceJobLimit
+	case drivers.KopiaVerify, drivers.KopiaListFiles:
+		return DefaultVerifyJobLimit
+	case drivers.KopiaReplicate:
+		return DefaultReplicateJobLimit
 	default:
 		log.Warnf("unsupported job type [%v]", jobType)
 		return DefaultJobLimit
@@ -139,6 +199,12 @@ func jobLimitByType(jobType string) int {
 
 // CanJobBeScheduled takes the jobType and returns whether the given job can run or not based on limit set
 func CanJobBeScheduled(jobType string) (bool, error) {
+	return CanVolumesBeScheduled(jobType, 1)
+}
+
+// CanVolumesBeScheduled returns whether a batch job of the given number of
+// volumes can run. A batch with more volumes than the limit only runs alone.
+func CanVolumesBeScheduled(jobType string, volumes int) (bool, error) {
 	jobCount, err := getJobCountByType(jobType)
 	if err != nil {
 		return false, err
@@ -147,6 +213,9 @@ func CanJobBeScheduled(jobType string) (bool, error) {
 	if jobCount >= jobLimitCount {
 		return false, nil
 	}
+	if jobCount > 0 && jobCount+volumes > jobLimitCount {
+		return false, nil
+	}
 	return true, nil
 }
 
@@ -0,0 +1,256 @@
+package jobratelimit
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/portworx/kdmp/pkg/drivers/utils"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// defaultQueueRefreshInterval is how often the running jobs are listed.
+	defaultQueueRefreshInterval = 30 * time.Second
+	// defaultPendingTTL drops the requests which are not polled anymore, like
+	// the ones of a DataExport removed while waiting.
+	defaultPendingTTL = 5 * time.Minute
+)
+
+// QueueRequest is a job waiting for a free slot of its driver job limit.
+type QueueRequest struct {
+	// ID identifies the job in the queue, like the DataExport namespace/name.
+	ID string
+	// Driver is the driver name of the job.
+	Driver string
+	// Namespace is used to share the slots between the namespaces.
+	Namespace string
+	// Priority is only called when the request is queued.
+	Priority func() int32
+	// Volumes is the number of volumes of a batch job, it takes as many slots.
+	// Zero is one volume.
+	Volumes int
+}
+
+type queueEntry struct {
+	id         string
+	driver     string
+	namespace  string
+	priority   int32
+	volumes    int
+	seq        uint64
+	lastSeen   time.Time
+	admittedAt time.Time
+}
+
+// Queue orders the jobs waiting for a free slot, per driver. The jobs with the
+// highest priority go first, then the ones of the namespaces with the less
+// admitted jobs, then the oldest ones.
+//
+// The running jobs are listed at most once per refresh interval. The jobs
+// admitted since the last listing are counted on top of it, as they may not be
+// created yet at the time of the listing.
+type Queue struct {
+	mu              sync.Mutex
+	countJobs       func() (map[string]int, error)
+	jobLimit        func(string) int
+	now             func() time.Time
+	refreshInterval time.Duration
+	pendingTTL      time.Duration
+
+	lastRefresh time.Time
+	running     map[string]int
+	limits      map[string]int
+	seq         uint64
+	pending     map[string]*queueEntry
+	admitted    map[string]*queueEntry
+}
+
+// defaultQueue is shared by the DataExport controller and the drivers, so that
+// the jobs of a driver wait for the same slots whoever starts them.
+var defaultQueue = NewQueue()
+
+// DefaultQueue returns the job queue shared by the controllers and the drivers.
+func DefaultQueue() *Queue {
+	return defaultQueue
+}
+
+// AdmitJob admits the job of a driver through the default queue. It returns
+// utils.ErrOutOfJobResources while the job is queued, the caller has to retry
+// until the job is admitted.
+func AdmitJob(id, driverName, namespace string) error {
+	return AdmitBatchJob(id, driverName, namespace, 1)
+}
+
+// AdmitBatchJob is AdmitJob for a batch job of several volumes, which takes a
+// slot by volume.
+func AdmitBatchJob(id, driverName, namespace string, volumes int) error {
+	admitted, position, err := defaultQueue.Admit(QueueRequest{
+		ID:        id,
+		Driver:    driverName,
+		Namespace: namespace,
+		Volumes:   volumes,
+	})
+	if err != nil {
+		log.Errorf("failed to admit job %v of driver %v: %v", id, driverName, err)
+		return err
+	}
+	if !admitted {
+		log.Debugf("job %v of driver %v is queued at position %d", id, driverName, position)
+		return utils.ErrOutOfJobResources
+	}
+	return nil
+}
+
+// ReleaseJob drops the job from the default queue, once it is deleted.
+func ReleaseJob(id string) {
+	defaultQueue.Remove(id)
+}
+
+// NewQueue returns a job queue enforcing the job limits of the kdmp config.
+func NewQueue() *Queue {
+	return newQueue(GetJobCountByDriver, jobLimitByType, time.Now)
+}
+
+func newQueue(countJobs func() (map[string]int, error), jobLimit func(string) int, now func() time.Time) *Queue {
+	return &Queue{
+		countJobs:       countJobs,
+		jobLimit:        jobLimit,
+		now:             now,
+		refreshInterval: defaultQueueRefreshInterval,
+		pendingTTL:      defaultPendingTTL,
+		running:         make(map[string]int),
+		limits:          make(map[string]int),
+		pending:         make(map[string]*queueEntry),
+		admitted:        make(map[string]*queueEntry),
+	}
+}
+
+// IsRateLimited returns true if the jobs of the driver have a job limit.
+func IsRateLimited(driverName string) bool {
+	_, err := getJobLimitConfigmapKey(driverName)
+	return err == nil
+}
+
+// Admit queues the request if needed and returns true once the job can be
+// started. Otherwise it returns the position of the request in the queue of
+// its driver, starting at 1. Admit has to be called again until the job is
+// admitted, a request not polled for a while is dropped from the queue.
+func (q *Queue) Admit(req QueueRequest) (bool, int, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if _, ok := q.admitted[req.ID]; ok {
+		return true, 0, nil
+	}
+
+	now := q.now()
+	if now.Sub(q.lastRefresh) >= q.refreshInterval {
+		running, err := q.countJobs()
+		if err != nil {
+			return false, 0, err
+		}
+		q.running = running
+		q.limits = make(map[string]int)
+		q.lastRefresh = now
+	}
+	for id, entry := range q.pending {
+		if now.Sub(entry.lastSeen) > q.pendingTTL {
+			delete(q.pending, id)
+		}
+	}
+
+	entry, ok := q.pending[req.ID]
+	if !ok {
+		q.seq++
+		entry = &queueEntry{
+			id:        req.ID,
+			driver:    req.Driver,
+			namespace: req.Namespace,
+			volumes:   req.Volumes,
+			seq:       q.seq,
+		}
+		if entry.volumes < 1 {
+			entry.volumes = 1
+		}
+		if req.Priority != nil {
+			entry.priority = req.Priority()
+		}
+		q.pending[req.ID] = entry
+	}
+	entry.lastSeen = now
+
+	// The request is admitted if there are enough slots for it and the ones
+	// before it. A batch with more volumes than the limit only runs alone.
+	position, volumes := q.position(entry)
+	free, running := q.freeSlots(entry.driver)
+	if volumes > free && (position > 1 || running > 0) {
+		return false, position, nil
+	}
+	delete(q.pending, entry.id)
+	entry.admittedAt = now
+	q.admitted[entry.id] = entry
+	return true, 0, nil
+}
+
+// Remove drops the job from the queue. It has to be called once the job is
+// done, or is not going to be started anymore.
+func (q *Queue) Remove(id string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	delete(q.pending, id)
+	delete(q.admitted, id)
+}
+
+// freeSlots returns the free slots of the driver and the slots in use.
+func (q *Queue) freeSlots(driver string) (int, int) {
+	limit, ok := q.limits[driver]
+	if !ok {
+		limit = q.jobLimit(driver)
+		q.limits[driver] = limit
+	}
+	running := q.running[driver]
+	for _, entry := range q.admitted {
+		if entry.driver == driver && !entry.admittedAt.Before(q.lastRefresh) {
+			running += entry.volumes
+		}
+	}
+	return limit - running, running
+}
+
+// position returns the position of the entry in the queue of its driver and
+// the volumes of the entries up to it.
+func (q *Queue) position(entry *queueEntry) (int, int) {
+	admittedByNamespace := make(map[string]int)
+	for _, admitted := range q.admitted {
+		if admitted.driver == entry.driver {
+			admittedByNamespace[admitted.namespace]++
+		}
+	}
+
+	var entries []*queueEntry
+	for _, pending := range q.pending {
+		if pending.driver == entry.driver {
+			entries = append(entries, pending)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].priority != entries[j].priority {
+			return entries[i].priority > entries[j].priority
+		}
+		ni, nj := admittedByNamespace[entries[i].namespace], admittedByNamespace[entries[j].namespace]
+		if ni != nj {
+			return ni < nj
+		}
+		return entries[i].seq < entries[j].seq
+	})
+	var volumes int
+	for i, pending := range entries {
+		volumes += pending.volumes
+		if pending == entry {
+			return i + 1, volumes
+		}
+	}
+	return len(entries), volumes
+}
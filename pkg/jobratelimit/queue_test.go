@@ -0,0 +1,199 @@
+package jobratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/portworx/kdmp/pkg/drivers"
+	"github.com/portworx/kdmp/pkg/drivers/utils"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeCluster struct {
+	now     time.Time
+	running map[string]int
+	limit   int
+	lists   int
+}
+
+func newFakeQueue(c *fakeCluster) *Queue {
+	return newQueue(
+		func() (map[string]int, error) {
+			c.lists++
+			running := make(map[string]int)
+			for driver, count := range c.running {
+				running[driver] = count
+			}
+			return running, nil
+		},
+		func(string) int { return c.limit },
+		func() time.Time { return c.now },
+	)
+}
+
+func request(id, namespace string, priority int32) QueueRequest {
+	return QueueRequest{
+		ID:        id,
+		Driver:    drivers.KopiaBackup,
+		Namespace: namespace,
+		Priority:  func() int32 { return priority },
+	}
+}
+
+func TestQueueOrder(t *testing.T) {
+	c := &fakeCluster{now: time.Now(), running: map[string]int{drivers.KopiaBackup: 1}, limit: 1}
+	q := newFakeQueue(c)
+
+	testCases := []struct {
+		req      QueueRequest
+		position int
+	}{
+		{req: request("ns1/a", "ns1", 0), position: 1},
+		{req: request("ns1/b", "ns1", 0), position: 2},
+		{req: request("ns2/c", "ns2", 10), position: 1},
+		{req: request("ns1/a", "ns1", 0), position: 2},
+		{req: request("ns1/b", "ns1", 0), position: 3},
+	}
+	for _, tc := range testCases {
+		admitted, position, err := q.Admit(tc.req)
+		require.NoError(t, err)
+		require.False(t, admitted, "request %v", tc.req.ID)
+		require.Equal(t, tc.position, position, "request %v", tc.req.ID)
+	}
+
+	// A slot is released, the highest priority goes first.
+	c.running[drivers.KopiaBackup] = 0
+	c.now = c.now.Add(defaultQueueRefreshInterval)
+	admitted, position, err := q.Admit(request("ns1/a", "ns1", 0))
+	require.NoError(t, err)
+	require.False(t, admitted)
+	require.Equal(t, 2, position)
+	admitted, _, err = q.Admit(request("ns2/c", "ns2", 10))
+	require.NoError(t, err)
+	require.True(t, admitted)
+
+	// The admitted job holds the slot until the next listing.
+	admitted, position, err = q.Admit(request("ns1/a", "ns1", 0))
+	require.NoError(t, err)
+	require.False(t, admitted)
+	require.Equal(t, 1, position)
+	require.Equal(t, 2, c.lists)
+
+	// Admit is idempotent.
+	admitted, _, err = q.Admit(request("ns2/c", "ns2", 10))
+	require.NoError(t, err)
+	require.True(t, admitted)
+}
+
+func TestQueueFairShare(t *testing.T) {
+	c := &fakeCluster{now: time.Now(), running: map[string]int{}, limit: 1}
+	q := newFakeQueue(c)
+
+	admitted, _, err := q.Admit(request("ns1/a", "ns1", 0))
+	require.NoError(t, err)
+	require.True(t, admitted)
+
+	// ns2 goes before ns1 as ns1 already has an admitted job.
+	_, position, err := q.Admit(request("ns1/b", "ns1", 0))
+	require.NoError(t, err)
+	require.Equal(t, 1, position)
+	_, position, err = q.Admit(request("ns2/c", "ns2", 0))
+	require.NoError(t, err)
+	require.Equal(t, 1, position)
+	_, position, err = q.Admit(request("ns1/b", "ns1", 0))
+	require.NoError(t, err)
+	require.Equal(t, 2, position)
+
+	// Removing the admitted job gives back the slot, and ns1 is back to the
+	// arrival order.
+	q.Remove("ns1/a")
+	admitted, position, err = q.Admit(request("ns2/c", "ns2", 0))
+	require.NoError(t, err)
+	require.False(t, admitted)
+	require.Equal(t, 2, position)
+	admitted, _, err = q.Admit(request("ns1/b", "ns1", 0))
+	require.NoError(t, err)
+	require.True(t, admitted)
+}
+
+func TestQueuePendingTTL(t *testing.T) {
+	c := &fakeCluster{now: time.Now(), running: map[string]int{drivers.KopiaBackup: 1}, limit: 1}
+	q := newFakeQueue(c)
+
+	_, position, err := q.Admit(request("ns1/a", "ns1", 0))
+	require.NoError(t, err)
+	require.Equal(t, 1, position)
+
+	// ns1/a is not polled anymore and is dropped from the queue.
+	c.now = c.now.Add(defaultPendingTTL + time.Second)
+	_, position, err = q.Admit(request("ns1/b", "ns1", 0))
+	require.NoError(t, err)
+	require.Equal(t, 1, position)
+}
+
+func TestAdmitJob(t *testing.T) {
+	c := &fakeCluster{now: time.Now(), running: map[string]int{}, limit: 1}
+	orig := defaultQueue
+	defer func() { defaultQueue = orig }()
+	defaultQueue = newFakeQueue(c)
+
+	// The jobs started by a driver share the slots of the controller.
+	admitted, _, err := DefaultQueue().Admit(request("ns1/export", "ns1", 0))
+	require.NoError(t, err)
+	require.True(t, admitted)
+	err = AdmitJob("ns1/job", drivers.KopiaBackup, "ns1")
+	require.Equal(t, utils.ErrOutOfJobResources, err)
+
+	// The slot is released once the job of the controller is deleted.
+	DefaultQueue().Remove("ns1/export")
+	require.NoError(t, AdmitJob("ns1/job", drivers.KopiaBackup, "ns1"))
+	require.NoError(t, AdmitJob("ns1/job", drivers.KopiaBackup, "ns1"))
+	ReleaseJob("ns1/job")
+	require.NoError(t, AdmitJob("ns2/job", drivers.KopiaBackup, "ns2"))
+
+	// A batch job takes the slots of its volumes.
+	ReleaseJob("ns2/job")
+	require.NoError(t, AdmitBatchJob("ns1/batch", drivers.KopiaBackup, "ns1", 3))
+	require.Equal(t, utils.ErrOutOfJobResources, AdmitJob("ns2/job", drivers.KopiaBackup, "ns2"))
+}
+
+func TestQueueBatchVolumes(t *testing.T) {
+	c := &fakeCluster{now: time.Now(), running: map[string]int{drivers.KopiaBackup: 2}, limit: 4}
+	q := newFakeQueue(c)
+
+	batch := request("ns1/batch", "ns1", 0)
+	batch.Volumes = 3
+	admitted, position, err := q.Admit(batch)
+	require.NoError(t, err)
+	require.False(t, admitted)
+	require.Equal(t, 1, position)
+
+	// A single volume job behind the batch waits for it.
+	admitted, position, err = q.Admit(request("ns2/a", "ns2", 0))
+	require.NoError(t, err)
+	require.False(t, admitted)
+	require.Equal(t, 2, position)
+
+	// The batch takes the slots of its volumes.
+	c.running[drivers.KopiaBackup] = 1
+	c.now = c.now.Add(defaultQueueRefreshInterval)
+	admitted, _, err = q.Admit(batch)
+	require.NoError(t, err)
+	require.True(t, admitted)
+	admitted, _, err = q.Admit(request("ns2/a", "ns2", 0))
+	require.NoError(t, err)
+	require.False(t, admitted)
+
+	// A batch larger than the limit runs alone.
+	q.Remove("ns1/batch")
+	c.running[drivers.KopiaBackup] = 0
+	c.now = c.now.Add(defaultQueueRefreshInterval)
+	large := request("ns3/large", "ns3", 10)
+	large.Volumes = 10
+	admitted, _, err = q.Admit(large)
+	require.NoError(t, err)
+	require.True(t, admitted)
+	admitted, _, err = q.Admit(request("ns2/a", "ns2", 0))
+	require.NoError(t, err)
+	require.False(t, admitted)
+}
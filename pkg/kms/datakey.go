@@ -0,0 +1,166 @@
+package kms
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	storkapi "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"github.com/libopenstorage/stork/pkg/objectstore"
+	"gocloud.dev/blob"
+	"gocloud.dev/gcerrors"
+)
+
+const (
+	// DataKeysCredentialsKey is the key of the repository data keys in the job
+	// credential secret.
+	DataKeysCredentialsKey = "datakeys"
+	// DataKeyFile holds the wrapped data key of a repository, next to the
+	// kopia repository file.
+	DataKeyFile = "kdmp.datakey"
+)
+
+// ErrNoDataKey is returned for a repository without a data key, either not
+// created yet or created before the BackupLocation had a KMS.
+var ErrNoDataKey = errors.New("repository has no data key")
+
+var getBucket = objectstore.GetBucket
+
+// DataKey is the data key of a repository, as passed to the jobs.
+type DataKey struct {
+	// Password is the kopia repository password of the data key.
+	Password string `json:"password"`
+	// Wrapped is only set for a data key not stored in the repository yet.
+	// The job stores it once the repository is created.
+	Wrapped string `json:"wrapped,omitempty"`
+}
+
+// DataKeys are the data keys of the repositories used by a job, by repository
+// path.
+type DataKeys map[string]DataKey
+
+// Get returns the data key of the repository.
+func (d DataKeys) Get(repository string) (DataKey, bool) {
+	dataKey, ok := d[repositoryKey(repository)]
+	return dataKey, ok
+}
+
+func (d DataKeys) set(repository string, dataKey DataKey) {
+	d[repositoryKey(repository)] = dataKey
+}
+
+func repositoryKey(repository string) string {
+	return strings.Trim(repository, "/")
+}
+
+// AddDataKeyCredentials adds the data keys of the repositories to the data of
+// a job credential secret, if the BackupLocation has a KMS. The data keys are
+// unwrapped here, so that the KMS config never leaves the controller. A data
+// key is generated for a repository which has none yet.
+func AddDataKeyCredentials(credentialData map[string][]byte, bl *storkapi.BackupLocation, repositories ...string) error {
+	if len(repositories) == 0 {
+		return nil
+	}
+	config, err := ConfigFromBackupLocation(bl)
+	if err != nil || config == nil {
+		return err
+	}
+	provider, err := New(config)
+	if err != nil {
+		return err
+	}
+
+	dataKeys := make(DataKeys)
+	for _, repository := range repositories {
+		wrapped, err := ReadDataKey(bl, repository)
+		if err == ErrNoDataKey {
+			key, wrapped, err := NewDataKey(provider)
+			if err != nil {
+				return err
+			}
+			dataKeys.set(repository, DataKey{Password: Password(key), Wrapped: wrapped})
+			continue
+		} else if err != nil {
+			return err
+		}
+		key, err := provider.Unwrap(wrapped)
+		if err != nil {
+			return fmt.Errorf("failed to unwrap the data key of repository %s: %v", repository, err)
+		}
+		dataKeys.set(repository, DataKey{Password: Password(key)})
+	}
+	return addDataKeys(credentialData, dataKeys)
+}
+
+// AddReplicaDataKeyCredentials adds the data key of a repository replicated
+// from the src BackupLocation to the data of the credential secret of the dst
+// one. The data key is wrapped with the KMS of dst, to be stored with the
+// replica. Nothing is added if the repository has no data key.
+func AddReplicaDataKeyCredentials(credentialData map[string][]byte, src, dst *storkapi.BackupLocation, repository string) error {
+	srcConfig, err := ConfigFromBackupLocation(src)
+	if err != nil || srcConfig == nil {
+		return err
+	}
+	srcProvider, err := New(srcConfig)
+	if err != nil {
+		return err
+	}
+	wrapped, err := ReadDataKey(src, repository)
+	if err == ErrNoDataKey {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	key, err := srcProvider.Unwrap(wrapped)
+	if err != nil {
+		return fmt.Errorf("failed to unwrap the data key of repository %s: %v", repository, err)
+	}
+
+	dstConfig, err := ConfigFromBackupLocation(dst)
+	if err != nil {
+		return err
+	}
+	if dstConfig == nil {
+		return fmt.Errorf("repository %s is encrypted with a data key but backuplocation %s/%s has no kms", repository, dst.Namespace, dst.Name)
+	}
+	dstProvider, err := New(dstConfig)
+	if err != nil {
+		return err
+	}
+	dstWrapped, err := dstProvider.Wrap(key)
+	if err != nil {
+		return fmt.Errorf("failed to wrap the data key of repository %s: %v", repository, err)
+	}
+	dataKeys := make(DataKeys)
+	dataKeys.set(repository, DataKey{Password: Password(key), Wrapped: dstWrapped})
+	return addDataKeys(credentialData, dataKeys)
+}
+
+// ReadDataKey returns the wrapped data key stored in the repository of an
+// object store BackupLocation.
+func ReadDataKey(bl *storkapi.BackupLocation, repository string) (string, error) {
+	bucket, err := getBucket(bl)
+	if err != nil {
+		return "", fmt.Errorf("failed to get the bucket of backuplocation %s/%s: %v", bl.Namespace, bl.Name, err)
+	}
+	defer bucket.Close()
+	data, err := blob.PrefixedBucket(bucket, repositoryKey(repository)+"/").ReadAll(context.TODO(), DataKeyFile)
+	if gcerrors.Code(err) == gcerrors.NotFound {
+		return "", ErrNoDataKey
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read the data key of repository %s: %v", repository, err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+func addDataKeys(credentialData map[string][]byte, dataKeys DataKeys) error {
+	data, err := json.Marshal(dataKeys)
+	if err != nil {
+		return fmt.Errorf("failed to marshal the data keys: %v", err)
+	}
+	credentialData[DataKeysCredentialsKey] = data
+	return nil
+}
@@ -0,0 +1,137 @@
+package kms
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	storkapi "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"github.com/libopenstorage/stork/pkg/objectstore"
+	"github.com/portworx/sched-ops/k8s/core"
+	"github.com/stretchr/testify/require"
+	"gocloud.dev/blob"
+	"gocloud.dev/blob/fileblob"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// fakeCore serves the KMS secrets of the backuplocations.
+type fakeCore struct {
+	core.Ops
+	secrets map[string]map[string][]byte
+}
+
+func (c *fakeCore) GetSecret(name, namespace string) (*corev1.Secret, error) {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Data:       c.secrets[name],
+	}, nil
+}
+
+func testBackupLocation(name, kmsSecret string) *storkapi.BackupLocation {
+	return &storkapi.BackupLocation{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "admin",
+			Annotations: map[string]string{
+				ProviderAnnotation: ProviderSecret,
+				SecretAnnotation:   kmsSecret,
+			},
+		},
+		Location: storkapi.BackupLocationItem{Type: storkapi.BackupLocationS3},
+	}
+}
+
+func readDataKeys(t *testing.T, credentialData map[string][]byte) DataKeys {
+	var dataKeys DataKeys
+	require.NoError(t, json.Unmarshal(credentialData[DataKeysCredentialsKey], &dataKeys))
+	return dataKeys
+}
+
+func TestAddDataKeyCredentials(t *testing.T) {
+	keys := map[string][]byte{"v1": testKey('a')}
+	core.SetInstance(&fakeCore{secrets: map[string]map[string][]byte{"kms": keys}})
+	dir := t.TempDir()
+	getBucket = func(*storkapi.BackupLocation) (*blob.Bucket, error) {
+		return fileblob.OpenBucket(dir, nil)
+	}
+	defer func() { getBucket = objectstore.GetBucket }()
+	bl := testBackupLocation("bl", "kms")
+	provider, err := New(&Config{Provider: ProviderSecret, Keys: keys})
+	require.NoError(t, err)
+
+	// A repository without a data key gets a new one, to be stored by the job
+	credentialData := make(map[string][]byte)
+	require.NoError(t, AddDataKeyCredentials(credentialData, bl, "generic-backup/app-data/"))
+	require.NotContains(t, credentialData, CredentialsKey)
+	dataKey, ok := readDataKeys(t, credentialData).Get("generic-backup/app-data")
+	require.True(t, ok)
+	require.NotEmpty(t, dataKey.Wrapped)
+	key, err := provider.Unwrap(dataKey.Wrapped)
+	require.NoError(t, err)
+	require.Equal(t, Password(key), dataKey.Password)
+
+	// The stored data key is unwrapped, only the password is passed
+	bucket, err := getBucket(bl)
+	require.NoError(t, err)
+	require.NoError(t, bucket.WriteAll(context.TODO(), "generic-backup/app-data/"+DataKeyFile, []byte(dataKey.Wrapped), nil))
+	require.NoError(t, bucket.Close())
+	credentialData = make(map[string][]byte)
+	require.NoError(t, AddDataKeyCredentials(credentialData, bl, "generic-backup/app-data/"))
+	require.Equal(t, DataKey{Password: Password(key)}, readDataKeys(t, credentialData)["generic-backup/app-data"])
+
+	// Without a kms nothing is added
+	bl.Annotations = nil
+	credentialData = make(map[string][]byte)
+	require.NoError(t, AddDataKeyCredentials(credentialData, bl, "generic-backup/app-data/"))
+	require.Empty(t, credentialData)
+
+	// The data keys of the nfs repositories can't be read
+	bl = testBackupLocation("bl", "kms")
+	bl.Location.Type = storkapi.BackupLocationNFS
+	require.Error(t, AddDataKeyCredentials(credentialData, bl, "generic-backup/app-data/"))
+}
+
+func TestAddReplicaDataKeyCredentials(t *testing.T) {
+	srcKeys := map[string][]byte{"v1": testKey('a')}
+	dstKeys := map[string][]byte{"v1": testKey('b')}
+	core.SetInstance(&fakeCore{secrets: map[string]map[string][]byte{"src-kms": srcKeys, "dst-kms": dstKeys}})
+	dir := t.TempDir()
+	getBucket = func(*storkapi.BackupLocation) (*blob.Bucket, error) {
+		return fileblob.OpenBucket(dir, nil)
+	}
+	defer func() { getBucket = objectstore.GetBucket }()
+	src := testBackupLocation("src", "src-kms")
+	dst := testBackupLocation("dst", "dst-kms")
+
+	// A repository without a data key is replicated as is
+	credentialData := make(map[string][]byte)
+	require.NoError(t, AddReplicaDataKeyCredentials(credentialData, src, dst, "generic-backup/app-data/"))
+	require.Empty(t, credentialData)
+
+	srcProvider, err := New(&Config{Provider: ProviderSecret, Keys: srcKeys})
+	require.NoError(t, err)
+	key, wrapped, err := NewDataKey(srcProvider)
+	require.NoError(t, err)
+	bucket, err := getBucket(src)
+	require.NoError(t, err)
+	require.NoError(t, bucket.WriteAll(context.TODO(), "generic-backup/app-data/"+DataKeyFile, []byte(wrapped), nil))
+	require.NoError(t, bucket.Close())
+
+	// The data key is wrapped with the kms of the destination
+	require.NoError(t, AddReplicaDataKeyCredentials(credentialData, src, dst, "generic-backup/app-data/"))
+	dataKey, ok := readDataKeys(t, credentialData).Get("generic-backup/app-data/")
+	require.True(t, ok)
+	require.Equal(t, Password(key), dataKey.Password)
+	dstProvider, err := New(&Config{Provider: ProviderSecret, Keys: dstKeys})
+	require.NoError(t, err)
+	dstKey, err := dstProvider.Unwrap(dataKey.Wrapped)
+	require.NoError(t, err)
+	require.Equal(t, key, dstKey)
+	_, err = srcProvider.Unwrap(dataKey.Wrapped)
+	require.Error(t, err)
+
+	// The destination needs a kms
+	dst.Annotations = nil
+	require.Error(t, AddReplicaDataKeyCredentials(credentialData, src, dst, "generic-backup/app-data/"))
+}
@@ -0,0 +1,150 @@
+// Package kms implements the envelope encryption of the kopia repositories.
+// Each repository has a random data key, used as the kopia repository
+// password, which is stored wrapped by a key of a KMS provider configured per
+// BackupLocation.
+//
+// The data keys are unwrapped by the controller, the jobs in the application
+// namespaces only get the data keys of their repositories. Only the
+// maintenance jobs rotating the data keys get the KMS config.
+package kms
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	storkapi "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"github.com/portworx/sched-ops/k8s/core"
+)
+
+const (
+	// ProviderSecret wraps the data keys with keys held in a Kubernetes Secret.
+	ProviderSecret = "secret"
+	// ProviderVault wraps the data keys with the transit engine of a Vault
+	// compatible server.
+	ProviderVault = "vault"
+
+	// ProviderAnnotation is the BackupLocation annotation selecting the KMS
+	// provider of its repositories.
+	ProviderAnnotation = "kdmp.portworx.com/kms-provider"
+	// SecretAnnotation is the BackupLocation annotation with the name of the
+	// Secret configuring the KMS provider, in the BackupLocation namespace.
+	SecretAnnotation = "kdmp.portworx.com/kms-secret"
+
+	// CredentialsKey is the key of the KMS config in the credential secret of
+	// the maintenance jobs.
+	CredentialsKey = "kms"
+
+	dataKeySize = 32
+)
+
+// Provider wraps and unwraps the repository data keys.
+type Provider interface {
+	// Wrap encrypts the data key with the current key of the provider.
+	Wrap(key []byte) (string, error)
+	// Unwrap decrypts a data key wrapped by any key version of the provider.
+	Unwrap(wrapped string) ([]byte, error)
+}
+
+// Config is the KMS config of a BackupLocation, as passed to the jobs.
+type Config struct {
+	Provider string `json:"provider"`
+	// Keys are the key versions of the secret provider.
+	Keys  map[string][]byte `json:"keys,omitempty"`
+	Vault *VaultConfig      `json:"vault,omitempty"`
+}
+
+// New returns the provider of the config.
+func New(config *Config) (Provider, error) {
+	switch config.Provider {
+	case ProviderSecret:
+		return newSecretProvider(config.Keys)
+	case ProviderVault:
+		if config.Vault == nil {
+			return nil, fmt.Errorf("vault config should be set")
+		}
+		return newVaultProvider(*config.Vault)
+	}
+	return nil, fmt.Errorf("unsupported kms provider %q", config.Provider)
+}
+
+// NewDataKey generates a data key and returns it with its wrapped form.
+func NewDataKey(p Provider) ([]byte, string, error) {
+	key := make([]byte, dataKeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, "", fmt.Errorf("failed to generate a data key: %v", err)
+	}
+	wrapped, err := p.Wrap(key)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to wrap the data key: %v", err)
+	}
+	return key, wrapped, nil
+}
+
+// Rewrap wraps a data key again with the current key of the provider.
+func Rewrap(p Provider, wrapped string) (string, error) {
+	key, err := p.Unwrap(wrapped)
+	if err != nil {
+		return "", fmt.Errorf("failed to unwrap the data key: %v", err)
+	}
+	return p.Wrap(key)
+}
+
+// Password returns the kopia repository password of a data key.
+func Password(key []byte) string {
+	return hex.EncodeToString(key)
+}
+
+// ConfigFromBackupLocation returns the KMS config of the BackupLocation, nil
+// if it has none.
+func ConfigFromBackupLocation(bl *storkapi.BackupLocation) (*Config, error) {
+	provider := bl.Annotations[ProviderAnnotation]
+	if provider == "" {
+		return nil, nil
+	}
+	secretName := bl.Annotations[SecretAnnotation]
+	if secretName == "" {
+		return nil, fmt.Errorf("backuplocation %s/%s has no %s annotation", bl.Namespace, bl.Name, SecretAnnotation)
+	}
+	secret, err := core.Instance().GetSecret(secretName, bl.Namespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get kms secret %s/%s: %v", bl.Namespace, secretName, err)
+	}
+
+	// The data keys of a NFS repository can't be read by the controller.
+	if bl.Location.Type == storkapi.BackupLocationNFS {
+		return nil, fmt.Errorf("kms is not supported with the nfs backuplocation %s/%s", bl.Namespace, bl.Name)
+	}
+
+	config := &Config{Provider: provider}
+	switch provider {
+	case ProviderSecret:
+		config.Keys = secret.Data
+	case ProviderVault:
+		config.Vault = vaultConfigFromSecret(secret.Data)
+	default:
+		return nil, fmt.Errorf("unsupported kms provider %q of backuplocation %s/%s", provider, bl.Namespace, bl.Name)
+	}
+	// catch config errors before starting any job
+	if _, err := New(config); err != nil {
+		return nil, fmt.Errorf("invalid kms config of backuplocation %s/%s: %v", bl.Namespace, bl.Name, err)
+	}
+	return config, nil
+}
+
+// AddMaintenanceCredentials adds the KMS config of the BackupLocation, if any,
+// to the data of a maintenance job credential secret. It must not be used for
+// the jobs of the application namespaces, see AddDataKeyCredentials.
+func AddMaintenanceCredentials(credentialData map[string][]byte, bl *storkapi.BackupLocation) error {
+	config, err := ConfigFromBackupLocation(bl)
+	if err != nil || config == nil {
+		return err
+	}
+	data, err := json.Marshal(config)
+	if err != nil {
+		return fmt.Errorf("failed to marshal the kms config: %v", err)
+	}
+	credentialData[CredentialsKey] = data
+	return nil
+}
@@ -0,0 +1,164 @@
+package kms
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testKey(b byte) []byte {
+	return []byte(strings.Repeat(string(b), dataKeySize))
+}
+
+func TestSecretProvider(t *testing.T) {
+	p, err := New(&Config{
+		Provider: ProviderSecret,
+		Keys:     map[string][]byte{"v1": testKey('a')},
+	})
+	require.NoError(t, err)
+
+	key, wrapped, err := NewDataKey(p)
+	require.NoError(t, err)
+	require.Len(t, key, dataKeySize)
+	require.True(t, strings.HasPrefix(wrapped, "kdmp:v1:"), wrapped)
+	unwrapped, err := p.Unwrap(wrapped)
+	require.NoError(t, err)
+	require.Equal(t, key, unwrapped)
+
+	// A new key version is added, the data key is rewrapped with it.
+	p, err = New(&Config{
+		Provider: ProviderSecret,
+		Keys: map[string][]byte{
+			"v1": testKey('a'),
+			"v2": []byte(base64.StdEncoding.EncodeToString(testKey('b'))),
+		},
+	})
+	require.NoError(t, err)
+	rewrapped, err := Rewrap(p, wrapped)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(rewrapped, "kdmp:v2:"), rewrapped)
+
+	// The old key version can be removed once all the data keys are rotated.
+	p, err = New(&Config{
+		Provider: ProviderSecret,
+		Keys:     map[string][]byte{"v2": testKey('b')},
+	})
+	require.NoError(t, err)
+	unwrapped, err = p.Unwrap(rewrapped)
+	require.NoError(t, err)
+	require.Equal(t, key, unwrapped)
+	_, err = p.Unwrap(wrapped)
+	require.Error(t, err)
+}
+
+func TestSecretProviderInvalid(t *testing.T) {
+	for _, keys := range []map[string][]byte{
+		nil,
+		{"key": testKey('a')},
+		{"v0": testKey('a')},
+		{"v1": []byte("short")},
+	} {
+		_, err := New(&Config{Provider: ProviderSecret, Keys: keys})
+		require.Errorf(t, err, "keys: %v", keys)
+	}
+}
+
+// vaultStub implements the encrypt and decrypt endpoints of the transit
+// engine, the ciphertext is the plaintext with the key version.
+type vaultStub struct {
+	mu      sync.Mutex
+	version int
+}
+
+func (s *vaultStub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r.Header.Get("X-Vault-Token") != "token" {
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, `{"errors":["permission denied"]}`)
+		return
+	}
+	var req vaultRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	var resp vaultResponse
+	switch r.URL.Path {
+	case "/v1/transit/encrypt/kdmp":
+		resp.Data.Ciphertext = fmt.Sprintf("vault:v%d:%s", s.version, req.Plaintext)
+	case "/v1/transit/decrypt/kdmp":
+		parts := strings.SplitN(req.Ciphertext, ":", 3)
+		if len(parts) != 3 {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"errors":["invalid ciphertext"]}`)
+			return
+		}
+		resp.Data.Plaintext = parts[2]
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+func TestVaultProvider(t *testing.T) {
+	stub := &vaultStub{version: 1}
+	server := httptest.NewServer(stub)
+	defer server.Close()
+
+	p, err := New(&Config{
+		Provider: ProviderVault,
+		Vault: vaultConfigFromSecret(map[string][]byte{
+			"address": []byte(server.URL),
+			"token":   []byte("token\n"),
+			"key":     []byte("kdmp"),
+		}),
+	})
+	require.NoError(t, err)
+
+	key, wrapped, err := NewDataKey(p)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(wrapped, "vault:v1:"), wrapped)
+	unwrapped, err := p.Unwrap(wrapped)
+	require.NoError(t, err)
+	require.Equal(t, key, unwrapped)
+
+	// The transit key is rotated, the data key is rewrapped with its new version.
+	stub.version = 2
+	rewrapped, err := Rewrap(p, wrapped)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(rewrapped, "vault:v2:"), rewrapped)
+	unwrapped, err = p.Unwrap(rewrapped)
+	require.NoError(t, err)
+	require.Equal(t, key, unwrapped)
+
+	p, err = New(&Config{
+		Provider: ProviderVault,
+		Vault:    &VaultConfig{Address: server.URL, Token: "invalid", KeyName: "kdmp"},
+	})
+	require.NoError(t, err)
+	_, err = p.Unwrap(wrapped)
+	require.ErrorContains(t, err, "permission denied")
+}
+
+func TestVaultProviderInvalid(t *testing.T) {
+	for _, config := range []*Config{
+		{Provider: ProviderVault},
+		{Provider: ProviderVault, Vault: &VaultConfig{Token: "token", KeyName: "kdmp"}},
+		{Provider: ProviderVault, Vault: &VaultConfig{Address: "http://vault", KeyName: "kdmp"}},
+		{Provider: ProviderVault, Vault: &VaultConfig{Address: "http://vault", Token: "token"}},
+		{Provider: "aws"},
+	} {
+		_, err := New(config)
+		require.Errorf(t, err, "config: %+v", config)
+	}
+}
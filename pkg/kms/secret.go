@@ -0,0 +1,117 @@
+package kms
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const secretPrefix = "kdmp"
+
+// secretProvider wraps the data keys with AES-256-GCM. The keys are the
+// entries of a Secret named v1, v2, ...: the data keys are wrapped with the
+// highest version, the older ones are kept to unwrap the data keys until they
+// are all rotated.
+type secretProvider struct {
+	keys    map[int][]byte
+	current int
+}
+
+func newSecretProvider(keys map[string][]byte) (*secretProvider, error) {
+	p := &secretProvider{keys: make(map[int][]byte)}
+	for name, val := range keys {
+		version, err := parseKeyVersion(name)
+		if err != nil {
+			return nil, err
+		}
+		key, err := decodeKey(val)
+		if err != nil {
+			return nil, fmt.Errorf("invalid key %s: %v", name, err)
+		}
+		p.keys[version] = key
+		if version > p.current {
+			p.current = version
+		}
+	}
+	if len(p.keys) == 0 {
+		return nil, fmt.Errorf("no key is set")
+	}
+	return p, nil
+}
+
+func parseKeyVersion(name string) (int, error) {
+	version, err := strconv.Atoi(strings.TrimPrefix(name, "v"))
+	if !strings.HasPrefix(name, "v") || err != nil || version <= 0 {
+		return 0, fmt.Errorf("invalid key name %q, expected v1, v2, ...", name)
+	}
+	return version, nil
+}
+
+// decodeKey accepts the raw keys or their base64 encoding, like the output of
+// openssl rand -base64 32.
+func decodeKey(val []byte) ([]byte, error) {
+	if len(val) == dataKeySize {
+		return val, nil
+	}
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(val)))
+	if err != nil || len(key) != dataKeySize {
+		return nil, fmt.Errorf("key should be %d bytes, or their base64 encoding", dataKeySize)
+	}
+	return key, nil
+}
+
+func (p *secretProvider) Wrap(key []byte) (string, error) {
+	gcm, err := newGCM(p.keys[p.current])
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := gcm.Seal(nonce, nonce, key, nil)
+	return fmt.Sprintf("%s:v%d:%s", secretPrefix, p.current, base64.StdEncoding.EncodeToString(sealed)), nil
+}
+
+func (p *secretProvider) Unwrap(wrapped string) ([]byte, error) {
+	parts := strings.SplitN(wrapped, ":", 3)
+	if len(parts) != 3 || parts[0] != secretPrefix {
+		return nil, fmt.Errorf("data key is not wrapped by the secret provider")
+	}
+	version, err := parseKeyVersion(parts[1])
+	if err != nil {
+		return nil, err
+	}
+	kek, ok := p.keys[version]
+	if !ok {
+		return nil, fmt.Errorf("key %s is not set", parts[1])
+	}
+	sealed, err := base64.StdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("invalid wrapped data key: %v", err)
+	}
+	gcm, err := newGCM(kek)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return nil, fmt.Errorf("invalid wrapped data key")
+	}
+	key, err := gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt the data key with key %s: %v", parts[1], err)
+	}
+	return key, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
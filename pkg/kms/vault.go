@@ -0,0 +1,154 @@
+package kms
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	defaultVaultMountPath = "transit"
+	vaultRequestTimeout   = 30 * time.Second
+)
+
+// VaultConfig is the config of the vault provider. It is read from the KMS
+// Secret keys address, token, key and optionally mount, namespace and ca.crt.
+type VaultConfig struct {
+	Address string `json:"address"`
+	Token   string `json:"token"`
+	// KeyName is the name of the transit key.
+	KeyName string `json:"keyName"`
+	// MountPath is the path of the transit engine, transit by default.
+	MountPath string `json:"mountPath,omitempty"`
+	// Namespace is the Vault Enterprise namespace.
+	Namespace string `json:"namespace,omitempty"`
+	CACert    []byte `json:"caCert,omitempty"`
+}
+
+func vaultConfigFromSecret(data map[string][]byte) *VaultConfig {
+	return &VaultConfig{
+		Address:   strings.TrimSpace(string(data["address"])),
+		Token:     strings.TrimSpace(string(data["token"])),
+		KeyName:   strings.TrimSpace(string(data["key"])),
+		MountPath: strings.TrimSpace(string(data["mount"])),
+		Namespace: strings.TrimSpace(string(data["namespace"])),
+		CACert:    data["ca.crt"],
+	}
+}
+
+// vaultProvider wraps the data keys with the encrypt and decrypt endpoints of
+// the Vault transit engine. Vault encrypts with the latest version of the
+// transit key, so rotating the transit key and then rewrapping the data keys
+// is enough to rotate them.
+type vaultProvider struct {
+	config VaultConfig
+	client *http.Client
+}
+
+type vaultRequest struct {
+	Plaintext  string `json:"plaintext,omitempty"`
+	Ciphertext string `json:"ciphertext,omitempty"`
+}
+
+type vaultResponse struct {
+	Data struct {
+		Plaintext  string `json:"plaintext"`
+		Ciphertext string `json:"ciphertext"`
+	} `json:"data"`
+	Errors []string `json:"errors"`
+}
+
+func newVaultProvider(config VaultConfig) (*vaultProvider, error) {
+	if config.Address == "" {
+		return nil, fmt.Errorf("vault address should be set")
+	}
+	if config.Token == "" {
+		return nil, fmt.Errorf("vault token should be set")
+	}
+	if config.KeyName == "" {
+		return nil, fmt.Errorf("vault transit key name should be set")
+	}
+	if config.MountPath == "" {
+		config.MountPath = defaultVaultMountPath
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if len(config.CACert) > 0 {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(config.CACert) {
+			return nil, fmt.Errorf("invalid vault ca certificate")
+		}
+		transport.TLSClientConfig = &tls.Config{RootCAs: pool}
+	}
+	return &vaultProvider{
+		config: config,
+		client: &http.Client{Transport: transport, Timeout: vaultRequestTimeout},
+	}, nil
+}
+
+func (p *vaultProvider) Wrap(key []byte) (string, error) {
+	resp, err := p.do("encrypt", vaultRequest{Plaintext: base64.StdEncoding.EncodeToString(key)})
+	if err != nil {
+		return "", err
+	}
+	if resp.Data.Ciphertext == "" {
+		return "", fmt.Errorf("vault returned no ciphertext")
+	}
+	return resp.Data.Ciphertext, nil
+}
+
+func (p *vaultProvider) Unwrap(wrapped string) ([]byte, error) {
+	resp, err := p.do("decrypt", vaultRequest{Ciphertext: wrapped})
+	if err != nil {
+		return nil, err
+	}
+	key, err := base64.StdEncoding.DecodeString(resp.Data.Plaintext)
+	if err != nil {
+		return nil, fmt.Errorf("invalid plaintext returned by vault: %v", err)
+	}
+	return key, nil
+}
+
+func (p *vaultProvider) do(operation string, body vaultRequest) (*vaultResponse, error) {
+	reqBody, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	url := fmt.Sprintf("%s/v1/%s/%s/%s",
+		strings.TrimSuffix(p.config.Address, "/"), strings.Trim(p.config.MountPath, "/"), operation, p.config.KeyName)
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(reqBody))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Vault-Token", p.config.Token)
+	req.Header.Set("Content-Type", "application/json")
+	if p.config.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", p.config.Namespace)
+	}
+
+	httpResp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("vault %s request failed: %v", operation, err)
+	}
+	defer httpResp.Body.Close()
+	respBody, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the vault %s response: %v", operation, err)
+	}
+
+	resp := &vaultResponse{}
+	if err := json.Unmarshal(respBody, resp); err != nil && httpResp.StatusCode == http.StatusOK {
+		return nil, fmt.Errorf("invalid vault %s response: %v", operation, err)
+	}
+	if httpResp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("vault %s request failed with status %d: %s", operation, httpResp.StatusCode, strings.Join(resp.Errors, ", "))
+	}
+	return resp, nil
+}
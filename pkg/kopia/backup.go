@@ -6,13 +6,19 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"math"
 	"os"
 	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
+	kdmpapi "github.com/portworx/kdmp/pkg/apis/kdmp/v1alpha1"
 	cmdexec "github.com/portworx/kdmp/pkg/executor"
 	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 const (
@@ -29,6 +35,40 @@ type BackupSummaryResponse struct {
 	IncompleteReason string     `json:"incomplete,omitempty"`
 	RootEntry        RootEntry  `json:"rootEntry"`
 	RetentionReasons []string   `json:"retention"`
+	Stats            Stats      `json:"stats"`
+}
+
+// Stats are the statistics of a snapshot.
+type Stats struct {
+	TotalFileSize       int64 `json:"totalSize"`
+	TotalFileCount      int64 `json:"fileCount"`
+	CachedFiles         int64 `json:"cachedFiles"`
+	NonCachedFiles      int64 `json:"nonCachedFiles"`
+	TotalDirectoryCount int64 `json:"dirCount"`
+	ExcludedFileCount   int64 `json:"excludedFileCount"`
+	ExcludedDirCount    int64 `json:"excludedDirCount"`
+	IgnoredErrorCount   int64 `json:"ignoredErrorCount"`
+	ErrorCount          int64 `json:"errorCount"`
+}
+
+// uploadProgressRegexp matches the byte counters of the upload progress lines
+// printed by kopia on stderr, like
+// " * 0 hashing, 12 hashed (1.2 MB), 30 cached (15 MB), uploaded 800 KB, estimated ..."
+var uploadProgressRegexp = regexp.MustCompile(`hashed \(([^)]*)\), \d+ cached \(([^)]*)\), uploaded ([0-9.]+ ?[A-Za-z]+)`)
+
+// byteUnits are the units of the sizes printed by kopia.
+var byteUnits = map[string]float64{
+	"B":   1,
+	"KB":  1e3,
+	"MB":  1e6,
+	"GB":  1e9,
+	"TB":  1e12,
+	"PB":  1e15,
+	"KiB": 1 << 10,
+	"MiB": 1 << 20,
+	"GiB": 1 << 30,
+	"TiB": 1 << 40,
+	"PiB": 1 << 50,
 }
 
 // RootEntry storing directory information
@@ -75,6 +115,7 @@ type backupExecutor struct {
 	cmd *Command
 	//cmd               *ExecCommand
 	summaryResponse *BackupSummaryResponse
+	stats           *kdmpapi.TransferStats
 	execCmd         *exec.Cmd
 	outBuf          *bytes.Buffer
 	errBuf          *bytes.Buffer
@@ -164,6 +205,7 @@ func (b *backupExecutor) Run() error {
 			b.lastError = err
 			return
 		}
+		b.stats = getBackupStats(summaryResponse, b.errBuf.Bytes())
 		b.summaryResponse = summaryResponse
 	}()
 	return nil
@@ -186,6 +228,7 @@ func (b *backupExecutor) Status() (*cmdexec.Status, error) {
 			SnapshotID:          b.summaryResponse.ID,
 			Done:                true,
 			LastKnownError:      nil,
+			Stats:               b.stats,
 		}, nil
 	} // else backup is still in progress
 
@@ -247,3 +290,53 @@ func getBackupSummary(outBytes []byte, errBytes []byte) (*BackupSummaryResponse,
 
 	return summaryResponse, nil
 }
+
+// getBackupStats returns the statistics of a snapshot. The byte counters are
+// not part of the snapshot manifest, they are read from the last upload
+// progress line of kopia, rounded as printed.
+func getBackupStats(summary *BackupSummaryResponse, errBytes []byte) *kdmpapi.TransferStats {
+	stats := &kdmpapi.TransferStats{
+		FileCount:         summary.Stats.TotalFileCount,
+		DirCount:          summary.Stats.TotalDirectoryCount,
+		CachedFiles:       summary.Stats.CachedFiles,
+		HashedFiles:       summary.Stats.NonCachedFiles,
+		ErrorCount:        summary.Stats.ErrorCount,
+		IgnoredErrorCount: summary.Stats.IgnoredErrorCount,
+	}
+	if !summary.StartTime.IsZero() && !summary.EndTime.IsZero() {
+		startTime, endTime := metav1.NewTime(summary.StartTime), metav1.NewTime(summary.EndTime)
+		stats.StartTime, stats.EndTime = &startTime, &endTime
+	}
+	for _, entry := range summary.RootEntry.Summary.FailedEntries {
+		cmdexec.AddTransferError(stats, entry.EntryPath, entry.Error)
+	}
+
+	lines := bytes.FieldsFunc(errBytes, func(r rune) bool { return r == '\r' || r == '\n' })
+	for i := len(lines) - 1; i >= 0; i-- {
+		match := uploadProgressRegexp.FindSubmatch(lines[i])
+		if match == nil {
+			continue
+		}
+		stats.HashedBytes = parseBytes(string(match[1]))
+		stats.CachedBytes = parseBytes(string(match[2]))
+		stats.UploadedBytes = parseBytes(string(match[3]))
+		break
+	}
+	cmdexec.SetTransferRates(stats, uint64(summary.RootEntry.Summary.TotalFileSize))
+	return stats
+}
+
+// parseBytes parses a size printed by kopia, like "1.2 MB", 0 if invalid.
+func parseBytes(size string) uint64 {
+	size = strings.TrimSpace(size)
+	i := strings.IndexFunc(size, func(r rune) bool { return r != '.' && (r < '0' || r > '9') })
+	if i <= 0 {
+		return 0
+	}
+	value, err := strconv.ParseFloat(size[:i], 64)
+	unit, ok := byteUnits[strings.TrimSpace(size[i:])]
+	if err != nil || !ok {
+		return 0
+	}
+	return uint64(math.Round(value * unit))
+}
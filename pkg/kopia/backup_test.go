@@ -0,0 +1,55 @@
+package kopia
+
+import (
+	"encoding/json"
+	"testing"
+
+	kdmpapi "github.com/portworx/kdmp/pkg/apis/kdmp/v1alpha1"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetBackupStats(t *testing.T) {
+	manifest := `{"id":"k1a2b3","source":{"host":"kdmp","userName":"kdmp","path":"/data"},` +
+		`"startTime":"2024-01-01T10:00:00Z","endTime":"2024-01-01T10:00:10Z",` +
+		`"rootEntry":{"name":"data","type":"d","summ":{"size":20000000,"files":12,"dirs":3,"numFailed":0,` +
+		`"errors":[{"path":"db/lock","error":"permission denied"}]}},` +
+		`"stats":{"totalSize":20000000,"fileCount":12,"cachedFiles":8,"nonCachedFiles":4,"dirCount":3,"ignoredErrorCount":1}}`
+	summary := &BackupSummaryResponse{}
+	require.NoError(t, json.Unmarshal([]byte(manifest), summary))
+	progress := " - 0 hashing, 2 hashed (1.5 MB), 8 cached (18 MB), uploaded 400 KB, estimated 20 MB (100.0%) 0s left\r" +
+		" * 0 hashing, 4 hashed (2 MB), 8 cached (18 MB), uploaded 1 MiB, estimated 20 MB (100.0%) 0s left\n" +
+		"Created snapshot with root k1a2b3 and ID 0123 in 10s\n"
+
+	stats := getBackupStats(summary, []byte(progress))
+	require.Equal(t, int64(12), stats.FileCount)
+	require.Equal(t, int64(3), stats.DirCount)
+	require.Equal(t, int64(8), stats.CachedFiles)
+	require.Equal(t, int64(4), stats.HashedFiles)
+	require.Equal(t, int64(1), stats.IgnoredErrorCount)
+	require.Equal(t, uint64(2000000), stats.HashedBytes)
+	require.Equal(t, uint64(18000000), stats.CachedBytes)
+	require.Equal(t, uint64(1<<20), stats.UploadedBytes)
+	require.Equal(t, 19.07, stats.DeduplicationRatio)
+	require.Equal(t, uint64(2000000), stats.Throughput)
+	require.Equal(t, []kdmpapi.TransferError{{Path: "db/lock", Error: "permission denied"}}, stats.Errors)
+
+	// No progress line
+	stats = getBackupStats(summary, nil)
+	require.Zero(t, stats.UploadedBytes)
+	require.Zero(t, stats.DeduplicationRatio)
+}
+
+func TestParseBytes(t *testing.T) {
+	for size, expected := range map[string]uint64{
+		"0 B":     0,
+		"512 B":   512,
+		"1.2 MB":  1200000,
+		"3 GiB":   3 << 30,
+		"2.5KB":   2500,
+		"":        0,
+		"12 bits": 0,
+		"MB":      0,
+	} {
+		require.Equal(t, expected, parseBytes(size), size)
+	}
+}
@@ -1,8 +1,10 @@
 package kopia
 
 import (
+	"io"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
 
 	cmdexec "github.com/portworx/kdmp/pkg/executor"
@@ -51,6 +53,17 @@ type Command struct {
 	ExcludeFileList string
 	// Region for S3 object
 	Region string
+	// RetentionMode is the object-lock mode of the repository blobs on S3
+	RetentionMode string
+	// RetentionPeriod is the object-lock period of the repository blobs on S3
+	RetentionPeriod string
+	// ConfigDir is the directory of the config and cache of the repository
+	// connection, the default ones if empty. Several repositories can be
+	// connected at the same time with different directories.
+	ConfigDir string
+	// Stdin is the input of the command, snapshotted as a single file by a
+	// backup with the --stdin-file flag.
+	Stdin io.Reader
 }
 
 // Executor interface defines APIs for implementing a command wrapper
@@ -65,6 +78,20 @@ type Executor interface {
 	Status() (*cmdexec.Status, error)
 }
 
+func (c *Command) configFilePath() string {
+	if c.ConfigDir == "" {
+		return configFile
+	}
+	return filepath.Join(c.ConfigDir, filepath.Base(configFile))
+}
+
+func (c *Command) cacheDirPath() string {
+	if c.ConfigDir == "" {
+		return cacheDir
+	}
+	return filepath.Join(c.ConfigDir, "cache")
+}
+
 // AddArg adds an argument to the command
 func (c *Command) AddArg(arg string) *Command {
 	c.Args = append(c.Args, arg)
@@ -100,9 +127,9 @@ func (c *Command) CreateCmd() *exec.Cmd {
 			"--log-dir",
 			logDir,
 			"--cache-directory",
-			cacheDir,
+			c.cacheDirPath(),
 			"--config-file",
-			configFile,
+			c.configFilePath(),
 		}
 	case "s3":
 		argsSlice = []string{
@@ -116,11 +143,11 @@ func (c *Command) CreateCmd() *exec.Cmd {
 			"--prefix",
 			c.RepositoryName,
 			"--cache-directory",
-			cacheDir,
+			c.cacheDirPath(),
 			"--log-dir",
 			logDir,
 			"--config-file",
-			configFile,
+			c.configFilePath(),
 			"--region",
 			c.Region,
 		}
@@ -131,6 +158,14 @@ func (c *Command) CreateCmd() *exec.Cmd {
 			}
 			argsSlice = append(argsSlice, ssl...)
 		}
+		if c.RetentionMode != "" {
+			argsSlice = append(argsSlice,
+				"--retention-mode",
+				c.RetentionMode,
+				"--retention-period",
+				c.RetentionPeriod,
+			)
+		}
 	case "gcs":
 		argsSlice = []string{
 			"repository",
@@ -143,11 +178,11 @@ func (c *Command) CreateCmd() *exec.Cmd {
 			"--prefix",
 			c.RepositoryName,
 			"--cache-directory",
-			cacheDir,
+			c.cacheDirPath(),
 			"--log-dir",
 			logDir,
 			"--config-file",
-			configFile,
+			c.configFilePath(),
 		}
 	case "filesystem":
 		argsSlice = []string{
@@ -159,11 +194,11 @@ func (c *Command) CreateCmd() *exec.Cmd {
 			"--password",
 			c.Password,
 			"--cache-directory",
-			cacheDir,
+			c.cacheDirPath(),
 			"--log-dir",
 			logDir,
 			"--config-file",
-			configFile,
+			c.configFilePath(),
 		}
 	}
 
@@ -188,7 +223,7 @@ func (c *Command) BackupCmd() *exec.Cmd {
 		"--log-dir",
 		logDir,
 		"--config-file",
-		configFile,
+		c.configFilePath(),
 		"--json",
 	}
 	argsSlice = append(argsSlice, c.Flags...)
@@ -199,6 +234,7 @@ func (c *Command) BackupCmd() *exec.Cmd {
 		cmd.Env = append(os.Environ(), c.Env...)
 	}
 	cmd.Dir = c.Dir
+	cmd.Stdin = c.Stdin
 	logrus.Infof("the backup command is %+v", cmd)
 	return cmd
 }
@@ -217,11 +253,11 @@ func (c *Command) ConnectCmd() *exec.Cmd {
 			"--prefix",
 			c.RepositoryName,
 			"--cache-directory",
-			cacheDir,
+			c.cacheDirPath(),
 			"--log-dir",
 			logDir,
 			"--config-file",
-			configFile,
+			c.configFilePath(),
 		}
 	case "s3":
 		argsSlice = []string{
@@ -235,11 +271,11 @@ func (c *Command) ConnectCmd() *exec.Cmd {
 			"--prefix",
 			c.RepositoryName,
 			"--cache-directory",
-			cacheDir,
+			c.cacheDirPath(),
 			"--log-dir",
 			logDir,
 			"--config-file",
-			configFile,
+			c.configFilePath(),
 			"--region",
 			c.Region,
 		}
@@ -261,11 +297,11 @@ func (c *Command) ConnectCmd() *exec.Cmd {
 			"--prefix",
 			c.RepositoryName,
 			"--cache-directory",
-			cacheDir,
+			c.cacheDirPath(),
 			"--log-dir",
 			logDir,
 			"--config-file",
-			configFile,
+			c.configFilePath(),
 		}
 	case "filesystem":
 		argsSlice = []string{
@@ -277,11 +313,11 @@ func (c *Command) ConnectCmd() *exec.Cmd {
 			"--password",
 			c.Password,
 			"--cache-directory",
-			cacheDir,
+			c.cacheDirPath(),
 			"--log-dir",
 			logDir,
 			"--config-file",
-			configFile,
+			c.configFilePath(),
 		}
 	}
 
@@ -296,6 +332,45 @@ func (c *Command) ConnectCmd() *exec.Cmd {
 	return cmd
 }
 
+// SyncToCmd returns os/exec.Cmd object for the kopia repository sync-to Command.
+// The Path and RepositoryName are the ones of the destination repository, the
+// source is the connected repository.
+func (c *Command) SyncToCmd() *exec.Cmd {
+	argsSlice := []string{
+		"repository",
+		c.Name, // sync-to command
+		c.Provider,
+	}
+	switch c.Provider {
+	case "azure":
+		argsSlice = append(argsSlice, "--prefix", c.RepositoryName)
+	case "s3":
+		argsSlice = append(argsSlice, "--bucket", c.Path, "--prefix", c.RepositoryName, "--region", c.Region)
+		if c.DisableSsl {
+			argsSlice = append(argsSlice, "--disable-tls")
+		}
+	case "gcs":
+		argsSlice = append(argsSlice, "--bucket", c.Path, "--prefix", c.RepositoryName)
+	case "filesystem":
+		argsSlice = append(argsSlice, "--path", c.Path+c.RepositoryName)
+	}
+	argsSlice = append(argsSlice,
+		"--log-dir",
+		logDir,
+		"--config-file",
+		c.configFilePath(),
+	)
+	argsSlice = append(argsSlice, c.Flags...)
+	// Get the cmd args
+	argsSlice = append(argsSlice, c.Args...)
+	cmd := exec.Command(baseCmd, argsSlice...)
+	if len(c.Env) > 0 {
+		cmd.Env = append(os.Environ(), c.Env...)
+	}
+	cmd.Dir = c.Dir
+	return cmd
+}
+
 // RestoreCmd returns os/exec.Cmd object for the kopia restore Command
 func (c *Command) RestoreCmd() *exec.Cmd {
 	// Get all the flags
@@ -305,7 +380,7 @@ func (c *Command) RestoreCmd() *exec.Cmd {
 		"--log-dir",
 		logDir,
 		"--config-file",
-		configFile,
+		c.configFilePath(),
 	}
 	argsSlice = append(argsSlice, c.Flags...)
 	// Get the cmd args
@@ -327,7 +402,7 @@ func (c *Command) SetPolicyCmd() *exec.Cmd {
 		"--log-dir",
 		logDir,
 		"--config-file",
-		configFile,
+		c.configFilePath(),
 		"--global",
 	}
 	argsSlice = append(argsSlice, c.Flags...)
@@ -352,7 +427,7 @@ func (c *Command) DeleteCmd() *exec.Cmd {
 		"--log-dir",
 		logDir,
 		"--config-file",
-		configFile,
+		c.configFilePath(),
 		"--delete",
 	}
 	argsSlice = append(argsSlice, c.Flags...)
@@ -367,6 +442,75 @@ func (c *Command) DeleteCmd() *exec.Cmd {
 	return cmd
 }
 
+// VerifyCmd returns os/exec.Cmd object for the kopia snapshot verify Command
+func (c *Command) VerifyCmd() *exec.Cmd {
+	// Get all the flags
+	argsSlice := []string{
+		"snapshot",
+		c.Name, // verify command
+		"--log-dir",
+		logDir,
+		"--config-file",
+		c.configFilePath(),
+	}
+	argsSlice = append(argsSlice, c.Flags...)
+	// Get the cmd args
+	argsSlice = append(argsSlice, c.Args...)
+	argsSlice = append(argsSlice, c.SnapshotID)
+	cmd := exec.Command(baseCmd, argsSlice...)
+	if len(c.Env) > 0 {
+		cmd.Env = append(os.Environ(), c.Env...)
+	}
+	cmd.Dir = c.Dir
+
+	return cmd
+}
+
+// ListFilesCmd returns os/exec.Cmd object for the kopia ls Command
+func (c *Command) ListFilesCmd() *exec.Cmd {
+	// Get all the flags
+	argsSlice := []string{
+		c.Name, // ls command
+		"--log-dir",
+		logDir,
+		"--config-file",
+		c.configFilePath(),
+	}
+	argsSlice = append(argsSlice, c.Flags...)
+	// Get the cmd args
+	argsSlice = append(argsSlice, c.Args...)
+	argsSlice = append(argsSlice, c.SnapshotID)
+	cmd := exec.Command(baseCmd, argsSlice...)
+	if len(c.Env) > 0 {
+		cmd.Env = append(os.Environ(), c.Env...)
+	}
+	cmd.Dir = c.Dir
+
+	return cmd
+}
+
+// ShowCmd returns os/exec.Cmd object for the kopia show Command
+func (c *Command) ShowCmd() *exec.Cmd {
+	// Get all the flags
+	argsSlice := []string{
+		c.Name, // show command
+		"--log-dir",
+		logDir,
+		"--config-file",
+		c.configFilePath(),
+	}
+	argsSlice = append(argsSlice, c.Flags...)
+	// Get the cmd args
+	argsSlice = append(argsSlice, c.Args...)
+	cmd := exec.Command(baseCmd, argsSlice...)
+	if len(c.Env) > 0 {
+		cmd.Env = append(os.Environ(), c.Env...)
+	}
+	cmd.Dir = c.Dir
+
+	return cmd
+}
+
 // QuickMaintenanceRunCmd returns os/exec.Cmd object for the kopia quick maintenance run Command
 // For quick maintenance, we need not to give "--full" option.
 func (c *Command) QuickMaintenanceRunCmd() *exec.Cmd {
@@ -377,7 +521,7 @@ func (c *Command) QuickMaintenanceRunCmd() *exec.Cmd {
 		"--log-dir",
 		logDir,
 		"--config-file",
-		configFile,
+		c.configFilePath(),
 	}
 	argsSlice = append(argsSlice, c.Flags...)
 	// Get the cmd args
@@ -400,7 +544,7 @@ func (c *Command) MaintenanceRunCmd() *exec.Cmd {
 		"--log-dir",
 		logDir,
 		"--config-file",
-		configFile,
+		c.configFilePath(),
 		"--full",
 	}
 	argsSlice = append(argsSlice, c.Flags...)
@@ -425,7 +569,7 @@ func (c *Command) SnapshotListCmd() *exec.Cmd {
 		"--all",
 		"--json",
 		"--config-file",
-		configFile,
+		c.configFilePath(),
 	}
 	argsSlice = append(argsSlice, c.Flags...)
 	// Get the cmd args
@@ -451,7 +595,7 @@ func (c *Command) MaintenanceSetCmd() *exec.Cmd {
 		"--log-dir",
 		logDir,
 		"--config-file",
-		configFile,
+		c.configFilePath(),
 	}
 	argsSlice = append(argsSlice, c.Flags...)
 	// Get the cmd args
@@ -477,7 +621,7 @@ func (c *Command) CompressionCmd() *exec.Cmd {
 		"--log-dir",
 		logDir,
 		"--config-file",
-		configFile,
+		c.configFilePath(),
 	}
 	argsSlice = append(argsSlice, c.Flags...)
 	// Get the cmd args
@@ -501,7 +645,7 @@ func (c *Command) ExcludeFileListCmd() *exec.Cmd {
 		"--log-dir",
 		logDir,
 		"--config-file",
-		configFile,
+		c.configFilePath(),
 	}
 	commaSplit := strings.Split(c.ExcludeFileList, ",")
 	for _, file := range commaSplit {
@@ -519,3 +663,76 @@ func (c *Command) ExcludeFileListCmd() *exec.Cmd {
 	logrus.Infof("ExcludeFileListCmd: %+v", cmd)
 	return cmd
 }
+
+// RepositoryStatusCmd returns os/exec.Cmd object for the kopia repository status Command
+func (c *Command) RepositoryStatusCmd() *exec.Cmd {
+	// Get all the flags
+	argsSlice := []string{
+		c.Name, // repository command
+		"status",
+		"--json",
+		"--log-dir",
+		logDir,
+		"--config-file",
+		c.configFilePath(),
+	}
+	argsSlice = append(argsSlice, c.Flags...)
+	// Get the cmd args
+	argsSlice = append(argsSlice, c.Args...)
+	cmd := exec.Command(baseCmd, argsSlice...)
+	if len(c.Env) > 0 {
+		cmd.Env = append(os.Environ(), c.Env...)
+	}
+	cmd.Dir = c.Dir
+
+	return cmd
+}
+
+// BlobListCmd returns os/exec.Cmd object for the kopia blob list Command
+func (c *Command) BlobListCmd() *exec.Cmd {
+	// Get all the flags
+	argsSlice := []string{
+		c.Name, // blob command
+		"list",
+		"--json",
+		"--log-dir",
+		logDir,
+		"--config-file",
+		c.configFilePath(),
+	}
+	argsSlice = append(argsSlice, c.Flags...)
+	// Get the cmd args
+	argsSlice = append(argsSlice, c.Args...)
+	cmd := exec.Command(baseCmd, argsSlice...)
+	if len(c.Env) > 0 {
+		cmd.Env = append(os.Environ(), c.Env...)
+	}
+	cmd.Dir = c.Dir
+
+	return cmd
+}
+
+// ContentListCmd returns os/exec.Cmd object for the kopia content list Command
+func (c *Command) ContentListCmd() *exec.Cmd {
+	// Get all the flags
+	argsSlice := []string{
+		c.Name, // content command
+		"list",
+		"--json",
+		"--deleted",
+		"--log-dir",
+		logDir,
+		"--config-file",
+		c.configFilePath(),
+	}
+	argsSlice = append(argsSlice, c.Flags...)
+	// Get the cmd args
+	argsSlice = append(argsSlice, c.Args...)
+	cmd := exec.Command(baseCmd, argsSlice...)
+	if len(c.Env) > 0 {
+		cmd.Env = append(os.Environ(), c.Env...)
+	}
+	cmd.Dir = c.Dir
+
+	return cmd
+}
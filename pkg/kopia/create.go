@@ -34,19 +34,23 @@ type createExecutor struct {
 	lastError       error
 }
 
-// GetCreateCommand returns a wrapper over the kopia repo create command
-func GetCreateCommand(path, repoName, password, provider, region string, disableSsl bool) (*Command, error) {
+// GetCreateCommand returns a wrapper over the kopia repo create command. The
+// retention mode and period lock the repository blobs, they are only
+// supported on S3.
+func GetCreateCommand(path, repoName, password, provider, region string, disableSsl bool, retentionMode, retentionPeriod string) (*Command, error) {
 	if repoName == "" {
 		return nil, fmt.Errorf("repository name cannot be empty")
 	}
 	return &Command{
-		Name:           "create",
-		Provider:       provider,
-		RepositoryName: repoName,
-		Password:       password,
-		Path:           path,
-		DisableSsl:     disableSsl,
-		Region:         region,
+		Name:            "create",
+		Provider:        provider,
+		RepositoryName:  repoName,
+		Password:        password,
+		Path:            path,
+		DisableSsl:      disableSsl,
+		Region:          region,
+		RetentionMode:   retentionMode,
+		RetentionPeriod: retentionPeriod,
 	}, nil
 }
 
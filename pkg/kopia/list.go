@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"time"
 
 	cmdexec "github.com/portworx/kdmp/pkg/executor"
 	"github.com/sirupsen/logrus"
@@ -13,7 +14,10 @@ import (
 
 // ListSummaryResponse describes single snapshot list entry.
 type ListSummaryResponse struct {
-	ID string `json:"id"`
+	ID        string    `json:"id"`
+	StartTime time.Time `json:"startTime"`
+	EndTime   time.Time `json:"endTime"`
+	RootEntry RootEntry `json:"rootEntry"`
 }
 
 type listExecutor struct {
@@ -93,14 +97,22 @@ func (b *listExecutor) Status() (*cmdexec.Status, error) {
 		}, nil
 	}
 	var snapshotIds []string
+	startTimes := make(map[string]time.Time)
+	endTimes := make(map[string]time.Time)
+	rootObjectIDs := make(map[string]string)
 	for _, listSummaryResponse := range listSummaryResponses {
 		snapshotIds = append(snapshotIds, listSummaryResponse.ID)
-
+		startTimes[listSummaryResponse.ID] = listSummaryResponse.StartTime
+		endTimes[listSummaryResponse.ID] = listSummaryResponse.EndTime
+		rootObjectIDs[listSummaryResponse.ID] = listSummaryResponse.RootEntry.ObjectIdentifier
 	}
 
 	return &cmdexec.Status{
-		Done:           true,
-		SnapshotIDs:    snapshotIds,
-		LastKnownError: nil,
+		Done:               true,
+		SnapshotIDs:        snapshotIds,
+		SnapshotStartTimes: startTimes,
+		SnapshotEndTimes:   endTimes,
+		SnapshotRootIDs:    rootObjectIDs,
+		LastKnownError:     nil,
 	}, nil
 }
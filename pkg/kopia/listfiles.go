@@ -0,0 +1,211 @@
+package kopia
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"sort"
+	"strings"
+
+	cmdexec "github.com/portworx/kdmp/pkg/executor"
+	"github.com/sirupsen/logrus"
+)
+
+type listFilesExecutor struct {
+	cmd       *Command
+	execCmd   *exec.Cmd
+	outBuf    *bytes.Buffer
+	errBuf    *bytes.Buffer
+	lastError error
+	isRunning bool
+}
+
+// GetListFilesCommand returns a wrapper over the kopia ls command which
+// recursively lists the entries of dirPath in a snapshot.
+func GetListFilesCommand(snapshotID, dirPath string) (*Command, error) {
+	if snapshotID == "" {
+		return nil, fmt.Errorf("snapshot id cannot be empty")
+	}
+	objectPath := snapshotID
+	if dirPath = strings.Trim(dirPath, "/"); dirPath != "" {
+		objectPath = snapshotID + "/" + dirPath
+	}
+
+	return &Command{
+		Name:       "ls",
+		SnapshotID: objectPath,
+		Flags:      []string{"--recursive"},
+	}, nil
+}
+
+// NewListFilesExecutor returns an instance of Executor that can be used for
+// listing the entries of a snapshot. The directories have a trailing "/".
+func NewListFilesExecutor(cmd *Command) Executor {
+	return &listFilesExecutor{
+		cmd:    cmd,
+		outBuf: new(bytes.Buffer),
+		errBuf: new(bytes.Buffer),
+	}
+}
+
+func (l *listFilesExecutor) Run() error {
+	l.execCmd = l.cmd.ListFilesCmd()
+	l.execCmd.Stdout = l.outBuf
+	l.execCmd.Stderr = l.errBuf
+
+	if err := l.execCmd.Start(); err != nil {
+		l.lastError = err
+		return err
+	}
+	l.isRunning = true
+	go func() {
+		err := l.execCmd.Wait()
+		if err != nil {
+			l.lastError = fmt.Errorf("failed to run the kopia ls command: %v"+
+				" stdout: %v stderr: %v", err, l.outBuf.String(), l.errBuf.String())
+			logrus.Errorf("%v", l.lastError)
+		}
+		l.isRunning = false
+	}()
+	return nil
+}
+
+func (l *listFilesExecutor) Status() (*cmdexec.Status, error) {
+	if l.lastError != nil {
+		fmt.Fprintln(os.Stderr, l.errBuf.String())
+		return &cmdexec.Status{
+			LastKnownError: l.lastError,
+			Done:           true,
+		}, nil
+	}
+	if l.isRunning {
+		return &cmdexec.Status{
+			Done: false,
+		}, nil
+	}
+
+	return &cmdexec.Status{
+		Entries: parseListFilesOutput(l.outBuf.Bytes()),
+		Done:    true,
+	}, nil
+}
+
+func parseListFilesOutput(out []byte) []string {
+	var entries []string
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		entries = append(entries, line)
+	}
+	return entries
+}
+
+// MatchEntries returns the entries of a snapshot listing matching any of the
+// patterns. A "*" matches within a path element as in path.Match, a "**"
+// element matches any number of path elements, and a pattern without "/"
+// matches the entries of that name in any directory. A directory matches with
+// all of its content.
+//
+// An entry is skipped if one of its parent directories is selected, and a
+// directory whose entries are all selected is selected instead of them, so
+// that the restore of a subtree is done at once.
+func MatchEntries(entries []string, patterns []string) ([]string, error) {
+	var patternElems [][]string
+	for _, pattern := range patterns {
+		pattern = strings.Trim(pattern, "/")
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %v", pattern, err)
+		}
+		if !strings.Contains(pattern, "/") {
+			pattern = "**/" + pattern
+		}
+		patternElems = append(patternElems, strings.Split(pattern, "/"))
+	}
+
+	selected := make(map[string]bool)
+	children := make(map[string][]string)
+	var dirs []string
+	for _, entry := range entries {
+		name := strings.TrimSuffix(entry, "/")
+		if name == "" {
+			continue
+		}
+		children[path.Dir(name)] = append(children[path.Dir(name)], name)
+		if strings.HasSuffix(entry, "/") {
+			dirs = append(dirs, name)
+		}
+		for _, elems := range patternElems {
+			if matchElems(elems, strings.Split(name, "/")) {
+				selected[name] = true
+				break
+			}
+		}
+	}
+
+	// The deepest directories first, so that a selection is carried up to
+	// the top most directory whose entries are all selected.
+	sort.Slice(dirs, func(i, j int) bool {
+		return strings.Count(dirs[i], "/") > strings.Count(dirs[j], "/")
+	})
+	for _, dir := range dirs {
+		if selected[dir] || len(children[dir]) == 0 {
+			continue
+		}
+		all := true
+		for _, child := range children[dir] {
+			if !selected[child] {
+				all = false
+				break
+			}
+		}
+		selected[dir] = all
+	}
+
+	var result []string
+	for name := range selected {
+		if selected[name] && !hasSelectedParent(name, selected) {
+			result = append(result, name)
+		}
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
+// matchElems matches the path elements of a name against the elements of a
+// pattern, "**" matching any number of elements.
+func matchElems(pattern, name []string) bool {
+	if len(pattern) == 0 {
+		return len(name) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(name); i++ {
+			if matchElems(pattern[1:], name[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(name) == 0 {
+		return false
+	}
+	// Match error is already checked for all the patterns.
+	if ok, _ := path.Match(pattern[0], name[0]); !ok {
+		return false
+	}
+	return matchElems(pattern[1:], name[1:])
+}
+
+func hasSelectedParent(name string, selected map[string]bool) bool {
+	for dir := path.Dir(name); dir != "." && dir != "/"; dir = path.Dir(dir) {
+		if selected[dir] {
+			return true
+		}
+	}
+	return false
+}
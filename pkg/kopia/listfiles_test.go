@@ -0,0 +1,100 @@
+package kopia
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseListFilesOutput(t *testing.T) {
+	out := []byte("etc/\netc/app.conf\n\nvar/\nvar/log/\nvar/log/app.log\n")
+	require.Equal(t, []string{"etc/", "etc/app.conf", "var/", "var/log/", "var/log/app.log"}, parseListFilesOutput(out))
+	require.Empty(t, parseListFilesOutput(nil))
+}
+
+func TestMatchEntries(t *testing.T) {
+	entries := []string{
+		"etc/",
+		"etc/app.conf",
+		"etc/db.conf",
+		"etc/certs/",
+		"etc/certs/ca.crt",
+		"var/",
+		"var/log/",
+		"var/log/app.log",
+		"etc-old/",
+		"etc-old/app.conf",
+	}
+
+	testCases := []struct {
+		name        string
+		patterns    []string
+		expected    []string
+		expectedErr bool
+	}{
+		{
+			name:     "single_file",
+			patterns: []string{"etc/app.conf"},
+			expected: []string{"etc/app.conf"},
+		},
+		{
+			name:     "leading_slash",
+			patterns: []string{"/etc/db.conf"},
+			expected: []string{"etc/db.conf"},
+		},
+		{
+			name:     "glob",
+			patterns: []string{"etc/*.conf"},
+			expected: []string{"etc/app.conf", "etc/db.conf"},
+		},
+		{
+			name:     "directory_covers_content",
+			patterns: []string{"etc/certs/", "etc/certs/*"},
+			expected: []string{"etc/certs"},
+		},
+		{
+			name:     "multiple_patterns",
+			patterns: []string{"var/log/app.log", "etc/app.conf"},
+			expected: []string{"etc/app.conf", "var"},
+		},
+		{
+			name:     "whole_directory_content",
+			patterns: []string{"etc/*"},
+			expected: []string{"etc"},
+		},
+		{
+			name:     "any_depth",
+			patterns: []string{"**/*.crt"},
+			expected: []string{"etc/certs"},
+		},
+		{
+			name:     "name_in_any_directory",
+			patterns: []string{"*.conf", "app.log"},
+			expected: []string{"etc-old", "etc/app.conf", "etc/db.conf", "var"},
+		},
+		{
+			name:     "unsorted_and_overlapping",
+			patterns: []string{"etc-old/*", "etc/certs/ca.crt", "etc", "etc-old/app.conf"},
+			expected: []string{"etc", "etc-old"},
+		},
+		{
+			name:     "no_match",
+			patterns: []string{"opt/*"},
+		},
+		{
+			name:        "invalid_pattern",
+			patterns:    []string{"etc/[a"},
+			expectedErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		matched, err := MatchEntries(entries, tc.patterns)
+		if tc.expectedErr {
+			require.Errorf(t, err, "TC: %s", tc.name)
+			continue
+		}
+		require.NoErrorf(t, err, "TC: %s", tc.name)
+		require.Equalf(t, tc.expected, matched, "TC: %s", tc.name)
+	}
+}
@@ -0,0 +1,264 @@
+package kopia
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	kdmpapi "github.com/portworx/kdmp/pkg/apis/kdmp/v1alpha1"
+	cmdexec "github.com/portworx/kdmp/pkg/executor"
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// orphanedBlobMinAge is the age under which a pack blob with no content is
+// not reported as orphaned, as it may be written by a running backup.
+const orphanedBlobMinAge = time.Hour
+
+// packBlobPrefixes are the prefixes of the blobs holding the contents, the
+// other blobs hold the indexes and the repository metadata.
+var packBlobPrefixes = []string{"p", "q"}
+
+type repositoryStatusResponse struct {
+	ContentFormat struct {
+		Version int `json:"version"`
+	} `json:"contentFormat"`
+}
+
+type snapshotListEntry struct {
+	StartTime time.Time `json:"startTime"`
+	Stats     struct {
+		TotalSize uint64 `json:"totalSize"`
+	} `json:"stats"`
+}
+
+type contentListEntry struct {
+	PackBlobID string `json:"packFile"`
+}
+
+type blobListEntry struct {
+	ID        string    `json:"id"`
+	Length    uint64    `json:"length"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+type repositoryStatusExecutor struct {
+	cmd       *Command
+	errBuf    *bytes.Buffer
+	usage     *kdmpapi.RepositoryUsage
+	lastError error
+	isRunning bool
+}
+
+// GetRepositoryStatusCommand returns a wrapper over the kopia commands
+// reporting the usage of a connected repository.
+func GetRepositoryStatusCommand() (*Command, error) {
+	return &Command{
+		Name: "repository",
+	}, nil
+}
+
+// NewRepositoryStatusExecutor returns an instance of Executor that can be used
+// for collecting the usage of a repository. The snapshot, content and blob
+// lists are parsed as they are read, as they can be large.
+func NewRepositoryStatusExecutor(cmd *Command) Executor {
+	return &repositoryStatusExecutor{
+		cmd:    cmd,
+		errBuf: new(bytes.Buffer),
+	}
+}
+
+func (r *repositoryStatusExecutor) Run() error {
+	r.isRunning = true
+	go func() {
+		usage, err := r.getUsage()
+		if err != nil {
+			r.lastError = err
+			logrus.Errorf("%v", r.lastError)
+		}
+		r.usage = usage
+		r.isRunning = false
+	}()
+	return nil
+}
+
+func (r *repositoryStatusExecutor) Status() (*cmdexec.Status, error) {
+	if r.lastError != nil {
+		fmt.Fprintln(os.Stderr, r.errBuf.String())
+		return &cmdexec.Status{
+			LastKnownError: r.lastError,
+			Done:           true,
+		}, nil
+	}
+	if r.isRunning {
+		return &cmdexec.Status{
+			Done: false,
+		}, nil
+	}
+
+	return &cmdexec.Status{
+		RepositoryUsage: r.usage,
+		Done:            true,
+	}, nil
+}
+
+func (r *repositoryStatusExecutor) getUsage() (*kdmpapi.RepositoryUsage, error) {
+	usage := &kdmpapi.RepositoryUsage{}
+	repositoryCmd, snapshotCmd, contentCmd, blobCmd := *r.cmd, *r.cmd, *r.cmd, *r.cmd
+	snapshotCmd.Name, contentCmd.Name, blobCmd.Name = "snapshot", "content", "blob"
+
+	if err := r.runCmd(repositoryCmd.RepositoryStatusCmd(), func(out io.Reader) (err error) {
+		usage.FormatVersion, err = parseRepositoryFormatVersion(out)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	if err := r.runCmd(snapshotCmd.SnapshotListCmd(), func(out io.Reader) error {
+		return parseSnapshotList(out, usage)
+	}); err != nil {
+		return nil, err
+	}
+	var packs map[string]bool
+	if err := r.runCmd(contentCmd.ContentListCmd(), func(out io.Reader) (err error) {
+		packs, err = parseContentList(out, usage)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	if err := r.runCmd(blobCmd.BlobListCmd(), func(out io.Reader) error {
+		return parseBlobList(out, usage, packs, time.Now())
+	}); err != nil {
+		return nil, err
+	}
+	return usage, nil
+}
+
+// runCmd runs a command and parses its output while it is running.
+func (r *repositoryStatusExecutor) runCmd(execCmd *exec.Cmd, parse func(io.Reader) error) error {
+	r.errBuf.Reset()
+	execCmd.Stderr = r.errBuf
+	out, err := execCmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := execCmd.Start(); err != nil {
+		return err
+	}
+	parseErr := parse(out)
+	if parseErr != nil {
+		// Stop the command as the rest of its output isn't read.
+		_ = execCmd.Process.Kill()
+	}
+	if err := execCmd.Wait(); err != nil && parseErr == nil {
+		return fmt.Errorf("failed to run the kopia %v command: %v stderr: %v",
+			strings.Join(execCmd.Args[1:3], " "), err, r.errBuf.String())
+	}
+	if parseErr != nil {
+		return fmt.Errorf("failed to parse the output of the kopia %v command: %v",
+			strings.Join(execCmd.Args[1:3], " "), parseErr)
+	}
+	return nil
+}
+
+func parseRepositoryFormatVersion(out io.Reader) (int, error) {
+	var response repositoryStatusResponse
+	if err := json.NewDecoder(out).Decode(&response); err != nil {
+		return 0, err
+	}
+	return response.ContentFormat.Version, nil
+}
+
+func parseSnapshotList(out io.Reader, usage *kdmpapi.RepositoryUsage) error {
+	return decodeJSONArray(out, func(dec *json.Decoder) error {
+		var entry snapshotListEntry
+		if err := dec.Decode(&entry); err != nil {
+			return err
+		}
+		usage.SnapshotCount++
+		usage.LogicalBytes += entry.Stats.TotalSize
+		startTime := metav1.NewTime(entry.StartTime)
+		if usage.OldestSnapshotTime == nil || entry.StartTime.Before(usage.OldestSnapshotTime.Time) {
+			usage.OldestSnapshotTime = &startTime
+		}
+		if usage.NewestSnapshotTime == nil || entry.StartTime.After(usage.NewestSnapshotTime.Time) {
+			usage.NewestSnapshotTime = &startTime
+		}
+		return nil
+	})
+}
+
+// parseContentList counts the contents and returns the pack blobs holding
+// them.
+func parseContentList(out io.Reader, usage *kdmpapi.RepositoryUsage) (map[string]bool, error) {
+	packs := make(map[string]bool)
+	err := decodeJSONArray(out, func(dec *json.Decoder) error {
+		var entry contentListEntry
+		if err := dec.Decode(&entry); err != nil {
+			return err
+		}
+		usage.ContentCount++
+		if entry.PackBlobID != "" {
+			packs[entry.PackBlobID] = true
+		}
+		return nil
+	})
+	return packs, err
+}
+
+// parseBlobList counts the blobs and the pack blobs not holding any of the
+// contents.
+func parseBlobList(out io.Reader, usage *kdmpapi.RepositoryUsage, packs map[string]bool, now time.Time) error {
+	return decodeJSONArray(out, func(dec *json.Decoder) error {
+		var entry blobListEntry
+		if err := dec.Decode(&entry); err != nil {
+			return err
+		}
+		usage.BlobCount++
+		usage.PhysicalBytes += entry.Length
+		if isPackBlob(entry.ID) && !packs[entry.ID] && now.Sub(entry.Timestamp) >= orphanedBlobMinAge {
+			usage.OrphanedBlobCount++
+			usage.OrphanedBlobBytes += entry.Length
+		}
+		return nil
+	})
+}
+
+func isPackBlob(id string) bool {
+	for _, prefix := range packBlobPrefixes {
+		if strings.HasPrefix(id, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// decodeJSONArray calls decode for each element of a JSON array. An empty
+// output is an empty array.
+func decodeJSONArray(out io.Reader, decode func(*json.Decoder) error) error {
+	dec := json.NewDecoder(out)
+	token, err := dec.Token()
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if token == nil {
+		return nil
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '[' {
+		return fmt.Errorf("expected a JSON array, found %v", token)
+	}
+	for dec.More() {
+		if err := decode(dec); err != nil {
+			return err
+		}
+	}
+	_, err = dec.Token()
+	return err
+}
@@ -0,0 +1,59 @@
+package kopia
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	kdmpapi "github.com/portworx/kdmp/pkg/apis/kdmp/v1alpha1"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepositoryUsage(t *testing.T) {
+	version, err := parseRepositoryFormatVersion(strings.NewReader(
+		`{"configFile":"/tmp/kopiaconfig","contentFormat":{"hash":"BLAKE2B-256-128","version":2}}`))
+	require.NoError(t, err)
+	require.Equal(t, 2, version)
+
+	usage := &kdmpapi.RepositoryUsage{}
+	require.NoError(t, parseSnapshotList(strings.NewReader(`[
+		{"id":"a","startTime":"2024-01-02T00:00:00Z","stats":{"totalSize":300}},
+		{"id":"b","startTime":"2024-01-01T00:00:00Z","stats":{"totalSize":200}},
+		{"id":"c","startTime":"2024-01-03T00:00:00Z","stats":{"totalSize":100}}
+	]`), usage))
+	require.Equal(t, int64(3), usage.SnapshotCount)
+	require.Equal(t, uint64(600), usage.LogicalBytes)
+	require.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), usage.OldestSnapshotTime.UTC())
+	require.Equal(t, time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC), usage.NewestSnapshotTime.UTC())
+
+	packs, err := parseContentList(strings.NewReader(`[
+		{"contentID":"1","packFile":"p1"},
+		{"contentID":"2","packFile":"p1"},
+		{"contentID":"3","packFile":"q1","deleted":true}
+	]`), usage)
+	require.NoError(t, err)
+	require.Equal(t, int64(3), usage.ContentCount)
+	require.Equal(t, map[string]bool{"p1": true, "q1": true}, packs)
+
+	now := time.Date(2024, 1, 3, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, parseBlobList(strings.NewReader(`[
+		{"id":"p1","length":1000,"timestamp":"2024-01-01T00:00:00Z"},
+		{"id":"q1","length":100,"timestamp":"2024-01-01T00:00:00Z"},
+		{"id":"p2","length":500,"timestamp":"2024-01-02T00:00:00Z"},
+		{"id":"p3","length":700,"timestamp":"2024-01-03T11:30:00Z"},
+		{"id":"xn0_1","length":50,"timestamp":"2024-01-01T00:00:00Z"},
+		{"id":"kopia.repository","length":10,"timestamp":"2024-01-01T00:00:00Z"}
+	]`), usage, packs, now))
+	require.Equal(t, int64(6), usage.BlobCount)
+	require.Equal(t, uint64(2360), usage.PhysicalBytes)
+	// p3 is too recent to be orphaned
+	require.Equal(t, int64(1), usage.OrphanedBlobCount)
+	require.Equal(t, uint64(500), usage.OrphanedBlobBytes)
+
+	// An empty repository
+	empty := &kdmpapi.RepositoryUsage{}
+	require.NoError(t, parseSnapshotList(strings.NewReader(""), empty))
+	require.NoError(t, parseSnapshotList(strings.NewReader("[]\n"), empty))
+	require.Nil(t, empty.OldestSnapshotTime)
+	require.Error(t, parseSnapshotList(strings.NewReader(`{"id":"a"}`), empty))
+}
@@ -7,6 +7,7 @@ import (
 	"log"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strconv"
 	"strings"
 
@@ -65,6 +66,24 @@ func GetRestoreCommand(path, repoName, password, provider, targetPath, snapshotI
 	}, nil
 }
 
+// GetRestoreFileCommand returns a wrapper over the kopia restore command
+// which restores a single file of a snapshot into targetFile.
+func GetRestoreFileCommand(path, repoName, password, provider, snapshotID, filePath, targetFile string) (*Command, error) {
+	if filePath == "" {
+		return nil, fmt.Errorf("file path cannot be empty")
+	}
+	if targetFile == "" {
+		return nil, fmt.Errorf("target file cannot be empty")
+	}
+	cmd, err := GetRestoreCommand(path, repoName, password, provider, filepath.Dir(targetFile), snapshotID)
+	if err != nil {
+		return nil, err
+	}
+	cmd.Args = []string{snapshotID + "/" + strings.TrimPrefix(filePath, "/"), targetFile}
+
+	return cmd, nil
+}
+
 // RestoreProgressResponse is the json representation of the in-progress
 // output of kopia restore
 //
@@ -0,0 +1,92 @@
+package kopia
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+
+	cmdexec "github.com/portworx/kdmp/pkg/executor"
+	"github.com/sirupsen/logrus"
+)
+
+type showExecutor struct {
+	cmd       *Command
+	execCmd   *exec.Cmd
+	out       io.Writer
+	errBuf    *bytes.Buffer
+	lastError error
+	isRunning bool
+}
+
+// GetShowCommand returns a wrapper over the kopia show command, which writes
+// the content of an object, given as <object-id>[/<path>], to its output.
+func GetShowCommand(objectPath string) (*Command, error) {
+	if objectPath == "" {
+		return nil, fmt.Errorf("object path cannot be empty")
+	}
+
+	return &Command{
+		Name: "show",
+		Args: []string{objectPath},
+	}, nil
+}
+
+// NewShowExecutor returns an instance of Executor that can be used for
+// running a kopia show command, the object content is written to out.
+func NewShowExecutor(cmd *Command, out io.Writer) Executor {
+	return &showExecutor{
+		cmd:    cmd,
+		out:    out,
+		errBuf: new(bytes.Buffer),
+	}
+}
+
+func (b *showExecutor) Run() error {
+	b.execCmd = b.cmd.ShowCmd()
+	b.execCmd.Stdout = b.out
+	b.execCmd.Stderr = b.errBuf
+
+	if err := b.execCmd.Start(); err != nil {
+		b.lastError = err
+		return err
+	}
+
+	b.isRunning = true
+
+	go func() {
+		err := b.execCmd.Wait()
+
+		if err != nil {
+			b.lastError = fmt.Errorf("failed to run the kopia show command: %v"+
+				" stderr: %v", err, b.errBuf.String())
+			logrus.Errorf("%v", b.lastError)
+			return
+		}
+		b.isRunning = false
+	}()
+
+	return nil
+}
+
+func (b *showExecutor) Status() (*cmdexec.Status, error) {
+	if b.lastError != nil {
+		fmt.Fprintln(os.Stderr, b.errBuf.String())
+		return &cmdexec.Status{
+			LastKnownError: b.lastError,
+			Done:           true,
+		}, nil
+	}
+	if b.isRunning {
+		return &cmdexec.Status{
+			Done:           false,
+			LastKnownError: nil,
+		}, nil
+	}
+
+	return &cmdexec.Status{
+		Done:           true,
+		LastKnownError: nil,
+	}, nil
+}
@@ -0,0 +1,90 @@
+package kopia
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+
+	cmdexec "github.com/portworx/kdmp/pkg/executor"
+	"github.com/sirupsen/logrus"
+)
+
+type syncToExecutor struct {
+	cmd       *Command
+	execCmd   *exec.Cmd
+	outBuf    *bytes.Buffer
+	errBuf    *bytes.Buffer
+	lastError error
+	isRunning bool
+}
+
+// GetSyncToCommand returns a wrapper over the kopia repository sync-to command.
+// The blobs of the connected repository are copied to the destination
+// repository, the blobs already present in the destination are skipped and
+// the ones only present in the destination are kept.
+func GetSyncToCommand(path, repoName, provider, region string, disableSsl bool) (*Command, error) {
+	if repoName == "" {
+		return nil, fmt.Errorf("repository name cannot be empty")
+	}
+	return &Command{
+		Name:           "sync-to",
+		Provider:       provider,
+		RepositoryName: repoName,
+		Path:           path,
+		DisableSsl:     disableSsl,
+		Region:         region,
+	}, nil
+}
+
+// NewSyncToExecutor returns an instance of Executor that can be used for
+// running a kopia repository sync-to command
+func NewSyncToExecutor(cmd *Command) Executor {
+	return &syncToExecutor{
+		cmd:    cmd,
+		outBuf: new(bytes.Buffer),
+		errBuf: new(bytes.Buffer),
+	}
+}
+
+func (s *syncToExecutor) Run() error {
+	s.execCmd = s.cmd.SyncToCmd()
+	s.execCmd.Stdout = s.outBuf
+	s.execCmd.Stderr = s.errBuf
+
+	if err := s.execCmd.Start(); err != nil {
+		s.lastError = err
+		return err
+	}
+	s.isRunning = true
+	go func() {
+		err := s.execCmd.Wait()
+		if err != nil {
+			s.lastError = fmt.Errorf("failed to run the repository sync-to command: %v "+
+				" stdout: %v stderr: %v", err, s.outBuf.String(), s.errBuf.String())
+			logrus.Errorf("%v", s.lastError)
+		}
+		s.isRunning = false
+	}()
+	return nil
+}
+
+func (s *syncToExecutor) Status() (*cmdexec.Status, error) {
+	if s.lastError != nil {
+		fmt.Fprintln(os.Stderr, s.errBuf.String())
+		return &cmdexec.Status{
+			LastKnownError: s.lastError,
+			Done:           true,
+		}, nil
+	}
+	if s.isRunning {
+		return &cmdexec.Status{
+			Done:           false,
+			LastKnownError: nil,
+		}, nil
+	}
+	return &cmdexec.Status{
+		Done:           true,
+		LastKnownError: nil,
+	}, nil
+}
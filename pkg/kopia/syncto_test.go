@@ -0,0 +1,30 @@
+package kopia
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSyncToCmd(t *testing.T) {
+	_, err := GetSyncToCommand("bucket", "", "s3", "", false)
+	require.Error(t, err)
+
+	cmd, err := GetSyncToCommand("bucket", "generic-backup/ns-pvc/", "s3", "us-east-1", true)
+	require.NoError(t, err)
+	cmd.AddArg("--endpoint").AddArg("s3.example.com")
+	require.Equal(t, []string{
+		"kopia", "repository", "sync-to", "s3",
+		"--bucket", "bucket", "--prefix", "generic-backup/ns-pvc/", "--region", "us-east-1", "--disable-tls",
+		"--log-dir", logDir, "--config-file", configFile,
+		"--endpoint", "s3.example.com",
+	}, cmd.SyncToCmd().Args)
+
+	cmd, err = GetSyncToCommand("/mnt/nfs-target/share/", "generic-backup/ns-pvc/", "filesystem", "", false)
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"kopia", "repository", "sync-to", "filesystem",
+		"--path", "/mnt/nfs-target/share/generic-backup/ns-pvc/",
+		"--log-dir", logDir, "--config-file", configFile,
+	}, cmd.SyncToCmd().Args)
+}
@@ -0,0 +1,94 @@
+package kopia
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+
+	cmdexec "github.com/portworx/kdmp/pkg/executor"
+	"github.com/sirupsen/logrus"
+)
+
+type verifyExecutor struct {
+	cmd       *Command
+	execCmd   *exec.Cmd
+	outBuf    *bytes.Buffer
+	errBuf    *bytes.Buffer
+	lastError error
+	isRunning bool
+}
+
+// GetVerifyCommand returns a wrapper over the kopia snapshot verify command.
+// The content of verifyFilesPercent percent of the files is read back along
+// with the verification of the snapshot metadata.
+func GetVerifyCommand(snapshotID string, verifyFilesPercent int) (*Command, error) {
+	if snapshotID == "" {
+		return nil, fmt.Errorf("snapshot id cannot be empty")
+	}
+	if verifyFilesPercent < 0 || verifyFilesPercent > 100 {
+		return nil, fmt.Errorf("verify files percent should be between 0 and 100")
+	}
+
+	return &Command{
+		Name:       "verify",
+		SnapshotID: snapshotID,
+		Flags: []string{
+			"--verify-files-percent",
+			strconv.Itoa(verifyFilesPercent),
+		},
+	}, nil
+}
+
+// NewVerifyExecutor returns an instance of Executor that can be used for
+// running a kopia snapshot verify command
+func NewVerifyExecutor(cmd *Command) Executor {
+	return &verifyExecutor{
+		cmd:    cmd,
+		outBuf: new(bytes.Buffer),
+		errBuf: new(bytes.Buffer),
+	}
+}
+
+func (v *verifyExecutor) Run() error {
+	v.execCmd = v.cmd.VerifyCmd()
+	v.execCmd.Stdout = v.outBuf
+	v.execCmd.Stderr = v.errBuf
+
+	if err := v.execCmd.Start(); err != nil {
+		v.lastError = err
+		return err
+	}
+	v.isRunning = true
+	go func() {
+		err := v.execCmd.Wait()
+		if err != nil {
+			v.lastError = fmt.Errorf("failed to run the snapshot verify command: %v "+
+				" stdout: %v stderr: %v", err, v.outBuf.String(), v.errBuf.String())
+			logrus.Errorf("%v", v.lastError)
+		}
+		v.isRunning = false
+	}()
+	return nil
+}
+
+func (v *verifyExecutor) Status() (*cmdexec.Status, error) {
+	if v.lastError != nil {
+		fmt.Fprintln(os.Stderr, v.errBuf.String())
+		return &cmdexec.Status{
+			LastKnownError: v.lastError,
+			Done:           true,
+		}, nil
+	}
+	if v.isRunning {
+		return &cmdexec.Status{
+			Done:           false,
+			LastKnownError: nil,
+		}, nil
+	}
+	return &cmdexec.Status{
+		Done:           true,
+		LastKnownError: nil,
+	}, nil
+}
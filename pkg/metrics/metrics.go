@@ -0,0 +1,119 @@
+// Package metrics defines the kdmp prometheus metrics. They are registered to
+// the controller-runtime registry and are served by the manager metrics server.
+package metrics
+
+import (
+	"time"
+
+	kdmpapi "github.com/portworx/kdmp/pkg/apis/kdmp/v1alpha1"
+	"github.com/prometheus/client_golang/prometheus"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	namespace = "kdmp"
+
+	driverLabel = "driver"
+	stageLabel  = "stage"
+	statusLabel = "status"
+	reasonLabel = "reason"
+
+	// UnknownDriver is the driver label of the DataExports with an invalid
+	// source or destination.
+	UnknownDriver = "unknown"
+
+	// FailureReasonJobStartFailed - the data transfer job could not be created
+	FailureReasonJobStartFailed = "JobStartFailed"
+	// FailureReasonJobFailed - the data transfer job failed
+	FailureReasonJobFailed = "JobFailed"
+	// FailureReasonCleanupFailed - the resources of the DataExport could not be removed
+	FailureReasonCleanupFailed = "CleanupFailed"
+	// FailureReasonOther - any other failure
+	FailureReasonOther = "Other"
+)
+
+var (
+	stageTransitions = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "dataexport",
+			Name:      "stage_transitions_total",
+			Help:      "Number of DataExport stage and status changes.",
+		},
+		[]string{driverLabel, stageLabel, statusLabel},
+	)
+	failures = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "dataexport",
+			Name:      "failures_total",
+			Help:      "Number of failed DataExports.",
+		},
+		[]string{driverLabel, stageLabel, reasonLabel},
+	)
+	transferDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "dataexport",
+			Name:      "transfer_duration_seconds",
+			Help:      "Duration of the successful data transfer jobs.",
+			// 30s up to about 17h
+			Buckets: prometheus.ExponentialBuckets(30, 2, 12),
+		},
+		[]string{driverLabel},
+	)
+	transferredBytes = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "dataexport",
+			Name:      "transferred_bytes_total",
+			Help:      "Number of bytes transferred by the successful data transfer jobs.",
+		},
+		[]string{driverLabel},
+	)
+	runningJobs = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "jobs_running",
+			Help:      "Number of kdmp jobs which are not completed.",
+		},
+		[]string{driverLabel},
+	)
+	maintenanceLastSuccessAge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "maintenance_last_success_age_seconds",
+			Help:      "Time since the last successful maintenance of a repository.",
+		},
+		[]string{"backuplocation_namespace", "backuplocation", "repository", "type"},
+	)
+)
+
+func init() {
+	ctrlmetrics.Registry.MustRegister(
+		stageTransitions,
+		failures,
+		transferDuration,
+		transferredBytes,
+		runningJobs,
+		maintenanceLastSuccessAge,
+	)
+}
+
+// ObserveStageTransition records a change of the stage or the status of a
+// DataExport.
+func ObserveStageTransition(driver string, stage kdmpapi.DataExportStage, status kdmpapi.DataExportStatus) {
+	stageTransitions.WithLabelValues(driver, string(stage), string(status)).Inc()
+}
+
+// ObserveFailure records a failed DataExport. The reason has to be one of a
+// fixed set of values, the failure messages are in the DataExport status.
+func ObserveFailure(driver string, stage kdmpapi.DataExportStage, reason string) {
+	failures.WithLabelValues(driver, string(stage), reason).Inc()
+}
+
+// ObserveTransfer records a successful data transfer job.
+func ObserveTransfer(driver string, duration time.Duration, bytes uint64) {
+	transferDuration.WithLabelValues(driver).Observe(duration.Seconds())
+	transferredBytes.WithLabelValues(driver).Add(float64(bytes))
+}
@@ -0,0 +1,135 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	kdmpapi "github.com/portworx/kdmp/pkg/apis/kdmp/v1alpha1"
+	"github.com/portworx/kdmp/pkg/drivers"
+	"github.com/portworx/kdmp/pkg/jobratelimit"
+	kdmpSchedOps "github.com/portworx/sched-ops/k8s/kdmp"
+	"github.com/sirupsen/logrus"
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	defaultRefreshInterval = 30 * time.Second
+
+	fullMaintenance  = "full"
+	quickMaintenance = "quick"
+)
+
+// Refresher periodically updates the gauges which are computed from the
+// cluster state. It implements the controller-runtime Runnable interface so
+// that it runs on the leader only.
+type Refresher struct {
+	interval time.Duration
+	// reader is the informer cache of the manager, the jobs are read from it
+	// instead of being listed from the API server at every refresh.
+	reader client.Reader
+	// lastSuccess keeps the last successful maintenance time of the
+	// repositories, as a failed run overwrites it in the CR.
+	lastSuccess map[maintenanceKey]time.Time
+}
+
+type maintenanceKey struct {
+	namespace       string
+	backupLocation  string
+	repository      string
+	maintenanceType string
+}
+
+// NewRefresher returns a Refresher of the cluster state gauges, reading the
+// jobs from the reader.
+func NewRefresher(reader client.Reader) *Refresher {
+	return &Refresher{
+		interval:    defaultRefreshInterval,
+		reader:      reader,
+		lastSuccess: make(map[maintenanceKey]time.Time),
+	}
+}
+
+// Start refreshes the gauges until the context is done.
+func (r *Refresher) Start(ctx context.Context) error {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		r.refresh()
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (r *Refresher) refresh() {
+	if err := r.refreshRunningJobs(context.Background()); err != nil {
+		logrus.Warnf("failed to refresh the running jobs metric: %v", err)
+	}
+	if err := r.refreshMaintenance(); err != nil {
+		logrus.Warnf("failed to refresh the maintenance metric: %v", err)
+	}
+}
+
+func (r *Refresher) refreshRunningJobs(ctx context.Context) error {
+	jobs := &batchv1.JobList{}
+	if err := r.reader.List(ctx, jobs, client.HasLabels{drivers.DriverNameLabel}); err != nil {
+		return err
+	}
+	runningJobs.Reset()
+	for driver, count := range jobratelimit.CountJobsByDriver(jobs.Items) {
+		runningJobs.WithLabelValues(driver).Set(float64(count))
+	}
+	return nil
+}
+
+func (r *Refresher) refreshMaintenance() error {
+	list, err := kdmpSchedOps.Instance().ListBackupLocationMaintenance("", metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+
+	current := make(map[maintenanceKey]time.Time)
+	for _, blMaintenance := range list.Items {
+		r.addMaintenance(current, &blMaintenance, fullMaintenance, blMaintenance.Status.FullMaintenanceRepoStatus)
+		r.addMaintenance(current, &blMaintenance, quickMaintenance, blMaintenance.Status.QuickMaintenanceRepoStatus)
+	}
+	// Forget the repositories which are removed.
+	r.lastSuccess = current
+
+	now := time.Now()
+	maintenanceLastSuccessAge.Reset()
+	for key, lastSuccess := range r.lastSuccess {
+		maintenanceLastSuccessAge.WithLabelValues(
+			key.namespace,
+			key.backupLocation,
+			key.repository,
+			key.maintenanceType,
+		).Set(now.Sub(lastSuccess).Seconds())
+	}
+	return nil
+}
+
+func (r *Refresher) addMaintenance(
+	current map[maintenanceKey]time.Time,
+	blMaintenance *kdmpapi.BackupLocationMaintenance,
+	maintenanceType string,
+	repoStatus map[string]kdmpapi.RepoMaintenanceStatus,
+) {
+	for repo, status := range repoStatus {
+		key := maintenanceKey{
+			namespace:       blMaintenance.Namespace,
+			backupLocation:  blMaintenance.Spec.BackuplocationName,
+			repository:      repo,
+			maintenanceType: maintenanceType,
+		}
+		if status.Status == kdmpapi.RepoMaintenanceStatusSuccess {
+			current[key] = status.LastRunTimestamp.Time
+		} else if lastSuccess, ok := r.lastSuccess[key]; ok {
+			current[key] = lastSuccess
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package metrics
+
+import (
+	"context"
+	"testing"
+
+	"github.com/portworx/kdmp/pkg/drivers"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/require"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// fakeReader serves the jobs having all the labels of the list options.
+type fakeReader struct {
+	client.Reader
+	jobs []batchv1.Job
+}
+
+func (r *fakeReader) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	listOpts := &client.ListOptions{}
+	listOpts.ApplyOptions(opts)
+	jobList := list.(*batchv1.JobList)
+	for _, job := range r.jobs {
+		if listOpts.LabelSelector == nil || listOpts.LabelSelector.Matches(labelSet(job.Labels)) {
+			jobList.Items = append(jobList.Items, job)
+		}
+	}
+	return nil
+}
+
+type labelSet map[string]string
+
+func (l labelSet) Has(key string) bool {
+	_, ok := l[key]
+	return ok
+}
+
+func (l labelSet) Get(key string) string {
+	return l[key]
+}
+
+func newJob(name, driver string, completed bool) batchv1.Job {
+	job := batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	if driver != "" {
+		job.Labels = map[string]string{drivers.DriverNameLabel: driver}
+	}
+	if completed {
+		job.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: corev1.ConditionTrue}}
+	}
+	return job
+}
+
+func gaugeValue(t *testing.T, driver string) float64 {
+	metric := &dto.Metric{}
+	require.NoError(t, runningJobs.WithLabelValues(driver).Write(metric))
+	return metric.GetGauge().GetValue()
+}
+
+func TestRefreshRunningJobs(t *testing.T) {
+	reader := &fakeReader{jobs: []batchv1.Job{
+		newJob("backup-1", drivers.KopiaBackup, false),
+		newJob("backup-2", drivers.KopiaBackup, false),
+		newJob("backup-3", drivers.KopiaBackup, true),
+		newJob("restore-1", drivers.KopiaRestore, false),
+		newJob("other", "", false),
+	}}
+	r := NewRefresher(reader)
+	require.NoError(t, r.refreshRunningJobs(context.Background()))
+	require.Equal(t, float64(2), gaugeValue(t, drivers.KopiaBackup))
+	require.Equal(t, float64(1), gaugeValue(t, drivers.KopiaRestore))
+	require.Equal(t, float64(0), gaugeValue(t, ""))
+}
@@ -8,7 +8,9 @@ import (
 	"os/exec"
 	"sync"
 
+	kdmpapi "github.com/portworx/kdmp/pkg/apis/kdmp/v1alpha1"
 	cmdexec "github.com/portworx/kdmp/pkg/executor"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 // GetBackupCommand returns a wrapper over the restic backup
@@ -72,15 +74,28 @@ type BackupSummaryResponse struct {
 	SnapshotID          string  `json:"snapshot_id"`
 }
 
+// BackupErrorResponse is the json representation of the error output of
+// restic backup for an entry which could not be backed up
+type BackupErrorResponse struct {
+	MessageType string `json:"message_type"` // "error"
+	Error       struct {
+		Message string `json:"message"`
+	} `json:"error"`
+	During string `json:"during"`
+	Item   string `json:"item"`
+}
+
 type backupExecutor struct {
 	cmd             *Command
 	responseLock    sync.Mutex
 	summaryResponse *BackupSummaryResponse
+	stats           *kdmpapi.TransferStats
 	execCmd         *exec.Cmd
 	outBuf          *bytes.Buffer
 	errBuf          *bytes.Buffer
 	lastError       error
 	isRunning       bool
+	startTime       metav1.Time
 }
 
 // NewBackupExecutor returns an instance of Executor that can be used for
@@ -109,6 +124,7 @@ func (b *backupExecutor) Run() error {
 		return err
 	}
 	b.isRunning = true
+	b.startTime = metav1.Now()
 	go func() {
 		err := b.execCmd.Wait()
 		// backup has completed
@@ -127,6 +143,7 @@ func (b *backupExecutor) Run() error {
 			b.lastError = err
 			return
 		}
+		b.stats = getBackupStats(summaryResponse, b.errBuf.Bytes(), b.startTime, metav1.Now())
 		b.summaryResponse = summaryResponse
 	}()
 	return nil
@@ -152,6 +169,7 @@ func (b *backupExecutor) Status() (*cmdexec.Status, error) {
 			SnapshotID:          b.summaryResponse.SnapshotID,
 			Done:                true,
 			LastKnownError:      nil,
+			Stats:               b.stats,
 		}, nil
 	} // else backup is still in progress
 	progressResponse, err := getProgress(b.outBuf.Bytes(), b.errBuf.Bytes())
@@ -205,6 +223,31 @@ func getSummary(outBytes []byte, errBytes []byte) (*BackupSummaryResponse, error
 	}
 }
 
+// getBackupStats returns the statistics of a backup from its summary and
+// the errors printed on stderr. restic doesn't report the cached and hashed
+// bytes.
+func getBackupStats(summary *BackupSummaryResponse, errBytes []byte, startTime, endTime metav1.Time) *kdmpapi.TransferStats {
+	stats := &kdmpapi.TransferStats{
+		FileCount:     int64(summary.TotalFilesProcessed),
+		DirCount:      int64(summary.DirsNew + summary.DirsChanged + summary.DirsUnmodified),
+		CachedFiles:   int64(summary.FilesUnmodified),
+		HashedFiles:   int64(summary.FilesNew + summary.FilesChanged),
+		UploadedBytes: summary.DataAdded,
+		StartTime:     &startTime,
+		EndTime:       &endTime,
+	}
+	for _, line := range bytes.Split(errBytes, []byte("\n")) {
+		errResponse := &BackupErrorResponse{}
+		if err := json.Unmarshal(line, errResponse); err != nil || errResponse.MessageType != "error" {
+			continue
+		}
+		stats.ErrorCount++
+		cmdexec.AddTransferError(stats, errResponse.Item, errResponse.Error.Message)
+	}
+	cmdexec.SetTransferRates(stats, summary.TotalBytesProcessed)
+	return stats
+}
+
 func getProgress(outBytes []byte, errBytes []byte) (*BackupProgressResponse, error) {
 	outLines := bytes.Split(outBytes, []byte("\n"))
 	if len(outLines) <= 2 {
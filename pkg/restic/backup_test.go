@@ -9,8 +9,10 @@ import (
 	"testing"
 	"time"
 
+	kdmpapi "github.com/portworx/kdmp/pkg/apis/kdmp/v1alpha1"
 	cmdexec "github.com/portworx/kdmp/pkg/executor"
 	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 const (
@@ -113,6 +115,35 @@ func TestGetSummary(t *testing.T) {
 	require.Equal(t, "9310620e", backupSummary.SnapshotID, "unexpected snapshot_id")
 }
 
+func TestGetBackupStats(t *testing.T) {
+	summary := &BackupSummaryResponse{
+		FilesNew:            2,
+		FilesChanged:        1,
+		FilesUnmodified:     7,
+		DirsNew:             1,
+		DirsUnmodified:      2,
+		DataAdded:           2500000,
+		TotalFilesProcessed: 10,
+		TotalBytesProcessed: 10000000,
+	}
+	errBytes := []byte(`{"message_type":"error","error":{"message":"open /tmp/source/a: permission denied"},"during":"archival","item":"/tmp/source/a"}
+Warning: at least one source file could not be read
+`)
+	startTime := metav1.NewTime(time.Unix(1000, 0))
+	endTime := metav1.NewTime(time.Unix(1010, 0))
+
+	stats := getBackupStats(summary, errBytes, startTime, endTime)
+	require.Equal(t, int64(10), stats.FileCount)
+	require.Equal(t, int64(3), stats.DirCount)
+	require.Equal(t, int64(7), stats.CachedFiles)
+	require.Equal(t, int64(3), stats.HashedFiles)
+	require.Equal(t, uint64(2500000), stats.UploadedBytes)
+	require.Equal(t, float64(4), stats.DeduplicationRatio)
+	require.Equal(t, uint64(1000000), stats.Throughput)
+	require.Equal(t, int64(1), stats.ErrorCount)
+	require.Equal(t, []kdmpapi.TransferError{{Path: "/tmp/source/a", Error: "open /tmp/source/a: permission denied"}}, stats.Errors)
+}
+
 func TestGetSummaryNotAvailable(t *testing.T) {
 	testData := `
 `
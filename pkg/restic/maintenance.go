@@ -0,0 +1,121 @@
+package restic
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"sync"
+
+	cmdexec "github.com/portworx/kdmp/pkg/executor"
+)
+
+// GetForgetCommand returns a wrapper over the restic forget command. The
+// snapshots not matched by the keep policy are removed and the data no longer
+// referenced is pruned from the repository.
+func GetForgetCommand(repoName string, secretFilePath string, policy []string) (*Command, error) {
+	if repoName == "" {
+		return nil, fmt.Errorf("repository name cannot be empty")
+	}
+	if secretFilePath == "" {
+		return nil, fmt.Errorf("secret file path cannot be empty")
+	}
+	if len(policy) == 0 {
+		return nil, fmt.Errorf("forget policy cannot be empty")
+	}
+
+	return &Command{
+		Name:           "forget",
+		RepositoryName: repoName,
+		SecretFilePath: secretFilePath,
+		Flags:          append([]string{"--prune"}, policy...),
+	}, nil
+}
+
+// GetCheckCommand returns a wrapper over the restic check command.
+func GetCheckCommand(repoName string, secretFilePath string) (*Command, error) {
+	if repoName == "" {
+		return nil, fmt.Errorf("repository name cannot be empty")
+	}
+	if secretFilePath == "" {
+		return nil, fmt.Errorf("secret file path cannot be empty")
+	}
+
+	return &Command{
+		Name:           "check",
+		RepositoryName: repoName,
+		SecretFilePath: secretFilePath,
+	}, nil
+}
+
+type maintenanceExecutor struct {
+	cmd          *Command
+	responseLock sync.Mutex
+	execCmd      *exec.Cmd
+	outBuf       *bytes.Buffer
+	errBuf       *bytes.Buffer
+	lastError    error
+	isRunning    bool
+	done         bool
+}
+
+// NewMaintenanceExecutor returns an instance of Executor that can be used for
+// running restic forget and check commands
+func NewMaintenanceExecutor(cmd *Command) Executor {
+	return &maintenanceExecutor{
+		cmd:    cmd,
+		outBuf: new(bytes.Buffer),
+		errBuf: new(bytes.Buffer),
+	}
+}
+
+func (b *maintenanceExecutor) Run() error {
+	b.responseLock.Lock()
+	defer b.responseLock.Unlock()
+
+	if b.isRunning {
+		return fmt.Errorf("another %s operation is already running", b.cmd.Name)
+	}
+
+	b.execCmd = b.cmd.Cmd()
+	b.execCmd.Stdout = b.outBuf
+	b.execCmd.Stderr = b.errBuf
+
+	if err := b.execCmd.Start(); err != nil {
+		b.lastError = err
+		return err
+	}
+	b.isRunning = true
+	go func() {
+		err := b.execCmd.Wait()
+		b.responseLock.Lock()
+		defer b.responseLock.Unlock()
+		b.isRunning = false
+		if err != nil {
+			b.lastError = fmt.Errorf("failed to run the %s command: %v", b.cmd.Name, err)
+			if stdErr := parseStdErr(b.errBuf.Bytes()); stdErr != nil {
+				b.lastError = stdErr
+			}
+			return
+		}
+		b.done = true
+	}()
+	return nil
+}
+
+func (b *maintenanceExecutor) Status() (*cmdexec.Status, error) {
+	b.responseLock.Lock()
+	defer b.responseLock.Unlock()
+
+	if b.lastError != nil {
+		fmt.Fprintln(os.Stderr, b.errBuf.String())
+		return &cmdexec.Status{
+			LastKnownError: b.lastError,
+			Done:           true,
+		}, nil
+	}
+
+	return &cmdexec.Status{
+		Done: b.done,
+	}, nil
+}
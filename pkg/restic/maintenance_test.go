@@ -0,0 +1,65 @@
+package restic
+
+import (
+	"fmt"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetForgetCommand(t *testing.T) {
+	testCases := []struct {
+		name        string
+		repo        string
+		secretPath  string
+		policy      []string
+		expectedErr error
+		expectedCmd *exec.Cmd
+	}{
+		{
+			name:        "repoName_is_not_provided",
+			expectedErr: fmt.Errorf("repository name cannot be empty"),
+		},
+		{
+			name:        "secretFilePass_is_not_provided",
+			repo:        "test",
+			expectedErr: fmt.Errorf("secret file path cannot be empty"),
+		},
+		{
+			name:        "no_policy",
+			repo:        "test",
+			secretPath:  "secret/path",
+			expectedErr: fmt.Errorf("forget policy cannot be empty"),
+		},
+		{
+			name:       "with_policy",
+			repo:       "test",
+			secretPath: "secret/path",
+			policy:     []string{"--keep-last", "5"},
+			expectedCmd: exec.Command("restic", "forget", "--repo", "test", "--password-file", "secret/path",
+				"--prune", "--keep-last", "5"),
+		},
+	}
+
+	for _, tc := range testCases {
+		forget, err := GetForgetCommand(tc.repo, tc.secretPath, tc.policy)
+
+		require.Equalf(t, tc.expectedErr, err, "TC: %s", tc.name)
+		if err == nil {
+			require.Equalf(t, tc.expectedCmd, forget.Cmd(), "TC: %s", tc.name)
+		}
+	}
+}
+
+func TestGetCheckCommand(t *testing.T) {
+	_, err := GetCheckCommand("", "secret/path")
+	require.Equal(t, fmt.Errorf("repository name cannot be empty"), err)
+
+	_, err = GetCheckCommand("test", "")
+	require.Equal(t, fmt.Errorf("secret file path cannot be empty"), err)
+
+	check, err := GetCheckCommand("test", "secret/path")
+	require.NoError(t, err)
+	require.Equal(t, exec.Command("restic", "check", "--repo", "test", "--password-file", "secret/path"), check.Cmd())
+}
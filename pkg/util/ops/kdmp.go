@@ -22,6 +22,8 @@ var (
 type Ops interface {
 	DataExportOps
 	VolumeBackupOps
+	RestoreTransformationOps
+	RepositoryStatusOps
 
 	// SetConfig sets the config and resets the client
 	SetConfig(config *rest.Config)
@@ -0,0 +1,52 @@
+package ops
+
+import (
+	"context"
+
+	kdmpv1alpha1 "github.com/portworx/kdmp/pkg/apis/kdmp/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RepositoryStatusOps is an interface to perform k8s RepositoryStatus operations
+type RepositoryStatusOps interface {
+	// CreateRepositoryStatus creates the RepositoryStatus
+	CreateRepositoryStatus(ctx context.Context, repositoryStatus *kdmpv1alpha1.RepositoryStatus) (*kdmpv1alpha1.RepositoryStatus, error)
+	// GetRepositoryStatus gets the RepositoryStatus
+	GetRepositoryStatus(ctx context.Context, name string, namespace string) (*kdmpv1alpha1.RepositoryStatus, error)
+	// ListRepositoryStatuses lists all the RepositoryStatuses
+	ListRepositoryStatuses(ctx context.Context, namespace string) (*kdmpv1alpha1.RepositoryStatusList, error)
+	// UpdateRepositoryStatus updates the RepositoryStatus
+	UpdateRepositoryStatus(ctx context.Context, repositoryStatus *kdmpv1alpha1.RepositoryStatus) (*kdmpv1alpha1.RepositoryStatus, error)
+}
+
+// CreateRepositoryStatus creates the RepositoryStatus
+func (c *Client) CreateRepositoryStatus(ctx context.Context, repositoryStatus *kdmpv1alpha1.RepositoryStatus) (*kdmpv1alpha1.RepositoryStatus, error) {
+	if err := c.initClient(); err != nil {
+		return nil, err
+	}
+	return c.kdmp.KdmpV1alpha1().RepositoryStatuses(repositoryStatus.Namespace).Create(ctx, repositoryStatus, metav1.CreateOptions{})
+}
+
+// GetRepositoryStatus gets the RepositoryStatus
+func (c *Client) GetRepositoryStatus(ctx context.Context, name string, namespace string) (*kdmpv1alpha1.RepositoryStatus, error) {
+	if err := c.initClient(); err != nil {
+		return nil, err
+	}
+	return c.kdmp.KdmpV1alpha1().RepositoryStatuses(namespace).Get(ctx, name, metav1.GetOptions{})
+}
+
+// ListRepositoryStatuses lists all the RepositoryStatuses
+func (c *Client) ListRepositoryStatuses(ctx context.Context, namespace string) (*kdmpv1alpha1.RepositoryStatusList, error) {
+	if err := c.initClient(); err != nil {
+		return nil, err
+	}
+	return c.kdmp.KdmpV1alpha1().RepositoryStatuses(namespace).List(ctx, metav1.ListOptions{})
+}
+
+// UpdateRepositoryStatus updates the RepositoryStatus
+func (c *Client) UpdateRepositoryStatus(ctx context.Context, repositoryStatus *kdmpv1alpha1.RepositoryStatus) (*kdmpv1alpha1.RepositoryStatus, error) {
+	if err := c.initClient(); err != nil {
+		return nil, err
+	}
+	return c.kdmp.KdmpV1alpha1().RepositoryStatuses(repositoryStatus.Namespace).Update(ctx, repositoryStatus, metav1.UpdateOptions{})
+}
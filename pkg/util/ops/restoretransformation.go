@@ -0,0 +1,22 @@
+package ops
+
+import (
+	"context"
+
+	kdmpv1alpha1 "github.com/portworx/kdmp/pkg/apis/kdmp/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RestoreTransformationOps is an interface to perform k8s RestoreTransformation operations
+type RestoreTransformationOps interface {
+	// GetRestoreTransformation gets the RestoreTransformation
+	GetRestoreTransformation(ctx context.Context, name string, namespace string) (*kdmpv1alpha1.RestoreTransformation, error)
+}
+
+// GetRestoreTransformation gets the RestoreTransformation
+func (c *Client) GetRestoreTransformation(ctx context.Context, name string, namespace string) (*kdmpv1alpha1.RestoreTransformation, error) {
+	if err := c.initClient(); err != nil {
+		return nil, err
+	}
+	return c.kdmp.KdmpV1alpha1().RestoreTransformations(namespace).Get(ctx, name, metav1.GetOptions{})
+}